@@ -2,13 +2,26 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
 )
 
 type Config struct {
-	Port     string
-	Database DatabaseConfig
-	JWT      JWTConfig
-	Env      string
+	Port         string
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	WebSocket    WebSocketConfig
+	Payment      PaymentConfig
+	Storage      StorageConfig
+	Delivery     DeliveryConfig
+	Assignment   AssignmentConfig
+	Store        StoreConfig
+	Portal       PortalConfig
+	SMS          SMSConfig
+	OTP          OTPConfig
+	Loyalty      LoyaltyConfig
+	Availability AvailabilityConfig
+	Env          string
 }
 
 type DatabaseConfig struct {
@@ -23,6 +36,83 @@ type JWTConfig struct {
 	SecretKey string
 }
 
+type WebSocketConfig struct {
+	AllowedOrigins []string
+}
+
+// DefaultPaymentMockSecret is the placeholder mock gateway secret, only accepted in development
+const DefaultPaymentMockSecret = "mock-payment-secret-change-in-production"
+
+type PaymentConfig struct {
+	Provider   string // Empty disables online payments, "mock" runs the local HMAC-signed gateway for momo/vnpay
+	AllowMock  bool   // Allow the mock gateway outside development
+	MockSecret string
+	PublicURL  string // Public URL of this API, used for gateway return/IPN URLs
+	ReturnURL  string // Frontend page the customer lands on after paying
+}
+
+// MockEnabled reports whether the mock gateway and its checkout page may run.
+// Anyone with a pay URL can mark a mock payment as paid, so it is limited to
+// development unless explicitly allowed.
+func (c PaymentConfig) MockEnabled(env string) bool {
+	return c.Provider == "mock" && (env == "development" || c.AllowMock)
+}
+
+type StorageConfig struct {
+	Driver   string // "local" stores uploads on disk, they are only served through authenticated routes
+	LocalDir string
+}
+
+type DeliveryConfig struct {
+	ProofRequired []string // Proof needed before a delivery can be marked delivered: photo, signature, location
+}
+
+type AssignmentConfig struct {
+	Strategy string // round_robin, least_active or zone
+	Mode     string // off, propose or auto when an order becomes ready_for_delivery
+}
+
+type StoreConfig struct {
+	Latitude  float64 // Store location, center of radius delivery zones
+	Longitude float64
+	Timezone  string // IANA zone menus and price list schedules are in, e.g. Asia/Ho_Chi_Minh
+}
+
+type PortalConfig struct {
+	RateLimit         int      // Requests per minute per client IP on public customer portal routes
+	CheckoutRateLimit int      // Checkout and order tracking requests per minute per client IP
+	TrustedProxies    []string // Reverse proxies whose X-Forwarded-For is believed, empty trusts none
+}
+
+type SMSConfig struct {
+	Driver     string // "console" logs messages, "file" appends them to FilePath
+	AllowLocal bool   // Allow the console and file drivers outside development
+	FilePath   string
+}
+
+// LocalEnabled reports whether the console or file driver may run. They expose
+// OTP codes to anyone reading the log or file, so they are limited to
+// development unless explicitly allowed.
+func (c SMSConfig) LocalEnabled(env string) bool {
+	return env == "development" || c.AllowLocal
+}
+
+type OTPConfig struct {
+	Length          int
+	TTLSeconds      int
+	MaxAttempts     int // Wrong codes allowed per code
+	ResendSeconds   int // Minimum delay between two codes to the same phone
+	MaxSendsPerHour int // Codes per phone per hour
+}
+
+type LoyaltyConfig struct {
+	TierRecalcHours int // Hours between loyalty tier recalculations, 0 disables the periodic run
+}
+
+type AvailabilityConfig struct {
+	CheckSeconds int // Seconds between checks for back-at times and stock driven sell outs, 0 disables them
+}
+
 func LoadConfig() *Config {
 	return &Config{
 		Port: getEnv("PORT", "8080"),
@@ -36,6 +126,55 @@ func LoadConfig() *Config {
 		JWT: JWTConfig{
 			SecretKey: getEnv("JWT_SECRET_KEY", "your-super-secret-jwt-key-change-in-production"),
 		},
+		WebSocket: WebSocketConfig{
+			AllowedOrigins: getEnvList("WS_ALLOWED_ORIGINS", "http://localhost:5173,http://localhost:3000"),
+		},
+		Payment: PaymentConfig{
+			Provider:   getEnv("PAYMENT_PROVIDER", ""),
+			AllowMock:  getEnvBool("PAYMENT_ALLOW_MOCK", false),
+			MockSecret: getEnv("PAYMENT_MOCK_SECRET", DefaultPaymentMockSecret),
+			PublicURL:  getEnv("PAYMENT_PUBLIC_URL", "http://localhost:8080"),
+			ReturnURL:  getEnv("PAYMENT_RETURN_URL", "http://localhost:5173/payment-result"),
+		},
+		Storage: StorageConfig{
+			Driver:   getEnv("STORAGE_DRIVER", "local"),
+			LocalDir: getEnv("STORAGE_LOCAL_DIR", "./uploads"),
+		},
+		Delivery: DeliveryConfig{
+			ProofRequired: getEnvList("DELIVERY_PROOF_REQUIRED", "photo"),
+		},
+		Assignment: AssignmentConfig{
+			Strategy: getEnv("ASSIGNMENT_STRATEGY", "least_active"),
+			Mode:     getEnv("ASSIGNMENT_MODE", "propose"),
+		},
+		Store: StoreConfig{
+			Latitude:  getEnvFloat("STORE_LATITUDE", 10.776889),
+			Longitude: getEnvFloat("STORE_LONGITUDE", 106.700806),
+			Timezone:  getEnv("STORE_TIMEZONE", "Asia/Ho_Chi_Minh"),
+		},
+		Portal: PortalConfig{
+			RateLimit:         getEnvInt("PORTAL_RATE_LIMIT", 60),
+			CheckoutRateLimit: getEnvInt("PORTAL_CHECKOUT_RATE_LIMIT", 10),
+			TrustedProxies:    getEnvList("TRUSTED_PROXIES", ""),
+		},
+		SMS: SMSConfig{
+			Driver:     getEnv("SMS_DRIVER", "console"),
+			AllowLocal: getEnvBool("SMS_ALLOW_LOCAL", false),
+			FilePath:   getEnv("SMS_FILE_PATH", "./sms.log"),
+		},
+		OTP: OTPConfig{
+			Length:          getEnvInt("OTP_LENGTH", 6),
+			TTLSeconds:      getEnvInt("OTP_TTL_SECONDS", 300),
+			MaxAttempts:     getEnvInt("OTP_MAX_ATTEMPTS", 5),
+			ResendSeconds:   getEnvInt("OTP_RESEND_SECONDS", 60),
+			MaxSendsPerHour: getEnvInt("OTP_MAX_SENDS_PER_HOUR", 5),
+		},
+		Loyalty: LoyaltyConfig{
+			TierRecalcHours: getEnvInt("LOYALTY_TIER_RECALC_HOURS", 24),
+		},
+		Availability: AvailabilityConfig{
+			CheckSeconds: getEnvInt("AVAILABILITY_CHECK_SECONDS", 30),
+		},
 		Env: getEnv("ENV", "development"),
 	}
 }
@@ -45,4 +184,36 @@ func getEnv(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-} 
\ No newline at end of file
+}
+
+// getEnvList reads a comma-separated environment variable
+func getEnvList(key, defaultValue string) []string {
+	values := make([]string, 0)
+	for _, value := range strings.Split(getEnv(key, defaultValue), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
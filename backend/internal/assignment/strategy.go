@@ -0,0 +1,119 @@
+package assignment
+
+import (
+	"fmt"
+	"sort"
+
+	"food-pos-backend/internal/model"
+)
+
+// Strategy names
+const (
+	StrategyRoundRobin  = "round_robin"
+	StrategyLeastActive = "least_active"
+	StrategyZone        = "zone"
+)
+
+// Strategy picks a shipper for an order among the eligible candidates
+type Strategy interface {
+	Name() string
+	// Pick returns nil when no candidate suits the order
+	Pick(order *model.Order, candidates []model.ShipperCandidate) *model.ShipperCandidate
+}
+
+// NewStrategy creates a strategy by name
+func NewStrategy(name string) (Strategy, error) {
+	switch name {
+	case StrategyRoundRobin:
+		return RoundRobinStrategy{}, nil
+	case StrategyLeastActive:
+		return LeastActiveStrategy{}, nil
+	case StrategyZone:
+		return ZoneStrategy{Fallback: LeastActiveStrategy{}}, nil
+	default:
+		return nil, fmt.Errorf("unknown assignment strategy %q", name)
+	}
+}
+
+// RoundRobinStrategy rotates through shippers: the one assigned longest ago goes next
+type RoundRobinStrategy struct{}
+
+func (RoundRobinStrategy) Name() string { return StrategyRoundRobin }
+
+func (RoundRobinStrategy) Pick(order *model.Order, candidates []model.ShipperCandidate) *model.ShipperCandidate {
+	return first(candidates, func(a, b model.ShipperCandidate) bool {
+		return assignedBefore(a, b)
+	})
+}
+
+// LeastActiveStrategy picks the shipper with the fewest deliveries in progress
+type LeastActiveStrategy struct{}
+
+func (LeastActiveStrategy) Name() string { return StrategyLeastActive }
+
+func (LeastActiveStrategy) Pick(order *model.Order, candidates []model.ShipperCandidate) *model.ShipperCandidate {
+	return first(candidates, func(a, b model.ShipperCandidate) bool {
+		if a.ActiveDeliveries != b.ActiveDeliveries {
+			return a.ActiveDeliveries < b.ActiveDeliveries
+		}
+		return assignedBefore(a, b)
+	})
+}
+
+// ZoneStrategy only considers shippers of the order's delivery zone and lets the
+// fallback strategy choose among them. Orders without a zone use every candidate
+type ZoneStrategy struct {
+	Fallback Strategy
+}
+
+func (ZoneStrategy) Name() string { return StrategyZone }
+
+func (s ZoneStrategy) Pick(order *model.Order, candidates []model.ShipperCandidate) *model.ShipperCandidate {
+	if order.DeliveryZone == nil || *order.DeliveryZone == "" {
+		return s.Fallback.Pick(order, candidates)
+	}
+
+	inZone := make([]model.ShipperCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate.Zone != nil && *candidate.Zone == *order.DeliveryZone {
+			inZone = append(inZone, candidate)
+		}
+	}
+	return s.Fallback.Pick(order, inZone)
+}
+
+// first returns the first candidate in the given order, nil if there is none
+func first(candidates []model.ShipperCandidate, less func(a, b model.ShipperCandidate) bool) *model.ShipperCandidate {
+	if len(candidates) == 0 {
+		return nil
+	}
+	sorted := make([]model.ShipperCandidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+	return &sorted[0]
+}
+
+// assignedBefore orders shippers never assigned first, then by last assignment time
+func assignedBefore(a, b model.ShipperCandidate) bool {
+	switch {
+	case a.LastAssignedAt == nil && b.LastAssignedAt == nil:
+		return a.ID < b.ID
+	case a.LastAssignedAt == nil:
+		return true
+	case b.LastAssignedAt == nil:
+		return false
+	case !a.LastAssignedAt.Equal(*b.LastAssignedAt):
+		return a.LastAssignedAt.Before(*b.LastAssignedAt)
+	default:
+		return a.ID < b.ID
+	}
+}
+
+// Modes of the assignment engine when an order becomes ready for delivery
+const (
+	ModeOff     = "off"     // Không chạy tự động
+	ModePropose = "propose" // Gợi ý shipper cho admin
+	ModeAuto    = "auto"    // Tự động gán shipper
+)
@@ -30,7 +30,7 @@ type CreateUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Phone    string `json:"phone"`
 	Password string `json:"password" binding:"required,min=6"`
-	Role     string `json:"role" binding:"required,oneof=admin staff customer"`
+	Role     string `json:"role" binding:"required,oneof=admin staff customer kitchen"`
 	IsActive bool   `json:"is_active"`
 }
 
@@ -39,7 +39,7 @@ type UpdateUserRequest struct {
 	Email    string `json:"email" binding:"required,email"`
 	Phone    string `json:"phone"`
 	Password string `json:"password"` // Optional
-	Role     string `json:"role" binding:"required,oneof=admin staff customer"`
+	Role     string `json:"role" binding:"required,oneof=admin staff customer kitchen"`
 	IsActive bool   `json:"is_active"`
 }
 
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AssignmentHandler struct {
+	assignmentService *service.AssignmentService
+	shipperService    *service.ShipperService
+	userRepo          *repository.UserRepository
+}
+
+func NewAssignmentHandler(assignmentService *service.AssignmentService, shipperService *service.ShipperService, userRepo *repository.UserRepository) *AssignmentHandler {
+	return &AssignmentHandler{
+		assignmentService: assignmentService,
+		shipperService:    shipperService,
+		userRepo:          userRepo,
+	}
+}
+
+// ListEligibleShippers lists the on shift shippers below their concurrent delivery limit
+func (h *AssignmentHandler) ListEligibleShippers(c *gin.Context) {
+	candidates, err := h.assignmentService.ListEligibleShippers(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get eligible shippers: "+err.Error())
+		return
+	}
+
+	response.Success(c, candidates, "Eligible shippers retrieved successfully")
+}
+
+// ProposeShipper returns the shipper the assignment engine would pick for an order
+func (h *AssignmentHandler) ProposeShipper(c *gin.Context) {
+	proposal, err := h.assignmentService.ProposeShipper(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Order not found", "Failed to propose shipper: ")
+		return
+	}
+
+	response.Success(c, proposal, "Shipper proposal retrieved successfully")
+}
+
+// AutoAssign assigns the shipper picked by the assignment engine to an order
+func (h *AssignmentHandler) AutoAssign(c *gin.Context) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return
+	}
+
+	proposal, err := h.assignmentService.AutoAssign(c.Request.Context(), c.Param("id"), user.ID)
+	if err != nil {
+		h.handleError(c, err, "Order not found", "Failed to assign shipper: ")
+		return
+	}
+
+	response.Success(c, proposal, "Shipper assigned successfully")
+}
+
+// UpdateShipperAvailability updates the shift, concurrent delivery limit and zone of a shipper
+func (h *AssignmentHandler) UpdateShipperAvailability(c *gin.Context) {
+	var req model.UpdateShipperAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	shipper, err := h.shipperService.UpdateAvailability(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Shipper not found", "Failed to update shipper availability: ")
+		return
+	}
+
+	response.Success(c, model.ShipperResponse{Shipper: *shipper}, "Shipper availability updated successfully")
+}
+
+func (h *AssignmentHandler) handleError(c *gin.Context, err error, notFoundMessage, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, notFoundMessage)
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AvailabilityHandler struct {
+	availabilityService *service.AvailabilityService
+}
+
+func NewAvailabilityHandler(availabilityService *service.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{availabilityService: availabilityService}
+}
+
+// ListAvailability lists whether each variant can be sold right now
+func (h *AvailabilityHandler) ListAvailability(c *gin.Context) {
+	variants, err := h.availabilityService.ListAvailability(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get availability: "+err.Error())
+		return
+	}
+
+	response.Success(c, variants, "Availability retrieved successfully")
+}
+
+// SetProductAvailability marks a whole product as available or sold out
+func (h *AvailabilityHandler) SetProductAvailability(c *gin.Context) {
+	var req model.UpdateAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	variants, err := h.availabilityService.SetProductAvailability(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Product not found", "Failed to update product availability: ")
+		return
+	}
+
+	response.Success(c, variants, "Product availability updated successfully")
+}
+
+// SetVariantAvailability marks a single variant as available or sold out
+func (h *AvailabilityHandler) SetVariantAvailability(c *gin.Context) {
+	var req model.UpdateVariantAvailabilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	variant, err := h.availabilityService.SetVariantAvailability(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Variant not found", "Failed to update variant availability: ")
+		return
+	}
+
+	response.Success(c, variant, "Variant availability updated successfully")
+}
+
+func (h *AvailabilityHandler) handleError(c *gin.Context, err error, notFound, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, notFound)
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
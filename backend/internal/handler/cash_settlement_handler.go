@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CashSettlementHandler struct {
+	settlementService *service.CashSettlementService
+	userRepo          *repository.UserRepository
+}
+
+func NewCashSettlementHandler(settlementService *service.CashSettlementService, userRepo *repository.UserRepository) *CashSettlementHandler {
+	return &CashSettlementHandler{
+		settlementService: settlementService,
+		userRepo:          userRepo,
+	}
+}
+
+// ListShipperCashBalances lists the COD cash held by each shipper
+func (h *CashSettlementHandler) ListShipperCashBalances(c *gin.Context) {
+	balances, err := h.settlementService.ListShipperCashBalances(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get shipper cash balances: "+err.Error())
+		return
+	}
+
+	response.Success(c, balances, "Shipper cash balances retrieved successfully")
+}
+
+// GetShipperCashBalance returns the outstanding COD deliveries of a shipper
+func (h *CashSettlementHandler) GetShipperCashBalance(c *gin.Context) {
+	balance, err := h.settlementService.GetShipperCashBalance(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Shipper not found", "Failed to get shipper cash balance: ")
+		return
+	}
+
+	response.Success(c, balance, "Shipper cash balance retrieved successfully")
+}
+
+// SettleShipperCash confirms the cash handed over by a shipper
+func (h *CashSettlementHandler) SettleShipperCash(c *gin.Context) {
+	var req model.CreateCashSettlementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	settlement, err := h.settlementService.SettleShipperCash(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Shipper not found", "Failed to settle shipper cash: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Shipper cash settled successfully", settlement)
+}
+
+// ListSettlements lists cash settlements (has_discrepancy=true for the discrepancy report)
+func (h *CashSettlementHandler) ListSettlements(c *gin.Context) {
+	var req model.ListCashSettlementsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "Invalid query parameters")
+		return
+	}
+
+	resp, err := h.settlementService.ListSettlements(c.Request.Context(), &req)
+	if err != nil {
+		response.InternalServerError(c, "Failed to list cash settlements: "+err.Error())
+		return
+	}
+
+	response.Success(c, resp, "Cash settlements retrieved successfully")
+}
+
+// GetSettlement returns a settlement with the deliveries it covers
+func (h *CashSettlementHandler) GetSettlement(c *gin.Context) {
+	settlement, err := h.settlementService.GetSettlement(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Cash settlement not found", "Failed to get cash settlement: ")
+		return
+	}
+
+	response.Success(c, settlement, "Cash settlement retrieved successfully")
+}
+
+func (h *CashSettlementHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *CashSettlementHandler) handleError(c *gin.Context, err error, notFoundMessage, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, notFoundMessage)
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
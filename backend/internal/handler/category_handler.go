@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CategoryHandler struct {
+	categoryService *service.CategoryService
+}
+
+func NewCategoryHandler(categoryService *service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+// CreateCategory creates a new category
+func (h *CategoryHandler) CreateCategory(c *gin.Context) {
+	var req model.CreateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	category, err := h.categoryService.CreateCategory(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create category: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Category created successfully", category)
+}
+
+// ListCategories lists the categories as a tree
+func (h *CategoryHandler) ListCategories(c *gin.Context) {
+	categories, err := h.categoryService.ListCategories(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get categories: "+err.Error())
+		return
+	}
+
+	response.Success(c, categories, "Categories retrieved successfully")
+}
+
+// GetCategory gets a category by ID
+func (h *CategoryHandler) GetCategory(c *gin.Context) {
+	category, err := h.categoryService.GetCategory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get category: ")
+		return
+	}
+
+	response.Success(c, category, "Category retrieved successfully")
+}
+
+// UpdateCategory updates a category
+func (h *CategoryHandler) UpdateCategory(c *gin.Context) {
+	var req model.UpdateCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	category, err := h.categoryService.UpdateCategory(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update category: ")
+		return
+	}
+
+	response.Success(c, category, "Category updated successfully")
+}
+
+// DeleteCategory deletes a category without subcategories
+func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
+	if err := h.categoryService.DeleteCategory(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "Failed to delete category: ")
+		return
+	}
+
+	response.Success(c, nil, "Category deleted successfully")
+}
+
+// ReorderCategories sets the order of sibling categories
+func (h *CategoryHandler) ReorderCategories(c *gin.Context) {
+	var req model.ReorderCategoriesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	if err := h.categoryService.ReorderCategories(c.Request.Context(), &req); err != nil {
+		h.handleError(c, err, "Failed to reorder categories: ")
+		return
+	}
+
+	response.Success(c, nil, "Categories reordered successfully")
+}
+
+// SetCategoryProducts sets the products of a category in menu order
+func (h *CategoryHandler) SetCategoryProducts(c *gin.Context) {
+	var req model.SetCategoryProductsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	category, err := h.categoryService.SetCategoryProducts(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to set category products: ")
+		return
+	}
+
+	response.Success(c, category, "Category products updated successfully")
+}
+
+func (h *CategoryHandler) handleError(c *gin.Context, err error, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Category not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomerAddressHandler struct {
+	addressService *service.CustomerAddressService
+}
+
+func NewCustomerAddressHandler(addressService *service.CustomerAddressService) *CustomerAddressHandler {
+	return &CustomerAddressHandler{addressService: addressService}
+}
+
+// ListAddresses lists the saved addresses of a user
+func (h *CustomerAddressHandler) ListAddresses(c *gin.Context) {
+	addresses, err := h.addressService.ListAddresses(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get addresses: ")
+		return
+	}
+
+	response.Success(c, addresses, "Addresses retrieved successfully")
+}
+
+// CreateAddress saves a new address for a user
+func (h *CustomerAddressHandler) CreateAddress(c *gin.Context) {
+	var req model.CustomerAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	address, err := h.addressService.CreateAddress(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create address: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Address created successfully", address)
+}
+
+// UpdateAddress updates a saved address of a user
+func (h *CustomerAddressHandler) UpdateAddress(c *gin.Context) {
+	var req model.CustomerAddressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(c.Request.Context(), c.Param("id"), c.Param("addressId"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update address: ")
+		return
+	}
+
+	response.Success(c, address, "Address updated successfully")
+}
+
+// SetDefaultAddress makes a saved address the default address of a user
+func (h *CustomerAddressHandler) SetDefaultAddress(c *gin.Context) {
+	address, err := h.addressService.SetDefaultAddress(c.Request.Context(), c.Param("id"), c.Param("addressId"))
+	if err != nil {
+		h.handleError(c, err, "Failed to set default address: ")
+		return
+	}
+
+	response.Success(c, address, "Default address updated successfully")
+}
+
+// DeleteAddress deletes a saved address of a user
+func (h *CustomerAddressHandler) DeleteAddress(c *gin.Context) {
+	if err := h.addressService.DeleteAddress(c.Request.Context(), c.Param("id"), c.Param("addressId")); err != nil {
+		h.handleError(c, err, "Failed to delete address: ")
+		return
+	}
+
+	response.Success(c, nil, "Address deleted successfully")
+}
+
+func (h *CustomerAddressHandler) handleError(c *gin.Context, err error, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Address not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CustomerAuthHandler serves customer registration and login on the portal
+type CustomerAuthHandler struct {
+	authService *service.CustomerAuthService
+}
+
+func NewCustomerAuthHandler(authService *service.CustomerAuthService) *CustomerAuthHandler {
+	return &CustomerAuthHandler{authService: authService}
+}
+
+// POST /api/public/auth/register
+func (h *CustomerAuthHandler) Register(c *gin.Context) {
+	var req model.CustomerRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Thông tin đăng ký không hợp lệ")
+		return
+	}
+
+	result, err := h.authService.Register(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Customer registered successfully", result)
+}
+
+// POST /api/public/auth/login
+func (h *CustomerAuthHandler) Login(c *gin.Context) {
+	var req model.CustomerLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Thông tin đăng nhập không hợp lệ")
+		return
+	}
+
+	result, err := h.authService.Login(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Customer login successful")
+}
+
+// POST /api/public/auth/register/otp
+func (h *CustomerAuthHandler) RequestRegisterOTP(c *gin.Context) {
+	var req model.OTPSendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Số điện thoại không hợp lệ")
+		return
+	}
+
+	result, err := h.authService.RequestRegisterOTP(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, result, "OTP sent successfully")
+}
+
+// POST /api/public/auth/otp/request
+func (h *CustomerAuthHandler) RequestLoginOTP(c *gin.Context) {
+	var req model.OTPSendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Số điện thoại không hợp lệ")
+		return
+	}
+
+	result, err := h.authService.RequestLoginOTP(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, result, "OTP sent successfully")
+}
+
+// POST /api/public/auth/otp/verify
+func (h *CustomerAuthHandler) LoginWithOTP(c *gin.Context) {
+	var req model.OTPVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Thông tin đăng nhập không hợp lệ")
+		return
+	}
+
+	result, err := h.authService.LoginWithOTP(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, result, "Customer login successful")
+}
+
+// GetProfile returns the logged in customer
+func (h *CustomerAuthHandler) GetProfile(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	account, err := h.authService.GetProfile(c.Request.Context(), userID.(string))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, account, "Customer fetched successfully")
+}
+
+// handleError maps service errors to responses; internal errors are logged but
+// not shown to customers
+func (h *CustomerAuthHandler) handleError(c *gin.Context, err error) {
+	if err == service.ErrInvalidCredentials {
+		response.Error(c, http.StatusUnauthorized, "Số điện thoại/email hoặc mật khẩu không đúng")
+		return
+	}
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Không tìm thấy tài khoản")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	c.Error(err)
+	response.InternalServerError(c, "Hệ thống đang bận, vui lòng thử lại sau")
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomerMergeHandler struct {
+	mergeService *service.CustomerMergeService
+	userRepo     *repository.UserRepository
+}
+
+func NewCustomerMergeHandler(mergeService *service.CustomerMergeService, userRepo *repository.UserRepository) *CustomerMergeHandler {
+	return &CustomerMergeHandler{
+		mergeService: mergeService,
+		userRepo:     userRepo,
+	}
+}
+
+// FindDuplicates lists probable duplicate customers
+func (h *CustomerMergeHandler) FindDuplicates(c *gin.Context) {
+	minScore, err := strconv.ParseFloat(c.DefaultQuery("min_score", "0.5"), 64)
+	if err != nil || minScore < 0 || minScore > 1 {
+		response.BadRequest(c, "min_score must be between 0 and 1")
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	candidates, err := h.mergeService.FindDuplicates(c.Request.Context(), minScore, limit)
+	if err != nil {
+		response.InternalServerError(c, "Failed to find duplicate customers: "+err.Error())
+		return
+	}
+
+	response.Success(c, candidates, "Duplicate customers retrieved successfully")
+}
+
+// MergeCustomers merges a guest customer into another customer
+func (h *CustomerMergeHandler) MergeCustomers(c *gin.Context) {
+	var req model.MergeCustomersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	merge, err := h.mergeService.MergeCustomers(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to merge customers: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Customers merged successfully", merge)
+}
+
+// ListMerges lists the customer merge audit records
+func (h *CustomerMergeHandler) ListMerges(c *gin.Context) {
+	merges, err := h.mergeService.ListMerges(c.Request.Context(), c.Query("user_id"))
+	if err != nil {
+		response.InternalServerError(c, "Failed to get customer merges: "+err.Error())
+		return
+	}
+
+	response.Success(c, merges, "Customer merges retrieved successfully")
+}
+
+func (h *CustomerMergeHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *CustomerMergeHandler) handleError(c *gin.Context, err error, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Customer not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"io"
 	"net/http"
 	"strconv"
 
@@ -13,6 +14,9 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// maxDeliveryProofSize is the largest proof photo/signature accepted (5MB)
+const maxDeliveryProofSize = 5 << 20
+
 type DeliveryHandler struct {
 	deliveryService *service.DeliveryService
 	deliveryRepo    *repository.DeliveryRepository
@@ -322,3 +326,100 @@ func (h *DeliveryHandler) GetDeliveryStatuses(c *gin.Context) {
 
 	response.Success(c, statuses, "Delivery statuses retrieved successfully")
 }
+
+// AddDeliveryProof attaches a proof photo/signature (multipart "file") with an optional geotag
+func (h *DeliveryHandler) AddDeliveryProof(c *gin.Context) {
+	var req model.AddDeliveryProofRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "Proof file is required")
+		return
+	}
+	if fileHeader.Size > maxDeliveryProofSize {
+		response.BadRequest(c, "Proof file must not exceed 5MB")
+		return
+	}
+
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, "Invalid proof file")
+		return
+	}
+	defer file.Close()
+
+	proof, err := h.deliveryService.AddDeliveryProof(c.Request.Context(), c.Param("id"), &req, file, fileHeader.Size, user.ID)
+	if err != nil {
+		if err == service.ErrNotFound {
+			response.NotFound(c, "Delivery order not found")
+			return
+		}
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
+		response.InternalServerError(c, "Failed to add delivery proof: "+err.Error())
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Delivery proof added successfully", proof)
+}
+
+// ListDeliveryProofs lists the proofs attached to a delivery order
+func (h *DeliveryHandler) ListDeliveryProofs(c *gin.Context) {
+	proofs, err := h.deliveryService.ListDeliveryProofs(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			response.NotFound(c, "Delivery order not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to list delivery proofs: "+err.Error())
+		return
+	}
+
+	response.Success(c, proofs, "Delivery proofs retrieved successfully")
+}
+
+// GetDeliveryProofFile streams the photo or signature of a delivery proof
+func (h *DeliveryHandler) GetDeliveryProofFile(c *gin.Context) {
+	proof, file, err := h.deliveryService.OpenDeliveryProof(c.Request.Context(), c.Param("id"), c.Param("proofId"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			response.NotFound(c, "Delivery proof not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to open delivery proof: "+err.Error())
+		return
+	}
+	serveDeliveryProof(c, proof, file)
+}
+
+// serveDeliveryProof streams a proof file, it must not be cached by shared caches
+func serveDeliveryProof(c *gin.Context, proof *model.DeliveryProof, file io.ReadCloser) {
+	defer file.Close()
+	c.DataFromReader(http.StatusOK, proof.FileSize, proof.ContentType, file, map[string]string{
+		"Cache-Control":          "private, no-store",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// GetProofPolicy returns the proof required before a delivery can be marked delivered
+func (h *DeliveryHandler) GetProofPolicy(c *gin.Context) {
+	response.Success(c, h.deliveryService.GetProofPolicy(), "Delivery proof policy retrieved successfully")
+}
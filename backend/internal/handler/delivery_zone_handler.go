@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DeliveryZoneHandler struct {
+	zoneService  *service.DeliveryZoneService
+	orderService *service.OrderService
+	userRepo     *repository.UserRepository
+}
+
+func NewDeliveryZoneHandler(zoneService *service.DeliveryZoneService, orderService *service.OrderService, userRepo *repository.UserRepository) *DeliveryZoneHandler {
+	return &DeliveryZoneHandler{
+		zoneService:  zoneService,
+		orderService: orderService,
+		userRepo:     userRepo,
+	}
+}
+
+// CreateZone creates a new delivery zone
+func (h *DeliveryZoneHandler) CreateZone(c *gin.Context) {
+	var req model.DeliveryZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	zone, err := h.zoneService.CreateZone(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Delivery zone not found", "Failed to create delivery zone: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Delivery zone created successfully", zone)
+}
+
+// ListZones lists all delivery zones
+func (h *DeliveryZoneHandler) ListZones(c *gin.Context) {
+	zones, err := h.zoneService.ListZones(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get delivery zones: "+err.Error())
+		return
+	}
+
+	response.Success(c, zones, "Delivery zones retrieved successfully")
+}
+
+// GetZone gets a delivery zone by public ID
+func (h *DeliveryZoneHandler) GetZone(c *gin.Context) {
+	zone, err := h.zoneService.GetZone(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Delivery zone not found", "Failed to get delivery zone: ")
+		return
+	}
+
+	response.Success(c, zone, "Delivery zone retrieved successfully")
+}
+
+// UpdateZone updates a delivery zone
+func (h *DeliveryZoneHandler) UpdateZone(c *gin.Context) {
+	var req model.DeliveryZoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	zone, err := h.zoneService.UpdateZone(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Delivery zone not found", "Failed to update delivery zone: ")
+		return
+	}
+
+	response.Success(c, zone, "Delivery zone updated successfully")
+}
+
+// DeleteZone deletes a delivery zone
+func (h *DeliveryZoneHandler) DeleteZone(c *gin.Context) {
+	if err := h.zoneService.DeleteZone(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "Delivery zone not found", "Failed to delete delivery zone: ")
+		return
+	}
+
+	response.Success(c, nil, "Delivery zone deleted successfully")
+}
+
+// QuoteShippingFee finds the delivery zone of a coordinate and its shipping fee
+func (h *DeliveryZoneHandler) QuoteShippingFee(c *gin.Context) {
+	var req model.ShippingQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	quote, err := h.zoneService.QuoteInZone(c.Request.Context(), req.Latitude, req.Longitude, req.Subtotal)
+	if err != nil {
+		h.handleError(c, err, "Delivery zone not found", "Failed to quote shipping fee: ")
+		return
+	}
+
+	response.Success(c, quote, "Shipping fee calculated successfully")
+}
+
+// UpdateOrderShippingFee overrides the shipping fee of an order
+func (h *DeliveryZoneHandler) UpdateOrderShippingFee(c *gin.Context) {
+	var req model.UpdateShippingFeeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	order, err := h.orderService.UpdateShippingFee(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Order not found", "Failed to update shipping fee: ")
+		return
+	}
+
+	response.Success(c, order, "Shipping fee updated successfully")
+}
+
+// ListOrderShippingFeeAudits lists the shipping fee changes of an order
+func (h *DeliveryZoneHandler) ListOrderShippingFeeAudits(c *gin.Context) {
+	audits, err := h.orderService.ListShippingFeeAudits(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Order not found", "Failed to get shipping fee audits: ")
+		return
+	}
+
+	response.Success(c, audits, "Shipping fee audits retrieved successfully")
+}
+
+// currentUserID resolves the internal ID of the authenticated user
+func (h *DeliveryZoneHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *DeliveryZoneHandler) handleError(c *gin.Context, err error, notFoundMessage, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, notFoundMessage)
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
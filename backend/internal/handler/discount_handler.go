@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DiscountHandler struct {
+	discountService *service.DiscountService
+	userRepo        *repository.UserRepository
+}
+
+func NewDiscountHandler(discountService *service.DiscountService, userRepo *repository.UserRepository) *DiscountHandler {
+	return &DiscountHandler{
+		discountService: discountService,
+		userRepo:        userRepo,
+	}
+}
+
+// CreateDiscountCode creates a new discount code
+func (h *DiscountHandler) CreateDiscountCode(c *gin.Context) {
+	var req model.CreateDiscountCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return
+	}
+
+	discountCode, err := h.discountService.CreateDiscountCode(c.Request.Context(), &req, user.ID)
+	if err != nil {
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
+		response.InternalServerError(c, "Failed to create discount code: "+err.Error())
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Discount code created successfully", discountCode)
+}
+
+// ListDiscountCodes lists discount codes with filtering and pagination
+func (h *DiscountHandler) ListDiscountCodes(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	req := &model.ListDiscountCodesRequest{
+		Page:   page,
+		Limit:  limit,
+		Search: c.Query("search"),
+	}
+	if isActiveStr := c.Query("is_active"); isActiveStr != "" {
+		if isActive, err := strconv.ParseBool(isActiveStr); err == nil {
+			req.IsActive = &isActive
+		}
+	}
+
+	resp, err := h.discountService.ListDiscountCodes(c.Request.Context(), req)
+	if err != nil {
+		response.InternalServerError(c, "Failed to list discount codes: "+err.Error())
+		return
+	}
+
+	response.Success(c, resp, "Discount codes retrieved successfully")
+}
+
+// GetDiscountCode gets discount code by public ID
+func (h *DiscountHandler) GetDiscountCode(c *gin.Context) {
+	publicID := c.Param("id")
+
+	discountCode, err := h.discountService.GetDiscountCode(c.Request.Context(), publicID)
+	if err != nil {
+		response.NotFound(c, "Discount code not found")
+		return
+	}
+
+	response.Success(c, discountCode, "Discount code retrieved successfully")
+}
+
+// UpdateDiscountCode updates discount code
+func (h *DiscountHandler) UpdateDiscountCode(c *gin.Context) {
+	publicID := c.Param("id")
+
+	var req model.UpdateDiscountCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	discountCode, err := h.discountService.UpdateDiscountCode(c.Request.Context(), publicID, &req)
+	if err != nil {
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
+		if err.Error() == "discount code not found" {
+			response.NotFound(c, "Discount code not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to update discount code: "+err.Error())
+		return
+	}
+
+	response.Success(c, discountCode, "Discount code updated successfully")
+}
+
+// DeleteDiscountCode deletes discount code
+func (h *DiscountHandler) DeleteDiscountCode(c *gin.Context) {
+	publicID := c.Param("id")
+
+	err := h.discountService.DeleteDiscountCode(c.Request.Context(), publicID)
+	if err != nil {
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
+		if err.Error() == "discount code not found" {
+			response.NotFound(c, "Discount code not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to delete discount code: "+err.Error())
+		return
+	}
+
+	response.Success(c, nil, "Discount code deleted successfully")
+}
+
+// ListDiscountCodeUsages lists redemptions of a discount code
+func (h *DiscountHandler) ListDiscountCodeUsages(c *gin.Context) {
+	publicID := c.Param("id")
+
+	usages, err := h.discountService.ListDiscountCodeUsages(c.Request.Context(), publicID)
+	if err != nil {
+		if err.Error() == "discount code not found" {
+			response.NotFound(c, "Discount code not found")
+			return
+		}
+		response.InternalServerError(c, "Failed to get discount code usages: "+err.Error())
+		return
+	}
+
+	response.Success(c, usages, "Discount code usages retrieved successfully")
+}
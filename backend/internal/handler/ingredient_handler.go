@@ -59,9 +59,9 @@ func (h *IngredientHandler) GetIngredient(c *gin.Context) {
 	response.Success(c, ingredient, "Ingredient fetched successfully")
 }
 
-// GetAllIngredients gets all ingredients
+// GetAllIngredients gets all ingredients, archived ones only with ?include_archived=true
 func (h *IngredientHandler) GetAllIngredients(c *gin.Context) {
-	ingredients, err := h.ingredientService.GetAllIngredients(c.Request.Context())
+	ingredients, err := h.ingredientService.GetAllIngredients(c.Request.Context(), c.Query("include_archived") == "true")
 	if err != nil {
 		response.InternalServerError(c, err.Error())
 		return
@@ -99,25 +99,50 @@ func (h *IngredientHandler) UpdateIngredient(c *gin.Context) {
 	response.Success(c, ingredient, "Ingredient updated successfully")
 }
 
-// DeleteIngredient deletes an ingredient
-func (h *IngredientHandler) DeleteIngredient(c *gin.Context) {
+// ArchiveIngredient archives an ingredient instead of deleting it
+func (h *IngredientHandler) ArchiveIngredient(c *gin.Context) {
 	publicID := c.Param("public_id")
 	if publicID == "" {
 		response.BadRequest(c, "public_id is required")
 		return
 	}
 
-	err := h.ingredientService.DeleteIngredient(c.Request.Context(), publicID)
+	ingredient, err := h.ingredientService.ArchiveIngredient(c.Request.Context(), publicID)
 	if err != nil {
-		if err == service.ErrNotFound {
-			response.NotFound(c, "ingredient not found")
-			return
-		}
-		response.InternalServerError(c, err.Error())
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, ingredient, "Ingredient archived successfully")
+}
+
+// RestoreIngredient restores an archived ingredient
+func (h *IngredientHandler) RestoreIngredient(c *gin.Context) {
+	publicID := c.Param("public_id")
+	if publicID == "" {
+		response.BadRequest(c, "public_id is required")
 		return
 	}
 
-	response.Success(c, gin.H{"message": "ingredient deleted successfully"}, "Ingredient deleted successfully")
+	ingredient, err := h.ingredientService.RestoreIngredient(c.Request.Context(), publicID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, ingredient, "Ingredient restored successfully")
+}
+
+func (h *IngredientHandler) handleError(c *gin.Context, err error) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "ingredient not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, err.Error())
 }
 
 // GetVariantIngredients gets all ingredients for a variant
@@ -161,6 +186,10 @@ func (h *IngredientHandler) AddIngredientToVariant(c *gin.Context) {
 			response.NotFound(c, "variant or ingredient not found")
 			return
 		}
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
 		response.InternalServerError(c, err.Error())
 		return
 	}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryHandler struct {
+	inventoryService *service.InventoryService
+	userRepo         *repository.UserRepository
+}
+
+func NewInventoryHandler(inventoryService *service.InventoryService, userRepo *repository.UserRepository) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+		userRepo:         userRepo,
+	}
+}
+
+// ReceiveStock records goods received for an ingredient
+func (h *InventoryHandler) ReceiveStock(c *gin.Context) {
+	var req model.CreateStockReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	movement, err := h.inventoryService.ReceiveStock(c.Request.Context(), c.Param("public_id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to receive stock: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Stock received successfully", movement)
+}
+
+// AdjustStock records a stock count correction for an ingredient
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	var req model.CreateStockAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	movement, err := h.inventoryService.AdjustStock(c.Request.Context(), c.Param("public_id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to adjust stock: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Stock adjusted successfully", movement)
+}
+
+// ListStockMovements lists the stock ledger of an ingredient
+func (h *InventoryHandler) ListStockMovements(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	req := &model.ListStockMovementsRequest{
+		Page:  page,
+		Limit: limit,
+	}
+	if movementType := c.Query("movement_type"); movementType != "" {
+		t := model.StockMovementType(movementType)
+		req.MovementType = &t
+	}
+	if dateFromStr := c.Query("date_from"); dateFromStr != "" {
+		if parsed, err := time.Parse("2006-01-02", dateFromStr); err == nil {
+			req.DateFrom = &parsed
+		}
+	}
+	if dateToStr := c.Query("date_to"); dateToStr != "" {
+		if parsed, err := time.Parse("2006-01-02", dateToStr); err == nil {
+			req.DateTo = &parsed
+		}
+	}
+
+	resp, err := h.inventoryService.ListStockMovements(c.Request.Context(), c.Param("public_id"), req)
+	if err != nil {
+		h.handleError(c, err, "Failed to list stock movements: ")
+		return
+	}
+
+	response.Success(c, resp, "Stock movements retrieved successfully")
+}
+
+// ListLowStockIngredients lists ingredients at or below their low stock threshold
+func (h *InventoryHandler) ListLowStockIngredients(c *gin.Context) {
+	ingredients, err := h.inventoryService.ListLowStockIngredients(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to list low stock ingredients: "+err.Error())
+		return
+	}
+
+	response.Success(c, ingredients, "Low stock ingredients retrieved successfully")
+}
+
+// currentUserID resolves the internal ID of the authenticated user
+func (h *InventoryHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *InventoryHandler) handleError(c *gin.Context, err error, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "ingredient not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type KitchenHandler struct {
+	kitchenService *service.KitchenService
+	userRepo       *repository.UserRepository
+}
+
+func NewKitchenHandler(kitchenService *service.KitchenService, userRepo *repository.UserRepository) *KitchenHandler {
+	return &KitchenHandler{
+		kitchenService: kitchenService,
+		userRepo:       userRepo,
+	}
+}
+
+// CreateStation creates a kitchen station
+func (h *KitchenHandler) CreateStation(c *gin.Context) {
+	var req model.CreateKitchenStationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	station, err := h.kitchenService.CreateStation(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "kitchen station not found", "Failed to create kitchen station: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Kitchen station created successfully", station)
+}
+
+// ListStations lists all kitchen stations
+func (h *KitchenHandler) ListStations(c *gin.Context) {
+	stations, err := h.kitchenService.ListStations(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to list kitchen stations: "+err.Error())
+		return
+	}
+
+	response.Success(c, stations, "Kitchen stations retrieved successfully")
+}
+
+// GetStation gets a kitchen station by public ID
+func (h *KitchenHandler) GetStation(c *gin.Context) {
+	station, err := h.kitchenService.GetStation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "kitchen station not found", "Failed to get kitchen station: ")
+		return
+	}
+
+	response.Success(c, station, "Kitchen station retrieved successfully")
+}
+
+// UpdateStation updates a kitchen station
+func (h *KitchenHandler) UpdateStation(c *gin.Context) {
+	var req model.UpdateKitchenStationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	station, err := h.kitchenService.UpdateStation(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "kitchen station not found", "Failed to update kitchen station: ")
+		return
+	}
+
+	response.Success(c, station, "Kitchen station updated successfully")
+}
+
+// DeleteStation deletes a kitchen station
+func (h *KitchenHandler) DeleteStation(c *gin.Context) {
+	if err := h.kitchenService.DeleteStation(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "kitchen station not found", "Failed to delete kitchen station: ")
+		return
+	}
+
+	response.Success(c, nil, "Kitchen station deleted successfully")
+}
+
+// SetStationProducts routes products to a kitchen station
+func (h *KitchenHandler) SetStationProducts(c *gin.Context) {
+	var req model.SetKitchenStationProductsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	station, err := h.kitchenService.SetStationProducts(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "kitchen station not found", "Failed to update kitchen station products: ")
+		return
+	}
+
+	response.Success(c, station, "Kitchen station products updated successfully")
+}
+
+// GetQueue gets the live kitchen queue
+func (h *KitchenHandler) GetQueue(c *gin.Context) {
+	includeBumped, _ := strconv.ParseBool(c.DefaultQuery("include_bumped", "false"))
+	req := &model.KitchenQueueRequest{
+		StationID:     c.Query("station_id"),
+		IncludeBumped: includeBumped,
+	}
+
+	tickets, err := h.kitchenService.GetQueue(c.Request.Context(), req)
+	if err != nil {
+		h.handleError(c, err, "kitchen station not found", "Failed to get kitchen queue: ")
+		return
+	}
+
+	response.Success(c, tickets, "Kitchen queue retrieved successfully")
+}
+
+// UpdateTicketStatus updates the preparation state of an order item
+func (h *KitchenHandler) UpdateTicketStatus(c *gin.Context) {
+	var req model.UpdateKitchenTicketStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return
+	}
+
+	ticket, err := h.kitchenService.UpdateTicketStatus(c.Request.Context(), c.Param("id"), &req, user.ID)
+	if err != nil {
+		h.handleError(c, err, "kitchen ticket not found", "Failed to update kitchen ticket: ")
+		return
+	}
+
+	response.Success(c, ticket, "Kitchen ticket updated successfully")
+}
+
+func (h *KitchenHandler) handleError(c *gin.Context, err error, notFoundMessage, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, notFoundMessage)
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
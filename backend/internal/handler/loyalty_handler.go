@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoyaltyHandler struct {
+	loyaltyService *service.LoyaltyService
+	userRepo       *repository.UserRepository
+}
+
+func NewLoyaltyHandler(loyaltyService *service.LoyaltyService, userRepo *repository.UserRepository) *LoyaltyHandler {
+	return &LoyaltyHandler{
+		loyaltyService: loyaltyService,
+		userRepo:       userRepo,
+	}
+}
+
+// GetSettings gets the loyalty program settings
+func (h *LoyaltyHandler) GetSettings(c *gin.Context) {
+	settings, err := h.loyaltyService.GetSettings(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get loyalty settings: "+err.Error())
+		return
+	}
+
+	response.Success(c, settings, "Loyalty settings retrieved successfully")
+}
+
+// UpdateSettings updates the loyalty program settings
+func (h *LoyaltyHandler) UpdateSettings(c *gin.Context) {
+	var req model.UpdateLoyaltySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	settings, err := h.loyaltyService.UpdateSettings(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to update loyalty settings: ")
+		return
+	}
+
+	response.Success(c, settings, "Loyalty settings updated successfully")
+}
+
+// ListTiers lists the loyalty tier levels
+func (h *LoyaltyHandler) ListTiers(c *gin.Context) {
+	tiers, err := h.loyaltyService.ListTiers(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get loyalty tiers: "+err.Error())
+		return
+	}
+
+	response.Success(c, tiers, "Loyalty tiers retrieved successfully")
+}
+
+// UpdateTier updates a loyalty tier level
+func (h *LoyaltyHandler) UpdateTier(c *gin.Context) {
+	var req model.UpdateLoyaltyTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	tier, err := h.loyaltyService.UpdateTier(c.Request.Context(), c.Param("code"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update loyalty tier: ")
+		return
+	}
+
+	response.Success(c, tier, "Loyalty tier updated successfully")
+}
+
+// RecalculateTiers recalculates the tier of every customer now
+func (h *LoyaltyHandler) RecalculateTiers(c *gin.Context) {
+	result, err := h.loyaltyService.RecalculateTiers(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to recalculate loyalty tiers: "+err.Error())
+		return
+	}
+
+	response.Success(c, result, "Loyalty tiers recalculated successfully")
+}
+
+// ListProductBonuses lists the per product bonus points
+func (h *LoyaltyHandler) ListProductBonuses(c *gin.Context) {
+	bonuses, err := h.loyaltyService.ListProductBonuses(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get product bonuses: "+err.Error())
+		return
+	}
+
+	response.Success(c, bonuses, "Product bonuses retrieved successfully")
+}
+
+// SetProductBonus sets the bonus points of a product
+func (h *LoyaltyHandler) SetProductBonus(c *gin.Context) {
+	var req model.SetLoyaltyProductBonusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	bonus, err := h.loyaltyService.SetProductBonus(c.Request.Context(), c.Param("productId"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to set product bonus: ")
+		return
+	}
+
+	response.Success(c, bonus, "Product bonus saved successfully")
+}
+
+// DeleteProductBonus removes the bonus points of a product
+func (h *LoyaltyHandler) DeleteProductBonus(c *gin.Context) {
+	if err := h.loyaltyService.DeleteProductBonus(c.Request.Context(), c.Param("productId")); err != nil {
+		h.handleError(c, err, "Failed to delete product bonus: ")
+		return
+	}
+
+	response.Success(c, nil, "Product bonus deleted successfully")
+}
+
+// GetAccount gets the points balance and tier of a customer
+func (h *LoyaltyHandler) GetAccount(c *gin.Context) {
+	account, err := h.loyaltyService.GetAccount(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get loyalty account: ")
+		return
+	}
+
+	response.Success(c, account, "Loyalty account retrieved successfully")
+}
+
+// ListLedger lists the points ledger of a customer
+func (h *LoyaltyHandler) ListLedger(c *gin.Context) {
+	h.listLedger(c, c.Param("id"))
+}
+
+// AdjustPoints adds or removes points of a customer by hand
+func (h *LoyaltyHandler) AdjustPoints(c *gin.Context) {
+	var req model.AdjustLoyaltyPointsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	account, err := h.loyaltyService.AdjustPoints(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to adjust loyalty points: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Loyalty points adjusted successfully", account)
+}
+
+// GetMyAccount gets the points balance and tier of the logged in customer
+func (h *LoyaltyHandler) GetMyAccount(c *gin.Context) {
+	account, err := h.loyaltyService.GetAccount(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get loyalty account: ")
+		return
+	}
+
+	response.Success(c, account, "Loyalty account retrieved successfully")
+}
+
+// ListMyLedger lists the points ledger of the logged in customer
+func (h *LoyaltyHandler) ListMyLedger(c *gin.Context) {
+	h.listLedger(c, c.GetString("user_id"))
+}
+
+func (h *LoyaltyHandler) listLedger(c *gin.Context, userPublicID string) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	resp, err := h.loyaltyService.ListLedger(c.Request.Context(), userPublicID, &model.ListLoyaltyLedgerRequest{
+		Page:  page,
+		Limit: limit,
+	})
+	if err != nil {
+		h.handleError(c, err, "Failed to get loyalty ledger: ")
+		return
+	}
+
+	response.Success(c, resp, "Loyalty ledger retrieved successfully")
+}
+
+func (h *LoyaltyHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *LoyaltyHandler) handleError(c *gin.Context, err error, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
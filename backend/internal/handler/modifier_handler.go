@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModifierHandler struct {
+	modifierService *service.ModifierService
+}
+
+func NewModifierHandler(modifierService *service.ModifierService) *ModifierHandler {
+	return &ModifierHandler{
+		modifierService: modifierService,
+	}
+}
+
+// CreateModifierGroup creates a modifier group with its options
+func (h *ModifierHandler) CreateModifierGroup(c *gin.Context) {
+	var req model.CreateModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	group, err := h.modifierService.CreateModifierGroup(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "modifier group not found", "Failed to create modifier group: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Modifier group created successfully", group)
+}
+
+// ListModifierGroups lists all modifier groups
+func (h *ModifierHandler) ListModifierGroups(c *gin.Context) {
+	groups, err := h.modifierService.ListModifierGroups(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to list modifier groups: "+err.Error())
+		return
+	}
+
+	response.Success(c, groups, "Modifier groups retrieved successfully")
+}
+
+// GetModifierGroup gets a modifier group by public ID
+func (h *ModifierHandler) GetModifierGroup(c *gin.Context) {
+	group, err := h.modifierService.GetModifierGroup(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "modifier group not found", "Failed to get modifier group: ")
+		return
+	}
+
+	response.Success(c, group, "Modifier group retrieved successfully")
+}
+
+// UpdateModifierGroup updates a modifier group
+func (h *ModifierHandler) UpdateModifierGroup(c *gin.Context) {
+	var req model.UpdateModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	group, err := h.modifierService.UpdateModifierGroup(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "modifier group not found", "Failed to update modifier group: ")
+		return
+	}
+
+	response.Success(c, group, "Modifier group updated successfully")
+}
+
+// DeleteModifierGroup deletes a modifier group
+func (h *ModifierHandler) DeleteModifierGroup(c *gin.Context) {
+	if err := h.modifierService.DeleteModifierGroup(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "modifier group not found", "Failed to delete modifier group: ")
+		return
+	}
+
+	response.Success(c, nil, "Modifier group deleted successfully")
+}
+
+// AddModifierOption adds an option to a modifier group
+func (h *ModifierHandler) AddModifierOption(c *gin.Context) {
+	var req model.CreateModifierOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	option, err := h.modifierService.AddModifierOption(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "modifier group not found", "Failed to add modifier option: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Modifier option created successfully", option)
+}
+
+// UpdateModifierOption updates a modifier option
+func (h *ModifierHandler) UpdateModifierOption(c *gin.Context) {
+	var req model.UpdateModifierOptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	option, err := h.modifierService.UpdateModifierOption(c.Request.Context(), c.Param("option_id"), &req)
+	if err != nil {
+		h.handleError(c, err, "modifier option not found", "Failed to update modifier option: ")
+		return
+	}
+
+	response.Success(c, option, "Modifier option updated successfully")
+}
+
+// DeleteModifierOption deletes a modifier option
+func (h *ModifierHandler) DeleteModifierOption(c *gin.Context) {
+	if err := h.modifierService.DeleteModifierOption(c.Request.Context(), c.Param("option_id")); err != nil {
+		h.handleError(c, err, "modifier option not found", "Failed to delete modifier option: ")
+		return
+	}
+
+	response.Success(c, nil, "Modifier option deleted successfully")
+}
+
+// GetProductModifierGroups gets the modifier groups attached to a product
+func (h *ModifierHandler) GetProductModifierGroups(c *gin.Context) {
+	groups, err := h.modifierService.GetProductModifierGroups(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "product not found", "Failed to get product modifier groups: ")
+		return
+	}
+
+	response.Success(c, groups, "Product modifier groups retrieved successfully")
+}
+
+// SetProductModifierGroups replaces the modifier groups attached to a product
+func (h *ModifierHandler) SetProductModifierGroups(c *gin.Context) {
+	var req model.SetProductModifierGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	groups, err := h.modifierService.SetProductModifierGroups(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "product not found", "Failed to update product modifier groups: ")
+		return
+	}
+
+	response.Success(c, groups, "Product modifier groups updated successfully")
+}
+
+func (h *ModifierHandler) handleError(c *gin.Context, err error, notFoundMessage, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, notFoundMessage)
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
@@ -81,6 +81,12 @@ type OrderItemResponse struct {
 	Notes       *string `json:"notes"`
 	CreatedAt   string  `json:"created_at"`
 	UpdatedAt   string  `json:"updated_at"`
+
+	BasePrice     *float64 `json:"base_price"`
+	PriceListName *string  `json:"price_list_name"`
+
+	Modifiers  []model.OrderItemModifier  `json:"modifiers"`
+	Promotions []model.OrderItemPromotion `json:"promotions"`
 }
 
 type ShipperResponse struct {
@@ -105,6 +111,20 @@ type OrderResponse struct {
 	DiscountNote   *string             `json:"discount_note"`
 	ManualDiscountAmount float64             `json:"manual_discount_amount"`
 	ShippingFee    float64             `json:"shipping_fee"`
+	PromotionDiscountAmount float64    `json:"promotion_discount_amount"`
+	LoyaltyPointsRedeemed int          `json:"loyalty_points_redeemed"`
+	LoyaltyDiscountAmount float64      `json:"loyalty_discount_amount"`
+	LoyaltyPointsEarned   int          `json:"loyalty_points_earned"`
+	ShippingFeeSource  string          `json:"shipping_fee_source"`
+	DeliveryZone       *string         `json:"delivery_zone"`
+	CustomerAddressID  *string         `json:"customer_address_id"`
+	DeliveryAddress    *string         `json:"delivery_address"`
+	DeliveryWard       *string         `json:"delivery_ward"`
+	DeliveryDistrict   *string         `json:"delivery_district"`
+	DeliveryProvince   *string         `json:"delivery_province"`
+	DeliveryLatitude   *float64        `json:"delivery_latitude"`
+	DeliveryLongitude  *float64        `json:"delivery_longitude"`
+	DeliveryDistanceKm *float64        `json:"delivery_distance_km"`
 	TotalAmount    float64             `json:"total_amount"`
 	PaymentMethod  string              `json:"payment_method"`
 	PaymentStatus  string              `json:"payment_status"`
@@ -123,6 +143,14 @@ func toOrderItemResponse(item model.OrderItem) OrderItemResponse {
 	if item.Notes.Valid {
 		notesPtr = &item.Notes.String
 	}
+	modifiers := item.Modifiers
+	if modifiers == nil {
+		modifiers = make([]model.OrderItemModifier, 0)
+	}
+	promotions := item.Promotions
+	if promotions == nil {
+		promotions = make([]model.OrderItemPromotion, 0)
+	}
 	return OrderItemResponse{
 		ID:          strconv.FormatInt(item.ID, 10),
 		VariantID:   strconv.FormatInt(item.VariantID, 10),
@@ -134,6 +162,11 @@ func toOrderItemResponse(item model.OrderItem) OrderItemResponse {
 		Notes:       notesPtr,
 		CreatedAt:   item.CreatedAt.Format(time.RFC3339),
 		UpdatedAt:   item.UpdatedAt.Format(time.RFC3339),
+		Modifiers:   modifiers,
+		Promotions:  promotions,
+
+		BasePrice:     item.BasePrice,
+		PriceListName: item.PriceListName,
 	}
 }
 
@@ -187,6 +220,20 @@ func (h *OrderHandler) toOrderResponse(order *model.Order) *OrderResponse {
 		DiscountNote:   discountNotePtr,
 		ManualDiscountAmount: order.ManualDiscountAmount,
 		ShippingFee:    order.ShippingFee,
+		PromotionDiscountAmount: order.PromotionDiscountAmount,
+		LoyaltyPointsRedeemed: order.LoyaltyPointsRedeemed,
+		LoyaltyDiscountAmount: order.LoyaltyDiscountAmount,
+		LoyaltyPointsEarned:   order.LoyaltyPointsEarned,
+		ShippingFeeSource:  order.ShippingFeeSource,
+		DeliveryZone:       order.DeliveryZone,
+		CustomerAddressID:  order.CustomerAddressID,
+		DeliveryAddress:    order.DeliveryAddress,
+		DeliveryWard:       order.DeliveryWard,
+		DeliveryDistrict:   order.DeliveryDistrict,
+		DeliveryProvince:   order.DeliveryProvince,
+		DeliveryLatitude:   order.DeliveryLatitude,
+		DeliveryLongitude:  order.DeliveryLongitude,
+		DeliveryDistanceKm: order.DeliveryDistanceKm,
 		TotalAmount:    order.TotalAmount,
 		PaymentMethod:  order.PaymentMethod,
 		PaymentStatus:  string(order.PaymentStatus),
@@ -260,6 +307,10 @@ func (h *OrderHandler) UpdateOrder(c *gin.Context) {
 
 	order, err := h.orderService.UpdateOrder(c.Request.Context(), publicID, &req, user.ID)
 	if err != nil {
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
 		response.InternalServerError(c, "Failed to update order: "+err.Error())
 		return
 	}
@@ -370,6 +421,10 @@ func (h *OrderHandler) ValidateDiscountCode(c *gin.Context) {
 	// Validate discount code
 	validation, err := h.orderService.ValidateDiscountCode(c.Request.Context(), &req)
 	if err != nil {
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
 		response.InternalServerError(c, "Failed to validate discount code")
 		return
 	}
@@ -389,19 +444,6 @@ func (h *OrderHandler) GetOrderStatuses(c *gin.Context) {
 	response.Success(c, statuses, "Order statuses retrieved successfully")
 }
 
-// GetPaymentMethods returns available payment methods
-func (h *OrderHandler) GetPaymentMethods(c *gin.Context) {
-	methods := []gin.H{
-		{"value": "cash", "label": "Tiền mặt"},
-		{"value": "card", "label": "Thẻ tín dụng"},
-		{"value": "transfer", "label": "Chuyển khoản"},
-		{"value": "momo", "label": "MoMo"},
-		{"value": "vnpay", "label": "VNPay"},
-	}
-
-	response.Success(c, methods, "Payment methods retrieved successfully")
-}
-
 // GetOrderStatistics returns order statistics
 func (h *OrderHandler) GetOrderStatistics(c *gin.Context) {
 	// Parse date range if provided
@@ -0,0 +1,237 @@
+package handler
+
+import (
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/payment"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService *service.PaymentService
+	userRepo       *repository.UserRepository
+	returnURL      string
+}
+
+func NewPaymentHandler(paymentService *service.PaymentService, userRepo *repository.UserRepository, returnURL string) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+		userRepo:       userRepo,
+		returnURL:      returnURL,
+	}
+}
+
+// GetPaymentMethods returns available payment methods
+func (h *PaymentHandler) GetPaymentMethods(c *gin.Context) {
+	methods, err := h.paymentService.ListPaymentMethods(c.Request.Context())
+	if err != nil {
+		response.InternalServerError(c, "Failed to get payment methods: "+err.Error())
+		return
+	}
+
+	response.Success(c, methods, "Payment methods retrieved successfully")
+}
+
+// GetOrderPayments returns the payments and outstanding balance of an order
+func (h *PaymentHandler) GetOrderPayments(c *gin.Context) {
+	summary, err := h.paymentService.GetOrderPayments(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Order not found", "Failed to get order payments: ")
+		return
+	}
+
+	response.Success(c, summary, "Order payments retrieved successfully")
+}
+
+// RecordPayment records one or more tenders against an order
+func (h *PaymentHandler) RecordPayment(c *gin.Context) {
+	var req model.RecordPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.paymentService.RecordPayment(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Order not found", "Failed to record payment: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Payment recorded successfully", summary)
+}
+
+// RefundPayment refunds a payment fully or partially
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	var req model.RefundPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.paymentService.RefundPayment(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Payment not found", "Failed to refund payment: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Payment refunded successfully", summary)
+}
+
+// CreatePaymentIntent starts a gateway checkout for an order
+func (h *PaymentHandler) CreatePaymentIntent(c *gin.Context) {
+	var req model.CreatePaymentIntentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	intent, err := h.paymentService.CreatePaymentIntent(c.Request.Context(), c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Order not found", "Failed to create payment intent: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Payment intent created successfully", intent)
+}
+
+// SyncPaymentStatus queries the gateway for the state of a pending payment
+func (h *PaymentHandler) SyncPaymentStatus(c *gin.Context) {
+	result, err := h.paymentService.SyncPaymentStatus(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Payment not found", "Failed to sync payment status: ")
+		return
+	}
+
+	response.Success(c, result, "Payment status synced successfully")
+}
+
+// HandleIPN handles the server-to-server payment notification from a gateway
+func (h *PaymentHandler) HandleIPN(c *gin.Context) {
+	result, err := h.paymentService.HandleProviderCallback(c.Request.Context(), c.Param("provider"), callbackParams(c))
+	if err != nil {
+		log.Printf("[Payment] IPN rejected: provider=%s, error=%v", c.Param("provider"), err)
+		h.handleError(c, err, "Payment not found", "Failed to handle payment notification: ")
+		return
+	}
+
+	response.Success(c, result, "Payment notification handled successfully")
+}
+
+// HandleReturn handles the customer redirect back from a gateway and forwards
+// them to the frontend result page
+func (h *PaymentHandler) HandleReturn(c *gin.Context) {
+	query := url.Values{}
+	result, err := h.paymentService.HandleProviderCallback(c.Request.Context(), c.Param("provider"), callbackParams(c))
+	if err != nil {
+		log.Printf("[Payment] Return rejected: provider=%s, error=%v", c.Param("provider"), err)
+		query.Set("status", model.PaymentTransactionFailed)
+	} else {
+		query.Set("status", result.Status)
+		query.Set("order_id", result.OrderPublicID)
+		query.Set("payment_id", result.PublicID)
+	}
+
+	c.Redirect(http.StatusFound, h.returnURL+"?"+query.Encode())
+}
+
+// MockCheckout serves the checkout page of the local mock gateway. Without a
+// result it shows pay/cancel links, with one it sends the signed IPN and
+// redirects to the return URL like the real gateways do
+func (h *PaymentHandler) MockCheckout(c *gin.Context) {
+	provider, ok := h.paymentService.Provider(c.Param("provider"))
+	mock, isMock := provider.(*payment.MockProvider)
+	if !ok || !isMock {
+		response.NotFound(c, "Mock payment provider not found")
+		return
+	}
+
+	params := c.Request.URL.Query()
+	result := params.Get("result")
+	params.Del("result")
+	if result == "" {
+		payURL := c.Request.URL.Path + "?" + params.Encode()
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(fmt.Sprintf(
+			`<html><body><h3>%s - Đơn hàng %s</h3><p>Số tiền: %s</p><a href="%s">Thanh toán</a> | <a href="%s">Hủy</a></body></html>`,
+			html.EscapeString(strings.ToUpper(mock.Code())), html.EscapeString(params.Get("order_number")), html.EscapeString(params.Get("amount")),
+			html.EscapeString(payURL+"&result=success"), html.EscapeString(payURL+"&result=cancel"),
+		)))
+		return
+	}
+
+	callback, returnURL, err := mock.Checkout(params, result == "success")
+	if err != nil {
+		response.BadRequest(c, "Invalid checkout: "+err.Error())
+		return
+	}
+
+	// Gateway -> backend IPN
+	if _, err := h.paymentService.HandleProviderCallback(c.Request.Context(), mock.Code(), callback); err != nil {
+		log.Printf("[Payment] Mock IPN failed: provider=%s, error=%v", mock.Code(), err)
+	}
+
+	c.Redirect(http.StatusFound, returnURL+"?"+callback.Encode())
+}
+
+// callbackParams merges query and form params, gateways use either
+func callbackParams(c *gin.Context) url.Values {
+	params := c.Request.URL.Query()
+	if err := c.Request.ParseForm(); err == nil {
+		for key, values := range c.Request.PostForm {
+			params[key] = values
+		}
+	}
+	return params
+}
+
+func (h *PaymentHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *PaymentHandler) handleError(c *gin.Context, err error, notFoundMessage, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, notFoundMessage)
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
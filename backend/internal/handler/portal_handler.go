@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PortalHandler serves the public customer ordering portal
+type PortalHandler struct {
+	portalService *service.PortalService
+}
+
+func NewPortalHandler(portalService *service.PortalService) *PortalHandler {
+	return &PortalHandler{portalService: portalService}
+}
+
+// GetMenu lists the products customers can order
+func (h *PortalHandler) GetMenu(c *gin.Context) {
+	menu, err := h.portalService.GetMenu(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, menu, "Menu retrieved successfully")
+}
+
+// GetMenuCategories lists the menu sections as a tree
+func (h *PortalHandler) GetMenuCategories(c *gin.Context) {
+	categories, err := h.portalService.GetMenuCategories(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, categories, "Categories retrieved successfully")
+}
+
+// QuoteCart prices a cart on the server
+func (h *PortalHandler) QuoteCart(c *gin.Context) {
+	var req model.CartRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Giỏ hàng không hợp lệ")
+		return
+	}
+
+	quote, err := h.portalService.QuoteCart(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, quote, "Cart priced successfully")
+}
+
+// Checkout places a guest order
+func (h *PortalHandler) Checkout(c *gin.Context) {
+	var req model.CheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Thông tin đặt hàng không hợp lệ")
+		return
+	}
+
+	tracking, err := h.portalService.Checkout(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Order placed successfully", tracking)
+}
+
+// PayOrder opens an online checkout for an order by order number and customer phone
+func (h *PortalHandler) PayOrder(c *gin.Context) {
+	var req model.PayOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Vui lòng nhập mã đơn hàng và số điện thoại")
+		return
+	}
+
+	tracking, err := h.portalService.PayOrder(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, tracking, "Payment started successfully")
+}
+
+// TrackOrder gets an order by order number and customer phone
+func (h *PortalHandler) TrackOrder(c *gin.Context) {
+	tracking, err := h.portalService.TrackOrder(c.Request.Context(), c.Query("order_number"), c.Query("phone"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, tracking, "Order retrieved successfully")
+}
+
+// handleError maps service errors to responses; internal errors are logged but
+// not shown to customers
+func (h *PortalHandler) handleError(c *gin.Context, err error) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Không tìm thấy đơn hàng")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	c.Error(err)
+	response.InternalServerError(c, "Hệ thống đang bận, vui lòng thử lại sau")
+}
@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PriceListHandler struct {
+	priceListService *service.PriceListService
+	userRepo         *repository.UserRepository
+}
+
+func NewPriceListHandler(priceListService *service.PriceListService, userRepo *repository.UserRepository) *PriceListHandler {
+	return &PriceListHandler{
+		priceListService: priceListService,
+		userRepo:         userRepo,
+	}
+}
+
+// CreatePriceList creates a new price list
+func (h *PriceListHandler) CreatePriceList(c *gin.Context) {
+	var req model.CreatePriceListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	priceList, err := h.priceListService.CreatePriceList(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to create price list: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Price list created successfully", priceList)
+}
+
+// ListPriceLists lists price lists, optionally filtered by is_active
+func (h *PriceListHandler) ListPriceLists(c *gin.Context) {
+	var isActive *bool
+	if value := c.Query("is_active"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid is_active")
+			return
+		}
+		isActive = &parsed
+	}
+
+	priceLists, err := h.priceListService.ListPriceLists(c.Request.Context(), isActive)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get price lists: "+err.Error())
+		return
+	}
+
+	response.Success(c, priceLists, "Price lists retrieved successfully")
+}
+
+// GetPriceList gets a price list by ID
+func (h *PriceListHandler) GetPriceList(c *gin.Context) {
+	priceList, err := h.priceListService.GetPriceList(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get price list: ")
+		return
+	}
+
+	response.Success(c, priceList, "Price list retrieved successfully")
+}
+
+// UpdatePriceList updates a price list
+func (h *PriceListHandler) UpdatePriceList(c *gin.Context) {
+	var req model.UpdatePriceListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	priceList, err := h.priceListService.UpdatePriceList(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update price list: ")
+		return
+	}
+
+	response.Success(c, priceList, "Price list updated successfully")
+}
+
+// DeletePriceList deletes a price list
+func (h *PriceListHandler) DeletePriceList(c *gin.Context) {
+	if err := h.priceListService.DeletePriceList(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "Failed to delete price list: ")
+		return
+	}
+
+	response.Success(c, nil, "Price list deleted successfully")
+}
+
+// PreviewMenu shows the menu prices at the time given by ?at= (RFC3339),
+// now by default
+func (h *PriceListHandler) PreviewMenu(c *gin.Context) {
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.BadRequest(c, "Invalid at, expected RFC3339 time")
+			return
+		}
+		at = parsed
+	}
+
+	menu, err := h.priceListService.PreviewMenu(c.Request.Context(), at)
+	if err != nil {
+		response.InternalServerError(c, "Failed to preview menu: "+err.Error())
+		return
+	}
+
+	response.Success(c, menu, "Menu retrieved successfully")
+}
+
+func (h *PriceListHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *PriceListHandler) handleError(c *gin.Context, err error, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Price list not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
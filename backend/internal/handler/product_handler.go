@@ -3,6 +3,7 @@ package handler
 import (
 	"fmt"
 	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
 	"food-pos-backend/internal/service"
 	"food-pos-backend/pkg/response"
 	"net/http"
@@ -13,11 +14,13 @@ import (
 
 type ProductHandler struct {
 	productService *service.ProductService
+	userRepo       *repository.UserRepository
 }
 
-func NewProductHandler(productService *service.ProductService) *ProductHandler {
+func NewProductHandler(productService *service.ProductService, userRepo *repository.UserRepository) *ProductHandler {
 	return &ProductHandler{
 		productService: productService,
+		userRepo:       userRepo,
 	}
 }
 
@@ -28,7 +31,12 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.CreateProduct(c.Request.Context(), &req)
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.CreateProduct(c.Request.Context(), &req, userID)
 	if err != nil {
 		// Check if it's a validation error
 		if validationErr, ok := err.(*service.ValidationError); ok {
@@ -56,7 +64,12 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.UpdateProductByPublicID(c.Request.Context(), productID, &req)
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	product, err := h.productService.UpdateProductByPublicID(c.Request.Context(), productID, &req, userID)
 	if err != nil {
 		// Check if it's a validation error
 		if validationErr, ok := err.(*service.ValidationError); ok {
@@ -78,6 +91,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 func (h *ProductHandler) ListProducts(c *gin.Context) {
 	// Get query parameters
 	search := c.Query("search")
+	categoryID := c.Query("category_id")
 	page := c.DefaultQuery("page", "1")
 	limit := c.DefaultQuery("limit", "20")
 	sortBy := c.DefaultQuery("sort_by", "created_at")
@@ -104,6 +118,7 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 		"name":       true,
 		"created_at": true,
 		"updated_at": true,
+		"menu":       true,
 	}
 	if !validSortFields[sortBy] {
 		sortBy = "created_at"
@@ -111,11 +126,14 @@ func (h *ProductHandler) ListProducts(c *gin.Context) {
 
 	// Create filter
 	filter := &model.ProductFilter{
-		Search:    search,
-		Page:      pageNum,
-		Limit:     limitNum,
-		SortBy:    sortBy,
-		SortOrder: sortOrder,
+		Search:     search,
+		CategoryID: categoryID,
+		Page:       pageNum,
+		Limit:      limitNum,
+		SortBy:     sortBy,
+		SortOrder:  sortOrder,
+
+		IncludeArchived: c.Query("include_archived") == "true",
 	}
 
 	// Get products with pagination
@@ -143,3 +161,49 @@ func (h *ProductHandler) GetProductByID(c *gin.Context) {
 
 	response.SuccessWithStatus(c, http.StatusOK, "Product fetched successfully", product)
 }
+
+// ArchiveProduct archives a product instead of deleting it, so past orders keep resolving
+func (h *ProductHandler) ArchiveProduct(c *gin.Context) {
+	product, err := h.productService.ArchiveProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleArchiveError(c, err, "Failed to archive product")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusOK, "Product archived successfully", product)
+}
+
+// RestoreProduct puts an archived product back on the menu
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	product, err := h.productService.RestoreProduct(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleArchiveError(c, err, "Failed to restore product")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusOK, "Product restored successfully", product)
+}
+
+func (h *ProductHandler) handleArchiveError(c *gin.Context, err error, message string) {
+	if err.Error() == "product not found" {
+		response.Error(c, http.StatusNotFound, "Product not found")
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, message)
+}
+
+func (h *ProductHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
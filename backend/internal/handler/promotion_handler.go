@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler struct {
+	promotionService *service.PromotionService
+	orderService     *service.OrderService
+	userRepo         *repository.UserRepository
+}
+
+func NewPromotionHandler(promotionService *service.PromotionService, orderService *service.OrderService, userRepo *repository.UserRepository) *PromotionHandler {
+	return &PromotionHandler{
+		promotionService: promotionService,
+		orderService:     orderService,
+		userRepo:         userRepo,
+	}
+}
+
+// CreatePromotion creates a new promotion
+func (h *PromotionHandler) CreatePromotion(c *gin.Context) {
+	var req model.CreatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	promotion, err := h.promotionService.CreatePromotion(c.Request.Context(), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to create promotion: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Promotion created successfully", promotion)
+}
+
+// ListPromotions lists promotions, optionally filtered by is_active
+func (h *PromotionHandler) ListPromotions(c *gin.Context) {
+	var isActive *bool
+	if value := c.Query("is_active"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			response.BadRequest(c, "Invalid is_active")
+			return
+		}
+		isActive = &parsed
+	}
+
+	promotions, err := h.promotionService.ListPromotions(c.Request.Context(), isActive)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get promotions: "+err.Error())
+		return
+	}
+
+	response.Success(c, promotions, "Promotions retrieved successfully")
+}
+
+// GetPromotion gets a promotion by ID
+func (h *PromotionHandler) GetPromotion(c *gin.Context) {
+	promotion, err := h.promotionService.GetPromotion(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get promotion: ")
+		return
+	}
+
+	response.Success(c, promotion, "Promotion retrieved successfully")
+}
+
+// UpdatePromotion updates a promotion
+func (h *PromotionHandler) UpdatePromotion(c *gin.Context) {
+	var req model.UpdatePromotionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	promotion, err := h.promotionService.UpdatePromotion(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update promotion: ")
+		return
+	}
+
+	response.Success(c, promotion, "Promotion updated successfully")
+}
+
+// DeletePromotion deletes a promotion that was never applied
+func (h *PromotionHandler) DeletePromotion(c *gin.Context) {
+	if err := h.promotionService.DeletePromotion(c.Request.Context(), c.Param("id")); err != nil {
+		h.handleError(c, err, "Failed to delete promotion: ")
+		return
+	}
+
+	response.Success(c, nil, "Promotion deleted successfully")
+}
+
+// PreviewPromotions shows the savings a cart would get without creating an order
+func (h *PromotionHandler) PreviewPromotions(c *gin.Context) {
+	var req model.PromotionPreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	preview, err := h.orderService.PreviewPromotions(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to preview promotions: ")
+		return
+	}
+
+	response.Success(c, preview, "Promotions evaluated successfully")
+}
+
+func (h *PromotionHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *PromotionHandler) handleError(c *gin.Context, err error, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Promotion not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
@@ -0,0 +1,352 @@
+package handler
+
+import (
+	"net/http"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/service"
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ShipperAppHandler serves the shipper mobile app
+type ShipperAppHandler struct {
+	shipperService    *service.ShipperService
+	deliveryService   *service.DeliveryService
+	settlementService *service.CashSettlementService
+	userRepo          *repository.UserRepository
+}
+
+func NewShipperAppHandler(shipperService *service.ShipperService, deliveryService *service.DeliveryService, settlementService *service.CashSettlementService, userRepo *repository.UserRepository) *ShipperAppHandler {
+	return &ShipperAppHandler{
+		shipperService:    shipperService,
+		deliveryService:   deliveryService,
+		settlementService: settlementService,
+		userRepo:          userRepo,
+	}
+}
+
+// POST /api/shipper/login
+func (h *ShipperAppHandler) Login(c *gin.Context) {
+	var req model.ShipperLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	result, err := h.shipperService.Login(c.Request.Context(), &req)
+	if err != nil {
+		response.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response.Success(c, result, "Shipper login successful")
+}
+
+// POST /api/shipper/otp/request
+func (h *ShipperAppHandler) RequestLoginOTP(c *gin.Context) {
+	var req model.OTPSendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	result, err := h.shipperService.RequestLoginOTP(c.Request.Context(), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to send OTP: ")
+		return
+	}
+
+	response.Success(c, result, "OTP sent successfully")
+}
+
+// POST /api/shipper/otp/verify
+func (h *ShipperAppHandler) LoginWithOTP(c *gin.Context) {
+	var req model.OTPVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request body")
+		return
+	}
+
+	result, err := h.shipperService.LoginWithOTP(c.Request.Context(), &req)
+	if err != nil {
+		if _, ok := err.(*model.ValidationError); ok {
+			h.handleError(c, err, "")
+			return
+		}
+		response.Error(c, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	response.Success(c, result, "Shipper login successful")
+}
+
+// GetProfile returns the logged in shipper
+func (h *ShipperAppHandler) GetProfile(c *gin.Context) {
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+
+	response.Success(c, model.ShipperResponse{Shipper: *shipper}, "Shipper fetched successfully")
+}
+
+// ListDeliveries lists the deliveries assigned to the logged in shipper
+func (h *ShipperAppHandler) ListDeliveries(c *gin.Context) {
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+
+	var status *model.DeliveryStatus
+	if statusStr := c.Query("status"); statusStr != "" {
+		deliveryStatus := model.DeliveryStatus(statusStr)
+		status = &deliveryStatus
+	}
+
+	deliveries, err := h.deliveryService.ListShipperDeliveries(c.Request.Context(), shipper, status)
+	if err != nil {
+		response.InternalServerError(c, "Failed to list deliveries: "+err.Error())
+		return
+	}
+
+	response.Success(c, deliveries, "Deliveries retrieved successfully")
+}
+
+// GetDelivery gets one delivery of the logged in shipper
+func (h *ShipperAppHandler) GetDelivery(c *gin.Context) {
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+
+	deliveryOrder, err := h.deliveryService.GetShipperDelivery(c.Request.Context(), shipper, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get delivery: ")
+		return
+	}
+
+	response.Success(c, deliveryOrder, "Delivery retrieved successfully")
+}
+
+// GetDeliveryProofFile streams a proof photo or signature of one of the shipper's deliveries
+func (h *ShipperAppHandler) GetDeliveryProofFile(c *gin.Context) {
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+
+	proof, file, err := h.deliveryService.OpenShipperDeliveryProof(c.Request.Context(), shipper, c.Param("id"), c.Param("proofId"))
+	if err != nil {
+		h.handleError(c, err, "Failed to open delivery proof: ")
+		return
+	}
+	serveDeliveryProof(c, proof, file)
+}
+
+// AcceptDelivery accepts an assigned delivery
+func (h *ShipperAppHandler) AcceptDelivery(c *gin.Context) {
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	deliveryOrder, err := h.deliveryService.AcceptDelivery(c.Request.Context(), shipper, c.Param("id"), userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to accept delivery: ")
+		return
+	}
+
+	response.Success(c, deliveryOrder, "Delivery accepted successfully")
+}
+
+// RejectDelivery rejects an assigned delivery
+func (h *ShipperAppHandler) RejectDelivery(c *gin.Context) {
+	var req model.RejectDeliveryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	deliveryOrder, err := h.deliveryService.RejectDelivery(c.Request.Context(), shipper, c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to reject delivery: ")
+		return
+	}
+
+	response.Success(c, deliveryOrder, "Delivery rejected successfully")
+}
+
+// UpdateDeliveryStatus moves a delivery through picked_up -> in_transit -> delivered/failed
+func (h *ShipperAppHandler) UpdateDeliveryStatus(c *gin.Context) {
+	var req model.ShipperUpdateDeliveryStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	deliveryOrder, err := h.deliveryService.UpdateShipperDeliveryStatus(c.Request.Context(), shipper, c.Param("id"), &req, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to update delivery status: ")
+		return
+	}
+
+	response.Success(c, deliveryOrder, "Delivery status updated successfully")
+}
+
+// AddDeliveryProof uploads a proof photo/signature (multipart "file") with an optional geotag
+func (h *ShipperAppHandler) AddDeliveryProof(c *gin.Context) {
+	var req model.AddDeliveryProofRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		response.BadRequest(c, "Proof file is required")
+		return
+	}
+	if fileHeader.Size > maxDeliveryProofSize {
+		response.BadRequest(c, "Proof file must not exceed 5MB")
+		return
+	}
+
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		response.BadRequest(c, "Invalid proof file")
+		return
+	}
+	defer file.Close()
+
+	proof, err := h.deliveryService.AddShipperDeliveryProof(c.Request.Context(), shipper, c.Param("id"), &req, file, fileHeader.Size, userID)
+	if err != nil {
+		h.handleError(c, err, "Failed to add delivery proof: ")
+		return
+	}
+
+	response.SuccessWithStatus(c, http.StatusCreated, "Delivery proof added successfully", proof)
+}
+
+// GetProofPolicy returns the proof required before a delivery can be marked delivered
+func (h *ShipperAppHandler) GetProofPolicy(c *gin.Context) {
+	response.Success(c, h.deliveryService.GetProofPolicy(), "Delivery proof policy retrieved successfully")
+}
+
+// GetCashBalance returns the COD cash the logged in shipper has to hand over
+func (h *ShipperAppHandler) GetCashBalance(c *gin.Context) {
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.settlementService.GetOwnCashBalance(c.Request.Context(), shipper)
+	if err != nil {
+		response.InternalServerError(c, "Failed to get cash balance: "+err.Error())
+		return
+	}
+
+	response.Success(c, balance, "Cash balance retrieved successfully")
+}
+
+// SetShift starts or ends the shift of the logged in shipper
+func (h *ShipperAppHandler) SetShift(c *gin.Context) {
+	var req model.SetShiftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	shipper, ok := h.currentShipper(c)
+	if !ok {
+		return
+	}
+
+	shipper, err := h.shipperService.SetShift(c.Request.Context(), shipper, req.Availability)
+	if err != nil {
+		h.handleError(c, err, "Failed to update shift: ")
+		return
+	}
+
+	response.Success(c, model.ShipperResponse{Shipper: *shipper}, "Shift updated successfully")
+}
+
+// currentShipper resolves the shipper from the shipper_id token claim
+func (h *ShipperAppHandler) currentShipper(c *gin.Context) (*model.Shipper, bool) {
+	shipperPublicID, exists := c.Get("shipper_id")
+	if !exists {
+		response.Error(c, http.StatusForbidden, "Shipper account required")
+		return nil, false
+	}
+
+	shipper, err := h.shipperService.GetActiveShipper(c.Request.Context(), shipperPublicID.(string))
+	if err != nil {
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.Error(c, http.StatusForbidden, validationErr.Message)
+			return nil, false
+		}
+		response.Error(c, http.StatusForbidden, "Shipper not found")
+		return nil, false
+	}
+	return shipper, true
+}
+
+func (h *ShipperAppHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
+
+func (h *ShipperAppHandler) handleError(c *gin.Context, err error, prefix string) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Delivery not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, prefix+err.Error())
+}
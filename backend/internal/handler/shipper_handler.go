@@ -82,6 +82,9 @@ func (h *ShipperHandler) ListShippers(c *gin.Context) {
 			filters["is_active"] = isActive
 		}
 	}
+	if c.Query("include_archived") == "true" {
+		filters["include_archived"] = true
+	}
 
 	resp, err := h.shipperService.ListShippers(c.Request.Context(), filters, page, limit)
 	if err != nil {
@@ -124,17 +127,26 @@ func (h *ShipperHandler) UpdateShipper(c *gin.Context) {
 	response.Success(c, model.ShipperResponse{Shipper: *shipper}, "Shipper updated successfully")
 }
 
-// DeleteShipper deletes shipper
-func (h *ShipperHandler) DeleteShipper(c *gin.Context) {
-	publicID := c.Param("id")
+// ArchiveShipper archives shipper instead of deleting it
+func (h *ShipperHandler) ArchiveShipper(c *gin.Context) {
+	shipper, err := h.shipperService.ArchiveShipper(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, shipper, "Shipper archived successfully")
+}
 
-	err := h.shipperService.DeleteShipper(c.Request.Context(), publicID)
+// RestoreShipper restores an archived shipper
+func (h *ShipperHandler) RestoreShipper(c *gin.Context) {
+	shipper, err := h.shipperService.RestoreShipper(c.Request.Context(), c.Param("id"))
 	if err != nil {
-		response.InternalServerError(c, err.Error())
+		h.handleError(c, err)
 		return
 	}
 
-	response.Success(c, "Shipper deleted successfully", "Shipper deleted successfully")
+	response.Success(c, shipper, "Shipper restored successfully")
 }
 
 // GetActiveShippers gets all active shippers
@@ -147,3 +159,40 @@ func (h *ShipperHandler) GetActiveShippers(c *gin.Context) {
 
 	response.Success(c, shippers, "Active shippers fetched successfully")
 }
+
+// SetShipperAccount sets the login credentials of a shipper
+func (h *ShipperHandler) SetShipperAccount(c *gin.Context) {
+	var req model.SetShipperAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	shipper, err := h.shipperService.SetAccount(c.Request.Context(), c.Param("id"), &req)
+	if err != nil {
+		if err == service.ErrNotFound {
+			response.NotFound(c, "Shipper not found")
+			return
+		}
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			response.BadRequest(c, validationErr.Message)
+			return
+		}
+		response.InternalServerError(c, err.Error())
+		return
+	}
+
+	response.Success(c, model.ShipperResponse{Shipper: *shipper}, "Shipper account updated successfully")
+}
+
+func (h *ShipperHandler) handleError(c *gin.Context, err error) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Shipper not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, err.Error())
+}
@@ -1,6 +1,12 @@
 package handler
 
 import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
 	"food-pos-backend/internal/service"
 	"food-pos-backend/pkg/response"
 
@@ -9,11 +15,13 @@ import (
 
 type VariantHandler struct {
 	variantService *service.VariantService
+	userRepo       *repository.UserRepository
 }
 
-func NewVariantHandler(variantService *service.VariantService) *VariantHandler {
+func NewVariantHandler(variantService *service.VariantService, userRepo *repository.UserRepository) *VariantHandler {
 	return &VariantHandler{
 		variantService: variantService,
+		userRepo:       userRepo,
 	}
 }
 
@@ -30,7 +38,11 @@ func (h *VariantHandler) CreateVariant(c *gin.Context) {
 		response.BadRequest(c, "Invalid request body")
 		return
 	}
-	variant, err := h.variantService.CreateVariant(c.Request.Context(), req.ProductID, req.Name, req.Price)
+	userID, ok := h.currentUserID(c)
+	if !ok {
+		return
+	}
+	variant, err := h.variantService.CreateVariant(c.Request.Context(), req.ProductID, req.Name, req.Price, userID)
 	if err != nil {
 		response.InternalServerError(c, err.Error())
 		return
@@ -45,10 +57,107 @@ func (h *VariantHandler) ListVariantsByProduct(c *gin.Context) {
 		response.BadRequest(c, "Missing product_id")
 		return
 	}
-	variants, err := h.variantService.ListVariantsByProduct(c.Request.Context(), productID)
+	variants, err := h.variantService.ListVariantsByProduct(c.Request.Context(), productID, c.Query("include_archived") == "true")
 	if err != nil {
 		response.InternalServerError(c, err.Error())
 		return
 	}
 	response.Success(c, variants, "Variant list fetched successfully")
-} 
\ No newline at end of file
+}
+
+// DELETE /api/admin/variants/:variant_public_id
+// Archives the variant, order lines keep pointing at it
+func (h *VariantHandler) ArchiveVariant(c *gin.Context) {
+	variant, err := h.variantService.ArchiveVariant(c.Request.Context(), c.Param("variant_public_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Success(c, variant, "Variant archived successfully")
+}
+
+// POST /api/admin/variants/:variant_public_id/restore
+func (h *VariantHandler) RestoreVariant(c *gin.Context) {
+	variant, err := h.variantService.RestoreVariant(c.Request.Context(), c.Param("variant_public_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Success(c, variant, "Variant restored successfully")
+}
+
+// GET /api/admin/variants/:variant_public_id/price-history
+func (h *VariantHandler) GetPriceHistory(c *gin.Context) {
+	history, err := h.variantService.GetPriceHistory(c.Request.Context(), c.Param("variant_public_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Success(c, history, "Price history retrieved successfully")
+}
+
+// GET /api/admin/variants/:variant_public_id/price?at=
+// Regular price at the given time (RFC3339), now by default
+func (h *VariantHandler) GetPriceAt(c *gin.Context) {
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			response.BadRequest(c, "Invalid at, expected RFC3339 time")
+			return
+		}
+		at = parsed
+	}
+
+	price, err := h.variantService.GetPriceAt(c.Request.Context(), c.Param("variant_public_id"), at)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Success(c, price, "Price retrieved successfully")
+}
+
+// GET /api/admin/variants/:variant_public_id/price-impact?days=
+// Sales before and after each price change, 14 days each side by default
+func (h *VariantHandler) GetPriceChangeImpact(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "14"))
+	if err != nil {
+		response.BadRequest(c, "Invalid days")
+		return
+	}
+
+	impacts, err := h.variantService.GetPriceChangeImpact(c.Request.Context(), c.Param("variant_public_id"), days)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	response.Success(c, impacts, "Price change impact retrieved successfully")
+}
+
+func (h *VariantHandler) handleError(c *gin.Context, err error) {
+	if err == service.ErrNotFound {
+		response.NotFound(c, "Variant not found")
+		return
+	}
+	if validationErr, ok := err.(*model.ValidationError); ok {
+		response.BadRequest(c, validationErr.Message)
+		return
+	}
+	response.InternalServerError(c, err.Error())
+}
+
+func (h *VariantHandler) currentUserID(c *gin.Context) (int64, bool) {
+	userPublicID, exists := c.Get("user_id")
+	if !exists {
+		response.Error(c, http.StatusUnauthorized, "User not authenticated")
+		return 0, false
+	}
+
+	// Get internal user ID from database using public_id
+	user, err := h.userRepo.GetByPublicID(userPublicID.(string))
+	if err != nil {
+		response.BadRequest(c, "Invalid user")
+		return 0, false
+	}
+	return user.ID, true
+}
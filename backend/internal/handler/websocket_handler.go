@@ -1,55 +1,123 @@
 package handler
 
 import (
+	"food-pos-backend/internal/jwt"
 	"food-pos-backend/internal/ws"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
 )
 
 type WebSocketHandler struct {
-	hub *ws.Hub
+	hub            *ws.Hub
+	jwtService     *jwt.JWTService
+	allowedOrigins map[string]bool
+	upgrader       websocket.Upgrader
 }
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool {
-		return true // Can customize for security
-	},
-}
-
-func NewWebSocketHandler(hub *ws.Hub) *WebSocketHandler {
-	return &WebSocketHandler{
-		hub: hub,
+func NewWebSocketHandler(hub *ws.Hub, jwtService *jwt.JWTService, allowedOrigins []string) *WebSocketHandler {
+	h := &WebSocketHandler{
+		hub:            hub,
+		jwtService:     jwtService,
+		allowedOrigins: make(map[string]bool, len(allowedOrigins)),
+	}
+	for _, origin := range allowedOrigins {
+		h.allowedOrigins[strings.TrimRight(origin, "/")] = true
+	}
+	h.upgrader = websocket.Upgrader{
+		CheckOrigin: h.checkOrigin,
 	}
+	return h
 }
 
 // WebSocketHandler handles websocket requests
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	// Log thông tin request
-	log.Printf("[WebSocket] New connection attempt: remote=%s, origin=%s, url=%s, user-agent=%s", c.Request.RemoteAddr, c.Request.Header.Get("Origin"), c.Request.URL.String(), c.Request.UserAgent())
+	log.Printf("[WebSocket] New connection attempt: remote=%s, origin=%s, user-agent=%s", c.Request.RemoteAddr, c.Request.Header.Get("Origin"), c.Request.UserAgent())
 
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	// Xác thực JWT trước khi upgrade (trình duyệt không gửi được header nên cho phép ?token=)
+	claims, err := h.jwtService.ValidateToken(h.extractToken(c))
 	if err != nil {
-		log.Printf("[WebSocket] Upgrade failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upgrade to WebSocket"})
+		log.Printf("[WebSocket] Unauthorized: remote=%s, error=%v", c.Request.RemoteAddr, err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 		return
 	}
-	log.Printf("[WebSocket] Upgrade success: remote=%s, url=%s", c.Request.RemoteAddr, c.Request.URL.String())
 
-	role := c.Query("role") // Lấy role từ query param
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("[WebSocket] Upgrade failed: %v", err)
+		return
+	}
+	log.Printf("[WebSocket] Upgrade success: remote=%s, user=%s, role=%s", c.Request.RemoteAddr, claims.UserID, claims.Role)
 
 	client := &ws.Client{
-		Hub:  h.hub,
-		Conn: conn,
-		Send: make(chan []byte, 256),
-		Role: role,
-		// UserID: có thể lấy từ context nếu cần phân quyền
+		Hub:    h.hub,
+		Conn:   conn,
+		Send:   make(chan []byte, 256),
+		UserID: claims.UserID,
+		Role:   claims.Role,
+		Groups: groupsForRole(claims.Role),
 	}
 	h.hub.Register(client)
 
 	// Goroutine đọc và ghi message
 	go client.WritePump()
 	go client.ReadPump()
-}
\ No newline at end of file
+}
+
+// HandlePublicWebSocket accepts anonymous connections from the public menu.
+// They only receive events sent to every client, such as availability updates.
+func (h *WebSocketHandler) HandlePublicWebSocket(c *gin.Context) {
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		log.Printf("[WebSocket] Public upgrade failed: %v", err)
+		return
+	}
+
+	client := &ws.Client{
+		Hub:    h.hub,
+		Conn:   conn,
+		Send:   make(chan []byte, 256),
+		Groups: []string{"public"},
+	}
+	h.hub.Register(client)
+
+	go client.WritePump()
+	go client.ReadPump()
+}
+
+// extractToken lấy token từ header Authorization hoặc query param token
+func (h *WebSocketHandler) extractToken(c *gin.Context) string {
+	if authHeader := c.GetHeader("Authorization"); strings.HasPrefix(authHeader, "Bearer ") {
+		return strings.TrimPrefix(authHeader, "Bearer ")
+	}
+	return c.Query("token")
+}
+
+// checkOrigin chỉ cho phép các origin trong allow-list (client không phải trình duyệt không gửi Origin)
+func (h *WebSocketHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if h.allowedOrigins["*"] || h.allowedOrigins[strings.TrimRight(origin, "/")] {
+		return true
+	}
+	log.Printf("[WebSocket] Origin not allowed: %s", origin)
+	return false
+}
+
+// groupsForRole maps a JWT role to the broadcast groups it joins
+func groupsForRole(role string) []string {
+	switch role {
+	case "admin", "super_admin":
+		return []string{"admin", "kitchen"}
+	case "":
+		return nil
+	default:
+		return []string{role}
+	}
+}
@@ -7,11 +7,19 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// RoleShipper is the role of shipper mobile app accounts
+const RoleShipper = "shipper"
+
+// RoleClient is the role of registered customer portal accounts
+const RoleClient = "client"
+
 // Claims represents the JWT claims
 type Claims struct {
 	UserID   string `json:"user_id"`
 	Username string `json:"username"`
 	Role     string `json:"role"`
+	// ShipperID is the shipper public ID, set only for the "shipper" role
+	ShipperID string `json:"shipper_id,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -29,10 +37,20 @@ func NewJWTService(secretKey string) *JWTService {
 
 // GenerateToken generates a new JWT token
 func (j *JWTService) GenerateToken(userID, username, role string) (string, error) {
+	return j.generateToken(userID, username, role, "")
+}
+
+// GenerateShipperToken generates a JWT token for a shipper account
+func (j *JWTService) GenerateShipperToken(userID, username, shipperID string) (string, error) {
+	return j.generateToken(userID, username, RoleShipper, shipperID)
+}
+
+func (j *JWTService) generateToken(userID, username, role, shipperID string) (string, error) {
 	claims := Claims{
-		UserID:   userID,
-		Username: username,
-		Role:     role,
+		UserID:    userID,
+		Username:  username,
+		Role:      role,
+		ShipperID: shipperID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -75,7 +93,7 @@ func (j *JWTService) RefreshToken(tokenString string) (string, error) {
 	}
 
 	// Generate new token with extended expiration
-	return j.GenerateToken(claims.UserID, claims.Username, claims.Role)
+	return j.generateToken(claims.UserID, claims.Username, claims.Role, claims.ShipperID)
 }
 
 // VerifyToken là alias cho ValidateToken để dùng cho handler
@@ -43,6 +43,9 @@ func AuthMiddleware(jwtService *jwt.JWTService) gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("role", claims.Role)
+		if claims.ShipperID != "" {
+			c.Set("shipper_id", claims.ShipperID)
+		}
 
 		c.Next()
 	}
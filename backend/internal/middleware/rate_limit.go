@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"food-pos-backend/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter counts requests per key in fixed time windows
+type RateLimiter struct {
+	limit  int
+	window time.Duration
+
+	mu       sync.Mutex
+	counters map[string]*rateCounter
+}
+
+type rateCounter struct {
+	count   int
+	resetAt time.Time
+}
+
+// NewRateLimiter allows limit requests per key in each window; a limit of 0 or less disables limiting
+func NewRateLimiter(limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		limit:    limit,
+		window:   window,
+		counters: make(map[string]*rateCounter),
+	}
+}
+
+// Allow records a request for the key and reports whether it is within the limit
+func (l *RateLimiter) Allow(key string) bool {
+	if l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	counter, ok := l.counters[key]
+	if !ok || now.After(counter.resetAt) {
+		// Dọn các key đã hết hạn để map không phình ra theo số IP
+		if len(l.counters) > 10000 {
+			for k, c := range l.counters {
+				if now.After(c.resetAt) {
+					delete(l.counters, k)
+				}
+			}
+		}
+		counter = &rateCounter{resetAt: now.Add(l.window)}
+		l.counters[key] = counter
+	}
+
+	counter.count++
+	return counter.count <= l.limit
+}
+
+// RateLimitMiddleware rejects clients that exceed the limiter's rate, keyed by client IP
+func RateLimitMiddleware(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limiter.Allow(c.ClientIP()) {
+			response.Error(c, http.StatusTooManyRequests, "Bạn thao tác quá nhanh, vui lòng thử lại sau")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
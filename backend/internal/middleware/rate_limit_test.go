@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newRateLimitedEngine(t *testing.T, trustedProxies []string, limit int) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatalf("SetTrustedProxies: %v", err)
+	}
+	r.GET("/", RateLimitMiddleware(NewRateLimiter(limit, time.Minute)), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func sendFrom(r *gin.Engine, remoteAddr, forwardedFor string) int {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestRateLimitIgnoresSpoofedForwardedFor(t *testing.T) {
+	r := newRateLimitedEngine(t, nil, 2)
+
+	spoofed := []string{"1.1.1.1", "2.2.2.2", "3.3.3.3"}
+	codes := make([]int, 0, len(spoofed))
+	for _, ip := range spoofed {
+		codes = append(codes, sendFrom(r, "203.0.113.7:5000", ip))
+	}
+
+	if codes[0] != http.StatusOK || codes[1] != http.StatusOK {
+		t.Fatalf("first requests = %v, want 200s", codes[:2])
+	}
+	if codes[2] != http.StatusTooManyRequests {
+		t.Fatalf("third request with a new X-Forwarded-For = %d, want 429", codes[2])
+	}
+}
+
+func TestRateLimitUsesForwardedForFromTrustedProxy(t *testing.T) {
+	r := newRateLimitedEngine(t, []string{"10.0.0.0/8"}, 1)
+
+	if code := sendFrom(r, "10.0.0.1:5000", "198.51.100.1"); code != http.StatusOK {
+		t.Fatalf("first client = %d, want 200", code)
+	}
+	if code := sendFrom(r, "10.0.0.1:5000", "198.51.100.2"); code != http.StatusOK {
+		t.Fatalf("second client behind the proxy = %d, want 200", code)
+	}
+	if code := sendFrom(r, "10.0.0.1:5000", "198.51.100.1"); code != http.StatusTooManyRequests {
+		t.Fatalf("first client again = %d, want 429", code)
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+)
+
+// Why a variant cannot be sold right now
+const (
+	AvailabilityReasonProduct    = "product_unavailable" // Cả món bị tắt
+	AvailabilityReasonVariant    = "variant_unavailable" // Chỉ size/loại này bị tắt
+	AvailabilityReasonOutOfStock = "out_of_stock"        // Tự động: nguyên liệu không đủ
+	AvailabilityReasonArchived   = "archived"            // Món hoặc variant đã lưu trữ
+)
+
+// UpdateAvailabilityRequest marks a product or variant as available or sold
+// out. AvailableAt is the time it is expected back, after which it is sold again
+// automatically.
+type UpdateAvailabilityRequest struct {
+	IsAvailable *bool      `json:"is_available" binding:"required"`
+	AvailableAt *time.Time `json:"available_at"`
+}
+
+type UpdateVariantAvailabilityRequest struct {
+	UpdateAvailabilityRequest
+	AutoAvailability *bool `json:"auto_availability"` // Không gửi = giữ nguyên
+}
+
+// VariantAvailability is whether a variant can be ordered right now, taking
+// its product, its own flag and (when enabled) ingredient stock into account
+type VariantAvailability struct {
+	VariantID   string     `json:"variant_id" db:"variant_id"`
+	ProductID   string     `json:"product_id" db:"product_id"`
+	ProductName string     `json:"product_name" db:"product_name"`
+	VariantName string     `json:"variant_name" db:"variant_name"`
+	IsAvailable bool       `json:"is_available" db:"is_available"`
+	Reason      *string    `json:"reason,omitempty" db:"reason"`
+	AvailableAt *time.Time `json:"available_at,omitempty" db:"available_at"`
+}
+
+// AvailabilityUpdate is the websocket payload sent when variants become
+// available or sold out
+type AvailabilityUpdate struct {
+	Variants []*VariantAvailability `json:"variants"`
+}
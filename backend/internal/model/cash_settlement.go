@@ -0,0 +1,88 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Settlement results, derived from the discrepancy between received and expected cash
+const (
+	CashSettlementBalanced = "balanced" // Nộp đủ
+	CashSettlementShort    = "short"    // Nộp thiếu
+	CashSettlementOver     = "over"     // Nộp thừa
+)
+
+// CODDelivery is a delivered order whose cash is (or was) held by a shipper
+type CODDelivery struct {
+	DeliveryID     string     `json:"delivery_id" db:"public_id"`
+	DeliveryNumber string     `json:"delivery_number" db:"delivery_number"`
+	OrderID        string     `json:"order_id" db:"order_public_id"`
+	OrderNumber    string     `json:"order_number" db:"order_number"`
+	CodAmount      float64    `json:"cod_amount" db:"cod_amount"`
+	CollectedAt    *time.Time `json:"collected_at" db:"cod_collected_at"`
+}
+
+// ShipperCashBalance is the COD cash a shipper holds and has not handed over yet
+type ShipperCashBalance struct {
+	ShipperID         string        `json:"shipper_id" db:"shipper_public_id"`
+	ShipperName       string        `json:"shipper_name" db:"shipper_name"`
+	OutstandingAmount float64       `json:"outstanding_amount" db:"outstanding_amount"`
+	OutstandingCount  int           `json:"outstanding_count" db:"outstanding_count"`
+	OldestCollectedAt *time.Time    `json:"oldest_collected_at" db:"oldest_collected_at"`
+	Deliveries        []CODDelivery `json:"deliveries,omitempty" db:"-"`
+}
+
+// Shipper Cash Settlement Model
+type CashSettlement struct {
+	ID              int64          `json:"-" db:"id"`
+	PublicID        string         `json:"id" db:"public_id"`
+	ShipperID       int64          `json:"-" db:"shipper_id"`
+	ShipperPublicID string         `json:"shipper_id" db:"shipper_public_id"`
+	ShipperName     string         `json:"shipper_name" db:"shipper_name"`
+	ExpectedAmount  float64        `json:"expected_amount" db:"expected_amount"`
+	ReceivedAmount  float64        `json:"received_amount" db:"received_amount"`
+	Discrepancy     float64        `json:"discrepancy" db:"discrepancy"`
+	Status          string         `json:"status" db:"-"`
+	DeliveryCount   int            `json:"delivery_count" db:"delivery_count"`
+	Notes           sql.NullString `json:"notes" db:"notes"`
+	SettledBy       *int64         `json:"settled_by" db:"settled_by"`
+	CreatedAt       time.Time      `json:"created_at" db:"created_at"`
+
+	// Relations
+	Deliveries []CODDelivery `json:"deliveries,omitempty" db:"-"`
+}
+
+// SettlementStatus classifies the discrepancy of a settlement
+func SettlementStatus(discrepancy float64) string {
+	switch {
+	case discrepancy < 0:
+		return CashSettlementShort
+	case discrepancy > 0:
+		return CashSettlementOver
+	default:
+		return CashSettlementBalanced
+	}
+}
+
+// CreateCashSettlementRequest confirms the cash received from a shipper.
+// DeliveryIDs defaults to every outstanding COD delivery of the shipper
+type CreateCashSettlementRequest struct {
+	ReceivedAmount *float64 `json:"received_amount" binding:"required"`
+	DeliveryIDs    []string `json:"delivery_ids"`
+	Notes          string   `json:"notes"`
+}
+
+type ListCashSettlementsRequest struct {
+	Page           int    `form:"page"`
+	Limit          int    `form:"limit"`
+	ShipperID      string `form:"shipper_id"`
+	HasDiscrepancy *bool  `form:"has_discrepancy"`
+}
+
+type ListCashSettlementsResponse struct {
+	Settlements []CashSettlement `json:"settlements"`
+	Total       int              `json:"total"`
+	Page        int              `json:"page"`
+	Limit       int              `json:"limit"`
+	Pages       int              `json:"pages"`
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+)
+
+// Category is a menu section; categories nest through their parent
+type Category struct {
+	ID             int64       `json:"-" db:"id"`
+	PublicID       string      `json:"id" db:"public_id"`
+	ParentID       *int64      `json:"-" db:"parent_id"`
+	ParentPublicID *string     `json:"parent_id" db:"parent_public_id"`
+	Name           string      `json:"name" db:"name"`
+	Description    string      `json:"description" db:"description"`
+	SortOrder      int         `json:"sort_order" db:"sort_order"`
+	ProductCount   int         `json:"product_count" db:"product_count"`
+	CreatedAt      time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time   `json:"updated_at" db:"updated_at"`
+	Children       []*Category `json:"children"`
+}
+
+type CreateCategoryRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description string  `json:"description" binding:"omitempty,max=500"`
+	ParentID    *string `json:"parent_id"`  // Trống = danh mục gốc
+	SortOrder   *int    `json:"sort_order"` // Trống = xếp cuối danh mục cha
+}
+
+type UpdateCategoryRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description string  `json:"description" binding:"omitempty,max=500"`
+	ParentID    *string `json:"parent_id"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+// ReorderCategoriesRequest sets the order of the children of a category (or of
+// the root categories) to the order of CategoryIDs
+type ReorderCategoriesRequest struct {
+	ParentID    *string  `json:"parent_id"`
+	CategoryIDs []string `json:"category_ids" binding:"required,min=1"`
+}
+
+// SetCategoryProductsRequest sets the products of a category in menu order
+type SetCategoryProductsRequest struct {
+	ProductIDs []string `json:"product_ids" binding:"required"`
+}
@@ -0,0 +1,39 @@
+package model
+
+import "time"
+
+// CustomerAccount is a portal customer user, guest or registered
+type CustomerAccount struct {
+	ID           int64      `json:"-" db:"id"`
+	PublicID     string     `json:"id" db:"public_id"`
+	FullName     string     `json:"full_name" db:"full_name"`
+	Phone        string     `json:"phone" db:"phone"`
+	Email        string     `json:"email" db:"email"`
+	PasswordHash string     `json:"-" db:"password_hash"`
+	Role         string     `json:"-" db:"role"`
+	IsGuest      bool       `json:"-" db:"is_guest"`
+	IsActive     bool       `json:"-" db:"is_active"`
+	RegisteredAt *time.Time `json:"registered_at" db:"registered_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+type CustomerRegisterRequest struct {
+	FullName string `json:"full_name" binding:"required,max=100"`
+	Phone    string `json:"phone" binding:"required,max=20"`
+	Email    string `json:"email" binding:"max=100"`
+	Password string `json:"password" binding:"required,max=72"`
+	// OTP proves ownership of the phone: the code sent by POST /api/public/auth/register/otp
+	OTP string `json:"otp" binding:"max=10"`
+}
+
+type CustomerLoginRequest struct {
+	Login    string `json:"login" binding:"required"` // Phone or email
+	Password string `json:"password" binding:"required"`
+}
+
+type CustomerAuthResponse struct {
+	Token    string          `json:"token"`
+	Customer CustomerAccount `json:"customer"`
+	// ClaimedGuest is true when registration upgraded an existing guest user
+	ClaimedGuest bool `json:"claimed_guest"`
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Customer Address Model
+type CustomerAddress struct {
+	ID          int64     `json:"-" db:"id"`
+	PublicID    string    `json:"id" db:"public_id"`
+	UserID      int64     `json:"-" db:"user_id"`
+	Label       string    `json:"label" db:"label"`
+	AddressLine string    `json:"address_line" db:"address_line"`
+	Ward        string    `json:"ward" db:"ward"`
+	District    string    `json:"district" db:"district"`
+	Province    string    `json:"province" db:"province"`
+	Latitude    *float64  `json:"latitude" db:"latitude"`
+	Longitude   *float64  `json:"longitude" db:"longitude"`
+	IsDefault   bool      `json:"is_default" db:"is_default"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CustomerAddressRequest struct {
+	Label       string   `json:"label" binding:"max=50"`
+	AddressLine string   `json:"address_line" binding:"required,max=255"`
+	Ward        string   `json:"ward" binding:"max=100"`
+	District    string   `json:"district" binding:"max=100"`
+	Province    string   `json:"province" binding:"max=100"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
+	IsDefault   bool     `json:"is_default"`
+}
+
+// AddressSnapshot is the delivery address copied onto a delivery order, so
+// later edits of the order or of the saved address do not move a running delivery
+type AddressSnapshot struct {
+	RecipientName  string   `json:"recipient_name"`
+	RecipientPhone string   `json:"recipient_phone"`
+	Address        string   `json:"address"`
+	Ward           *string  `json:"ward"`
+	District       *string  `json:"district"`
+	Province       *string  `json:"province"`
+	Latitude       *float64 `json:"latitude"`
+	Longitude      *float64 `json:"longitude"`
+}
+
+func (a AddressSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(a)
+}
+
+func (a *AddressSnapshot) Scan(value interface{}) error {
+	data, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid address snapshot value")
+	}
+	return json.Unmarshal(data, a)
+}
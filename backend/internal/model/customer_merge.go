@@ -0,0 +1,78 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// MergeCustomer is a customer as shown to staff when finding and merging duplicates
+type MergeCustomer struct {
+	ID          int64      `json:"-" db:"id"`
+	PublicID    string     `json:"id" db:"public_id"`
+	FullName    string     `json:"full_name" db:"full_name"`
+	Phone       string     `json:"phone" db:"phone"`
+	Email       string     `json:"email" db:"email"`
+	IsGuest     bool       `json:"is_guest" db:"is_guest"`
+	TotalOrders int        `json:"total_orders" db:"total_orders"`
+	LastOrderAt *time.Time `json:"last_order_at" db:"last_order_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// Duplicate match reasons
+const (
+	DuplicateSamePhone   = "same_phone"   // Cùng số điện thoại sau khi chuẩn hóa
+	DuplicateSameEmail   = "same_email"   // Cùng email
+	DuplicatePhoneTypo   = "phone_typo"   // Tên giống nhau, số điện thoại lệch 1-2 chữ số
+	DuplicateSimilarName = "similar_name" // Tên giống nhau, thiếu sđt/email để so sánh
+)
+
+// DuplicateCandidate is a pair of customers that are probably the same person
+type DuplicateCandidate struct {
+	Customers [2]MergeCustomer `json:"customers"`
+	Score     float64          `json:"score"` // 0..1, higher is more likely the same person
+	Reasons   []string         `json:"reasons"`
+}
+
+type MergeCustomersRequest struct {
+	SourceUserID string `json:"source_user_id" binding:"required"` // Guest customer merged away
+	TargetUserID string `json:"target_user_id" binding:"required"` // Customer kept
+	Reason       string `json:"reason" binding:"max=500"`
+}
+
+// Customer Merge Model (audit)
+type CustomerMerge struct {
+	ID             int64                  `json:"-" db:"id"`
+	PublicID       string                 `json:"id" db:"public_id"`
+	SourceUserID   string                 `json:"source_user_id" db:"source_user_id"`
+	TargetUserID   string                 `json:"target_user_id" db:"target_user_id"`
+	SourceSnapshot MergedCustomerSnapshot `json:"source_snapshot" db:"source_snapshot"`
+	MovedOrders    int                    `json:"moved_orders" db:"moved_orders"`
+	MovedAddresses int                    `json:"moved_addresses" db:"moved_addresses"`
+	Reason         *string                `json:"reason" db:"reason"`
+	MergedBy       *string                `json:"merged_by" db:"merged_by"`
+	CreatedAt      time.Time              `json:"created_at" db:"created_at"`
+}
+
+// MergedCustomerSnapshot keeps the contact details of a merged customer, which
+// are cleared on the merged user so they can move to the kept customer
+type MergedCustomerSnapshot struct {
+	PublicID string `json:"id"`
+	FullName string `json:"full_name"`
+	Phone    string `json:"phone"`
+	Email    string `json:"email"`
+	IsGuest  bool   `json:"is_guest"`
+}
+
+func (s MergedCustomerSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *MergedCustomerSnapshot) Scan(value interface{}) error {
+	data, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid merged customer snapshot value")
+	}
+	return json.Unmarshal(data, s)
+}
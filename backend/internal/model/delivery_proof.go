@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type DeliveryProofType string
+
+const (
+	DeliveryProofPhoto     DeliveryProofType = "photo"
+	DeliveryProofSignature DeliveryProofType = "signature"
+)
+
+// Delivery Proof Model
+type DeliveryProof struct {
+	ID              int64             `json:"-" db:"id"`
+	PublicID        string            `json:"id" db:"public_id"`
+	DeliveryOrderID int64             `json:"-" db:"delivery_order_id"`
+	ProofType       DeliveryProofType `json:"type" db:"proof_type"`
+	FileKey         string            `json:"-" db:"file_key"` // Streamed by the proof file routes, never public
+	ContentType     string            `json:"content_type" db:"content_type"`
+	FileSize        int64             `json:"file_size" db:"file_size"`
+	Latitude        *float64          `json:"latitude" db:"latitude"`
+	Longitude       *float64          `json:"longitude" db:"longitude"`
+	UploadedBy      *int64            `json:"uploaded_by" db:"uploaded_by"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+}
+
+// AddDeliveryProofRequest is the multipart form sent along with the proof file
+type AddDeliveryProofRequest struct {
+	Type      DeliveryProofType `form:"type" binding:"required"`
+	Latitude  *float64          `form:"latitude"`
+	Longitude *float64          `form:"longitude"`
+}
+
+// DeliveryProofPolicy lists the proof required before a delivery can be marked delivered
+type DeliveryProofPolicy struct {
+	RequirePhoto     bool `json:"require_photo"`
+	RequireSignature bool `json:"require_signature"`
+	RequireLocation  bool `json:"require_location"`
+}
+
+// NewDeliveryProofPolicy builds a policy from names such as "photo", "signature", "location".
+// An unknown name is an error so a typo does not silently drop a requirement
+func NewDeliveryProofPolicy(required []string) (DeliveryProofPolicy, error) {
+	var policy DeliveryProofPolicy
+	for _, name := range required {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "photo":
+			policy.RequirePhoto = true
+		case "signature":
+			policy.RequireSignature = true
+		case "location":
+			policy.RequireLocation = true
+		default:
+			return policy, fmt.Errorf("unknown delivery proof %q, expected photo, signature or location", name)
+		}
+	}
+	return policy, nil
+}
+
+// Check returns a validation error when the proofs do not satisfy the policy
+func (p DeliveryProofPolicy) Check(proofs []DeliveryProof) error {
+	var hasPhoto, hasSignature, hasLocation bool
+	for _, proof := range proofs {
+		switch proof.ProofType {
+		case DeliveryProofPhoto:
+			hasPhoto = true
+		case DeliveryProofSignature:
+			hasSignature = true
+		}
+		if proof.Latitude != nil && proof.Longitude != nil {
+			hasLocation = true
+		}
+	}
+
+	if p.RequirePhoto && !hasPhoto {
+		return NewValidationError("proof", "Cần chụp ảnh giao hàng trước khi hoàn tất đơn giao")
+	}
+	if p.RequireSignature && !hasSignature {
+		return NewValidationError("proof", "Cần chữ ký người nhận trước khi hoàn tất đơn giao")
+	}
+	if p.RequireLocation && !hasLocation {
+		return NewValidationError("proof", "Cần vị trí giao hàng trước khi hoàn tất đơn giao")
+	}
+	return nil
+}
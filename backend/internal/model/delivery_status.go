@@ -0,0 +1,84 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+// DeliveryStatusTransitions is the delivery state machine: the statuses each
+// status may move to. Statuses without entries are terminal
+var DeliveryStatusTransitions = map[DeliveryStatus][]DeliveryStatus{
+	DeliveryStatusPending: {
+		DeliveryStatusAssigned,
+		DeliveryStatusCancelled,
+	},
+	DeliveryStatusAssigned: {
+		DeliveryStatusPending, // Bỏ gán / shipper từ chối
+		DeliveryStatusPickedUp,
+		DeliveryStatusCancelled,
+	},
+	DeliveryStatusPickedUp: {
+		DeliveryStatusInTransit,
+		DeliveryStatusFailed,
+		DeliveryStatusCancelled,
+	},
+	DeliveryStatusInTransit: {
+		DeliveryStatusDelivered,
+		DeliveryStatusFailed,
+	},
+	DeliveryStatusDelivered: {},
+	DeliveryStatusFailed:    {},
+	DeliveryStatusCancelled: {},
+}
+
+// IsValid reports whether the status is a known delivery status
+func (s DeliveryStatus) IsValid() bool {
+	_, ok := DeliveryStatusTransitions[s]
+	return ok
+}
+
+// CanTransitionTo reports whether a delivery may move from s to next
+func (s DeliveryStatus) CanTransitionTo(next DeliveryStatus) bool {
+	for _, allowed := range DeliveryStatusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether the delivery is finished; shipper and items are locked
+func (s DeliveryStatus) IsTerminal() bool {
+	return s.IsValid() && len(DeliveryStatusTransitions[s]) == 0
+}
+
+// TimestampColumn returns the delivery_orders column stamped when entering the status
+func (s DeliveryStatus) TimestampColumn() string {
+	switch s {
+	case DeliveryStatusAssigned:
+		return "assigned_at"
+	case DeliveryStatusPickedUp:
+		return "picked_up_at"
+	case DeliveryStatusInTransit:
+		return "in_transit_at"
+	case DeliveryStatusDelivered:
+		return "actual_delivery_time"
+	case DeliveryStatusFailed:
+		return "failed_at"
+	case DeliveryStatusCancelled:
+		return "cancelled_at"
+	default:
+		return ""
+	}
+}
+
+// Delivery Status History Model
+type DeliveryStatusHistory struct {
+	ID              int64           `json:"-" db:"id"`
+	DeliveryOrderID int64           `json:"-" db:"delivery_order_id"`
+	Status          DeliveryStatus  `json:"status" db:"status"`
+	PreviousStatus  *DeliveryStatus `json:"previous_status" db:"previous_status"`
+	Notes           sql.NullString  `json:"notes" db:"notes"`
+	ChangedBy       *int64          `json:"changed_by" db:"changed_by"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"food-pos-backend/internal/geo"
+)
+
+// Delivery zone shapes
+const (
+	ZoneTypeRadius  = "radius"  // Bán kính quanh cửa hàng
+	ZoneTypePolygon = "polygon" // Đa giác
+)
+
+// Shipping fee rules
+const (
+	ZoneFeeFlat  = "flat"   // Phí cố định
+	ZoneFeePerKm = "per_km" // Phí mở cửa + phí theo km
+)
+
+// How the shipping fee of an order was set
+const (
+	ShippingFeeSourceAuto     = "auto"     // Tính theo vùng giao hàng
+	ShippingFeeSourceManual   = "manual"   // Nhân viên nhập (không có tọa độ)
+	ShippingFeeSourceOverride = "override" // Nhân viên sửa phí đã tính
+)
+
+// ZonePolygon is the polygon of a delivery zone, stored as JSONB
+type ZonePolygon []geo.Point
+
+func (p ZonePolygon) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return json.Marshal(p)
+}
+
+func (p *ZonePolygon) Scan(value interface{}) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+	data, ok := value.([]byte)
+	if !ok {
+		return errors.New("invalid polygon value")
+	}
+	return json.Unmarshal(data, p)
+}
+
+// Delivery Zone Model
+type DeliveryZone struct {
+	ID                int64       `json:"-" db:"id"`
+	PublicID          string      `json:"id" db:"public_id"`
+	Code              string      `json:"code" db:"code"`
+	Name              string      `json:"name" db:"name"`
+	ZoneType          string      `json:"zone_type" db:"zone_type"`
+	RadiusKm          *float64    `json:"radius_km" db:"radius_km"`
+	Polygon           ZonePolygon `json:"polygon" db:"polygon"`
+	FeeType           string      `json:"fee_type" db:"fee_type"`
+	BaseFee           float64     `json:"base_fee" db:"base_fee"`
+	PerKmFee          float64     `json:"per_km_fee" db:"per_km_fee"`
+	FreeAboveSubtotal *float64    `json:"free_above_subtotal" db:"free_above_subtotal"`
+	Priority          int         `json:"priority" db:"priority"`
+	IsActive          bool        `json:"is_active" db:"is_active"`
+	CreatedBy         *int64      `json:"created_by" db:"created_by"`
+	CreatedAt         time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// Contains reports whether a point of the given distance from the store lies in the zone
+func (z *DeliveryZone) Contains(point geo.Point, distanceKm float64) bool {
+	switch z.ZoneType {
+	case ZoneTypeRadius:
+		return z.RadiusKm != nil && distanceKm <= *z.RadiusKm
+	case ZoneTypePolygon:
+		return geo.InPolygon(point, z.Polygon)
+	default:
+		return false
+	}
+}
+
+// Fee computes the shipping fee for a delivery distance and order subtotal
+func (z *DeliveryZone) Fee(distanceKm, subtotal float64) float64 {
+	if z.FreeAboveSubtotal != nil && subtotal >= *z.FreeAboveSubtotal {
+		return 0
+	}
+	fee := z.BaseFee
+	if z.FeeType == ZoneFeePerKm {
+		fee += z.PerKmFee * distanceKm
+	}
+	// Làm tròn lên hàng nghìn đồng
+	return math.Ceil(fee/1000) * 1000
+}
+
+type DeliveryZoneRequest struct {
+	Code              string      `json:"code" binding:"required,max=50"`
+	Name              string      `json:"name" binding:"required,max=100"`
+	ZoneType          string      `json:"zone_type" binding:"required"`
+	RadiusKm          *float64    `json:"radius_km"`
+	Polygon           ZonePolygon `json:"polygon"`
+	FeeType           string      `json:"fee_type" binding:"required"`
+	BaseFee           float64     `json:"base_fee"`
+	PerKmFee          float64     `json:"per_km_fee"`
+	FreeAboveSubtotal *float64    `json:"free_above_subtotal"`
+	Priority          int         `json:"priority"`
+	IsActive          *bool       `json:"is_active"`
+}
+
+// ShippingQuoteRequest asks the shipping fee for a delivery address
+type ShippingQuoteRequest struct {
+	Latitude  float64 `json:"latitude" binding:"required"`
+	Longitude float64 `json:"longitude" binding:"required"`
+	Subtotal  float64 `json:"subtotal"`
+}
+
+// ShippingQuote is the zone, distance and fee of a delivery address
+type ShippingQuote struct {
+	Zone       *DeliveryZone `json:"zone"`
+	DistanceKm float64       `json:"distance_km"`
+	Fee        float64       `json:"fee"`
+}
+
+// UpdateShippingFeeRequest overrides the shipping fee of an order
+type UpdateShippingFeeRequest struct {
+	ShippingFee *float64 `json:"shipping_fee" binding:"required"`
+	Reason      string   `json:"reason"`
+}
+
+// Shipping Fee Audit Model
+type ShippingFeeAudit struct {
+	ID            int64     `json:"-" db:"id"`
+	OrderID       int64     `json:"-" db:"order_id"`
+	CalculatedFee *float64  `json:"calculated_fee" db:"calculated_fee"`
+	PreviousFee   *float64  `json:"previous_fee" db:"previous_fee"`
+	AppliedFee    float64   `json:"applied_fee" db:"applied_fee"`
+	Reason        string    `json:"reason" db:"reason"`
+	ChangedBy     *int64    `json:"changed_by" db:"changed_by"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
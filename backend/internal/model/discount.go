@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+)
+
+// Discount Code Usage Model (one row per redemption)
+type DiscountCodeUsage struct {
+	ID             int64     `json:"-" db:"id"`
+	DiscountCodeID int64     `json:"-" db:"discount_code_id"`
+	OrderID        int64     `json:"-" db:"order_id"`
+	OrderPublicID  string    `json:"order_id" db:"order_public_id"`
+	OrderNumber    string    `json:"order_number" db:"order_number"`
+	CustomerPhone  string    `json:"customer_phone" db:"customer_phone"`
+	DiscountAmount float64   `json:"discount_amount" db:"discount_amount"`
+	CreatedBy      int64     `json:"created_by" db:"created_by"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// DiscountLine is an order line used to compute product/variant scoped discounts
+type DiscountLine struct {
+	ProductID int64
+	VariantID int64
+	Amount    float64
+}
+
+// DiscountEvaluation is the input for validating a discount code against an order
+type DiscountEvaluation struct {
+	Code                 string
+	CustomerPhone        string
+	OrderAmount          float64
+	ManualDiscountAmount float64
+	Lines                []DiscountLine
+}
+
+// List Discount Codes Request/Response
+type ListDiscountCodesRequest struct {
+	Page     int    `json:"page" binding:"gte=1"`
+	Limit    int    `json:"limit" binding:"gte=1,lte=100"`
+	Search   string `json:"search"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type ListDiscountCodesResponse struct {
+	DiscountCodes []*DiscountCode `json:"discount_codes"`
+	Total         int             `json:"total"`
+	Page          int             `json:"page"`
+	Limit         int             `json:"limit"`
+	Pages         int             `json:"pages"`
+}
+
+// IsScoped reports whether the code only applies to specific products or variants
+func (d *DiscountCode) IsScoped() bool {
+	return len(d.ProductIDs) > 0 || len(d.VariantIDs) > 0
+}
+
+// CalculateAmount returns the discount for the given eligible amount
+func (d *DiscountCode) CalculateAmount(eligibleAmount float64) float64 {
+	var amount float64
+	if d.DiscountType == DiscountTypePercentage {
+		amount = eligibleAmount * (d.DiscountValue / 100)
+		if d.MaxDiscountAmount != nil && amount > *d.MaxDiscountAmount {
+			amount = *d.MaxDiscountAmount
+		}
+	} else {
+		amount = d.DiscountValue
+	}
+	if amount > eligibleAmount {
+		amount = eligibleAmount
+	}
+	return amount
+}
@@ -5,25 +5,30 @@ import (
 )
 
 type Ingredient struct {
-	ID        int64     `json:"-" db:"id"`
-	PublicID  string    `json:"id" db:"public_id"`
-	Name      string    `json:"name" db:"name"`
-	UnitPrice float64   `json:"unit_price" db:"unit_price"`
-	Unit      string    `json:"unit" db:"unit"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID                int64      `json:"-" db:"id"`
+	PublicID          string     `json:"id" db:"public_id"`
+	Name              string     `json:"name" db:"name"`
+	UnitPrice         float64    `json:"unit_price" db:"unit_price"`
+	Unit              string     `json:"unit" db:"unit"`
+	StockQuantity     float64    `json:"stock_quantity" db:"stock_quantity"`
+	LowStockThreshold float64    `json:"low_stock_threshold" db:"low_stock_threshold"`
+	ArchivedAt        *time.Time `json:"archived_at" db:"archived_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
 }
 
 type CreateIngredientRequest struct {
-	Name      string  `json:"name" validate:"required,max=200"`
-	UnitPrice float64 `json:"unit_price" validate:"required,gt=0"`
-	Unit      string  `json:"unit" validate:"required,max=50"`
+	Name              string  `json:"name" validate:"required,max=200"`
+	UnitPrice         float64 `json:"unit_price" validate:"required,gt=0"`
+	Unit              string  `json:"unit" validate:"required,max=50"`
+	LowStockThreshold float64 `json:"low_stock_threshold" validate:"gte=0"`
 }
 
 type UpdateIngredientRequest struct {
-	Name      string  `json:"name" validate:"required,max=200"`
-	UnitPrice float64 `json:"unit_price" validate:"required,gt=0"`
-	Unit      string  `json:"unit" validate:"required,max=50"`
+	Name              string  `json:"name" validate:"required,max=200"`
+	UnitPrice         float64 `json:"unit_price" validate:"required,gt=0"`
+	Unit              string  `json:"unit" validate:"required,max=50"`
+	LowStockThreshold float64 `json:"low_stock_threshold" validate:"gte=0"`
 }
 
 type VariantIngredient struct {
@@ -0,0 +1,115 @@
+package model
+
+import (
+	"time"
+)
+
+// Kitchen Item Status Enum
+type KitchenItemStatus string
+
+const (
+	KitchenItemStatusQueued     KitchenItemStatus = "queued"      // Chờ làm
+	KitchenItemStatusInProgress KitchenItemStatus = "in_progress" // Đang làm
+	KitchenItemStatusDone       KitchenItemStatus = "done"        // Đã xong
+	KitchenItemStatusBumped     KitchenItemStatus = "bumped"      // Đã trả món
+)
+
+// Kitchen Station Model (bar, tea, topping, ...)
+type KitchenStation struct {
+	ID         int64     `json:"-" db:"id"`
+	PublicID   string    `json:"id" db:"public_id"`
+	Name       string    `json:"name" db:"name"`
+	Code       string    `json:"code" db:"code"`
+	IsDefault  bool      `json:"is_default" db:"is_default"`
+	SortOrder  int       `json:"sort_order" db:"sort_order"`
+	IsActive   bool      `json:"is_active" db:"is_active"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+	ProductIDs []string  `json:"product_ids"`
+}
+
+// Kitchen Ticket Model (one order item on its station)
+type KitchenTicket struct {
+	ID              int64             `json:"-" db:"id"`
+	PublicID        string            `json:"id" db:"public_id"`
+	OrderID         int64             `json:"-" db:"order_id"`
+	OrderPublicID   string            `json:"order_id" db:"order_public_id"`
+	OrderNumber     string            `json:"order_number" db:"order_number"`
+	OrderStatus     OrderStatus       `json:"order_status" db:"order_status"`
+	OrderItemID     int64             `json:"-" db:"order_item_id"`
+	StationID       int64             `json:"-" db:"kitchen_station_id"`
+	StationPublicID string            `json:"station_id" db:"station_public_id"`
+	StationName     string            `json:"station_name" db:"station_name"`
+	ProductName     string            `json:"product_name" db:"product_name"`
+	VariantName     string            `json:"variant_name" db:"variant_name"`
+	Quantity        int               `json:"quantity" db:"quantity"`
+	Notes           *string           `json:"notes" db:"notes"`
+	Status          KitchenItemStatus `json:"status" db:"status"`
+	StartedAt       *time.Time        `json:"started_at" db:"started_at"`
+	CompletedAt     *time.Time        `json:"completed_at" db:"completed_at"`
+	BumpedAt        *time.Time        `json:"bumped_at" db:"bumped_at"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+
+	Modifiers []OrderItemModifier `json:"modifiers"`
+}
+
+// KitchenUpdate is the WebSocket payload describing the tickets of one order
+type KitchenUpdate struct {
+	OrderID     string           `json:"order_id"`
+	OrderNumber string           `json:"order_number"`
+	OrderStatus OrderStatus      `json:"order_status"`
+	Tickets     []*KitchenTicket `json:"tickets"`
+}
+
+// Kitchen Station Request Models
+type CreateKitchenStationRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Code      string `json:"code" binding:"required,max=50"`
+	IsDefault bool   `json:"is_default"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type UpdateKitchenStationRequest struct {
+	Name      string `json:"name" binding:"required,max=100"`
+	Code      string `json:"code" binding:"required,max=50"`
+	IsDefault bool   `json:"is_default"`
+	SortOrder int    `json:"sort_order"`
+	IsActive  *bool  `json:"is_active"`
+}
+
+// SetKitchenStationProductsRequest routes products to a station
+type SetKitchenStationProductsRequest struct {
+	ProductIDs []string `json:"product_ids"`
+}
+
+type UpdateKitchenTicketStatusRequest struct {
+	Status KitchenItemStatus `json:"status" binding:"required"`
+}
+
+// KitchenQueueRequest filters the live queue
+type KitchenQueueRequest struct {
+	StationID     string `json:"station_id"`
+	IncludeBumped bool   `json:"include_bumped"`
+}
+
+// CanTransitionTo reports whether a ticket may move to the next status
+func (s KitchenItemStatus) CanTransitionTo(next KitchenItemStatus) bool {
+	allowed := map[KitchenItemStatus][]KitchenItemStatus{
+		KitchenItemStatusQueued:     {KitchenItemStatusInProgress, KitchenItemStatusDone},
+		KitchenItemStatusInProgress: {KitchenItemStatusQueued, KitchenItemStatusDone},
+		KitchenItemStatusDone:       {KitchenItemStatusInProgress, KitchenItemStatusBumped},
+		KitchenItemStatusBumped:     {KitchenItemStatusDone}, // Recall
+	}
+	for _, status := range allowed[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinished reports whether the item has been prepared
+func (s KitchenItemStatus) IsFinished() bool {
+	return s == KitchenItemStatusDone || s == KitchenItemStatusBumped
+}
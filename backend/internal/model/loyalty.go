@@ -0,0 +1,128 @@
+package model
+
+import (
+	"time"
+)
+
+// Loyalty ledger entry types
+type LoyaltyEntryType string
+
+const (
+	LoyaltyEntryEarn         LoyaltyEntryType = "earn"          // Tích điểm khi đơn hoàn thành
+	LoyaltyEntryRedeem       LoyaltyEntryType = "redeem"        // Dùng điểm giảm giá đơn hàng
+	LoyaltyEntryEarnReversal LoyaltyEntryType = "earn_reversal" // Thu hồi điểm đã tích khi đơn bị hủy
+	LoyaltyEntryRedeemRefund LoyaltyEntryType = "redeem_refund" // Hoàn điểm đã dùng khi đơn bị hủy
+	LoyaltyEntryAdjustment   LoyaltyEntryType = "adjustment"    // Nhân viên điều chỉnh
+)
+
+// Loyalty tier codes, lowest first
+const (
+	LoyaltyTierMember = "member"
+	LoyaltyTierSilver = "silver"
+	LoyaltyTierGold   = "gold"
+)
+
+// LoyaltySettings are the earn and redeem rules of the loyalty program
+type LoyaltySettings struct {
+	IsEnabled        bool      `json:"is_enabled" db:"is_enabled"`
+	PointsPerVND     float64   `json:"points_per_vnd" db:"points_per_vnd"`         // Điểm cho mỗi 1đ đã thanh toán tiền hàng
+	PointValue       float64   `json:"point_value" db:"point_value"`               // Số tiền giảm cho mỗi điểm khi đổi
+	MaxRedeemPercent float64   `json:"max_redeem_percent" db:"max_redeem_percent"` // % tối đa của tiền hàng được trừ bằng điểm
+	TierWindowDays   int       `json:"tier_window_days" db:"tier_window_days"`     // Số ngày chi tiêu gần nhất dùng để xét hạng
+	UpdatedAt        time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateLoyaltySettingsRequest struct {
+	IsEnabled        *bool    `json:"is_enabled"`
+	PointsPerVND     *float64 `json:"points_per_vnd"`
+	PointValue       *float64 `json:"point_value"`
+	MaxRedeemPercent *float64 `json:"max_redeem_percent"`
+	TierWindowDays   *int     `json:"tier_window_days"`
+}
+
+// LoyaltyTier is a tier level reached by spending within the tier window
+type LoyaltyTier struct {
+	Code           string    `json:"code" db:"code"`
+	Name           string    `json:"name" db:"name"`
+	MinSpent       float64   `json:"min_spent" db:"min_spent"`
+	EarnMultiplier float64   `json:"earn_multiplier" db:"earn_multiplier"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type UpdateLoyaltyTierRequest struct {
+	Name           string  `json:"name" binding:"required,max=50"`
+	MinSpent       float64 `json:"min_spent" binding:"gte=0"`
+	EarnMultiplier float64 `json:"earn_multiplier" binding:"required,gt=0"`
+}
+
+// LoyaltyProductBonus gives extra points per unit sold of a product
+type LoyaltyProductBonus struct {
+	ID              int64     `json:"-" db:"id"`
+	ProductID       int64     `json:"-" db:"product_id"`
+	ProductPublicID string    `json:"product_id" db:"product_public_id"`
+	ProductName     string    `json:"product_name" db:"product_name"`
+	BonusPoints     int       `json:"bonus_points" db:"bonus_points"`
+	IsActive        bool      `json:"is_active" db:"is_active"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type SetLoyaltyProductBonusRequest struct {
+	BonusPoints int   `json:"bonus_points" binding:"required,gt=0"`
+	IsActive    *bool `json:"is_active"`
+}
+
+// LoyaltyAccount is the points balance and tier of a customer
+type LoyaltyAccount struct {
+	UserID         int64      `json:"-" db:"user_id"`
+	UserPublicID   string     `json:"user_id" db:"user_public_id"`
+	FullName       string     `json:"full_name" db:"full_name"`
+	Phone          string     `json:"phone" db:"phone"`
+	PointsBalance  int        `json:"points_balance" db:"points_balance"`
+	LifetimePoints int        `json:"lifetime_points" db:"lifetime_points"`
+	Tier           string     `json:"tier" db:"tier"`
+	TierName       string     `json:"tier_name" db:"tier_name"`
+	TierSpent      float64    `json:"tier_spent" db:"tier_spent"`
+	TierUpdatedAt  *time.Time `json:"tier_updated_at" db:"tier_updated_at"`
+	PointValue     float64    `json:"point_value" db:"point_value"` // Giá trị quy đổi hiện tại của 1 điểm
+}
+
+// LoyaltyLedgerEntry is one change of a points balance
+type LoyaltyLedgerEntry struct {
+	ID           int64            `json:"-" db:"id"`
+	PublicID     string           `json:"id" db:"public_id"`
+	UserID       int64            `json:"-" db:"user_id"`
+	OrderID      *int64           `json:"-" db:"order_id"`
+	OrderNumber  *string          `json:"order_number,omitempty" db:"order_number"`
+	EntryType    LoyaltyEntryType `json:"entry_type" db:"entry_type"`
+	Points       int              `json:"points" db:"points"`
+	BalanceAfter int              `json:"balance_after" db:"balance_after"`
+	Note         *string          `json:"note,omitempty" db:"note"`
+	CreatedBy    *string          `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt    time.Time        `json:"created_at" db:"created_at"`
+}
+
+type ListLoyaltyLedgerRequest struct {
+	Page  int `json:"page"`
+	Limit int `json:"limit"`
+}
+
+type ListLoyaltyLedgerResponse struct {
+	Entries []LoyaltyLedgerEntry `json:"entries"`
+	Total   int                  `json:"total"`
+	Page    int                  `json:"page"`
+	Limit   int                  `json:"limit"`
+	Pages   int                  `json:"pages"`
+}
+
+// AdjustLoyaltyPointsRequest adds (positive) or removes (negative) points by hand
+type AdjustLoyaltyPointsRequest struct {
+	Points int    `json:"points" binding:"required"`
+	Note   string `json:"note" binding:"required,max=500"`
+}
+
+// TierRecalculationResult summarizes a tier recalculation run
+type TierRecalculationResult struct {
+	Evaluated int `json:"evaluated" db:"evaluated"`
+	Changed   int `json:"changed" db:"changed"`
+}
@@ -0,0 +1,102 @@
+package model
+
+import (
+	"time"
+)
+
+// Modifier Group Model (e.g. sugar level, ice level, toppings)
+type ModifierGroup struct {
+	ID          int64            `json:"-" db:"id"`
+	PublicID    string           `json:"id" db:"public_id"`
+	Name        string           `json:"name" db:"name"`
+	Description *string          `json:"description,omitempty" db:"description"`
+	MinSelect   int              `json:"min_select" db:"min_select"`
+	MaxSelect   int              `json:"max_select" db:"max_select"`
+	SortOrder   int              `json:"sort_order" db:"sort_order"`
+	IsActive    bool             `json:"is_active" db:"is_active"`
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time        `json:"updated_at" db:"updated_at"`
+	Options     []ModifierOption `json:"options"`
+}
+
+// Modifier Option Model (e.g. 50% sugar, less ice, + pearl)
+type ModifierOption struct {
+	ID                 int64     `json:"-" db:"id"`
+	PublicID           string    `json:"id" db:"public_id"`
+	ModifierGroupID    int64     `json:"-" db:"modifier_group_id"`
+	Name               string    `json:"name" db:"name"`
+	PriceDelta         float64   `json:"price_delta" db:"price_delta"`
+	IngredientID       *int64    `json:"-" db:"ingredient_id"`
+	IngredientPublicID *string   `json:"ingredient_id,omitempty" db:"ingredient_public_id"`
+	IngredientQuantity float64   `json:"ingredient_quantity" db:"ingredient_quantity"`
+	IsDefault          bool      `json:"is_default" db:"is_default"`
+	SortOrder          int       `json:"sort_order" db:"sort_order"`
+	IsActive           bool      `json:"is_active" db:"is_active"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Order Item Modifier Model (selected option snapshot)
+type OrderItemModifier struct {
+	ID                 int64     `json:"-" db:"id"`
+	OrderItemID        int64     `json:"-" db:"order_item_id"`
+	ModifierOptionID   *int64    `json:"-" db:"modifier_option_id"`
+	GroupName          string    `json:"group_name" db:"group_name"`
+	OptionName         string    `json:"option_name" db:"option_name"`
+	PriceDelta         float64   `json:"price_delta" db:"price_delta"`
+	IngredientID       *int64    `json:"-" db:"ingredient_id"`
+	IngredientQuantity float64   `json:"-" db:"ingredient_quantity"`
+	CreatedAt          time.Time `json:"created_at" db:"created_at"`
+}
+
+// Modifier Group Request Models
+type CreateModifierGroupRequest struct {
+	Name        string                        `json:"name" binding:"required,max=100"`
+	Description *string                       `json:"description" binding:"omitempty,max=500"`
+	MinSelect   int                           `json:"min_select" binding:"gte=0"`
+	MaxSelect   int                           `json:"max_select" binding:"omitempty,gte=1"`
+	SortOrder   int                           `json:"sort_order"`
+	Options     []CreateModifierOptionRequest `json:"options" binding:"dive"`
+}
+
+type UpdateModifierGroupRequest struct {
+	Name        string  `json:"name" binding:"required,max=100"`
+	Description *string `json:"description" binding:"omitempty,max=500"`
+	MinSelect   int     `json:"min_select" binding:"gte=0"`
+	MaxSelect   int     `json:"max_select" binding:"gte=1"`
+	SortOrder   int     `json:"sort_order"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+type CreateModifierOptionRequest struct {
+	Name               string  `json:"name" binding:"required,max=100"`
+	PriceDelta         float64 `json:"price_delta" binding:"gte=0"`
+	IngredientID       *string `json:"ingredient_id"`
+	IngredientQuantity float64 `json:"ingredient_quantity" binding:"gte=0"`
+	IsDefault          bool    `json:"is_default"`
+	SortOrder          int     `json:"sort_order"`
+}
+
+type UpdateModifierOptionRequest struct {
+	Name               string  `json:"name" binding:"required,max=100"`
+	PriceDelta         float64 `json:"price_delta" binding:"gte=0"`
+	IngredientID       *string `json:"ingredient_id"`
+	IngredientQuantity float64 `json:"ingredient_quantity" binding:"gte=0"`
+	IsDefault          bool    `json:"is_default"`
+	SortOrder          int     `json:"sort_order"`
+	IsActive           *bool   `json:"is_active"`
+}
+
+// SetProductModifierGroupsRequest replaces the modifier groups attached to a product
+type SetProductModifierGroupsRequest struct {
+	ModifierGroupIDs []string `json:"modifier_group_ids"`
+}
+
+// ModifiersAmount returns the sum of price deltas of the selected options
+func ModifiersAmount(modifiers []OrderItemModifier) float64 {
+	total := 0.0
+	for _, m := range modifiers {
+		total += m.PriceDelta
+	}
+	return total
+}
@@ -41,41 +41,58 @@ const (
 type PaymentStatus string
 
 const (
-	PaymentStatusPending PaymentStatus = "pending"
-	PaymentStatusPaid    PaymentStatus = "paid"
-	PaymentStatusFailed  PaymentStatus = "failed"
+	PaymentStatusPending       PaymentStatus = "pending"
+	PaymentStatusPartiallyPaid PaymentStatus = "partially_paid"
+	PaymentStatusPaid          PaymentStatus = "paid"
+	PaymentStatusRefunded      PaymentStatus = "refunded"
+	PaymentStatusFailed        PaymentStatus = "failed"
 )
 
 // Order Model
 type Order struct {
-	ID                    int64          `json:"-" db:"id"`
-	PublicID              string         `json:"id" db:"public_id"`
-	OrderNumber           string         `json:"order_number" db:"order_number"`
-	CustomerName          string         `json:"customer_name" db:"customer_name"`
-	CustomerPhone         string         `json:"customer_phone" db:"customer_phone"`
-	CustomerEmail         string         `json:"customer_email" db:"customer_email"`
-	Status                OrderStatus    `json:"status" db:"status"`
-	Subtotal              float64        `json:"subtotal" db:"subtotal"`
-	DiscountAmount        float64        `json:"discount_amount" db:"discount_amount"`
-	DiscountType          *DiscountType  `json:"discount_type" db:"discount_type"`
-	DiscountCode          string         `json:"discount_code" db:"discount_code"`
-	DiscountNote          sql.NullString `json:"discount_note" db:"discount_note"`
-	ManualDiscountAmount  float64        `json:"manual_discount_amount" db:"manual_discount_amount"`
-	ShippingFee           float64        `json:"shipping_fee" db:"shipping_fee"`
-	TotalAmount           float64        `json:"total_amount" db:"total_amount"`
-	PaymentMethod         string         `json:"payment_method" db:"payment_method"`
-	PaymentStatus         PaymentStatus  `json:"payment_status" db:"payment_status"`
-	Notes                 sql.NullString `json:"notes" db:"notes"`
-	ShipperID             *int64         `json:"shipper_id" db:"shipper_id"`
-	DeliveryStatus        DeliveryStatus `json:"delivery_status" db:"delivery_status"`
-	EstimatedDeliveryTime *time.Time     `json:"estimated_delivery_time" db:"estimated_delivery_time"`
-	ActualDeliveryTime    *time.Time     `json:"actual_delivery_time" db:"actual_delivery_time"`
-	DeliveryNotes         sql.NullString `json:"delivery_notes" db:"delivery_notes"`
-	CreatedBy             int64          `json:"created_by" db:"created_by"`
-	UpdatedBy             int64          `json:"updated_by" db:"updated_by"`
-	CreatedAt             time.Time      `json:"created_at" db:"created_at"`
-	UpdatedAt             time.Time      `json:"updated_at" db:"updated_at"`
-	ItemsCount            int            `json:"items_count" db:"items_count"`
+	ID                      int64          `json:"-" db:"id"`
+	PublicID                string         `json:"id" db:"public_id"`
+	OrderNumber             string         `json:"order_number" db:"order_number"`
+	CustomerName            string         `json:"customer_name" db:"customer_name"`
+	CustomerPhone           string         `json:"customer_phone" db:"customer_phone"`
+	CustomerEmail           string         `json:"customer_email" db:"customer_email"`
+	Status                  OrderStatus    `json:"status" db:"status"`
+	Subtotal                float64        `json:"subtotal" db:"subtotal"`
+	DiscountAmount          float64        `json:"discount_amount" db:"discount_amount"`
+	DiscountType            *DiscountType  `json:"discount_type" db:"discount_type"`
+	DiscountCode            string         `json:"discount_code" db:"discount_code"`
+	DiscountNote            sql.NullString `json:"discount_note" db:"discount_note"`
+	ManualDiscountAmount    float64        `json:"manual_discount_amount" db:"manual_discount_amount"`
+	ShippingFee             float64        `json:"shipping_fee" db:"shipping_fee"`
+	PromotionDiscountAmount float64        `json:"promotion_discount_amount" db:"promotion_discount_amount"` // Giảm giá từ khuyến mãi tự động
+	LoyaltyPointsRedeemed   int            `json:"loyalty_points_redeemed" db:"loyalty_points_redeemed"`
+	LoyaltyDiscountAmount   float64        `json:"loyalty_discount_amount" db:"loyalty_discount_amount"` // Số tiền giảm bằng điểm
+	LoyaltyPointsEarned     int            `json:"loyalty_points_earned" db:"loyalty_points_earned"`
+	TotalAmount             float64        `json:"total_amount" db:"total_amount"`
+	PaymentMethod           string         `json:"payment_method" db:"payment_method"`
+	PaymentStatus           PaymentStatus  `json:"payment_status" db:"payment_status"`
+	Notes                   sql.NullString `json:"notes" db:"notes"`
+	ShipperID               *int64         `json:"shipper_id" db:"shipper_id"`
+	DeliveryStatus          DeliveryStatus `json:"delivery_status" db:"delivery_status"`
+	EstimatedDeliveryTime   *time.Time     `json:"estimated_delivery_time" db:"estimated_delivery_time"`
+	ActualDeliveryTime      *time.Time     `json:"actual_delivery_time" db:"actual_delivery_time"`
+	DeliveryNotes           sql.NullString `json:"delivery_notes" db:"delivery_notes"`
+	DeliveryZone            *string        `json:"delivery_zone" db:"delivery_zone"`
+	CustomerAddressID       *string        `json:"customer_address_id" db:"customer_address_id"` // Địa chỉ đã lưu được chọn
+	DeliveryAddress         *string        `json:"delivery_address" db:"delivery_address"`
+	DeliveryWard            *string        `json:"delivery_ward" db:"delivery_ward"`
+	DeliveryDistrict        *string        `json:"delivery_district" db:"delivery_district"`
+	DeliveryProvince        *string        `json:"delivery_province" db:"delivery_province"`
+	DeliveryLatitude        *float64       `json:"delivery_latitude" db:"delivery_latitude"`
+	DeliveryLongitude       *float64       `json:"delivery_longitude" db:"delivery_longitude"`
+	DeliveryDistanceKm      *float64       `json:"delivery_distance_km" db:"delivery_distance_km"`
+	ShippingFeeSource       string         `json:"shipping_fee_source" db:"shipping_fee_source"`
+	CustomerID              *int64         `json:"-" db:"customer_id"` // Khách hàng của đơn, created_by là người tạo đơn
+	CreatedBy               int64          `json:"created_by" db:"created_by"`
+	UpdatedBy               int64          `json:"updated_by" db:"updated_by"`
+	CreatedAt               time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt               time.Time      `json:"updated_at" db:"updated_at"`
+	ItemsCount              int            `json:"items_count" db:"items_count"`
 
 	// Relations
 	Items          []OrderItem          `json:"items,omitempty"`
@@ -100,8 +117,15 @@ type OrderItem struct {
 	CreatedAt   time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at" db:"updated_at"`
 
+	// Bảng giá theo giờ đã áp dụng, BasePrice là giá gốc của variant lúc đặt
+	BasePrice     *float64 `json:"base_price" db:"base_price"`
+	PriceListID   *int64   `json:"-" db:"price_list_id"`
+	PriceListName *string  `json:"price_list_name" db:"price_list_name"`
+
 	// Relations
-	Variant *Variant `json:"variant,omitempty"`
+	Variant    *Variant             `json:"variant,omitempty"`
+	Modifiers  []OrderItemModifier  `json:"modifiers,omitempty"`
+	Promotions []OrderItemPromotion `json:"promotions,omitempty"`
 }
 
 // Order Status History Model
@@ -131,6 +155,8 @@ type DiscountCode struct {
 	MaxDiscountAmount *float64     `json:"max_discount_amount" db:"max_discount_amount"`
 	UsageLimit        *int         `json:"usage_limit" db:"usage_limit"`
 	UsedCount         int          `json:"used_count" db:"used_count"`
+	PerCustomerLimit  *int         `json:"per_customer_limit" db:"per_customer_limit"`
+	IsStackable       bool         `json:"is_stackable" db:"is_stackable"`
 	IsActive          bool         `json:"is_active" db:"is_active"`
 	ValidFrom         time.Time    `json:"valid_from" db:"valid_from"`
 	ValidUntil        time.Time    `json:"valid_until" db:"valid_until"`
@@ -138,6 +164,10 @@ type DiscountCode struct {
 	CreatedAt         time.Time    `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time    `json:"updated_at" db:"updated_at"`
 
+	// Scope (public IDs). Empty means the code applies to the whole order.
+	ProductIDs []string `json:"product_ids"`
+	VariantIDs []string `json:"variant_ids"`
+
 	// Relations
 	CreatedByUser *User `json:"created_by_user,omitempty"`
 }
@@ -158,12 +188,26 @@ type CreateOrderRequest struct {
 	PaymentMethod        string                   `json:"payment_method" validate:"omitempty,max=50"`
 	Notes                string                   `json:"notes" validate:"omitempty,max=1000"`
 	ShipperID            *string                  `json:"shipper_id"`
+	DeliveryZone         *string                  `json:"delivery_zone" validate:"omitempty,max=50"`
+	CustomerAddressID    *string                  `json:"customer_address_id"` // Chọn địa chỉ đã lưu, thay cho các trường địa chỉ bên dưới
+	DeliveryAddress      string                   `json:"delivery_address" validate:"omitempty,max=500"`
+	DeliveryWard         string                   `json:"delivery_ward" validate:"omitempty,max=100"`
+	DeliveryDistrict     string                   `json:"delivery_district" validate:"omitempty,max=100"`
+	DeliveryProvince     string                   `json:"delivery_province" validate:"omitempty,max=100"`
+	DeliveryLatitude     *float64                 `json:"delivery_latitude"`
+	DeliveryLongitude    *float64                 `json:"delivery_longitude"`
+	ShippingFeeOverride  *float64                 `json:"shipping_fee_override"` // Sửa phí đã tính theo vùng, cần lý do
+	ShippingFeeReason    string                   `json:"shipping_fee_reason" validate:"omitempty,max=500"`
+	RedeemPoints         int                      `json:"redeem_points" validate:"gte=0"` // Điểm tích lũy khách muốn dùng
+	ShippingQuote        *ShippingQuote           `json:"-"`                              // Set by OrderService from the delivery coordinates
+	CustomerID           *int64                   `json:"-"`                              // Set by OrderService: the customer account the order belongs to
 }
 
 type CreateOrderItemRequest struct {
-	VariantID string `json:"variant_id" validate:"required"`
-	Quantity  int    `json:"quantity" validate:"required,min=1"`
-	Notes     string `json:"notes" validate:"omitempty,max=500"`
+	VariantID         string   `json:"variant_id" validate:"required"`
+	Quantity          int      `json:"quantity" validate:"required,min=1"`
+	Notes             string   `json:"notes" validate:"omitempty,max=500"`
+	ModifierOptionIDs []string `json:"modifier_option_ids"`
 }
 
 type UpdateOrderRequest struct {
@@ -180,13 +224,23 @@ type UpdateOrderRequest struct {
 	PaymentMethod        string                   `json:"payment_method" validate:"omitempty,max=50"`
 	Notes                string                   `json:"notes" validate:"omitempty,max=1000"`
 	ShipperID            *string                  `json:"shipper_id"`
+	DeliveryZone         *string                  `json:"delivery_zone" validate:"omitempty,max=50"`
+	CustomerAddressID    *string                  `json:"customer_address_id"`                           // Chọn địa chỉ đã lưu, thay cho các trường địa chỉ bên dưới
+	DeliveryAddress      *string                  `json:"delivery_address" validate:"omitempty,max=500"` // nil keeps the current address
+	DeliveryWard         string                   `json:"delivery_ward" validate:"omitempty,max=100"`
+	DeliveryDistrict     string                   `json:"delivery_district" validate:"omitempty,max=100"`
+	DeliveryProvince     string                   `json:"delivery_province" validate:"omitempty,max=100"`
+	DeliveryLatitude     *float64                 `json:"delivery_latitude"`
+	DeliveryLongitude    *float64                 `json:"delivery_longitude"`
+	ShippingQuote        *ShippingQuote           `json:"-"` // Set by OrderService from the delivery coordinates
 }
 
 type UpdateOrderItemRequest struct {
-	ID        string `json:"id" validate:"omitempty"`
-	VariantID string `json:"variant_id" validate:"required"`
-	Quantity  int    `json:"quantity" validate:"required,min=1"`
-	Notes     string `json:"notes" validate:"omitempty,max=500"`
+	ID                string   `json:"id" validate:"omitempty"`
+	VariantID         string   `json:"variant_id" validate:"required"`
+	Quantity          int      `json:"quantity" validate:"required,min=1"`
+	Notes             string   `json:"notes" validate:"omitempty,max=500"`
+	ModifierOptionIDs []string `json:"modifier_option_ids"` // nil keeps the current options of an existing item
 }
 
 type UpdateOrderStatusRequest struct {
@@ -203,6 +257,10 @@ type CreateDiscountCodeRequest struct {
 	MinOrderAmount    float64      `json:"min_order_amount" validate:"gte=0"`
 	MaxDiscountAmount *float64     `json:"max_discount_amount" validate:"omitempty,gt=0"`
 	UsageLimit        *int         `json:"usage_limit" validate:"omitempty,gt=0"`
+	PerCustomerLimit  *int         `json:"per_customer_limit" validate:"omitempty,gt=0"`
+	IsStackable       bool         `json:"is_stackable"`
+	ProductIDs        []string     `json:"product_ids"`
+	VariantIDs        []string     `json:"variant_ids"`
 	ValidFrom         time.Time    `json:"valid_from" validate:"required"`
 	ValidUntil        time.Time    `json:"valid_until" validate:"required"`
 }
@@ -215,14 +273,20 @@ type UpdateDiscountCodeRequest struct {
 	MinOrderAmount    float64      `json:"min_order_amount" validate:"gte=0"`
 	MaxDiscountAmount *float64     `json:"max_discount_amount" validate:"omitempty,gt=0"`
 	UsageLimit        *int         `json:"usage_limit" validate:"omitempty,gt=0"`
+	PerCustomerLimit  *int         `json:"per_customer_limit" validate:"omitempty,gt=0"`
+	IsStackable       bool         `json:"is_stackable"`
+	ProductIDs        []string     `json:"product_ids"`
+	VariantIDs        []string     `json:"variant_ids"`
 	IsActive          bool         `json:"is_active"`
 	ValidFrom         time.Time    `json:"valid_from" validate:"required"`
 	ValidUntil        time.Time    `json:"valid_until" validate:"required"`
 }
 
 type ValidateDiscountCodeRequest struct {
-	Code        string  `json:"code" validate:"required"`
-	OrderAmount float64 `json:"order_amount" validate:"required,gt=0"`
+	Code          string                   `json:"code" validate:"required"`
+	OrderAmount   float64                  `json:"order_amount" validate:"required,gt=0"`
+	CustomerPhone string                   `json:"customer_phone" validate:"omitempty,max=20"` // Dùng để kiểm tra giới hạn theo khách hàng
+	Items         []CreateOrderItemRequest `json:"items" validate:"omitempty,dive"`            // Dùng để tính giảm giá theo sản phẩm
 }
 
 type ValidateDiscountCodeResponse struct {
@@ -231,6 +295,7 @@ type ValidateDiscountCodeResponse struct {
 	DiscountType   DiscountType `json:"discount_type,omitempty"`
 	DiscountValue  float64      `json:"discount_value,omitempty"`
 	DiscountAmount float64      `json:"discount_amount,omitempty"`
+	IsStackable    bool         `json:"is_stackable,omitempty"`
 }
 
 // List Orders Request
@@ -297,17 +362,32 @@ type DeliveryOrder struct {
 	EstimatedDeliveryTime *time.Time     `json:"estimated_delivery_time" db:"estimated_delivery_time"`
 	ActualDeliveryTime    *time.Time     `json:"actual_delivery_time" db:"actual_delivery_time"`
 	DeliveryNotes         sql.NullString `json:"delivery_notes" db:"delivery_notes"`
+	AcceptedAt            *time.Time     `json:"accepted_at" db:"accepted_at"`
+	AssignedAt            *time.Time     `json:"assigned_at" db:"assigned_at"`
+	PickedUpAt            *time.Time     `json:"picked_up_at" db:"picked_up_at"`
+	InTransitAt           *time.Time     `json:"in_transit_at" db:"in_transit_at"`
+	FailedAt              *time.Time     `json:"failed_at" db:"failed_at"`
+	CancelledAt           *time.Time     `json:"cancelled_at" db:"cancelled_at"`
+	RejectionReason       *string        `json:"rejection_reason,omitempty" db:"rejection_reason"`
+	CodAmount             float64        `json:"cod_amount" db:"cod_amount"`             // Tiền shipper thu hộ
+	CodCollectedAt        *time.Time     `json:"cod_collected_at" db:"cod_collected_at"` // Thời điểm shipper thu tiền
+	CodSettledAt          *time.Time     `json:"cod_settled_at" db:"cod_settled_at"`     // Thời điểm shipper nộp tiền
 	CreatedBy             int64          `json:"created_by" db:"created_by"`
 	UpdatedBy             int64          `json:"updated_by" db:"updated_by"`
 	CreatedAt             time.Time      `json:"created_at" db:"created_at"`
 	UpdatedAt             time.Time      `json:"updated_at" db:"updated_at"`
 
+	// Địa chỉ giao, chụp từ đơn hàng khi tạo đơn giao
+	Address *AddressSnapshot `json:"address" db:"delivery_address"`
+
 	// Relations
-	Order              *Order              `json:"order,omitempty"`
-	Shipper            *Shipper            `json:"shipper,omitempty"`
-	DeliveryOrderItems []DeliveryOrderItem `json:"delivery_order_items,omitempty"`
-	CreatedByUser      *User               `json:"created_by_user,omitempty"`
-	UpdatedByUser      *User               `json:"updated_by_user,omitempty"`
+	Order              *Order                  `json:"order,omitempty"`
+	Shipper            *Shipper                `json:"shipper,omitempty"`
+	DeliveryOrderItems []DeliveryOrderItem     `json:"delivery_order_items,omitempty"`
+	StatusHistory      []DeliveryStatusHistory `json:"status_history,omitempty"`
+	Proofs             []DeliveryProof         `json:"proofs,omitempty"`
+	CreatedByUser      *User                   `json:"created_by_user,omitempty"`
+	UpdatedByUser      *User                   `json:"updated_by_user,omitempty"`
 }
 
 // Delivery Order Item Model
@@ -329,6 +409,7 @@ type CreateDeliveryOrderRequest struct {
 	ShipperID             string                `json:"shipper_id" validate:"required"`
 	EstimatedDeliveryTime *time.Time            `json:"estimated_delivery_time"`
 	DeliveryNotes         string                `json:"delivery_notes" validate:"omitempty,max=500"`
+	CodAmount             float64               `json:"cod_amount" validate:"min=0"`
 	Items                 []DeliveryItemRequest `json:"items" validate:"required,min=1,dive"`
 }
 
@@ -343,13 +424,15 @@ type UpdateDeliveryOrderRequest struct {
 	EstimatedDeliveryTime *time.Time      `json:"estimated_delivery_time"`
 	ActualDeliveryTime    *time.Time      `json:"actual_delivery_time"`
 	DeliveryNotes         string          `json:"delivery_notes" validate:"omitempty,max=500"`
+	CodAmount             *float64        `json:"cod_amount"`
 }
 
 type AssignShipperRequest struct {
 	ShipperID             string     `json:"shipper_id" validate:"required"`
 	EstimatedDeliveryTime *time.Time `json:"estimated_delivery_time"`
 	DeliveryNotes         string     `json:"delivery_notes" validate:"omitempty,max=500"`
-	SplitOrder            bool       `json:"split_order"` // Whether to split the order into multiple deliveries
+	CodAmount             float64    `json:"cod_amount" validate:"min=0"` // Tiền shipper thu hộ khi giao
+	SplitOrder            bool       `json:"split_order"`                 // Whether to split the order into multiple deliveries
 }
 
 type SplitOrderRequest struct {
@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+// OTP purposes, a code can only be used for the purpose it was sent for
+const (
+	OTPPurposeCustomerLogin    = "customer_login"
+	OTPPurposeCustomerRegister = "customer_register" // Xác minh sđt khi đăng ký tài khoản
+	OTPPurposeShipperLogin     = "shipper_login"
+)
+
+// OTP Code Model
+type OTPCode struct {
+	ID         int64      `db:"id"`
+	Purpose    string     `db:"purpose"`
+	Phone      string     `db:"phone"`
+	CodeHash   string     `db:"code_hash"`
+	Attempts   int        `db:"attempts"`
+	ExpiresAt  time.Time  `db:"expires_at"`
+	ConsumedAt *time.Time `db:"consumed_at"`
+	CreatedAt  time.Time  `db:"created_at"`
+}
+
+// OTPPolicy configures code generation, expiry, attempt limits and resend throttling
+type OTPPolicy struct {
+	Length          int
+	TTL             time.Duration
+	MaxAttempts     int           // Wrong codes allowed before the code is burned
+	ResendInterval  time.Duration // Minimum time between two codes to the same phone
+	MaxSendsPerHour int
+}
+
+type OTPSendRequest struct {
+	Phone string `json:"phone" binding:"required,max=20"`
+}
+
+type OTPVerifyRequest struct {
+	Phone string `json:"phone" binding:"required,max=20"`
+	Code  string `json:"code" binding:"required,max=10"`
+}
+
+type OTPSentResponse struct {
+	ExpiresIn   int `json:"expires_in"`   // Seconds
+	ResendAfter int `json:"resend_after"` // Seconds
+}
@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+)
+
+// Payment Type Enum
+type PaymentType string
+
+const (
+	PaymentTypePayment PaymentType = "payment" // Thanh toán
+	PaymentTypeRefund  PaymentType = "refund"  // Hoàn tiền
+)
+
+// Payment Transaction Status
+const (
+	PaymentTransactionPending   = "pending"
+	PaymentTransactionCompleted = "completed"
+	PaymentTransactionFailed    = "failed"
+)
+
+// Payment Method Model
+type PaymentMethod struct {
+	ID        int64     `json:"-" db:"id"`
+	Code      string    `json:"value" db:"code"`
+	Name      string    `json:"label" db:"name"`
+	SortOrder int       `json:"sort_order" db:"sort_order"`
+	IsActive  bool      `json:"is_active" db:"is_active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Payment Model (payments ledger entry, refunds are stored as separate rows)
+type Payment struct {
+	ID               int64       `json:"-" db:"id"`
+	PublicID         string      `json:"id" db:"public_id"`
+	OrderID          int64       `json:"-" db:"order_id"`
+	OrderPublicID    string      `json:"order_id" db:"order_public_id"`
+	PaymentType      PaymentType `json:"payment_type" db:"payment_type"`
+	PaymentMethod    string      `json:"payment_method" db:"payment_method"`
+	Amount           float64     `json:"amount" db:"amount"`
+	Status           string      `json:"status" db:"status"`
+	Reference        *string     `json:"reference,omitempty" db:"reference"`
+	ProviderTxnID    *string     `json:"provider_transaction_id,omitempty" db:"provider_transaction_id"`
+	PayURL           *string     `json:"pay_url,omitempty" db:"pay_url"`
+	RefundOfID       *int64      `json:"-" db:"refund_of_id"`
+	RefundOfPublicID *string     `json:"refund_of_id,omitempty" db:"refund_of_public_id"`
+	RefundedAmount   float64     `json:"refunded_amount" db:"refunded_amount"`
+	NeedsRefund      bool        `json:"needs_refund" db:"needs_refund"` // Thanh toán vượt số tiền còn lại, cần hoàn tiền
+	Reason           *string     `json:"reason,omitempty" db:"reason"`
+	Notes            *string     `json:"notes,omitempty" db:"notes"`
+	CreatedBy        *int64      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt        time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at" db:"updated_at"`
+}
+
+// Order Payment Summary (outstanding balance derived from the ledger)
+type OrderPaymentSummary struct {
+	OrderID        string        `json:"order_id"`
+	OrderNumber    string        `json:"order_number"`
+	TotalAmount    float64       `json:"total_amount"`
+	PaidAmount     float64       `json:"paid_amount"`
+	RefundedAmount float64       `json:"refunded_amount"`
+	NetPaid        float64       `json:"net_paid"`
+	Balance        float64       `json:"balance"`
+	PendingAmount  float64       `json:"pending_amount"` // Online checkouts awaiting payment, held against the balance
+	PaymentStatus  PaymentStatus `json:"payment_status"`
+	Payments       []*Payment    `json:"payments"`
+}
+
+// Payment Tender Request (one tender of a possibly split payment)
+type PaymentTenderRequest struct {
+	PaymentMethod string  `json:"payment_method" binding:"required"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Reference     *string `json:"reference"`
+}
+
+// Record Payment Request
+type RecordPaymentRequest struct {
+	Tenders []PaymentTenderRequest `json:"tenders" binding:"required,min=1,dive"`
+	Notes   *string                `json:"notes"`
+}
+
+// Create Payment Intent Request (gateway checkout, amount omitted pays the outstanding balance)
+type CreatePaymentIntentRequest struct {
+	PaymentMethod string   `json:"payment_method" binding:"required"`
+	Amount        *float64 `json:"amount" binding:"omitempty,gt=0"`
+}
+
+// Refund Payment Request (amount omitted refunds the remaining refundable amount)
+type RefundPaymentRequest struct {
+	Amount *float64 `json:"amount" binding:"omitempty,gt=0"`
+	Reason string   `json:"reason" binding:"required"`
+}
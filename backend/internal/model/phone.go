@@ -0,0 +1,17 @@
+package model
+
+import (
+	"regexp"
+	"strings"
+)
+
+var nonDigitPattern = regexp.MustCompile(`\D`)
+
+// NormalizePhone keeps the digits of a phone number, with +84 written as a leading 0
+func NormalizePhone(phone string) string {
+	digits := nonDigitPattern.ReplaceAllString(phone, "")
+	if strings.HasPrefix(digits, "84") && len(digits) == 11 {
+		digits = "0" + digits[2:]
+	}
+	return digits
+}
@@ -0,0 +1,143 @@
+package model
+
+import "time"
+
+// Public menu models: what customers see on the ordering portal, without
+// internal fields such as private notes or ingredient links
+
+type PublicMenuProduct struct {
+	ID             string                `json:"id" db:"public_id"`
+	Name           string                `json:"name" db:"name"`
+	Description    string                `json:"description" db:"description"`
+	CategoryID     *string               `json:"category_id" db:"category_id"`
+	CategoryName   *string               `json:"category_name" db:"category_name"`
+	Variants       []PublicMenuVariant   `json:"variants"`
+	ModifierGroups []PublicModifierGroup `json:"modifier_groups"`
+}
+
+// PublicMenuCategory is a menu section shown on the customer portal
+type PublicMenuCategory struct {
+	ID       string                `json:"id"`
+	Name     string                `json:"name"`
+	Children []*PublicMenuCategory `json:"children"`
+}
+
+type PublicMenuVariant struct {
+	ID          string     `json:"id" db:"public_id"`
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description" db:"description"`
+	Price       float64    `json:"price" db:"price"`
+	IsAvailable bool       `json:"is_available" db:"is_available"`
+	AvailableAt *time.Time `json:"available_at,omitempty" db:"available_at"` // Giờ có lại món khi đang tạm hết
+
+	BasePrice     float64 `json:"base_price" db:"-"`                // Giá gốc, khác Price khi đang có bảng giá theo giờ
+	PriceListName *string `json:"price_list_name,omitempty" db:"-"` // Bảng giá đang áp dụng, ví dụ Happy hour
+}
+
+type PublicModifierGroup struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description *string                `json:"description,omitempty"`
+	MinSelect   int                    `json:"min_select"`
+	MaxSelect   int                    `json:"max_select"`
+	Options     []PublicModifierOption `json:"options"`
+}
+
+type PublicModifierOption struct {
+	ID         string  `json:"id"`
+	Name       string  `json:"name"`
+	PriceDelta float64 `json:"price_delta"`
+	IsDefault  bool    `json:"is_default"`
+}
+
+// CartRequest is a customer's cart, priced on the server
+type CartRequest struct {
+	Items             []CartItemRequest `json:"items" binding:"required,min=1,max=30,dive"`
+	DiscountCode      string            `json:"discount_code" binding:"max=50"`
+	CustomerPhone     string            `json:"customer_phone" binding:"max=20"` // Dùng để kiểm tra giới hạn mã giảm giá theo khách hàng
+	DeliveryLatitude  *float64          `json:"delivery_latitude"`
+	DeliveryLongitude *float64          `json:"delivery_longitude"`
+}
+
+type CartItemRequest struct {
+	VariantID         string   `json:"variant_id" binding:"required"`
+	Quantity          int      `json:"quantity" binding:"required,min=1,max=50"`
+	Notes             string   `json:"notes" binding:"max=200"`
+	ModifierOptionIDs []string `json:"modifier_option_ids"`
+}
+
+// CartQuote is the server-side pricing of a cart
+type CartQuote struct {
+	Items                   []CartLine `json:"items"`
+	Subtotal                float64    `json:"subtotal"`
+	DiscountCode            string     `json:"discount_code,omitempty"`
+	DiscountAmount          float64    `json:"discount_amount"`
+	DiscountError           string     `json:"discount_error,omitempty"`  // Lý do mã giảm giá không áp dụng được
+	PromotionDiscountAmount float64    `json:"promotion_discount_amount"` // Giảm giá từ khuyến mãi tự động
+	ShippingFee             float64    `json:"shipping_fee"`
+	DeliveryZone            string     `json:"delivery_zone,omitempty"`
+	TotalAmount             float64    `json:"total_amount"`
+}
+
+type CartLine struct {
+	VariantID   string              `json:"variant_id" db:"variant_id"`
+	ProductName string              `json:"product_name" db:"product_name"`
+	VariantName string              `json:"variant_name" db:"variant_name"`
+	Quantity    int                 `json:"quantity" db:"quantity"`
+	UnitPrice   float64             `json:"unit_price" db:"unit_price"`
+	TotalPrice  float64             `json:"total_price" db:"total_price"`
+	Notes       string              `json:"notes,omitempty" db:"-"`
+	Modifiers   []OrderItemModifier `json:"modifiers" db:"-"`
+
+	PriceListName *string `json:"price_list_name,omitempty" db:"-"` // Bảng giá theo giờ đã áp dụng cho món
+}
+
+// CheckoutRequest places a guest order from the portal
+type CheckoutRequest struct {
+	CustomerName      string            `json:"customer_name" binding:"required,max=100"`
+	CustomerPhone     string            `json:"customer_phone" binding:"required,max=20"`
+	CustomerEmail     string            `json:"customer_email" binding:"omitempty,email,max=100"`
+	Items             []CartItemRequest `json:"items" binding:"required,min=1,max=30,dive"`
+	DiscountCode      string            `json:"discount_code" binding:"max=50"`
+	DeliveryAddress   string            `json:"delivery_address" binding:"required,max=500"`
+	DeliveryWard      string            `json:"delivery_ward" binding:"max=100"`
+	DeliveryDistrict  string            `json:"delivery_district" binding:"max=100"`
+	DeliveryProvince  string            `json:"delivery_province" binding:"max=100"`
+	DeliveryLatitude  *float64          `json:"delivery_latitude"`
+	DeliveryLongitude *float64          `json:"delivery_longitude"`
+	PaymentMethod     string            `json:"payment_method" binding:"max=50"`
+	Notes             string            `json:"notes" binding:"max=500"`
+}
+
+// OrderTracking is the public view of an order, looked up by order number and phone
+type OrderTracking struct {
+	PublicID       string             `json:"-"`
+	CreatedBy      int64              `json:"-"`
+	OrderNumber    string             `json:"order_number"`
+	Status         OrderStatus        `json:"status"`
+	PaymentMethod  string             `json:"payment_method"`
+	PaymentStatus  PaymentStatus      `json:"payment_status"`
+	PayURL         *string            `json:"pay_url,omitempty"` // Online checkout still open for the order
+	Subtotal       float64            `json:"subtotal"`
+	DiscountAmount float64            `json:"discount_amount"`
+	ShippingFee    float64            `json:"shipping_fee"`
+	TotalAmount    float64            `json:"total_amount"`
+	Items          []CartLine         `json:"items"`
+	Deliveries     []DeliveryTracking `json:"deliveries"`
+	CreatedAt      time.Time          `json:"created_at"`
+}
+
+// PayOrderRequest opens an online checkout for a portal order
+type PayOrderRequest struct {
+	OrderNumber   string `json:"order_number" binding:"required,max=50"`
+	CustomerPhone string `json:"customer_phone" binding:"required,max=20"`
+}
+
+type DeliveryTracking struct {
+	DeliveryNumber        string         `json:"delivery_number" db:"delivery_number"`
+	Status                DeliveryStatus `json:"status" db:"status"`
+	EstimatedDeliveryTime *time.Time     `json:"estimated_delivery_time" db:"estimated_delivery_time"`
+	ActualDeliveryTime    *time.Time     `json:"actual_delivery_time" db:"actual_delivery_time"`
+	ShipperName           *string        `json:"shipper_name" db:"shipper_name"`
+	ShipperPhone          *string        `json:"shipper_phone" db:"shipper_phone"`
+}
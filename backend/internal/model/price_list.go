@@ -0,0 +1,202 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// PriceList is a set of scheduled prices, e.g. happy hour or a breakfast menu
+type PriceList struct {
+	ID          int64      `json:"-" db:"id"`
+	PublicID    string     `json:"id" db:"public_id"`
+	Name        string     `json:"name" db:"name"`
+	Description string     `json:"description" db:"description"`
+	Priority    int        `json:"priority" db:"priority"`
+	IsExclusive bool       `json:"is_exclusive" db:"is_exclusive"` // Món trong bảng giá chỉ bán khi bảng giá đang áp dụng
+	IsActive    bool       `json:"is_active" db:"is_active"`
+	ValidFrom   *time.Time `json:"valid_from" db:"valid_from"`
+	ValidUntil  *time.Time `json:"valid_until" db:"valid_until"`
+	CreatedBy   int64      `json:"created_by" db:"created_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+
+	Schedules []PriceListSchedule `json:"schedules"` // Trống = cả ngày
+	Items     []PriceListItem     `json:"items"`
+}
+
+// PriceListSchedule is a weekly time window in store local time
+type PriceListSchedule struct {
+	ID          int64  `json:"-" db:"id"`
+	PriceListID int64  `json:"-" db:"price_list_id"`
+	DaysMask    int    `json:"-" db:"days_mask"`
+	DaysOfWeek  []int  `json:"days_of_week"`               // 0 = Chủ nhật ... 6 = Thứ bảy
+	StartTime   string `json:"start_time" db:"start_time"` // HH:MM
+	EndTime     string `json:"end_time" db:"end_time"`     // HH:MM, không lớn hơn StartTime = qua nửa đêm
+}
+
+// PriceListItem prices one variant, every variant of a product or every
+// product of a category. Without Price and DiscountPercent the regular price is
+// kept, which only makes sense for exclusive lists.
+type PriceListItem struct {
+	ID              int64    `json:"-" db:"id"`
+	PriceListID     int64    `json:"-" db:"price_list_id"`
+	VariantDBID     *int64   `json:"-" db:"variant_id"`
+	ProductDBID     *int64   `json:"-" db:"product_id"`
+	CategoryDBID    *int64   `json:"-" db:"category_id"`
+	VariantID       *string  `json:"variant_id,omitempty" db:"variant_public_id"`
+	ProductID       *string  `json:"product_id,omitempty" db:"product_public_id"`
+	CategoryID      *string  `json:"category_id,omitempty" db:"category_public_id"`
+	TargetName      string   `json:"target_name" db:"target_name"`
+	Price           *float64 `json:"price,omitempty" db:"price"`
+	DiscountPercent *float64 `json:"discount_percent,omitempty" db:"discount_percent"`
+}
+
+type PriceListScheduleRequest struct {
+	DaysOfWeek []int  `json:"days_of_week"` // Trống = mọi ngày
+	StartTime  string `json:"start_time"`   // Trống = 00:00
+	EndTime    string `json:"end_time"`     // Trống = 00:00 (hết ngày)
+}
+
+type PriceListItemRequest struct {
+	VariantID       *string  `json:"variant_id"`
+	ProductID       *string  `json:"product_id"`
+	CategoryID      *string  `json:"category_id"`
+	Price           *float64 `json:"price" binding:"omitempty,gte=0"`
+	DiscountPercent *float64 `json:"discount_percent" binding:"omitempty,gt=0,lte=100"`
+}
+
+type CreatePriceListRequest struct {
+	Name        string                     `json:"name" binding:"required,max=100"`
+	Description string                     `json:"description" binding:"omitempty,max=500"`
+	Priority    int                        `json:"priority"`
+	IsExclusive bool                       `json:"is_exclusive"`
+	ValidFrom   *time.Time                 `json:"valid_from"`
+	ValidUntil  *time.Time                 `json:"valid_until"`
+	Schedules   []PriceListScheduleRequest `json:"schedules"`
+	Items       []PriceListItemRequest     `json:"items" binding:"required,min=1,dive"`
+}
+
+type UpdatePriceListRequest struct {
+	CreatePriceListRequest
+	IsActive bool `json:"is_active"`
+}
+
+// MenuPrice is the price of a variant at a given time
+type MenuPrice struct {
+	VariantID     string  `json:"variant_id"`
+	ProductID     string  `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	VariantName   string  `json:"variant_name"`
+	BasePrice     float64 `json:"base_price"`
+	Price         float64 `json:"price"`
+	PriceListDBID *int64  `json:"-"`
+	PriceListID   *string `json:"price_list_id"`
+	PriceListName *string `json:"price_list_name"`
+	IsOrderable   bool    `json:"is_orderable"` // false: món thuộc menu theo giờ đang đóng
+}
+
+// DaysOfWeekMask converts weekdays (0 = Sunday) to the days_mask bitmask, all
+// days when empty
+func DaysOfWeekMask(days []int) (int, error) {
+	if len(days) == 0 {
+		return 127, nil
+	}
+	mask := 0
+	for _, day := range days {
+		if day < 0 || day > 6 {
+			return 0, fmt.Errorf("invalid day of week %d", day)
+		}
+		mask |= 1 << day
+	}
+	return mask, nil
+}
+
+// DaysOfWeekFromMask converts a days_mask bitmask back to weekdays
+func DaysOfWeekFromMask(mask int) []int {
+	days := make([]int, 0, 7)
+	for day := 0; day < 7; day++ {
+		if mask&(1<<day) != 0 {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+// ParseClock parses an HH:MM time of day into minutes after midnight
+func ParseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Matches reports whether the wall clock time of t falls in the window. A
+// window ending at or before its start runs past midnight and belongs to the
+// day it starts on.
+func (s PriceListSchedule) Matches(t time.Time) bool {
+	start, err := ParseClock(s.StartTime)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(s.EndTime)
+	if err != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	day := int(t.Weekday())
+	if start < end {
+		return s.DaysMask&(1<<day) != 0 && minute >= start && minute < end
+	}
+	if minute >= start {
+		return s.DaysMask&(1<<day) != 0
+	}
+	previousDay := (day + 6) % 7
+	return minute < end && s.DaysMask&(1<<previousDay) != 0
+}
+
+// InEffect reports whether the list applies at t, given in store local time
+func (l *PriceList) InEffect(t time.Time) bool {
+	if !l.IsActive || !l.InDateRange(t) {
+		return false
+	}
+	if len(l.Schedules) == 0 {
+		return true
+	}
+	for _, schedule := range l.Schedules {
+		if schedule.Matches(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// InDateRange reports whether t is between ValidFrom and ValidUntil. Both are
+// stored as store wall clock times, so they are compared by wall clock.
+func (l *PriceList) InDateRange(t time.Time) bool {
+	wall := wallClock(t)
+	if l.ValidFrom != nil && wall.Before(wallClock(*l.ValidFrom)) {
+		return false
+	}
+	if l.ValidUntil != nil && wall.After(wallClock(*l.ValidUntil)) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the price of a variant whose regular price is basePrice
+func (i PriceListItem) Apply(basePrice float64) float64 {
+	switch {
+	case i.Price != nil:
+		return *i.Price
+	case i.DiscountPercent != nil:
+		return basePrice * (100 - *i.DiscountPercent) / 100
+	default:
+		return basePrice
+	}
+}
+
+// wallClock drops the location of t, keeping its date and time of day
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
@@ -5,20 +5,28 @@ import (
 )
 
 type Product struct {
-	ID          int64     `json:"-" db:"id"`
-	PublicID    string    `json:"id" db:"public_id"`
-	Name        string    `json:"name" db:"name"`
-	Description string    `json:"description" db:"description"`
-	PrivateNote string    `json:"private_note" db:"private_note"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
-	Variants    []Variant `json:"variants,omitempty"`
+	ID               int64      `json:"-" db:"id"`
+	PublicID         string     `json:"id" db:"public_id"`
+	Name             string     `json:"name" db:"name"`
+	Description      string     `json:"description" db:"description"`
+	PrivateNote      string     `json:"private_note" db:"private_note"`
+	CategoryID       *int64     `json:"-" db:"category_id"`
+	CategoryPublicID *string    `json:"category_id" db:"category_public_id"`
+	CategoryName     *string    `json:"category_name" db:"category_name"`
+	SortOrder        int        `json:"sort_order" db:"sort_order"` // Thứ tự trong danh mục
+	IsAvailable      bool       `json:"is_available" db:"is_available"`
+	AvailableAt      *time.Time `json:"available_at" db:"available_at"` // Giờ có lại món khi đang tạm hết
+	ArchivedAt       *time.Time `json:"archived_at" db:"archived_at"`   // Đã lưu trữ: ẩn khỏi danh sách, không bán nữa
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+	Variants         []Variant  `json:"variants,omitempty"`
 }
 
 type CreateProductRequest struct {
 	Name        string                    `json:"name" validate:"required,max=200"`
 	Description string                    `json:"description" validate:"max=1000"`
 	PrivateNote string                    `json:"private_note" validate:"max=1000"`
+	CategoryID  *string                   `json:"category_id"` // Trống = chưa phân loại
 	Variants     []CreateVariantRequest   `json:"variants" validate:"required,min=1,dive"`
 }
 
@@ -26,6 +34,7 @@ type UpdateProductRequest struct {
 	Name        string                    `json:"name" validate:"required,max=200"`
 	Description string                    `json:"description" validate:"max=1000"`
 	PrivateNote string                    `json:"private_note" validate:"max=1000"`
+	CategoryID  *string                   `json:"category_id"` // Không gửi = giữ nguyên, "" = bỏ phân loại
 	Variants     []UpdateVariantRequest   `json:"variants" validate:"required,min=1,dive"`
 }
 
@@ -47,13 +56,19 @@ type UpdateVariantRequest struct {
 } 
 
 type ProductFilter struct {
-	Search    string `json:"search"`
-	Page      int    `json:"page"`
-	Limit     int    `json:"limit"`
-	SortBy    string `json:"sort_by"`
-	SortOrder string `json:"sort_order"`
+	Search     string `json:"search"`
+	CategoryID string `json:"category_id"` // Gồm cả danh mục con, "none" = chưa phân loại
+	Page       int    `json:"page"`
+	Limit      int    `json:"limit"`
+	SortBy     string `json:"sort_by"`
+	SortOrder  string `json:"sort_order"`
+
+	IncludeArchived bool `json:"include_archived"`
 }
 
+// ProductCategoryNone filters the products without a category
+const ProductCategoryNone = "none"
+
 type PaginatedProducts struct {
 	Products   []*Product `json:"products"`
 	Total      int64      `json:"total"`
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"time"
+)
+
+// Promotion types
+type PromotionType string
+
+const (
+	PromotionTypeBuyXGetY     PromotionType = "buy_x_get_y"   // Mua X tặng Y trong cùng đơn
+	PromotionTypeStampCard    PromotionType = "stamp_card"    // Thẻ tích ly: mua đủ X (cộng dồn các đơn) tặng Y
+	PromotionTypeFreeCheapest PromotionType = "free_cheapest" // Mua tối thiểu X món tặng Y món rẻ nhất
+)
+
+// Promotion is an automatic promotion evaluated against the whole cart
+type Promotion struct {
+	ID              int64         `json:"-" db:"id"`
+	PublicID        string        `json:"id" db:"public_id"`
+	Name            string        `json:"name" db:"name"`
+	Description     string        `json:"description" db:"description"`
+	PromotionType   PromotionType `json:"promotion_type" db:"promotion_type"`
+	BuyQuantity     int           `json:"buy_quantity" db:"buy_quantity"`
+	GetQuantity     int           `json:"get_quantity" db:"get_quantity"`
+	DiscountPercent float64       `json:"discount_percent" db:"discount_percent"` // % giảm của món được tặng, 100 = miễn phí
+	Priority        int           `json:"priority" db:"priority"`
+	IsActive        bool          `json:"is_active" db:"is_active"`
+	ValidFrom       *time.Time    `json:"valid_from" db:"valid_from"`
+	ValidUntil      *time.Time    `json:"valid_until" db:"valid_until"`
+	CreatedBy       int64         `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at" db:"updated_at"`
+
+	// Scope (public IDs). Empty means every item qualifies.
+	ProductIDs []string `json:"product_ids"`
+	VariantIDs []string `json:"variant_ids"`
+}
+
+type CreatePromotionRequest struct {
+	Name            string        `json:"name" binding:"required,max=100"`
+	Description     string        `json:"description" binding:"omitempty,max=500"`
+	PromotionType   PromotionType `json:"promotion_type" binding:"required"`
+	BuyQuantity     int           `json:"buy_quantity" binding:"required,gt=0"`
+	GetQuantity     int           `json:"get_quantity" binding:"omitempty,gt=0"`
+	DiscountPercent float64       `json:"discount_percent" binding:"omitempty,gt=0,lte=100"`
+	Priority        int           `json:"priority"`
+	ProductIDs      []string      `json:"product_ids"`
+	VariantIDs      []string      `json:"variant_ids"`
+	ValidFrom       *time.Time    `json:"valid_from"`
+	ValidUntil      *time.Time    `json:"valid_until"`
+}
+
+type UpdatePromotionRequest struct {
+	CreatePromotionRequest
+	IsActive bool `json:"is_active"`
+}
+
+// OrderItemPromotion is a promotion applied to an order line
+type OrderItemPromotion struct {
+	ID                int64     `json:"-" db:"id"`
+	OrderItemID       int64     `json:"-" db:"order_item_id"`
+	PromotionID       int64     `json:"-" db:"promotion_id"`
+	PromotionPublicID string    `json:"promotion_id" db:"promotion_public_id"`
+	PromotionName     string    `json:"promotion_name" db:"promotion_name"`
+	FreeQuantity      int       `json:"free_quantity" db:"free_quantity"`
+	DiscountAmount    float64   `json:"discount_amount" db:"discount_amount"`
+	CreatedAt         time.Time `json:"created_at" db:"created_at"`
+}
+
+// PromotionLine is a cart line the promotions are evaluated against
+type PromotionLine struct {
+	OrderItemID int64   `db:"order_item_id"`
+	ProductID   int64   `db:"product_id"`
+	VariantID   int64   `db:"variant_id"`
+	Quantity    int     `db:"quantity"`
+	UnitPrice   float64 `db:"unit_price"`
+}
+
+// StampCardProgress is where a customer stands on a stamp card after the order
+type StampCardProgress struct {
+	PromotionID      string `json:"promotion_id"`
+	PromotionName    string `json:"promotion_name"`
+	Stamps           int    `json:"stamps"`            // Số ly đã tích cho phần thưởng kế tiếp
+	Required         int    `json:"required"`          // Số ly cần tích
+	RewardsAvailable int    `json:"rewards_available"` // Số món được tặng chưa dùng
+}
+
+// PromotionPreviewRequest prices a cart with the promotions without creating an order
+type PromotionPreviewRequest struct {
+	CustomerPhone string                   `json:"customer_phone" binding:"omitempty,max=20"` // Dùng để tính thẻ tích ly
+	Items         []CreateOrderItemRequest `json:"items" binding:"required,min=1"`
+}
+
+type PromotionPreviewLine struct {
+	VariantID      string               `json:"variant_id"`
+	ProductName    string               `json:"product_name"`
+	VariantName    string               `json:"variant_name"`
+	Quantity       int                  `json:"quantity"`
+	UnitPrice      float64              `json:"unit_price"`
+	TotalPrice     float64              `json:"total_price"`
+	DiscountAmount float64              `json:"discount_amount"`
+	Promotions     []OrderItemPromotion `json:"promotions"`
+}
+
+type PromotionPreview struct {
+	Lines                   []PromotionPreviewLine `json:"lines"`
+	Subtotal                float64                `json:"subtotal"`
+	PromotionDiscountAmount float64                `json:"promotion_discount_amount"`
+	TotalAmount             float64                `json:"total_amount"` // Tạm tính sau khuyến mãi
+	StampCards              []StampCardProgress    `json:"stamp_cards"`
+}
+
+// FreeUnits returns how many of the given qualifying units are free. Paid and
+// free are the qualifying units the customer bought and got free in earlier
+// orders, only stamp cards carry them over.
+func (p *Promotion) FreeUnits(units, paid, free int) int {
+	switch p.PromotionType {
+	case PromotionTypeFreeCheapest:
+		if units < p.BuyQuantity {
+			return 0
+		}
+		return min(p.GetQuantity, units)
+	case PromotionTypeBuyXGetY:
+		paid, free = 0, 0
+	}
+
+	// Every BuyQuantity paid units earn GetQuantity free units
+	for f := units; f > 0; f-- {
+		if free+f <= p.GetQuantity*((paid+units-f)/p.BuyQuantity) {
+			return f
+		}
+	}
+	return 0
+}
+
+// StampProgress returns the stamp card position for the given paid and free units
+func (p *Promotion) StampProgress(paid, free int) StampCardProgress {
+	return StampCardProgress{
+		PromotionID:      p.PublicID,
+		PromotionName:    p.Name,
+		Stamps:           paid % p.BuyQuantity,
+		Required:         p.BuyQuantity,
+		RewardsAvailable: max(0, p.GetQuantity*(paid/p.BuyQuantity)-free),
+	}
+}
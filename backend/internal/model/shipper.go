@@ -8,17 +8,30 @@ import (
 
 // Shipper represents a delivery person
 type Shipper struct {
-	ID        int64      `json:"-" db:"id"`
-	PublicID  uuid.UUID  `json:"id" db:"public_id"`
-	Name      string     `json:"name" db:"name"`
-	Phone     string     `json:"phone" db:"phone"`
-	Email     *string    `json:"email" db:"email"`
-	IsActive  bool       `json:"is_active" db:"is_active"`
-	CreatedBy *int64     `json:"created_by" db:"created_by"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	ID                      int64      `json:"-" db:"id"`
+	PublicID                uuid.UUID  `json:"id" db:"public_id"`
+	Name                    string     `json:"name" db:"name"`
+	Phone                   string     `json:"phone" db:"phone"`
+	Email                   *string    `json:"email" db:"email"`
+	IsActive                bool       `json:"is_active" db:"is_active"`
+	UserID                  *int64     `json:"-" db:"user_id"`
+	Availability            string     `json:"availability" db:"availability"`
+	MaxConcurrentDeliveries int        `json:"max_concurrent_deliveries" db:"max_concurrent_deliveries"`
+	Zone                    *string    `json:"zone" db:"zone"`
+	ShiftStartedAt          *time.Time `json:"shift_started_at" db:"shift_started_at"`
+	LastAssignedAt          *time.Time `json:"last_assigned_at" db:"last_assigned_at"`
+	ArchivedAt              *time.Time `json:"archived_at" db:"archived_at"` // Đã lưu trữ: không nhận đơn, không đăng nhập được
+	CreatedBy               *int64     `json:"created_by" db:"created_by"`
+	CreatedAt               time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt               time.Time  `json:"updated_at" db:"updated_at"`
 }
 
+// Shipper availability
+const (
+	ShipperOnShift  = "on_shift"  // Đang trong ca
+	ShipperOffShift = "off_shift" // Nghỉ ca
+)
+
 // Request/Response structs
 
 // CreateShipperRequest represents request to create shipper
@@ -48,4 +61,86 @@ type ShippersResponse struct {
 	Page     int       `json:"page"`
 	Limit    int       `json:"limit"`
 	Pages    int       `json:"pages"`
-} 
\ No newline at end of file
+}
+
+// SetShipperAccountRequest sets the login credentials of a shipper
+type SetShipperAccountRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
+
+// ShipperAccount is a shipper with its linked login credentials
+type ShipperAccount struct {
+	Shipper
+	UserPublicID string `db:"user_public_id"`
+	Username     string `db:"username"`
+	PasswordHash string `db:"password_hash"`
+	UserActive   bool   `db:"user_active"`
+}
+
+// ShipperLoginRequest represents shipper app login request
+type ShipperLoginRequest struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// ShipperLoginResponse represents shipper app login response
+type ShipperLoginResponse struct {
+	Token   string  `json:"token"`
+	Shipper Shipper `json:"shipper"`
+}
+
+// RejectDeliveryRequest represents a shipper rejecting an assigned delivery
+type RejectDeliveryRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// ShipperUpdateDeliveryStatusRequest represents a shipper moving a delivery forward
+type ShipperUpdateDeliveryStatusRequest struct {
+	Status DeliveryStatus `json:"status" binding:"required"`
+	Notes  string         `json:"notes"`
+}
+
+// ShipperCanSetDeliveryStatus reports whether a shipper may move an accepted
+// delivery from one status to another (picked_up -> in_transit -> delivered/failed)
+func ShipperCanSetDeliveryStatus(from, to DeliveryStatus) bool {
+	switch from {
+	case DeliveryStatusAssigned:
+		return to == DeliveryStatusPickedUp
+	case DeliveryStatusPickedUp:
+		return to == DeliveryStatusInTransit
+	case DeliveryStatusInTransit:
+		return to == DeliveryStatusDelivered || to == DeliveryStatusFailed
+	default:
+		return false
+	}
+}
+
+// UpdateShipperAvailabilityRequest updates the availability settings of a shipper
+type UpdateShipperAvailabilityRequest struct {
+	Availability            *string `json:"availability"`
+	MaxConcurrentDeliveries *int    `json:"max_concurrent_deliveries"`
+	Zone                    *string `json:"zone"`
+}
+
+// SetShiftRequest lets a shipper start or end their shift
+type SetShiftRequest struct {
+	Availability string `json:"availability" binding:"required"`
+}
+
+// ShipperCandidate is a shipper eligible for automatic assignment
+type ShipperCandidate struct {
+	Shipper
+	ActiveDeliveries int `json:"active_deliveries" db:"active_deliveries"`
+}
+
+// ShipperProposal is the shipper picked by the assignment engine for an order
+type ShipperProposal struct {
+	OrderID          string   `json:"order_id"`
+	Strategy         string   `json:"strategy"`
+	Shipper          *Shipper `json:"shipper"`
+	ActiveDeliveries int      `json:"active_deliveries"`
+	EligibleCount    int      `json:"eligible_count"`
+	Assigned         bool     `json:"assigned"`
+	DeliveryID       string   `json:"delivery_id,omitempty"`
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+)
+
+// Stock Movement Type Enum
+type StockMovementType string
+
+const (
+	StockMovementReceipt     StockMovementType = "receipt"
+	StockMovementConsumption StockMovementType = "consumption"
+	StockMovementAdjustment  StockMovementType = "adjustment"
+	StockMovementReversal    StockMovementType = "reversal"
+)
+
+// Stock Movement Model (ingredient stock ledger entry)
+type StockMovement struct {
+	ID                 int64             `json:"-" db:"id"`
+	PublicID           string            `json:"id" db:"public_id"`
+	IngredientID       int64             `json:"-" db:"ingredient_id"`
+	IngredientPublicID string            `json:"ingredient_id" db:"ingredient_public_id"`
+	IngredientName     string            `json:"ingredient_name" db:"ingredient_name"`
+	MovementType       StockMovementType `json:"movement_type" db:"movement_type"`
+	Quantity           float64           `json:"quantity" db:"quantity"`
+	BalanceAfter       float64           `json:"balance_after" db:"balance_after"`
+	UnitCost           *float64          `json:"unit_cost,omitempty" db:"unit_cost"`
+	OrderID            *int64            `json:"-" db:"order_id"`
+	OrderNumber        *string           `json:"order_number,omitempty" db:"order_number"`
+	Notes              *string           `json:"notes,omitempty" db:"notes"`
+	CreatedBy          *int64            `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt          time.Time         `json:"created_at" db:"created_at"`
+}
+
+// Stock Receipt Request (goods received)
+type CreateStockReceiptRequest struct {
+	Quantity float64  `json:"quantity" binding:"required,gt=0"`
+	UnitCost *float64 `json:"unit_cost" binding:"omitempty,gte=0"`
+	Notes    *string  `json:"notes"`
+}
+
+// Stock Adjustment Request (stock count correction, quantity may be negative)
+type CreateStockAdjustmentRequest struct {
+	Quantity float64 `json:"quantity" binding:"required"`
+	Notes    *string `json:"notes"`
+}
+
+// List Stock Movements Request/Response
+type ListStockMovementsRequest struct {
+	Page         int                `json:"page" binding:"gte=1"`
+	Limit        int                `json:"limit" binding:"gte=1,lte=100"`
+	MovementType *StockMovementType `json:"movement_type"`
+	DateFrom     *time.Time         `json:"date_from"`
+	DateTo       *time.Time         `json:"date_to"`
+}
+
+type ListStockMovementsResponse struct {
+	Movements []*StockMovement `json:"movements"`
+	Total     int              `json:"total"`
+	Page      int              `json:"page"`
+	Limit     int              `json:"limit"`
+	Pages     int              `json:"pages"`
+}
@@ -5,14 +5,56 @@ import (
 )
 
 type Variant struct {
-	ID          int64               `json:"-" db:"id"`
-	PublicID    string              `json:"id" db:"public_id"`
-	ProductID   int64               `json:"-" db:"product_id"`
-	Name        string              `json:"name" db:"name"`
-	Description string              `json:"description" db:"description"`
-	PrivateNote string              `json:"private_note" db:"private_note"`
-	Price       float64             `json:"price" db:"price"`
-	CreatedAt   time.Time           `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time           `json:"updated_at" db:"updated_at"`
-	Ingredients []VariantIngredient `json:"ingredients,omitempty"`
-} 
\ No newline at end of file
+	ID               int64               `json:"-" db:"id"`
+	PublicID         string              `json:"id" db:"public_id"`
+	ProductID        int64               `json:"-" db:"product_id"`
+	Name             string              `json:"name" db:"name"`
+	Description      string              `json:"description" db:"description"`
+	PrivateNote      string              `json:"private_note" db:"private_note"`
+	Price            float64             `json:"price" db:"price"`
+	IsAvailable      bool                `json:"is_available" db:"is_available"`
+	AvailableAt      *time.Time          `json:"available_at" db:"available_at"`
+	AutoAvailability bool                `json:"auto_availability" db:"auto_availability"` // Tự hết hàng theo tồn kho nguyên liệu
+	ArchivedAt       *time.Time          `json:"archived_at" db:"archived_at"`
+	CreatedAt        time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time           `json:"updated_at" db:"updated_at"`
+	Ingredients      []VariantIngredient `json:"ingredients,omitempty"`
+}
+
+// VariantPriceChange is one change of the regular price of a variant
+type VariantPriceChange struct {
+	ID            int64     `json:"-" db:"id"`
+	VariantID     int64     `json:"-" db:"variant_id"`
+	OldPrice      *float64  `json:"old_price" db:"old_price"` // nil khi tạo variant
+	NewPrice      float64   `json:"new_price" db:"new_price"`
+	ChangedBy     *int64    `json:"-" db:"changed_by"`
+	ChangedByName *string   `json:"changed_by_name" db:"changed_by_name"`
+	ChangedAt     time.Time `json:"changed_at" db:"changed_at"`
+}
+
+// PriceChangeImpact compares the sales of a variant before and after a price
+// change, from the unit prices stored on order lines
+type PriceChangeImpact struct {
+	OldPrice              float64           `json:"old_price" db:"old_price"`
+	NewPrice              float64           `json:"new_price" db:"new_price"`
+	PriceChangePercent    float64           `json:"price_change_percent" db:"-"`
+	ChangedByName         *string           `json:"changed_by_name" db:"changed_by_name"`
+	ChangedAt             time.Time         `json:"changed_at" db:"changed_at"`
+	Before                PriceChangePeriod `json:"before" db:"before"`
+	After                 PriceChangePeriod `json:"after" db:"after"`
+	QuantityChangePercent *float64          `json:"quantity_per_day_change_percent" db:"-"` // nil khi trước đó không bán được
+	RevenueChangePercent  *float64          `json:"revenue_per_day_change_percent" db:"-"`
+}
+
+// PriceChangePeriod is the sales of a variant over a period, cancelled orders excluded.
+// The period stops at the previous or next price change.
+type PriceChangePeriod struct {
+	From             time.Time `json:"from" db:"from"`
+	Until            time.Time `json:"until" db:"until"`
+	Days             float64   `json:"days" db:"-"`
+	Quantity         int       `json:"quantity" db:"quantity"`
+	Revenue          float64   `json:"revenue" db:"revenue"`
+	AverageUnitPrice float64   `json:"average_unit_price" db:"-"`
+	QuantityPerDay   float64   `json:"quantity_per_day" db:"-"`
+	RevenuePerDay    float64   `json:"revenue_per_day" db:"-"`
+}
@@ -0,0 +1,204 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockProvider is a local gateway that signs its redirects and IPNs with
+// HMAC-SHA256, so the MoMo/VNPay checkout flow can be exercised without the
+// real gateways
+type MockProvider struct {
+	code         string
+	secret       []byte
+	baseURL      string
+	mu           sync.Mutex
+	transactions map[string]*mockTransaction
+}
+
+type mockTransaction struct {
+	transactionID string
+	amount        float64
+	refunded      float64
+	status        string
+	returnURL     string
+}
+
+// NewMockProvider creates a mock provider for a payment method code. baseURL is
+// the public URL of this backend, which serves the mock checkout page
+func NewMockProvider(code, secret, baseURL string) *MockProvider {
+	return &MockProvider{
+		code:         code,
+		secret:       []byte(secret),
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		transactions: make(map[string]*mockTransaction),
+	}
+}
+
+// Code returns the payment method code handled by the provider
+func (p *MockProvider) Code() string {
+	return p.code
+}
+
+// CreateIntent registers the transaction and returns a signed mock checkout URL
+func (p *MockProvider) CreateIntent(ctx context.Context, req *IntentRequest) (*Intent, error) {
+	if req.Reference == "" || req.Amount <= 0 {
+		return nil, fmt.Errorf("invalid payment intent")
+	}
+
+	p.mu.Lock()
+	p.transactions[req.Reference] = &mockTransaction{
+		amount:    req.Amount,
+		status:    StatusPending,
+		returnURL: req.ReturnURL,
+	}
+	p.mu.Unlock()
+
+	params := url.Values{}
+	params.Set("reference", req.Reference)
+	params.Set("amount", formatAmount(req.Amount))
+	params.Set("order_number", req.OrderNumber)
+	params.Set("signature", p.Sign(params))
+
+	return &Intent{
+		Reference: req.Reference,
+		PayURL:    fmt.Sprintf("%s/api/payments/mock/%s/checkout?%s", p.baseURL, p.code, params.Encode()),
+	}, nil
+}
+
+// Checkout simulates the customer paying (or abandoning) on the gateway page.
+// It returns the signed callback params the gateway sends to the IPN and
+// return URLs
+func (p *MockProvider) Checkout(checkoutParams url.Values, success bool) (url.Values, string, error) {
+	if !p.verify(checkoutParams) {
+		return nil, "", ErrInvalidSignature
+	}
+	reference := checkoutParams.Get("reference")
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	txn, ok := p.transactions[reference]
+	if !ok {
+		return nil, "", fmt.Errorf("transaction not found")
+	}
+	if txn.status == StatusPending {
+		txn.transactionID = strconv.FormatInt(time.Now().UnixNano(), 10)
+		txn.status = StatusFailed
+		if success {
+			txn.status = StatusCompleted
+		}
+	}
+	return p.callbackParams(reference, txn), txn.returnURL, nil
+}
+
+// VerifyCallback verifies the signature of a return redirect or IPN
+func (p *MockProvider) VerifyCallback(ctx context.Context, params url.Values) (*CallbackResult, error) {
+	if !p.verify(params) {
+		return nil, ErrInvalidSignature
+	}
+	amount, err := strconv.ParseFloat(params.Get("amount"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %w", err)
+	}
+	return &CallbackResult{
+		Reference:     params.Get("reference"),
+		TransactionID: params.Get("transaction_id"),
+		Amount:        amount,
+		Success:       params.Get("result_code") == "0",
+		Message:       params.Get("message"),
+	}, nil
+}
+
+// QueryStatus returns the state of a mock transaction
+func (p *MockProvider) QueryStatus(ctx context.Context, reference string) (*StatusResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	txn, ok := p.transactions[reference]
+	if !ok {
+		return nil, fmt.Errorf("transaction not found")
+	}
+	return &StatusResult{
+		Reference:     reference,
+		TransactionID: txn.transactionID,
+		Amount:        txn.amount,
+		Status:        txn.status,
+	}, nil
+}
+
+// Refund refunds a completed mock transaction
+func (p *MockProvider) Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	txn, ok := p.transactions[req.Reference]
+	if !ok {
+		return nil, fmt.Errorf("transaction not found")
+	}
+	if txn.status != StatusCompleted {
+		return nil, fmt.Errorf("transaction is not completed")
+	}
+	if req.Amount <= 0 || req.Amount > txn.amount-txn.refunded+0.005 {
+		return nil, fmt.Errorf("refund amount exceeds refundable amount")
+	}
+	txn.refunded += req.Amount
+	return &RefundResult{
+		RefundID: fmt.Sprintf("RF%d", time.Now().UnixNano()),
+		Status:   StatusCompleted,
+	}, nil
+}
+
+// Sign computes the HMAC-SHA256 signature of params (the signature field excluded)
+func (p *MockProvider) Sign(params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		if key != "signature" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, key+"="+params.Get(key))
+	}
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write([]byte(strings.Join(parts, "&")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (p *MockProvider) verify(params url.Values) bool {
+	signature, err := hex.DecodeString(params.Get("signature"))
+	if err != nil || len(signature) == 0 {
+		return false
+	}
+	expected, _ := hex.DecodeString(p.Sign(params))
+	return hmac.Equal(signature, expected)
+}
+
+func (p *MockProvider) callbackParams(reference string, txn *mockTransaction) url.Values {
+	params := url.Values{}
+	params.Set("reference", reference)
+	params.Set("transaction_id", txn.transactionID)
+	params.Set("amount", formatAmount(txn.amount))
+	if txn.status == StatusCompleted {
+		params.Set("result_code", "0")
+		params.Set("message", "Giao dịch thành công")
+	} else {
+		params.Set("result_code", "1006")
+		params.Set("message", "Khách hàng hủy giao dịch")
+	}
+	params.Set("signature", p.Sign(params))
+	return params
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -0,0 +1,167 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func signedParams(p *MockProvider, values map[string]string) url.Values {
+	params := url.Values{}
+	for key, value := range values {
+		params.Set(key, value)
+	}
+	params.Set("signature", p.Sign(params))
+	return params
+}
+
+func TestMockProviderVerifyCallback(t *testing.T) {
+	provider := NewMockProvider("momo", "secret", "http://localhost:8080")
+	other := NewMockProvider("momo", "other-secret", "http://localhost:8080")
+	callback := map[string]string{
+		"reference":      "REF1",
+		"transaction_id": "TXN1",
+		"amount":         "50000.00",
+		"result_code":    "0",
+		"message":        "Giao dịch thành công",
+	}
+
+	tests := []struct {
+		name    string
+		params  func() url.Values
+		wantErr error
+		success bool
+	}{
+		{
+			name:    "valid signature",
+			params:  func() url.Values { return signedParams(provider, callback) },
+			success: true,
+		},
+		{
+			name: "tampered signature",
+			params: func() url.Values {
+				params := signedParams(provider, callback)
+				signature := []byte(params.Get("signature"))
+				if signature[0] == 'a' {
+					signature[0] = 'b'
+				} else {
+					signature[0] = 'a'
+				}
+				params.Set("signature", string(signature))
+				return params
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "changed amount",
+			params: func() url.Values {
+				params := signedParams(provider, callback)
+				params.Set("amount", "1000.00")
+				return params
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "changed result code",
+			params: func() url.Values {
+				failed := signedParams(provider, map[string]string{
+					"reference": "REF1", "transaction_id": "TXN1", "amount": "50000.00", "result_code": "1006",
+				})
+				failed.Set("result_code", "0")
+				return failed
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "missing signature",
+			params: func() url.Values {
+				params := signedParams(provider, callback)
+				params.Del("signature")
+				return params
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "signed with another secret",
+			params:  func() url.Values { return signedParams(other, callback) },
+			wantErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := provider.VerifyCallback(context.Background(), tt.params())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VerifyCallback() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VerifyCallback() unexpected error: %v", err)
+			}
+			if result.Success != tt.success || result.Reference != "REF1" || result.Amount != 50000 {
+				t.Fatalf("VerifyCallback() = %+v", result)
+			}
+		})
+	}
+}
+
+func TestMockProviderCheckout(t *testing.T) {
+	provider := NewMockProvider("vnpay", "secret", "http://localhost:8080/")
+	intent, err := provider.CreateIntent(context.Background(), &IntentRequest{
+		Reference:   "REF2",
+		Amount:      120000,
+		OrderNumber: "ORD-1",
+		ReturnURL:   "http://localhost:8080/api/payments/vnpay/return",
+	})
+	if err != nil {
+		t.Fatalf("CreateIntent() error: %v", err)
+	}
+	payURL, err := url.Parse(intent.PayURL)
+	if err != nil {
+		t.Fatalf("invalid pay URL %q: %v", intent.PayURL, err)
+	}
+	if payURL.Path != "/api/payments/mock/vnpay/checkout" {
+		t.Fatalf("pay URL path = %q", payURL.Path)
+	}
+
+	tampered := payURL.Query()
+	tampered.Set("amount", "1.00")
+	if _, _, err := provider.Checkout(tampered, true); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Checkout() with changed amount error = %v, want %v", err, ErrInvalidSignature)
+	}
+
+	callback, returnURL, err := provider.Checkout(payURL.Query(), true)
+	if err != nil {
+		t.Fatalf("Checkout() error: %v", err)
+	}
+	if returnURL != "http://localhost:8080/api/payments/vnpay/return" {
+		t.Fatalf("return URL = %q", returnURL)
+	}
+	result, err := provider.VerifyCallback(context.Background(), callback)
+	if err != nil {
+		t.Fatalf("VerifyCallback() error: %v", err)
+	}
+	if !result.Success || result.Amount != 120000 || result.TransactionID == "" {
+		t.Fatalf("VerifyCallback() = %+v", result)
+	}
+
+	// Paying again (or cancelling afterwards) does not change a settled transaction
+	again, _, err := provider.Checkout(payURL.Query(), false)
+	if err != nil {
+		t.Fatalf("second Checkout() error: %v", err)
+	}
+	if again.Get("transaction_id") != result.TransactionID || again.Get("result_code") != "0" {
+		t.Fatalf("second Checkout() changed the transaction: %v", again)
+	}
+
+	status, err := provider.QueryStatus(context.Background(), "REF2")
+	if err != nil {
+		t.Fatalf("QueryStatus() error: %v", err)
+	}
+	if status.Status != StatusCompleted {
+		t.Fatalf("QueryStatus() status = %q, want %q", status.Status, StatusCompleted)
+	}
+}
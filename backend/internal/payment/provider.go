@@ -0,0 +1,114 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sort"
+)
+
+// ErrInvalidSignature is returned when a callback/IPN signature does not match
+var ErrInvalidSignature = errors.New("invalid payment callback signature")
+
+// Transaction statuses reported by providers
+const (
+	StatusPending   = "pending"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// IntentRequest describes a payment the customer is redirected to pay
+type IntentRequest struct {
+	Reference   string
+	Amount      float64
+	OrderNumber string
+	Description string
+	ReturnURL   string
+	IPNURL      string
+}
+
+// Intent is the gateway checkout created for an IntentRequest
+type Intent struct {
+	Reference string
+	PayURL    string
+}
+
+// CallbackResult is the verified content of a return redirect or IPN
+type CallbackResult struct {
+	Reference     string
+	TransactionID string
+	Amount        float64
+	Success       bool
+	Message       string
+}
+
+// StatusResult is the provider-side state of a transaction
+type StatusResult struct {
+	Reference     string
+	TransactionID string
+	Amount        float64
+	Status        string
+}
+
+// RefundRequest refunds (part of) a completed transaction
+type RefundRequest struct {
+	Reference string
+	Amount    float64
+	Reason    string
+}
+
+// RefundResult is the provider-side refund
+type RefundResult struct {
+	RefundID string
+	Status   string
+}
+
+// Provider is implemented by each payment gateway (MoMo, VNPay, ...)
+type Provider interface {
+	// Code returns the payment method code handled by the provider
+	Code() string
+	// CreateIntent creates a checkout and returns the URL to redirect the customer to
+	CreateIntent(ctx context.Context, req *IntentRequest) (*Intent, error)
+	// VerifyCallback verifies a signed return redirect or IPN
+	VerifyCallback(ctx context.Context, params url.Values) (*CallbackResult, error)
+	// QueryStatus asks the gateway for the state of a transaction
+	QueryStatus(ctx context.Context, reference string) (*StatusResult, error)
+	// Refund refunds a completed transaction fully or partially
+	Refund(ctx context.Context, req *RefundRequest) (*RefundResult, error)
+}
+
+// Registry holds the configured providers by payment method code
+type Registry struct {
+	providers map[string]Provider
+}
+
+// NewRegistry creates a registry from the given providers
+func NewRegistry(providers ...Provider) *Registry {
+	r := &Registry{providers: make(map[string]Provider, len(providers))}
+	for _, provider := range providers {
+		r.providers[provider.Code()] = provider
+	}
+	return r
+}
+
+// Get returns the provider for a payment method code
+func (r *Registry) Get(code string) (Provider, bool) {
+	if r == nil {
+		return nil, false
+	}
+	provider, ok := r.providers[code]
+	return provider, ok
+}
+
+// Codes returns the payment method codes that have a provider
+func (r *Registry) Codes() []string {
+	if r == nil {
+		return nil
+	}
+	codes := make([]string, 0, len(r.providers))
+	for code := range r.providers {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// variantAvailabilitySelect computes whether each variant can be sold now. A
+// flag whose back-at time has passed no longer counts, even before
+// RestoreDue clears it. The reasons match the model.AvailabilityReason constants.
+const variantAvailabilitySelect = `
+	SELECT v.public_id::text AS variant_id, p.public_id::text AS product_id,
+		p.name AS product_name, v.name AS variant_name,
+		a.reason IS NULL AS is_available, a.reason,
+		CASE a.reason
+			WHEN 'product_unavailable' THEN p.available_at
+			WHEN 'variant_unavailable' THEN v.available_at
+		END AS available_at
+	FROM variants v
+	JOIN products p ON v.product_id = p.id
+	CROSS JOIN LATERAL (
+		SELECT CASE
+			WHEN p.archived_at IS NOT NULL OR v.archived_at IS NOT NULL THEN 'archived'
+			WHEN NOT p.is_available AND COALESCE(p.available_at > CURRENT_TIMESTAMP, TRUE) THEN 'product_unavailable'
+			WHEN NOT v.is_available AND COALESCE(v.available_at > CURRENT_TIMESTAMP, TRUE) THEN 'variant_unavailable'
+			WHEN v.auto_availability AND EXISTS (
+				SELECT 1
+				FROM variant_ingredients vi
+				JOIN ingredients i ON vi.ingredient_id = i.id
+				WHERE vi.variant_id = v.id AND i.stock_quantity < vi.quantity
+			) THEN 'out_of_stock'
+		END AS reason
+	) a`
+
+type AvailabilityRepository struct {
+	db *sqlx.DB
+}
+
+func NewAvailabilityRepository(db *sqlx.DB) *AvailabilityRepository {
+	return &AvailabilityRepository{db: db}
+}
+
+// ListVariantAvailability lists the current availability of every variant
+func (r *AvailabilityRepository) ListVariantAvailability(ctx context.Context) ([]*model.VariantAvailability, error) {
+	variants := make([]*model.VariantAvailability, 0)
+	err := r.db.SelectContext(ctx, &variants, variantAvailabilitySelect+`
+		WHERE p.archived_at IS NULL AND v.archived_at IS NULL
+		ORDER BY p.name ASC, v.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// SetProductAvailability marks a whole product as available or sold out and
+// returns the resulting availability of its variants, nil if the product does
+// not exist
+func (r *AvailabilityRepository) SetProductAvailability(ctx context.Context, publicID string, req *model.UpdateAvailabilityRequest) ([]*model.VariantAvailability, error) {
+	var productID int64
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE products
+		SET is_available = $1, available_at = CASE WHEN $1 THEN NULL ELSE $2::timestamp END, updated_at = CURRENT_TIMESTAMP
+		WHERE public_id::text = $3
+		RETURNING id
+	`, *req.IsAvailable, req.AvailableAt, publicID).Scan(&productID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	variants := make([]*model.VariantAvailability, 0)
+	err = r.db.SelectContext(ctx, &variants, variantAvailabilitySelect+` WHERE v.product_id = $1 AND v.archived_at IS NULL ORDER BY v.id ASC`, productID)
+	if err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
+
+// SetVariantAvailability marks a single variant as available or sold out,
+// nil if the variant does not exist
+func (r *AvailabilityRepository) SetVariantAvailability(ctx context.Context, publicID string, req *model.UpdateVariantAvailabilityRequest) (*model.VariantAvailability, error) {
+	var variantID int64
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE variants
+		SET is_available = $1, available_at = CASE WHEN $1 THEN NULL ELSE $2::timestamp END,
+			auto_availability = COALESCE($3, auto_availability), updated_at = CURRENT_TIMESTAMP
+		WHERE public_id::text = $4
+		RETURNING id
+	`, *req.IsAvailable, req.AvailableAt, req.AutoAvailability, publicID).Scan(&variantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.getVariantAvailability(ctx, r.db, variantID)
+}
+
+// RestoreDue puts products and variants whose back-at time has passed back on
+// sale and returns how many rows changed
+func (r *AvailabilityRepository) RestoreDue(ctx context.Context) (int64, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var restored int64
+	for _, table := range []string{"products", "variants"} {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE `+table+`
+			SET is_available = TRUE, available_at = NULL, updated_at = CURRENT_TIMESTAMP
+			WHERE NOT is_available AND available_at <= CURRENT_TIMESTAMP
+		`)
+		if err != nil {
+			return 0, err
+		}
+		rows, _ := result.RowsAffected()
+		restored += rows
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return restored, nil
+}
+
+func (r *AvailabilityRepository) getVariantAvailability(ctx context.Context, q sqlx.QueryerContext, variantID int64) (*model.VariantAvailability, error) {
+	var availability model.VariantAvailability
+	if err := sqlx.GetContext(ctx, q, &availability, variantAvailabilitySelect+` WHERE v.id = $1`, variantID); err != nil {
+		return nil, err
+	}
+	return &availability, nil
+}
+
+// ensureAvailable rejects a variant that cannot be sold right now
+func (r *AvailabilityRepository) ensureAvailable(ctx context.Context, q sqlx.QueryerContext, variantID int64) error {
+	availability, err := r.getVariantAvailability(ctx, q, variantID)
+	if err != nil {
+		return err
+	}
+	if availability.IsAvailable {
+		return nil
+	}
+	if availability.Reason != nil && *availability.Reason == model.AvailabilityReasonArchived {
+		return model.NewValidationError("items", "Món đã ngừng kinh doanh: "+availability.ProductName+" - "+availability.VariantName)
+	}
+	message := "Món đang tạm hết: " + availability.ProductName + " - " + availability.VariantName
+	if availability.AvailableAt != nil {
+		message += " (có lại lúc " + availability.AvailableAt.Format("15:04 02/01") + ")"
+	}
+	return model.NewValidationError("items", message)
+}
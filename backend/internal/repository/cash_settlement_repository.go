@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CashSettlementRepository struct {
+	db *sqlx.DB
+}
+
+func NewCashSettlementRepository(db *sqlx.DB) *CashSettlementRepository {
+	return &CashSettlementRepository{db: db}
+}
+
+const cashSettlementSelectQuery = `
+	SELECT cs.id, cs.public_id, cs.shipper_id, s.public_id AS shipper_public_id, s.name AS shipper_name,
+		cs.expected_amount, cs.received_amount, cs.discrepancy, cs.delivery_count, cs.notes, cs.settled_by, cs.created_at
+	FROM shipper_cash_settlements cs
+	JOIN shippers s ON cs.shipper_id = s.id
+`
+
+const codDeliverySelectQuery = `
+	SELECT d.public_id, d.delivery_number, o.public_id AS order_public_id, o.order_number, d.cod_amount, d.cod_collected_at
+	FROM delivery_orders d
+	JOIN orders o ON d.order_id = o.id
+`
+
+// Delivered COD orders whose cash the shipper has not handed over yet
+const codOutstandingCondition = "d.status = 'delivered' AND d.cod_amount > 0 AND d.cod_settlement_id IS NULL"
+
+// ListShipperCashBalances lists the shippers currently holding COD cash
+func (r *CashSettlementRepository) ListShipperCashBalances(ctx context.Context) ([]*model.ShipperCashBalance, error) {
+	balances := []*model.ShipperCashBalance{}
+	query := `
+		SELECT s.public_id AS shipper_public_id, s.name AS shipper_name,
+			SUM(d.cod_amount) AS outstanding_amount, COUNT(d.id) AS outstanding_count,
+			MIN(d.cod_collected_at) AS oldest_collected_at
+		FROM delivery_orders d
+		JOIN shippers s ON d.shipper_id = s.id
+		WHERE ` + codOutstandingCondition + `
+		GROUP BY s.id, s.public_id, s.name
+		ORDER BY outstanding_amount DESC
+	`
+	if err := r.db.SelectContext(ctx, &balances, query); err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// GetShipperCashBalance gets the COD cash held by a shipper with the deliveries it comes from
+func (r *CashSettlementRepository) GetShipperCashBalance(ctx context.Context, shipper *model.Shipper) (*model.ShipperCashBalance, error) {
+	deliveries := []model.CODDelivery{}
+	query := codDeliverySelectQuery + " WHERE d.shipper_id = $1 AND " + codOutstandingCondition + " ORDER BY d.cod_collected_at ASC, d.id ASC"
+	if err := r.db.SelectContext(ctx, &deliveries, query, shipper.ID); err != nil {
+		return nil, err
+	}
+
+	balance := &model.ShipperCashBalance{
+		ShipperID:   shipper.PublicID.String(),
+		ShipperName: shipper.Name,
+		Deliveries:  deliveries,
+	}
+	for _, delivery := range deliveries {
+		balance.OutstandingAmount += delivery.CodAmount
+		if balance.OldestCollectedAt == nil {
+			balance.OldestCollectedAt = delivery.CollectedAt
+		}
+	}
+	balance.OutstandingAmount = roundMoney(balance.OutstandingAmount)
+	balance.OutstandingCount = len(deliveries)
+	return balance, nil
+}
+
+// CreateSettlement records the cash an admin received from a shipper against the shipper's
+// outstanding COD deliveries (all of them when deliveryIDs is empty) and marks them settled
+func (r *CashSettlementRepository) CreateSettlement(ctx context.Context, shipperID int64, deliveryIDs []string, receivedAmount float64, notes string, userID int64) (string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// Khóa các đơn đang chờ đối soát để không bị đối soát hai lần
+	var outstanding []struct {
+		ID        int64   `db:"id"`
+		PublicID  string  `db:"public_id"`
+		CodAmount float64 `db:"cod_amount"`
+	}
+	query := "SELECT d.id, d.public_id, d.cod_amount FROM delivery_orders d WHERE d.shipper_id = $1 AND " + codOutstandingCondition + " FOR UPDATE"
+	if err = tx.SelectContext(ctx, &outstanding, query, shipperID); err != nil {
+		return "", err
+	}
+
+	selected := map[string]bool{}
+	for _, id := range deliveryIDs {
+		selected[id] = true
+	}
+	ids := []int64{}
+	expected := 0.0
+	for _, delivery := range outstanding {
+		if len(selected) > 0 && !selected[delivery.PublicID] {
+			continue
+		}
+		delete(selected, delivery.PublicID)
+		ids = append(ids, delivery.ID)
+		expected += delivery.CodAmount
+	}
+	if len(selected) > 0 {
+		return "", model.NewValidationError("delivery_ids", "Có đơn giao không thuộc shipper hoặc đã được đối soát")
+	}
+	if len(ids) == 0 {
+		return "", model.NewValidationError("delivery_ids", "Shipper không có tiền thu hộ cần đối soát")
+	}
+	expected = roundMoney(expected)
+
+	var settlementID int64
+	var publicID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO shipper_cash_settlements (
+			shipper_id, expected_amount, received_amount, discrepancy, delivery_count, notes, settled_by
+		) VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+		RETURNING id, public_id
+	`, shipperID, expected, receivedAmount, roundMoney(receivedAmount-expected), len(ids), notes, userID).Scan(&settlementID, &publicID)
+	if err != nil {
+		return "", err
+	}
+
+	updateQuery, args, err := sqlx.In(`
+		UPDATE delivery_orders SET cod_settlement_id = ?, cod_settled_at = CURRENT_TIMESTAMP
+		WHERE id IN (?)
+	`, settlementID, ids)
+	if err != nil {
+		return "", err
+	}
+	if _, err = tx.ExecContext(ctx, tx.Rebind(updateQuery), args...); err != nil {
+		return "", err
+	}
+
+	return publicID, tx.Commit()
+}
+
+// GetSettlementByPublicID gets a settlement with its deliveries, returns nil if not found
+func (r *CashSettlementRepository) GetSettlementByPublicID(ctx context.Context, publicID string) (*model.CashSettlement, error) {
+	var settlement model.CashSettlement
+	err := r.db.GetContext(ctx, &settlement, cashSettlementSelectQuery+" WHERE cs.public_id = $1", publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	settlement.Status = model.SettlementStatus(settlement.Discrepancy)
+
+	deliveries := []model.CODDelivery{}
+	query := codDeliverySelectQuery + " WHERE d.cod_settlement_id = $1 ORDER BY d.cod_collected_at ASC, d.id ASC"
+	if err := r.db.SelectContext(ctx, &deliveries, query, settlement.ID); err != nil {
+		return nil, err
+	}
+	settlement.Deliveries = deliveries
+	return &settlement, nil
+}
+
+// ListSettlements lists settlements, newest first
+func (r *CashSettlementRepository) ListSettlements(ctx context.Context, req *model.ListCashSettlementsRequest) (*model.ListCashSettlementsResponse, error) {
+	whereClause := "WHERE 1=1"
+	args := []interface{}{}
+	argIndex := 1
+
+	if req.ShipperID != "" {
+		whereClause += fmt.Sprintf(" AND s.public_id::text = $%d", argIndex)
+		args = append(args, req.ShipperID)
+		argIndex++
+	}
+	if req.HasDiscrepancy != nil {
+		if *req.HasDiscrepancy {
+			whereClause += " AND cs.discrepancy <> 0"
+		} else {
+			whereClause += " AND cs.discrepancy = 0"
+		}
+	}
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM shipper_cash_settlements cs JOIN shippers s ON cs.shipper_id = s.id " + whereClause
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, err
+	}
+
+	settlements := []model.CashSettlement{}
+	query := fmt.Sprintf("%s %s ORDER BY cs.created_at DESC, cs.id DESC LIMIT $%d OFFSET $%d", cashSettlementSelectQuery, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Limit, (req.Page-1)*req.Limit)
+	if err := r.db.SelectContext(ctx, &settlements, query, args...); err != nil {
+		return nil, err
+	}
+	for i := range settlements {
+		settlements[i].Status = model.SettlementStatus(settlements[i].Discrepancy)
+	}
+
+	return &model.ListCashSettlementsResponse{
+		Settlements: settlements,
+		Total:       total,
+		Page:        req.Page,
+		Limit:       req.Limit,
+		Pages:       (total + req.Limit - 1) / req.Limit,
+	}, nil
+}
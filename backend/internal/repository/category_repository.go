@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const categorySelect = `
+	SELECT c.id, c.public_id::text AS public_id, c.parent_id, pc.public_id::text AS parent_public_id,
+		c.name, COALESCE(c.description, '') AS description, c.sort_order,
+		(SELECT COUNT(*) FROM products WHERE category_id = c.id AND archived_at IS NULL) AS product_count,
+		c.created_at, c.updated_at
+	FROM categories c
+	LEFT JOIN categories pc ON c.parent_id = pc.id`
+
+// categoryTreeCTE numbers the categories in menu order: a category comes after
+// its parent and before its later siblings. Join on category_tree.id and order
+// by category_tree.path.
+const categoryTreeCTE = `
+	WITH RECURSIVE category_tree AS (
+		SELECT id, ARRAY[sort_order::bigint, id] AS path
+		FROM categories
+		WHERE parent_id IS NULL
+		UNION ALL
+		SELECT c.id, t.path || ARRAY[c.sort_order::bigint, c.id]
+		FROM categories c
+		JOIN category_tree t ON c.parent_id = t.id
+	)`
+
+type CategoryRepository struct {
+	db *sqlx.DB
+}
+
+func NewCategoryRepository(db *sqlx.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+// ListCategories lists all categories, parents before children and siblings in sort order
+func (r *CategoryRepository) ListCategories(ctx context.Context) ([]*model.Category, error) {
+	categories := make([]*model.Category, 0)
+	err := r.db.SelectContext(ctx, &categories, categoryTreeCTE+categorySelect+`
+		JOIN category_tree t ON t.id = c.id
+		ORDER BY t.path ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+// GetCategoryByPublicID gets a category by public ID, nil if it does not exist
+func (r *CategoryRepository) GetCategoryByPublicID(ctx context.Context, publicID string) (*model.Category, error) {
+	var category model.Category
+	err := r.db.GetContext(ctx, &category, categorySelect+` WHERE c.public_id::text = $1`, publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &category, nil
+}
+
+// CreateCategory creates a category, placed last among its siblings when no sort order is given
+func (r *CategoryRepository) CreateCategory(ctx context.Context, req *model.CreateCategoryRequest, parentID *int64) (*model.Category, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO categories (parent_id, name, description, sort_order)
+		VALUES ($1, $2, $3, COALESCE($4, (
+			SELECT COALESCE(MAX(sort_order), 0) + 1 FROM categories WHERE parent_id IS NOT DISTINCT FROM $1
+		)))
+		RETURNING id
+	`, parentID, req.Name, req.Description, req.SortOrder).Scan(&id)
+	if err != nil {
+		return nil, err
+	}
+	return r.getCategoryByID(ctx, id)
+}
+
+// UpdateCategory updates a category
+func (r *CategoryRepository) UpdateCategory(ctx context.Context, id int64, req *model.UpdateCategoryRequest, parentID *int64) (*model.Category, error) {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE categories
+		SET parent_id = $1, name = $2, description = $3, sort_order = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+	`, parentID, req.Name, req.Description, req.SortOrder, id)
+	if err != nil {
+		return nil, err
+	}
+	return r.getCategoryByID(ctx, id)
+}
+
+// DeleteCategory deletes a category, its products become uncategorized
+func (r *CategoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	return err
+}
+
+// HasChildren reports whether a category has subcategories
+func (r *CategoryRepository) HasChildren(ctx context.Context, id int64) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `SELECT EXISTS (SELECT 1 FROM categories WHERE parent_id = $1)`, id)
+	return exists, err
+}
+
+// IsDescendant reports whether candidateID is categoryID itself or one of its subcategories
+func (r *CategoryRepository) IsDescendant(ctx context.Context, categoryID, candidateID int64) (bool, error) {
+	var exists bool
+	err := r.db.GetContext(ctx, &exists, `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+		)
+		SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)
+	`, categoryID, candidateID)
+	return exists, err
+}
+
+// ReorderCategories sets the sort order of the children of a parent (nil for
+// the root categories) to the order of the given public IDs
+func (r *CategoryRepository) ReorderCategories(ctx context.Context, parentID *int64, publicIDs []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i, publicID := range publicIDs {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE categories SET sort_order = $1, updated_at = CURRENT_TIMESTAMP
+			WHERE public_id::text = $2 AND parent_id IS NOT DISTINCT FROM $3
+		`, i+1, publicID, parentID)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return model.NewValidationError("category_ids", "Danh mục không thuộc danh mục cha đã chọn: "+publicID)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// SetCategoryProducts makes the given products, in order, the products of a
+// category. Products no longer listed become uncategorized.
+func (r *CategoryRepository) SetCategoryProducts(ctx context.Context, categoryID int64, productPublicIDs []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE products SET category_id = NULL, sort_order = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE category_id = $1
+	`, categoryID)
+	if err != nil {
+		return err
+	}
+
+	for i, publicID := range productPublicIDs {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE products SET category_id = $1, sort_order = $2, updated_at = CURRENT_TIMESTAMP
+			WHERE public_id::text = $3
+		`, categoryID, i+1, publicID)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return model.NewValidationError("product_ids", "Sản phẩm không tồn tại: "+publicID)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *CategoryRepository) getCategoryByID(ctx context.Context, id int64) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.GetContext(ctx, &category, categorySelect+` WHERE c.id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const customerAccountColumns = `
+	id, public_id, COALESCE(full_name, '') AS full_name, COALESCE(phone, '') AS phone,
+	COALESCE(email, '') AS email, COALESCE(password_hash, '') AS password_hash,
+	role, is_guest, is_active, registered_at, created_at`
+
+// phoneMatchSQL matches users.phone against normalized phone digits ($1), also
+// when the phone was saved with the 84 country code or with separators
+const phoneMatchSQL = `($1 <> '' AND regexp_replace(phone, '\D', '', 'g') IN ($1, '84' || substring($1 from 2)))`
+
+type CustomerAccountRepository struct {
+	db *sqlx.DB
+}
+
+func NewCustomerAccountRepository(db *sqlx.DB) *CustomerAccountRepository {
+	return &CustomerAccountRepository{db: db}
+}
+
+// FindByContact lists the users whose phone or email matches, registered or guest
+func (r *CustomerAccountRepository) FindByContact(ctx context.Context, phoneDigits, email string) ([]model.CustomerAccount, error) {
+	accounts := []model.CustomerAccount{}
+	query := `SELECT ` + customerAccountColumns + `
+		FROM users
+		WHERE ` + phoneMatchSQL + ` OR ($2 <> '' AND lower(email) = lower($2))
+		ORDER BY id`
+	if err := r.db.SelectContext(ctx, &accounts, query, phoneDigits, email); err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// GetRegisteredByPhone gets a registered customer by normalized phone digits, nil if none
+func (r *CustomerAccountRepository) GetRegisteredByPhone(ctx context.Context, phoneDigits string) (*model.CustomerAccount, error) {
+	return r.getRegistered(ctx, phoneMatchSQL, phoneDigits)
+}
+
+// GetRegisteredByEmail gets a registered customer by email, nil if none
+func (r *CustomerAccountRepository) GetRegisteredByEmail(ctx context.Context, email string) (*model.CustomerAccount, error) {
+	return r.getRegistered(ctx, `lower(email) = lower($1)`, email)
+}
+
+func (r *CustomerAccountRepository) getRegistered(ctx context.Context, condition string, arg string) (*model.CustomerAccount, error) {
+	var account model.CustomerAccount
+	query := `SELECT ` + customerAccountColumns + `
+		FROM users
+		WHERE ` + condition + ` AND role = 'client' AND is_guest = false
+		LIMIT 1`
+	if err := r.db.GetContext(ctx, &account, query, arg); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetByPublicID gets a customer by public ID, nil if none
+func (r *CustomerAccountRepository) GetByPublicID(ctx context.Context, publicID string) (*model.CustomerAccount, error) {
+	var account model.CustomerAccount
+	query := `SELECT ` + customerAccountColumns + ` FROM users WHERE public_id::text = $1`
+	if err := r.db.GetContext(ctx, &account, query, publicID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ClaimGuest turns a guest user into a registered customer. Contact fields the
+// guest already has are kept, only missing ones are filled in; an empty password
+// hash keeps the account passwordless (OTP login). Returns false if the user is
+// no longer a guest.
+func (r *CustomerAccountRepository) ClaimGuest(ctx context.Context, userID int64, fullName, phone, email, passwordHash string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE users SET
+			full_name = COALESCE(NULLIF(full_name, ''), $2),
+			phone = COALESCE(NULLIF(phone, ''), $3),
+			email = COALESCE(NULLIF(email, ''), NULLIF($4, '')),
+			password_hash = COALESCE(NULLIF($5, ''), password_hash),
+			role = 'client',
+			is_guest = false,
+			registered_at = CURRENT_TIMESTAMP,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND is_guest = true
+	`, userID, fullName, phone, email, passwordHash)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// CreateCustomer creates a registered customer, without password if the hash is empty
+func (r *CustomerAccountRepository) CreateCustomer(ctx context.Context, fullName, phone, email, passwordHash string) (*model.CustomerAccount, error) {
+	var account model.CustomerAccount
+	query := `
+		INSERT INTO users (full_name, phone, email, password_hash, role, is_guest, is_active, registered_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), 'client', false, true, CURRENT_TIMESTAMP)
+		RETURNING ` + customerAccountColumns
+	if err := r.db.GetContext(ctx, &account, query, fullName, phone, email, passwordHash); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type CustomerAddressRepository struct {
+	db *sqlx.DB
+}
+
+func NewCustomerAddressRepository(db *sqlx.DB) *CustomerAddressRepository {
+	return &CustomerAddressRepository{db: db}
+}
+
+// GetUserDBID gets the internal ID of a user by public ID, nil if the user does not exist
+func (r *CustomerAddressRepository) GetUserDBID(ctx context.Context, userPublicID string) (*int64, error) {
+	var userID int64
+	err := r.db.GetContext(ctx, &userID, "SELECT id FROM users WHERE public_id::text = $1", userPublicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &userID, nil
+}
+
+// ListAddresses lists the saved addresses of a user, default address first
+func (r *CustomerAddressRepository) ListAddresses(ctx context.Context, userID int64) ([]model.CustomerAddress, error) {
+	addresses := []model.CustomerAddress{}
+	query := `SELECT * FROM customer_addresses WHERE user_id = $1 ORDER BY is_default DESC, created_at DESC, id DESC`
+	if err := r.db.SelectContext(ctx, &addresses, query, userID); err != nil {
+		return nil, err
+	}
+	return addresses, nil
+}
+
+// GetAddressByPublicID gets a saved address by public ID
+func (r *CustomerAddressRepository) GetAddressByPublicID(ctx context.Context, publicID string) (*model.CustomerAddress, error) {
+	var address model.CustomerAddress
+	err := r.db.GetContext(ctx, &address, "SELECT * FROM customer_addresses WHERE public_id::text = $1", publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &address, nil
+}
+
+// CreateAddress saves a new address; the first address of a user becomes the default
+func (r *CustomerAddressRepository) CreateAddress(ctx context.Context, address *model.CustomerAddress) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Khóa các địa chỉ của khách để tránh hai địa chỉ mặc định khi tạo đồng thời
+	var count int
+	if err = tx.GetContext(ctx, &count, "SELECT COUNT(*) FROM (SELECT id FROM customer_addresses WHERE user_id = $1 FOR UPDATE) a", address.UserID); err != nil {
+		return err
+	}
+	if count == 0 {
+		address.IsDefault = true
+	}
+	if address.IsDefault {
+		if err = r.clearDefault(ctx, tx, address.UserID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO customer_addresses (
+			user_id, label, address_line, ward, district, province, latitude, longitude, is_default
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9
+		) RETURNING id, public_id, created_at, updated_at`
+	err = tx.QueryRowContext(ctx, query,
+		address.UserID, address.Label, address.AddressLine, address.Ward, address.District, address.Province,
+		address.Latitude, address.Longitude, address.IsDefault,
+	).Scan(&address.ID, &address.PublicID, &address.CreatedAt, &address.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateAddress updates a saved address
+func (r *CustomerAddressRepository) UpdateAddress(ctx context.Context, address *model.CustomerAddress) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if address.IsDefault {
+		if err = r.clearDefault(ctx, tx, address.UserID); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE customer_addresses SET
+			label = $1, address_line = $2, ward = $3, district = $4, province = $5,
+			latitude = $6, longitude = $7, is_default = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9
+		RETURNING updated_at`
+	err = tx.QueryRowContext(ctx, query,
+		address.Label, address.AddressLine, address.Ward, address.District, address.Province,
+		address.Latitude, address.Longitude, address.IsDefault, address.ID,
+	).Scan(&address.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteAddress deletes a saved address; if it was the default, the newest remaining address becomes the default
+func (r *CustomerAddressRepository) DeleteAddress(ctx context.Context, address *model.CustomerAddress) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM customer_addresses WHERE id = $1", address.ID); err != nil {
+		return err
+	}
+
+	if address.IsDefault {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE customer_addresses SET is_default = true, updated_at = CURRENT_TIMESTAMP
+			WHERE id = (
+				SELECT id FROM customer_addresses WHERE user_id = $1
+				ORDER BY created_at DESC, id DESC LIMIT 1
+			)
+		`, address.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// clearDefault unsets the default address of a user
+func (r *CustomerAddressRepository) clearDefault(ctx context.Context, q sqlx.ExecerContext, userID int64) error {
+	_, err := q.ExecContext(ctx, "UPDATE customer_addresses SET is_default = false, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1 AND is_default = true", userID)
+	return err
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const mergeCustomerColumns = `
+	u.id, u.public_id, COALESCE(u.full_name, '') AS full_name, COALESCE(u.phone, '') AS phone,
+	COALESCE(u.email, '') AS email, u.is_guest, u.created_at,
+	(SELECT COUNT(*) FROM orders o WHERE o.created_by = u.id) AS total_orders,
+	(SELECT MAX(o.created_at) FROM orders o WHERE o.created_by = u.id) AS last_order_at`
+
+const customerMergeSelect = `
+	SELECT m.id, m.public_id, su.public_id AS source_user_id, tu.public_id AS target_user_id,
+		m.source_snapshot, m.moved_orders, m.moved_addresses, m.reason, mu.public_id::text AS merged_by, m.created_at
+	FROM user_merges m
+	JOIN users su ON m.source_user_id = su.id
+	JOIN users tu ON m.target_user_id = tu.id
+	LEFT JOIN users mu ON m.merged_by = mu.id`
+
+type CustomerMergeRepository struct {
+	db          *sqlx.DB
+	loyaltyRepo *LoyaltyRepository
+}
+
+func NewCustomerMergeRepository(db *sqlx.DB) *CustomerMergeRepository {
+	return &CustomerMergeRepository{
+		db:          db,
+		loyaltyRepo: NewLoyaltyRepository(db),
+	}
+}
+
+// ListActiveCustomers lists the active customers that may have duplicates
+func (r *CustomerMergeRepository) ListActiveCustomers(ctx context.Context) ([]model.MergeCustomer, error) {
+	customers := []model.MergeCustomer{}
+	query := `SELECT ` + mergeCustomerColumns + `
+		FROM users u
+		WHERE u.role = 'client' AND u.is_active = true AND u.merged_into_id IS NULL
+		ORDER BY u.id`
+	if err := r.db.SelectContext(ctx, &customers, query); err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+// GetActiveCustomer gets an active customer by public ID, nil if none
+func (r *CustomerMergeRepository) GetActiveCustomer(ctx context.Context, publicID string) (*model.MergeCustomer, error) {
+	var customer model.MergeCustomer
+	query := `SELECT ` + mergeCustomerColumns + `
+		FROM users u
+		WHERE u.public_id::text = $1 AND u.role = 'client' AND u.is_active = true AND u.merged_into_id IS NULL`
+	if err := r.db.GetContext(ctx, &customer, query, publicID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// MergeCustomers moves the orders, saved addresses and loyalty points of source
+// to target in one transaction. The source user is deactivated and its phone/email move to the
+// target when the target has none; the audit row keeps the original contacts.
+func (r *CustomerMergeRepository) MergeCustomers(ctx context.Context, source, target *model.MergeCustomer, reason *string, mergedBy int64) (*model.CustomerMerge, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock both users so a concurrent merge or registration does not interleave
+	var lockedIDs []int64
+	err = tx.SelectContext(ctx, &lockedIDs, `
+		SELECT id FROM users
+		WHERE id IN ($1, $2) AND is_active = true AND merged_into_id IS NULL
+		ORDER BY id
+		FOR UPDATE
+	`, source.ID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(lockedIDs) != 2 {
+		return nil, model.NewValidationError("source_user_id", "Khách hàng đã được gộp hoặc bị khóa")
+	}
+
+	result, err := tx.ExecContext(ctx, "UPDATE orders SET created_by = $1 WHERE created_by = $2", target.ID, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	movedOrders, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = tx.ExecContext(ctx, "UPDATE orders SET customer_id = $1 WHERE customer_id = $2", target.ID, source.ID); err != nil {
+		return nil, err
+	}
+	if err = r.loyaltyRepo.moveAccount(ctx, tx, source.ID, target.ID); err != nil {
+		return nil, err
+	}
+
+	// The target keeps its own default address, if it has one
+	_, err = tx.ExecContext(ctx, `
+		UPDATE customer_addresses SET is_default = false
+		WHERE user_id = $1 AND EXISTS (SELECT 1 FROM customer_addresses WHERE user_id = $2 AND is_default = true)
+	`, source.ID, target.ID)
+	if err != nil {
+		return nil, err
+	}
+	result, err = tx.ExecContext(ctx, "UPDATE customer_addresses SET user_id = $1, updated_at = CURRENT_TIMESTAMP WHERE user_id = $2", target.ID, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	movedAddresses, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET phone = NULL, email = NULL, is_active = false, merged_into_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, target.ID, source.ID)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE users SET
+			full_name = COALESCE(NULLIF(full_name, ''), NULLIF($2, '')),
+			phone = COALESCE(NULLIF(phone, ''), NULLIF($3, '')),
+			email = COALESCE(NULLIF(email, ''), NULLIF($4, '')),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, target.ID, source.FullName, source.Phone, source.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := model.MergedCustomerSnapshot{
+		PublicID: source.PublicID,
+		FullName: source.FullName,
+		Phone:    source.Phone,
+		Email:    source.Email,
+		IsGuest:  source.IsGuest,
+	}
+	var mergeID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO user_merges (source_user_id, target_user_id, source_snapshot, moved_orders, moved_addresses, reason, merged_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, source.ID, target.ID, snapshot, movedOrders, movedAddresses, reason, mergedBy).Scan(&mergeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.getMerge(ctx, mergeID)
+}
+
+// ListMerges lists the merge audit records, newest first; filtered by the kept customer public ID when given
+func (r *CustomerMergeRepository) ListMerges(ctx context.Context, targetPublicID string) ([]model.CustomerMerge, error) {
+	merges := []model.CustomerMerge{}
+	query := customerMergeSelect + `
+		WHERE ($1 = '' OR tu.public_id::text = $1)
+		ORDER BY m.created_at DESC, m.id DESC`
+	if err := r.db.SelectContext(ctx, &merges, query, targetPublicID); err != nil {
+		return nil, err
+	}
+	return merges, nil
+}
+
+func (r *CustomerMergeRepository) getMerge(ctx context.Context, id int64) (*model.CustomerMerge, error) {
+	var merge model.CustomerMerge
+	if err := r.db.GetContext(ctx, &merge, customerMergeSelect+` WHERE m.id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"food-pos-backend/internal/model"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
 )
@@ -20,6 +21,13 @@ func NewDeliveryRepository(db *sqlx.DB) *DeliveryRepository {
 	}
 }
 
+// addressSnapshotSQL builds the address snapshot of a delivery from its order (aliased o)
+const addressSnapshotSQL = `CASE WHEN o.delivery_address IS NULL THEN NULL ELSE jsonb_build_object(
+	'recipient_name', o.customer_name, 'recipient_phone', o.customer_phone, 'address', o.delivery_address,
+	'ward', o.delivery_ward, 'district', o.delivery_district, 'province', o.delivery_province,
+	'latitude', o.delivery_latitude, 'longitude', o.delivery_longitude
+) END`
+
 // CreateDeliveryOrder creates a new delivery order
 func (r *DeliveryRepository) CreateDeliveryOrder(ctx context.Context, req *model.CreateDeliveryOrderRequest, userID int64) (*model.DeliveryOrder, error) {
 	tx, err := r.db.BeginTxx(ctx, nil)
@@ -32,19 +40,25 @@ func (r *DeliveryRepository) CreateDeliveryOrder(ctx context.Context, req *model
 	var deliveryOrder model.DeliveryOrder
 	deliveryQuery := `
 		INSERT INTO delivery_orders (
-			order_id, shipper_id, estimated_delivery_time, delivery_notes, created_by, updated_by
+			order_id, shipper_id, estimated_delivery_time, delivery_notes, cod_amount, created_by, updated_by, delivery_address
+		)
+		VALUES (
+			(SELECT id FROM orders WHERE public_id::text = $1 OR id::text = $1),
+			(SELECT id FROM shippers WHERE public_id::text = $2 OR id::text = $2),
+			$3, $4, $5, $6, $7,
+			(SELECT ` + addressSnapshotSQL + ` FROM orders o WHERE o.public_id::text = $1 OR o.id::text = $1)
 		)
-		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, public_id, order_id, shipper_id, delivery_number, status,
-			estimated_delivery_time, actual_delivery_time, delivery_notes,
+			estimated_delivery_time, actual_delivery_time, delivery_notes, accepted_at, assigned_at, picked_up_at, in_transit_at, failed_at, cancelled_at, rejection_reason, cod_amount, cod_collected_at, cod_settled_at, delivery_address,
 			created_by, updated_by, created_at, updated_at
 	`
 	err = tx.QueryRowContext(ctx, deliveryQuery,
-		req.OrderID, req.ShipperID, req.EstimatedDeliveryTime, req.DeliveryNotes, userID, userID,
+		req.OrderID, req.ShipperID, req.EstimatedDeliveryTime, req.DeliveryNotes, req.CodAmount, userID, userID,
 	).Scan(
 		&deliveryOrder.ID, &deliveryOrder.PublicID, &deliveryOrder.OrderID, &deliveryOrder.ShipperID,
 		&deliveryOrder.DeliveryNumber, &deliveryOrder.Status, &deliveryOrder.EstimatedDeliveryTime,
-		&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.CreatedBy,
+		&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.AcceptedAt,
+		&deliveryOrder.AssignedAt, &deliveryOrder.PickedUpAt, &deliveryOrder.InTransitAt, &deliveryOrder.FailedAt, &deliveryOrder.CancelledAt, &deliveryOrder.RejectionReason, &deliveryOrder.CodAmount, &deliveryOrder.CodCollectedAt, &deliveryOrder.CodSettledAt, &deliveryOrder.Address, &deliveryOrder.CreatedBy,
 		&deliveryOrder.UpdatedBy, &deliveryOrder.CreatedAt, &deliveryOrder.UpdatedAt,
 	)
 	if err != nil {
@@ -73,14 +87,22 @@ func (r *DeliveryRepository) CreateDeliveryOrder(ctx context.Context, req *model
 	// Update delivery order status to assigned
 	updateQuery := `
 		UPDATE delivery_orders 
-		SET status = 'assigned', updated_at = CURRENT_TIMESTAMP, updated_by = $1
+		SET status = 'assigned', assigned_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, updated_by = $1
 		WHERE id = $2
+		RETURNING assigned_at
 	`
-	_, err = tx.ExecContext(ctx, updateQuery, userID, deliveryOrder.ID)
+	err = tx.QueryRowContext(ctx, updateQuery, userID, deliveryOrder.ID).Scan(&deliveryOrder.AssignedAt)
 	if err != nil {
 		return nil, err
 	}
+	previousStatus := deliveryOrder.Status
 	deliveryOrder.Status = model.DeliveryStatusAssigned
+	if err = r.recordStatusHistory(ctx, tx, deliveryOrder.ID, deliveryOrder.Status, &previousStatus, req.DeliveryNotes, userID); err != nil {
+		return nil, err
+	}
+	if err = r.touchShipperAssignment(ctx, tx, deliveryOrder.ShipperID); err != nil {
+		return nil, err
+	}
 
 	if err = tx.Commit(); err != nil {
 		return nil, err
@@ -94,7 +116,7 @@ func (r *DeliveryRepository) CreateDeliveryOrder(ctx context.Context, req *model
 func (r *DeliveryRepository) GetDeliveryOrderByID(ctx context.Context, publicID string) (*model.DeliveryOrder, error) {
 	query := `
 		SELECT id, public_id, order_id, shipper_id, delivery_number, status,
-			estimated_delivery_time, actual_delivery_time, delivery_notes,
+			estimated_delivery_time, actual_delivery_time, delivery_notes, accepted_at, assigned_at, picked_up_at, in_transit_at, failed_at, cancelled_at, rejection_reason, cod_amount, cod_collected_at, cod_settled_at, delivery_address,
 			created_by, updated_by, created_at, updated_at
 		FROM delivery_orders
 		WHERE public_id = $1
@@ -103,7 +125,8 @@ func (r *DeliveryRepository) GetDeliveryOrderByID(ctx context.Context, publicID
 	err := r.db.QueryRowContext(ctx, query, publicID).Scan(
 		&deliveryOrder.ID, &deliveryOrder.PublicID, &deliveryOrder.OrderID, &deliveryOrder.ShipperID,
 		&deliveryOrder.DeliveryNumber, &deliveryOrder.Status, &deliveryOrder.EstimatedDeliveryTime,
-		&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.CreatedBy,
+		&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.AcceptedAt,
+		&deliveryOrder.AssignedAt, &deliveryOrder.PickedUpAt, &deliveryOrder.InTransitAt, &deliveryOrder.FailedAt, &deliveryOrder.CancelledAt, &deliveryOrder.RejectionReason, &deliveryOrder.CodAmount, &deliveryOrder.CodCollectedAt, &deliveryOrder.CodSettledAt, &deliveryOrder.Address, &deliveryOrder.CreatedBy,
 		&deliveryOrder.UpdatedBy, &deliveryOrder.CreatedAt, &deliveryOrder.UpdatedAt,
 	)
 	if err != nil {
@@ -136,24 +159,72 @@ func (r *DeliveryRepository) GetDeliveryOrderByID(ctx context.Context, publicID
 	}
 	deliveryOrder.DeliveryOrderItems = items
 
+	// Get status history
+	history := []model.DeliveryStatusHistory{}
+	historyQuery := `
+		SELECT id, delivery_order_id, status, previous_status, notes, changed_by, created_at
+		FROM delivery_status_history
+		WHERE delivery_order_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+	if err := r.db.SelectContext(ctx, &history, historyQuery, deliveryOrder.ID); err != nil {
+		return nil, err
+	}
+	deliveryOrder.StatusHistory = history
+
+	// Get proof of delivery
+	proofs, err := r.ListDeliveryProofs(ctx, deliveryOrder.ID)
+	if err != nil {
+		return nil, err
+	}
+	deliveryOrder.Proofs = proofs
+
 	return &deliveryOrder, nil
 }
 
-// UpdateDeliveryOrder updates delivery order
-func (r *DeliveryRepository) UpdateDeliveryOrder(ctx context.Context, publicID string, req *model.UpdateDeliveryOrderRequest, userID int64) (*model.DeliveryOrder, error) {
+// UpdateDeliveryOrder updates delivery order. check runs against the locked
+// row (status, shipper, acceptance, COD amount and proofs) and aborts the update on error
+func (r *DeliveryRepository) UpdateDeliveryOrder(ctx context.Context, publicID string, req *model.UpdateDeliveryOrderRequest, userID int64, check func(current *model.DeliveryOrder) error) (*model.DeliveryOrder, error) {
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer tx.Rollback()
 
+	var current model.DeliveryOrder
+	err = tx.GetContext(ctx, &current, "SELECT id, status, shipper_id, accepted_at, cod_amount FROM delivery_orders WHERE public_id = $1 FOR UPDATE", publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("delivery order not found")
+		}
+		return nil, err
+	}
+	previousStatus := current.Status
+	if check != nil {
+		if current.Proofs, err = r.ListDeliveryProofs(ctx, current.ID); err != nil {
+			return nil, err
+		}
+		if err = check(&current); err != nil {
+			return nil, err
+		}
+	}
+
 	// Build update query dynamically
 	updateFields := []string{}
 	args := []interface{}{}
 	argIndex := 1
 
-	if req.ShipperID != nil {
-		updateFields = append(updateFields, fmt.Sprintf("shipper_id = $%d", argIndex))
+	// Back to pending releases the delivery: no shipper and no acceptance
+	backToPending := req.Status != nil && *req.Status == model.DeliveryStatusPending && previousStatus != model.DeliveryStatusPending
+	if backToPending {
+		updateFields = append(updateFields, "shipper_id = NULL, accepted_at = NULL")
+	} else if req.ShipperID != nil {
+		// A new shipper has to accept the delivery again
+		shipperQuery := fmt.Sprintf("(SELECT id FROM shippers WHERE public_id::text = $%d OR id::text = $%d)", argIndex, argIndex)
+		updateFields = append(updateFields,
+			"shipper_id = "+shipperQuery,
+			"accepted_at = CASE WHEN shipper_id IS DISTINCT FROM "+shipperQuery+" THEN NULL ELSE accepted_at END",
+		)
 		args = append(args, *req.ShipperID)
 		argIndex++
 	}
@@ -162,6 +233,21 @@ func (r *DeliveryRepository) UpdateDeliveryOrder(ctx context.Context, publicID s
 		updateFields = append(updateFields, fmt.Sprintf("status = $%d", argIndex))
 		args = append(args, *req.Status)
 		argIndex++
+
+		// Stamp the time the delivery entered the new status
+		if column := req.Status.TimestampColumn(); column != "" && *req.Status != previousStatus && !(column == "actual_delivery_time" && req.ActualDeliveryTime != nil) {
+			updateFields = append(updateFields, column+" = CURRENT_TIMESTAMP")
+		}
+		// Shipper giữ tiền thu hộ kể từ lúc giao thành công
+		if *req.Status == model.DeliveryStatusDelivered && previousStatus != model.DeliveryStatusDelivered {
+			updateFields = append(updateFields, "cod_collected_at = CURRENT_TIMESTAMP")
+		}
+	}
+
+	if req.CodAmount != nil {
+		updateFields = append(updateFields, fmt.Sprintf("cod_amount = $%d", argIndex))
+		args = append(args, *req.CodAmount)
+		argIndex++
 	}
 
 	if req.EstimatedDeliveryTime != nil {
@@ -191,15 +277,16 @@ func (r *DeliveryRepository) UpdateDeliveryOrder(ctx context.Context, publicID s
 		SET %s
 		WHERE public_id = $%d
 		RETURNING id, public_id, order_id, shipper_id, delivery_number, status,
-			estimated_delivery_time, actual_delivery_time, delivery_notes,
+			estimated_delivery_time, actual_delivery_time, delivery_notes, accepted_at, assigned_at, picked_up_at, in_transit_at, failed_at, cancelled_at, rejection_reason, cod_amount, cod_collected_at, cod_settled_at, delivery_address,
 			created_by, updated_by, created_at, updated_at
-	`, updateFields, argIndex)
+	`, strings.Join(updateFields, ", "), argIndex)
 
 	var deliveryOrder model.DeliveryOrder
 	err = tx.QueryRowContext(ctx, query, args...).Scan(
 		&deliveryOrder.ID, &deliveryOrder.PublicID, &deliveryOrder.OrderID, &deliveryOrder.ShipperID,
 		&deliveryOrder.DeliveryNumber, &deliveryOrder.Status, &deliveryOrder.EstimatedDeliveryTime,
-		&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.CreatedBy,
+		&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.AcceptedAt,
+		&deliveryOrder.AssignedAt, &deliveryOrder.PickedUpAt, &deliveryOrder.InTransitAt, &deliveryOrder.FailedAt, &deliveryOrder.CancelledAt, &deliveryOrder.RejectionReason, &deliveryOrder.CodAmount, &deliveryOrder.CodCollectedAt, &deliveryOrder.CodSettledAt, &deliveryOrder.Address, &deliveryOrder.CreatedBy,
 		&deliveryOrder.UpdatedBy, &deliveryOrder.CreatedAt, &deliveryOrder.UpdatedAt,
 	)
 	if err != nil {
@@ -209,6 +296,17 @@ func (r *DeliveryRepository) UpdateDeliveryOrder(ctx context.Context, publicID s
 		return nil, err
 	}
 
+	if deliveryOrder.Status != previousStatus {
+		if err = r.recordStatusHistory(ctx, tx, deliveryOrder.ID, deliveryOrder.Status, &previousStatus, req.DeliveryNotes, userID); err != nil {
+			return nil, err
+		}
+	}
+	if req.ShipperID != nil && !backToPending {
+		if err = r.touchShipperAssignment(ctx, tx, deliveryOrder.ShipperID); err != nil {
+			return nil, err
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, err
 	}
@@ -256,7 +354,7 @@ func (r *DeliveryRepository) ListDeliveryOrders(ctx context.Context, req *model.
 	// Get delivery orders
 	query := fmt.Sprintf(`
 		SELECT id, public_id, order_id, shipper_id, delivery_number, status,
-			estimated_delivery_time, actual_delivery_time, delivery_notes,
+			estimated_delivery_time, actual_delivery_time, delivery_notes, accepted_at, assigned_at, picked_up_at, in_transit_at, failed_at, cancelled_at, rejection_reason, cod_amount, cod_collected_at, cod_settled_at, delivery_address,
 			created_by, updated_by, created_at, updated_at
 		FROM delivery_orders
 		%s
@@ -276,7 +374,8 @@ func (r *DeliveryRepository) ListDeliveryOrders(ctx context.Context, req *model.
 		err := rows.Scan(
 			&deliveryOrder.ID, &deliveryOrder.PublicID, &deliveryOrder.OrderID, &deliveryOrder.ShipperID,
 			&deliveryOrder.DeliveryNumber, &deliveryOrder.Status, &deliveryOrder.EstimatedDeliveryTime,
-			&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.CreatedBy,
+			&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.AcceptedAt,
+			&deliveryOrder.AssignedAt, &deliveryOrder.PickedUpAt, &deliveryOrder.InTransitAt, &deliveryOrder.FailedAt, &deliveryOrder.CancelledAt, &deliveryOrder.RejectionReason, &deliveryOrder.CodAmount, &deliveryOrder.CodCollectedAt, &deliveryOrder.CodSettledAt, &deliveryOrder.Address, &deliveryOrder.CreatedBy,
 			&deliveryOrder.UpdatedBy, &deliveryOrder.CreatedAt, &deliveryOrder.UpdatedAt,
 		)
 		if err != nil {
@@ -300,7 +399,7 @@ func (r *DeliveryRepository) ListDeliveryOrders(ctx context.Context, req *model.
 func (r *DeliveryRepository) GetDeliveryOrdersByOrderID(ctx context.Context, orderID string) ([]*model.DeliveryOrder, error) {
 	query := `
 		SELECT id, public_id, order_id, shipper_id, delivery_number, status,
-			estimated_delivery_time, actual_delivery_time, delivery_notes,
+			estimated_delivery_time, actual_delivery_time, delivery_notes, accepted_at, assigned_at, picked_up_at, in_transit_at, failed_at, cancelled_at, rejection_reason, cod_amount, cod_collected_at, cod_settled_at, delivery_address,
 			created_by, updated_by, created_at, updated_at
 		FROM delivery_orders
 		WHERE order_id = $1
@@ -318,7 +417,8 @@ func (r *DeliveryRepository) GetDeliveryOrdersByOrderID(ctx context.Context, ord
 		err := rows.Scan(
 			&deliveryOrder.ID, &deliveryOrder.PublicID, &deliveryOrder.OrderID, &deliveryOrder.ShipperID,
 			&deliveryOrder.DeliveryNumber, &deliveryOrder.Status, &deliveryOrder.EstimatedDeliveryTime,
-			&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.CreatedBy,
+			&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.AcceptedAt,
+			&deliveryOrder.AssignedAt, &deliveryOrder.PickedUpAt, &deliveryOrder.InTransitAt, &deliveryOrder.FailedAt, &deliveryOrder.CancelledAt, &deliveryOrder.RejectionReason, &deliveryOrder.CodAmount, &deliveryOrder.CodCollectedAt, &deliveryOrder.CodSettledAt, &deliveryOrder.Address, &deliveryOrder.CreatedBy,
 			&deliveryOrder.UpdatedBy, &deliveryOrder.CreatedAt, &deliveryOrder.UpdatedAt,
 		)
 		if err != nil {
@@ -370,3 +470,159 @@ func (r *DeliveryRepository) GetAvailableShippers(ctx context.Context) ([]*model
 
 	return shippers, nil
 }
+
+// ListShipperDeliveries lists the delivery orders assigned to a shipper with an order summary
+func (r *DeliveryRepository) ListShipperDeliveries(ctx context.Context, shipperID int64, status *model.DeliveryStatus, activeOnly bool) ([]*model.DeliveryOrder, error) {
+	whereClause := "WHERE d.shipper_id = $1"
+	args := []interface{}{shipperID}
+	if status != nil {
+		whereClause += " AND d.status = $2"
+		args = append(args, *status)
+	} else if activeOnly {
+		whereClause += " AND d.status IN ('assigned', 'picked_up', 'in_transit')"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT d.id, d.public_id, d.order_id, d.shipper_id, d.delivery_number, d.status,
+			d.estimated_delivery_time, d.actual_delivery_time, d.delivery_notes, d.accepted_at, d.assigned_at, d.picked_up_at, d.in_transit_at, d.failed_at, d.cancelled_at, d.rejection_reason, d.cod_amount, d.cod_collected_at, d.cod_settled_at, d.delivery_address,
+			d.created_by, d.updated_by, d.created_at, d.updated_at,
+			o.public_id, o.order_number, o.customer_name, o.customer_phone, o.total_amount,
+			o.payment_method, o.payment_status, o.notes
+		FROM delivery_orders d
+		JOIN orders o ON d.order_id = o.id
+		%s
+		ORDER BY d.created_at ASC
+	`, whereClause)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveryOrders := []*model.DeliveryOrder{}
+	for rows.Next() {
+		var deliveryOrder model.DeliveryOrder
+		var order model.Order
+		var paymentMethod sql.NullString
+		err := rows.Scan(
+			&deliveryOrder.ID, &deliveryOrder.PublicID, &deliveryOrder.OrderID, &deliveryOrder.ShipperID,
+			&deliveryOrder.DeliveryNumber, &deliveryOrder.Status, &deliveryOrder.EstimatedDeliveryTime,
+			&deliveryOrder.ActualDeliveryTime, &deliveryOrder.DeliveryNotes, &deliveryOrder.AcceptedAt,
+			&deliveryOrder.AssignedAt, &deliveryOrder.PickedUpAt, &deliveryOrder.InTransitAt, &deliveryOrder.FailedAt, &deliveryOrder.CancelledAt, &deliveryOrder.RejectionReason, &deliveryOrder.CodAmount, &deliveryOrder.CodCollectedAt, &deliveryOrder.CodSettledAt, &deliveryOrder.Address, &deliveryOrder.CreatedBy,
+			&deliveryOrder.UpdatedBy, &deliveryOrder.CreatedAt, &deliveryOrder.UpdatedAt,
+			&order.PublicID, &order.OrderNumber, &order.CustomerName, &order.CustomerPhone, &order.TotalAmount,
+			&paymentMethod, &order.PaymentStatus, &order.Notes,
+		)
+		if err != nil {
+			return nil, err
+		}
+		order.ID = deliveryOrder.OrderID
+		order.PaymentMethod = paymentMethod.String
+		deliveryOrder.Order = &order
+		deliveryOrders = append(deliveryOrders, &deliveryOrder)
+	}
+
+	return deliveryOrders, nil
+}
+
+// AcceptDelivery marks an assigned delivery as accepted by its shipper
+func (r *DeliveryRepository) AcceptDelivery(ctx context.Context, deliveryID, shipperID, userID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE delivery_orders
+		SET accepted_at = CURRENT_TIMESTAMP, updated_by = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND shipper_id = $3 AND status = 'assigned' AND accepted_at IS NULL
+	`, userID, deliveryID, shipperID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// RejectDelivery returns an assigned delivery to the pending pool
+func (r *DeliveryRepository) RejectDelivery(ctx context.Context, deliveryID, shipperID int64, reason string, userID int64) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE delivery_orders
+		SET shipper_id = NULL, status = 'pending', accepted_at = NULL,
+			rejected_by = $1, rejection_reason = $2, updated_by = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND shipper_id = $1 AND status = 'assigned' AND accepted_at IS NULL
+	`, shipperID, reason, userID, deliveryID)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	previousStatus := model.DeliveryStatusAssigned
+	if err = r.recordStatusHistory(ctx, tx, deliveryID, model.DeliveryStatusPending, &previousStatus, "Shipper từ chối: "+reason, userID); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// touchShipperAssignment stamps the last time a shipper got a delivery (used by round-robin assignment)
+func (r *DeliveryRepository) touchShipperAssignment(ctx context.Context, q sqlx.ExecerContext, shipperID *int64) error {
+	if shipperID == nil {
+		return nil
+	}
+	_, err := q.ExecContext(ctx, "UPDATE shippers SET last_assigned_at = CURRENT_TIMESTAMP WHERE id = $1", *shipperID)
+	return err
+}
+
+// recordStatusHistory appends a delivery status change to delivery_status_history
+func (r *DeliveryRepository) recordStatusHistory(ctx context.Context, q sqlx.ExecerContext, deliveryID int64, status model.DeliveryStatus, previousStatus *model.DeliveryStatus, notes string, userID int64) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO delivery_status_history (delivery_order_id, status, previous_status, notes, changed_by)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+	`, deliveryID, status, previousStatus, notes, userID)
+	return err
+}
+
+// CreateDeliveryProof stores a proof of delivery attached to a delivery order
+func (r *DeliveryRepository) CreateDeliveryProof(ctx context.Context, proof *model.DeliveryProof) error {
+	query := `
+		INSERT INTO delivery_proofs (
+			delivery_order_id, proof_type, file_key, content_type, file_size, latitude, longitude, uploaded_by
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, public_id, created_at
+	`
+	return r.db.QueryRowContext(ctx, query,
+		proof.DeliveryOrderID, proof.ProofType, proof.FileKey, proof.ContentType,
+		proof.FileSize, proof.Latitude, proof.Longitude, proof.UploadedBy,
+	).Scan(&proof.ID, &proof.PublicID, &proof.CreatedAt)
+}
+
+// ListDeliveryProofs lists the proofs attached to a delivery order, oldest first
+func (r *DeliveryRepository) ListDeliveryProofs(ctx context.Context, deliveryID int64) ([]model.DeliveryProof, error) {
+	proofs := []model.DeliveryProof{}
+	query := `
+		SELECT id, public_id, delivery_order_id, proof_type, file_key, content_type, file_size,
+			latitude, longitude, uploaded_by, created_at
+		FROM delivery_proofs
+		WHERE delivery_order_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+	if err := r.db.SelectContext(ctx, &proofs, query, deliveryID); err != nil {
+		return nil, err
+	}
+	return proofs, nil
+}
+
+// HasActiveDelivery reports whether an order already has a delivery that is not finished
+func (r *DeliveryRepository) HasActiveDelivery(ctx context.Context, orderID int64) (bool, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `
+		SELECT COUNT(*) FROM delivery_orders
+		WHERE order_id = $1 AND status NOT IN ('delivered', 'failed', 'cancelled')
+	`, orderID)
+	return count > 0, err
+}
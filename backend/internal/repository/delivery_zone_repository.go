@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DeliveryZoneRepository struct {
+	db *sqlx.DB
+}
+
+func NewDeliveryZoneRepository(db *sqlx.DB) *DeliveryZoneRepository {
+	return &DeliveryZoneRepository{db: db}
+}
+
+// CreateZone creates a new delivery zone
+func (r *DeliveryZoneRepository) CreateZone(ctx context.Context, zone *model.DeliveryZone) error {
+	query := `
+		INSERT INTO delivery_zones (
+			code, name, zone_type, radius_km, polygon, fee_type, base_fee, per_km_fee,
+			free_above_subtotal, priority, is_active, created_by
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		) RETURNING id, public_id, created_at, updated_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		zone.Code, zone.Name, zone.ZoneType, zone.RadiusKm, zone.Polygon, zone.FeeType, zone.BaseFee, zone.PerKmFee,
+		zone.FreeAboveSubtotal, zone.Priority, zone.IsActive, zone.CreatedBy,
+	).Scan(&zone.ID, &zone.PublicID, &zone.CreatedAt, &zone.UpdatedAt)
+}
+
+// GetZoneByPublicID gets a delivery zone by public ID
+func (r *DeliveryZoneRepository) GetZoneByPublicID(ctx context.Context, publicID string) (*model.DeliveryZone, error) {
+	var zone model.DeliveryZone
+	err := r.db.GetContext(ctx, &zone, "SELECT * FROM delivery_zones WHERE public_id = $1", publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &zone, nil
+}
+
+// IsCodeTaken checks whether a zone code belongs to another zone
+func (r *DeliveryZoneRepository) IsCodeTaken(ctx context.Context, code string, exceptID *int64) (bool, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM delivery_zones WHERE code = $1 AND ($2::BIGINT IS NULL OR id <> $2)", code, exceptID)
+	return count > 0, err
+}
+
+// ListZones lists all delivery zones in matching order
+func (r *DeliveryZoneRepository) ListZones(ctx context.Context) ([]model.DeliveryZone, error) {
+	zones := []model.DeliveryZone{}
+	if err := r.db.SelectContext(ctx, &zones, "SELECT * FROM delivery_zones ORDER BY priority, id"); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// ListActiveZones lists the active delivery zones in matching order
+func (r *DeliveryZoneRepository) ListActiveZones(ctx context.Context) ([]model.DeliveryZone, error) {
+	zones := []model.DeliveryZone{}
+	if err := r.db.SelectContext(ctx, &zones, "SELECT * FROM delivery_zones WHERE is_active = true ORDER BY priority, id"); err != nil {
+		return nil, err
+	}
+	return zones, nil
+}
+
+// UpdateZone updates a delivery zone
+func (r *DeliveryZoneRepository) UpdateZone(ctx context.Context, zone *model.DeliveryZone) error {
+	query := `
+		UPDATE delivery_zones SET
+			code = $1, name = $2, zone_type = $3, radius_km = $4, polygon = $5, fee_type = $6,
+			base_fee = $7, per_km_fee = $8, free_above_subtotal = $9, priority = $10, is_active = $11,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $12
+		RETURNING updated_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		zone.Code, zone.Name, zone.ZoneType, zone.RadiusKm, zone.Polygon, zone.FeeType,
+		zone.BaseFee, zone.PerKmFee, zone.FreeAboveSubtotal, zone.Priority, zone.IsActive, zone.ID,
+	).Scan(&zone.UpdatedAt)
+}
+
+// DeleteZone deletes a delivery zone
+func (r *DeliveryZoneRepository) DeleteZone(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM delivery_zones WHERE id = $1", id)
+	return err
+}
@@ -0,0 +1,465 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"food-pos-backend/internal/model"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DiscountRepository struct {
+	db *sqlx.DB
+}
+
+func NewDiscountRepository(db *sqlx.DB) *DiscountRepository {
+	return &DiscountRepository{
+		db: db,
+	}
+}
+
+const discountCodeColumns = `
+	id, public_id, code, name, COALESCE(description, '') AS description, discount_type, discount_value,
+	COALESCE(min_order_amount, 0) AS min_order_amount, max_discount_amount, usage_limit,
+	COALESCE(used_count, 0) AS used_count, per_customer_limit, is_stackable, is_active,
+	valid_from, valid_until, COALESCE(created_by, 0) AS created_by, created_at, updated_at
+`
+
+// CreateDiscountCode creates a new discount code with its product/variant scope
+func (r *DiscountRepository) CreateDiscountCode(ctx context.Context, req *model.CreateDiscountCodeRequest, userID int64) (*model.DiscountCode, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var discountCode model.DiscountCode
+	query := `
+		INSERT INTO discount_codes (
+			code, name, description, discount_type, discount_value, min_order_amount,
+			max_discount_amount, usage_limit, per_customer_limit, is_stackable,
+			valid_from, valid_until, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING ` + discountCodeColumns
+	err = tx.GetContext(ctx, &discountCode, query,
+		req.Code, req.Name, req.Description, req.DiscountType, req.DiscountValue, req.MinOrderAmount,
+		req.MaxDiscountAmount, req.UsageLimit, req.PerCustomerLimit, req.IsStackable,
+		req.ValidFrom, req.ValidUntil, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.replaceScope(ctx, tx, discountCode.ID, req.ProductIDs, req.VariantIDs); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	discountCode.ProductIDs = req.ProductIDs
+	discountCode.VariantIDs = req.VariantIDs
+	return &discountCode, nil
+}
+
+// GetDiscountCodeByPublicID gets discount code by public ID
+func (r *DiscountRepository) GetDiscountCodeByPublicID(ctx context.Context, publicID string) (*model.DiscountCode, error) {
+	var discountCode model.DiscountCode
+	query := `SELECT ` + discountCodeColumns + ` FROM discount_codes WHERE public_id::text = $1`
+	err := r.db.GetContext(ctx, &discountCode, query, publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("discount code not found")
+		}
+		return nil, err
+	}
+
+	if err = r.loadScope(ctx, r.db, &discountCode); err != nil {
+		return nil, err
+	}
+	return &discountCode, nil
+}
+
+// getDiscountCodeByCode gets discount code by code, using the given queryer so it can run inside a transaction
+func (r *DiscountRepository) getDiscountCodeByCode(ctx context.Context, q sqlx.QueryerContext, code string) (*model.DiscountCode, error) {
+	var discountCode model.DiscountCode
+	query := `SELECT ` + discountCodeColumns + ` FROM discount_codes WHERE code = $1`
+	err := sqlx.GetContext(ctx, q, &discountCode, query, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.loadScope(ctx, q, &discountCode); err != nil {
+		return nil, err
+	}
+	return &discountCode, nil
+}
+
+// ListDiscountCodes lists discount codes with filtering and pagination
+func (r *DiscountRepository) ListDiscountCodes(ctx context.Context, req *model.ListDiscountCodesRequest) (*model.ListDiscountCodesResponse, error) {
+	whereConditions := []string{"1=1"}
+	args := []any{}
+	argIndex := 1
+
+	if req.Search != "" {
+		whereConditions = append(whereConditions, fmt.Sprintf("(code ILIKE $%d OR name ILIKE $%d)", argIndex, argIndex))
+		args = append(args, "%"+req.Search+"%")
+		argIndex++
+	}
+
+	if req.IsActive != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("is_active = $%d", argIndex))
+		args = append(args, *req.IsActive)
+		argIndex++
+	}
+
+	whereClause := strings.Join(whereConditions, " AND ")
+
+	// Count total
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM discount_codes WHERE %s", whereClause)
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM discount_codes
+		WHERE %s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, discountCodeColumns, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Limit, offset)
+
+	discountCodes := make([]*model.DiscountCode, 0)
+	if err := r.db.SelectContext(ctx, &discountCodes, query, args...); err != nil {
+		return nil, err
+	}
+	for _, discountCode := range discountCodes {
+		if err := r.loadScope(ctx, r.db, discountCode); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.ListDiscountCodesResponse{
+		DiscountCodes: discountCodes,
+		Total:         total,
+		Page:          req.Page,
+		Limit:         req.Limit,
+		Pages:         int(math.Ceil(float64(total) / float64(req.Limit))),
+	}, nil
+}
+
+// UpdateDiscountCode updates discount code and replaces its scope
+func (r *DiscountRepository) UpdateDiscountCode(ctx context.Context, publicID string, req *model.UpdateDiscountCodeRequest) (*model.DiscountCode, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var discountCode model.DiscountCode
+	query := `
+		UPDATE discount_codes
+		SET name = $1, description = $2, discount_type = $3, discount_value = $4, min_order_amount = $5,
+			max_discount_amount = $6, usage_limit = $7, per_customer_limit = $8, is_stackable = $9,
+			is_active = $10, valid_from = $11, valid_until = $12, updated_at = $13
+		WHERE public_id::text = $14
+		RETURNING ` + discountCodeColumns
+	err = tx.GetContext(ctx, &discountCode, query,
+		req.Name, req.Description, req.DiscountType, req.DiscountValue, req.MinOrderAmount,
+		req.MaxDiscountAmount, req.UsageLimit, req.PerCustomerLimit, req.IsStackable,
+		req.IsActive, req.ValidFrom, req.ValidUntil, time.Now(), publicID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("discount code not found")
+		}
+		return nil, err
+	}
+
+	if err = r.replaceScope(ctx, tx, discountCode.ID, req.ProductIDs, req.VariantIDs); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	discountCode.ProductIDs = req.ProductIDs
+	discountCode.VariantIDs = req.VariantIDs
+	return &discountCode, nil
+}
+
+// DeleteDiscountCode deletes a discount code, codes with usages are kept for the redemption history
+func (r *DiscountRepository) DeleteDiscountCode(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM discount_codes
+		WHERE id = $1 AND NOT EXISTS (SELECT 1 FROM discount_code_usages WHERE discount_code_id = $1)
+	`, id)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.NewValidationError("code", "Mã giảm giá đã được sử dụng, hãy vô hiệu hóa thay vì xóa")
+	}
+	return nil
+}
+
+// ListDiscountCodeUsages lists redemptions of a discount code
+func (r *DiscountRepository) ListDiscountCodeUsages(ctx context.Context, discountCodeID int64) ([]*model.DiscountCodeUsage, error) {
+	query := `
+		SELECT u.id, u.discount_code_id, u.order_id, o.public_id AS order_public_id, o.order_number,
+			COALESCE(u.customer_phone, '') AS customer_phone, u.discount_amount,
+			COALESCE(u.created_by, 0) AS created_by, u.created_at
+		FROM discount_code_usages u
+		JOIN orders o ON u.order_id = o.id
+		WHERE u.discount_code_id = $1
+		ORDER BY u.created_at DESC
+	`
+	usages := make([]*model.DiscountCodeUsage, 0)
+	if err := r.db.SelectContext(ctx, &usages, query, discountCodeID); err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
+
+// EvaluateDiscountCode validates a discount code against an order and calculates the discount amount
+func (r *DiscountRepository) EvaluateDiscountCode(ctx context.Context, req *model.DiscountEvaluation) (*model.ValidateDiscountCodeResponse, *model.DiscountCode, error) {
+	return r.evaluateDiscountCode(ctx, r.db, req)
+}
+
+func (r *DiscountRepository) evaluateDiscountCode(ctx context.Context, q sqlx.QueryerContext, req *model.DiscountEvaluation) (*model.ValidateDiscountCodeResponse, *model.DiscountCode, error) {
+	discountCode, err := r.getDiscountCodeByCode(ctx, q, req.Code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invalidDiscount("Mã giảm giá không tồn tại"), nil, nil
+		}
+		return nil, nil, err
+	}
+
+	// Check if code is active
+	if !discountCode.IsActive {
+		return invalidDiscount("Mã giảm giá đã bị vô hiệu hóa"), discountCode, nil
+	}
+
+	// Check validity period
+	now := time.Now()
+	if now.Before(discountCode.ValidFrom) {
+		return invalidDiscount("Mã giảm giá chưa có hiệu lực"), discountCode, nil
+	}
+	if now.After(discountCode.ValidUntil) {
+		return invalidDiscount("Mã giảm giá đã hết hạn"), discountCode, nil
+	}
+
+	// Check usage limit
+	if discountCode.UsageLimit != nil && discountCode.UsedCount >= *discountCode.UsageLimit {
+		return invalidDiscount("Mã giảm giá đã hết lượt sử dụng"), discountCode, nil
+	}
+
+	// Check per-customer usage limit, usages are counted by normalized phone
+	if discountCode.PerCustomerLimit != nil {
+		phone := model.NormalizePhone(req.CustomerPhone)
+		if phone == "" {
+			return invalidDiscount("Mã giảm giá giới hạn theo khách hàng, vui lòng nhập số điện thoại"), discountCode, nil
+		}
+		var customerUsages int
+		err := sqlx.GetContext(ctx, q, &customerUsages,
+			`SELECT COUNT(*) FROM discount_code_usages WHERE discount_code_id = $1 AND customer_phone = $2`,
+			discountCode.ID, phone,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if customerUsages >= *discountCode.PerCustomerLimit {
+			return invalidDiscount("Khách hàng đã dùng hết lượt cho mã giảm giá này"), discountCode, nil
+		}
+	}
+
+	// Check stacking rule
+	if req.ManualDiscountAmount > 0 && !discountCode.IsStackable {
+		return invalidDiscount("Mã giảm giá không thể dùng chung với giảm giá thủ công"), discountCode, nil
+	}
+
+	// Check minimum order amount
+	if req.OrderAmount < discountCode.MinOrderAmount {
+		return invalidDiscount(fmt.Sprintf("Đơn hàng tối thiểu %.0f VNĐ để áp dụng mã giảm giá", discountCode.MinOrderAmount)), discountCode, nil
+	}
+
+	// Work out the amount the code applies to. A scoped code only applies to the
+	// lines it covers, without lines nothing is eligible.
+	eligibleAmount := req.OrderAmount
+	if discountCode.IsScoped() {
+		if len(req.Lines) == 0 {
+			return invalidDiscount("Mã giảm giá chỉ áp dụng cho một số sản phẩm, vui lòng chọn món trước khi áp dụng"), discountCode, nil
+		}
+		productIDs, variantIDs, err := r.getScopeIDs(ctx, q, discountCode.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		eligibleAmount = 0
+		for _, line := range req.Lines {
+			if productIDs[line.ProductID] || variantIDs[line.VariantID] {
+				eligibleAmount += line.Amount
+			}
+		}
+		if eligibleAmount == 0 {
+			return invalidDiscount("Mã giảm giá không áp dụng cho sản phẩm trong đơn hàng"), discountCode, nil
+		}
+	}
+
+	return &model.ValidateDiscountCodeResponse{
+		IsValid:        true,
+		Message:        "Mã giảm giá hợp lệ",
+		DiscountType:   discountCode.DiscountType,
+		DiscountValue:  discountCode.DiscountValue,
+		DiscountAmount: discountCode.CalculateAmount(eligibleAmount),
+		IsStackable:    discountCode.IsStackable,
+	}, discountCode, nil
+}
+
+// lockDiscountCode locks the code row so concurrent orders redeem it one at a time
+func (r *DiscountRepository) lockDiscountCode(ctx context.Context, tx *sqlx.Tx, code string) error {
+	_, err := tx.ExecContext(ctx, `SELECT id FROM discount_codes WHERE code = $1 FOR UPDATE`, code)
+	return err
+}
+
+// recordUsage increments the usage counter and stores the redemption,
+// failing when the usage limit has been reached in the meantime
+func (r *DiscountRepository) recordUsage(ctx context.Context, tx *sqlx.Tx, discountCodeID, orderID int64, customerPhone string, amount float64, userID int64) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE discount_codes
+		SET used_count = used_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (usage_limit IS NULL OR used_count < usage_limit)
+	`, discountCodeID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.NewValidationError("discount_code", "Mã giảm giá đã hết lượt sử dụng")
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO discount_code_usages (discount_code_id, order_id, customer_phone, discount_amount, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`, discountCodeID, orderID, model.NormalizePhone(customerPhone), amount, userID)
+	return err
+}
+
+// loadScope fills the public product/variant IDs a code is restricted to
+func (r *DiscountRepository) loadScope(ctx context.Context, q sqlx.QueryerContext, discountCode *model.DiscountCode) error {
+	productIDs := make([]string, 0)
+	err := sqlx.SelectContext(ctx, q, &productIDs, `
+		SELECT p.public_id FROM discount_code_products dp
+		JOIN products p ON dp.product_id = p.id
+		WHERE dp.discount_code_id = $1
+	`, discountCode.ID)
+	if err != nil {
+		return err
+	}
+
+	variantIDs := make([]string, 0)
+	err = sqlx.SelectContext(ctx, q, &variantIDs, `
+		SELECT v.public_id FROM discount_code_variants dv
+		JOIN variants v ON dv.variant_id = v.id
+		WHERE dv.discount_code_id = $1
+	`, discountCode.ID)
+	if err != nil {
+		return err
+	}
+
+	discountCode.ProductIDs = productIDs
+	discountCode.VariantIDs = variantIDs
+	return nil
+}
+
+// getScopeIDs returns the internal product/variant IDs a code is restricted to
+func (r *DiscountRepository) getScopeIDs(ctx context.Context, q sqlx.QueryerContext, discountCodeID int64) (map[int64]bool, map[int64]bool, error) {
+	var productIDs, variantIDs []int64
+	err := sqlx.SelectContext(ctx, q, &productIDs, `SELECT product_id FROM discount_code_products WHERE discount_code_id = $1`, discountCodeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = sqlx.SelectContext(ctx, q, &variantIDs, `SELECT variant_id FROM discount_code_variants WHERE discount_code_id = $1`, discountCodeID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	products := make(map[int64]bool, len(productIDs))
+	for _, id := range productIDs {
+		products[id] = true
+	}
+	variants := make(map[int64]bool, len(variantIDs))
+	for _, id := range variantIDs {
+		variants[id] = true
+	}
+	return products, variants, nil
+}
+
+// replaceScope replaces the product/variant restrictions of a code. IDs are
+// deduplicated first so every insert must add a row, otherwise the ID is unknown
+func (r *DiscountRepository) replaceScope(ctx context.Context, tx *sqlx.Tx, discountCodeID int64, productIDs, variantIDs []string) error {
+	productIDs, variantIDs = uniqueIDs(productIDs), uniqueIDs(variantIDs)
+	if _, err := tx.ExecContext(ctx, `DELETE FROM discount_code_products WHERE discount_code_id = $1`, discountCodeID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM discount_code_variants WHERE discount_code_id = $1`, discountCodeID); err != nil {
+		return err
+	}
+
+	for _, productID := range productIDs {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO discount_code_products (discount_code_id, product_id)
+			SELECT $1, id FROM products WHERE public_id::text = $2
+		`, discountCodeID, productID)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return model.NewValidationError("product_ids", "Sản phẩm không tồn tại: "+productID)
+		}
+	}
+
+	for _, variantID := range variantIDs {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO discount_code_variants (discount_code_id, variant_id)
+			SELECT $1, id FROM variants WHERE public_id::text = $2
+		`, discountCodeID, variantID)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return model.NewValidationError("variant_ids", "Biến thể không tồn tại: "+variantID)
+		}
+	}
+
+	return nil
+}
+
+// uniqueIDs removes duplicate IDs while keeping order
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	result := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func invalidDiscount(message string) *model.ValidateDiscountCodeResponse {
+	return &model.ValidateDiscountCodeResponse{
+		IsValid: false,
+		Message: message,
+	}
+}
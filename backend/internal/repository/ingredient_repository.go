@@ -21,8 +21,8 @@ func NewIngredientRepository(db *sqlx.DB) *IngredientRepository {
 
 func (r *IngredientRepository) Create(ctx context.Context, ingredient *model.Ingredient) error {
 	query := `
-		INSERT INTO ingredients (public_id, name, unit_price, unit, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO ingredients (public_id, name, unit_price, unit, low_stock_threshold, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 		RETURNING id
 	`
 	ingredient.PublicID = uuid.New().String()
@@ -31,6 +31,7 @@ func (r *IngredientRepository) Create(ctx context.Context, ingredient *model.Ing
 		ingredient.Name,
 		ingredient.UnitPrice,
 		ingredient.Unit,
+		ingredient.LowStockThreshold,
 		ingredient.CreatedAt,
 		ingredient.UpdatedAt,
 	).Scan(&ingredient.ID)
@@ -38,7 +39,7 @@ func (r *IngredientRepository) Create(ctx context.Context, ingredient *model.Ing
 
 func (r *IngredientRepository) GetByID(ctx context.Context, id int64) (*model.Ingredient, error) {
 	query := `
-		SELECT id, public_id, name, unit_price, unit, created_at, updated_at
+		SELECT id, public_id, name, unit_price, unit, stock_quantity, low_stock_threshold, archived_at, created_at, updated_at
 		FROM ingredients
 		WHERE id = $1
 	`
@@ -55,7 +56,7 @@ func (r *IngredientRepository) GetByID(ctx context.Context, id int64) (*model.In
 
 func (r *IngredientRepository) GetByPublicID(ctx context.Context, publicID string) (*model.Ingredient, error) {
 	query := `
-		SELECT id, public_id, name, unit_price, unit, created_at, updated_at
+		SELECT id, public_id, name, unit_price, unit, stock_quantity, low_stock_threshold, archived_at, created_at, updated_at
 		FROM ingredients
 		WHERE public_id = $1
 	`
@@ -72,15 +73,16 @@ func (r *IngredientRepository) GetByPublicID(ctx context.Context, publicID strin
 	return &ingredient, nil
 }
 
-func (r *IngredientRepository) GetAll(ctx context.Context) ([]*model.Ingredient, error) {
+func (r *IngredientRepository) GetAll(ctx context.Context, includeArchived bool) ([]*model.Ingredient, error) {
 	query := `
-		SELECT id, public_id, name, unit_price, unit, created_at, updated_at
+		SELECT id, public_id, name, unit_price, unit, stock_quantity, low_stock_threshold, archived_at, created_at, updated_at
 		FROM ingredients
+		WHERE $1 OR archived_at IS NULL
 		ORDER BY name
 	`
 	
 	var ingredients []*model.Ingredient
-	err := r.db.SelectContext(ctx, &ingredients, query)
+	err := r.db.SelectContext(ctx, &ingredients, query, includeArchived)
 	if err != nil {
 		return nil, err
 	}
@@ -91,14 +93,15 @@ func (r *IngredientRepository) GetAll(ctx context.Context) ([]*model.Ingredient,
 func (r *IngredientRepository) Update(ctx context.Context, ingredient *model.Ingredient) error {
 	query := `
 		UPDATE ingredients
-		SET name = $1, unit_price = $2, unit = $3, updated_at = $4
-		WHERE id = $5
+		SET name = $1, unit_price = $2, unit = $3, low_stock_threshold = $4, updated_at = $5
+		WHERE id = $6
 	`
 	
 	_, err := r.db.ExecContext(ctx, query,
 		ingredient.Name,
 		ingredient.UnitPrice,
 		ingredient.Unit,
+		ingredient.LowStockThreshold,
 		ingredient.UpdatedAt,
 		ingredient.ID,
 	)
@@ -106,12 +109,34 @@ func (r *IngredientRepository) Update(ctx context.Context, ingredient *model.Ing
 	return err
 }
 
-func (r *IngredientRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM ingredients WHERE id = $1`
+// Archive hides an ingredient from the ingredient list. Stock movements and
+// purchase history keep pointing at the row.
+func (r *IngredientRepository) Archive(ctx context.Context, id int64) error {
+	query := `UPDATE ingredients SET archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), updated_at = CURRENT_TIMESTAMP WHERE id = $1`
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
+func (r *IngredientRepository) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE ingredients SET archived_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// CountLiveRecipes counts the variants still on the menu whose recipe uses the ingredient
+func (r *IngredientRepository) CountLiveRecipes(ctx context.Context, id int64) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM variant_ingredients vi
+		JOIN variants v ON vi.variant_id = v.id
+		JOIN products p ON v.product_id = p.id
+		WHERE vi.ingredient_id = $1 AND v.archived_at IS NULL AND p.archived_at IS NULL
+	`
+	var count int
+	err := r.db.GetContext(ctx, &count, query, id)
+	return count, err
+}
+
 func (r *IngredientRepository) GetByVariantID(ctx context.Context, variantID int64) ([]*model.VariantIngredient, error) {
 	query := `
 		SELECT 
@@ -0,0 +1,313 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type KitchenRepository struct {
+	db           *sqlx.DB
+	modifierRepo *ModifierRepository
+}
+
+func NewKitchenRepository(db *sqlx.DB) *KitchenRepository {
+	return &KitchenRepository{
+		db:           db,
+		modifierRepo: NewModifierRepository(db),
+	}
+}
+
+const kitchenStationColumns = `id, public_id, name, code, is_default, sort_order, is_active, created_at, updated_at`
+
+const kitchenTicketSelect = `
+	SELECT t.id, t.public_id, t.order_id, o.public_id AS order_public_id, o.order_number, o.status AS order_status,
+		t.order_item_id, t.kitchen_station_id, ks.public_id AS station_public_id, ks.name AS station_name,
+		oi.product_name, oi.variant_name, oi.quantity, oi.notes,
+		t.status, t.started_at, t.completed_at, t.bumped_at, t.created_at, t.updated_at
+	FROM kitchen_tickets t
+	JOIN orders o ON t.order_id = o.id
+	JOIN order_items oi ON t.order_item_id = oi.id
+	JOIN kitchen_stations ks ON t.kitchen_station_id = ks.id
+`
+
+// CreateStation creates a kitchen station
+func (r *KitchenRepository) CreateStation(ctx context.Context, station *model.KitchenStation) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if station.IsDefault {
+		if err = r.clearDefaultStation(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO kitchen_stations (name, code, is_default, sort_order, is_active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, public_id, created_at, updated_at
+	`, station.Name, station.Code, station.IsDefault, station.SortOrder, station.IsActive).Scan(
+		&station.ID, &station.PublicID, &station.CreatedAt, &station.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	station.ProductIDs = make([]string, 0)
+
+	return tx.Commit()
+}
+
+// GetStationByPublicID gets a kitchen station, nil when not found
+func (r *KitchenRepository) GetStationByPublicID(ctx context.Context, publicID string) (*model.KitchenStation, error) {
+	var station model.KitchenStation
+	query := fmt.Sprintf("SELECT %s FROM kitchen_stations WHERE public_id = $1", kitchenStationColumns)
+	if err := r.db.GetContext(ctx, &station, query, publicID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := r.loadStationProducts(ctx, &station); err != nil {
+		return nil, err
+	}
+	return &station, nil
+}
+
+// ListStations lists all kitchen stations
+func (r *KitchenRepository) ListStations(ctx context.Context) ([]*model.KitchenStation, error) {
+	stations := make([]*model.KitchenStation, 0)
+	query := fmt.Sprintf("SELECT %s FROM kitchen_stations ORDER BY sort_order ASC, name ASC", kitchenStationColumns)
+	if err := r.db.SelectContext(ctx, &stations, query); err != nil {
+		return nil, err
+	}
+	for _, station := range stations {
+		if err := r.loadStationProducts(ctx, station); err != nil {
+			return nil, err
+		}
+	}
+	return stations, nil
+}
+
+// UpdateStation updates a kitchen station
+func (r *KitchenRepository) UpdateStation(ctx context.Context, station *model.KitchenStation) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if station.IsDefault {
+		if err = r.clearDefaultStation(ctx, tx); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE kitchen_stations
+		SET name = $1, code = $2, is_default = $3, sort_order = $4, is_active = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6
+	`, station.Name, station.Code, station.IsDefault, station.SortOrder, station.IsActive, station.ID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DeleteStation deletes a kitchen station
+func (r *KitchenRepository) DeleteStation(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM kitchen_stations WHERE id = $1", id)
+	return err
+}
+
+// CountStationTickets counts the tickets ever routed to a station
+func (r *KitchenRepository) CountStationTickets(ctx context.Context, id int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM kitchen_tickets WHERE kitchen_station_id = $1", id)
+	return count, err
+}
+
+// SetStationProducts replaces the products routed to a station; a product
+// routed elsewhere is moved to this station
+func (r *KitchenRepository) SetStationProducts(ctx context.Context, stationID int64, productPublicIDs []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM kitchen_station_products WHERE kitchen_station_id = $1", stationID); err != nil {
+		return err
+	}
+
+	for _, productPublicID := range productPublicIDs {
+		var productID int64
+		err = tx.QueryRowContext(ctx, "SELECT id FROM products WHERE public_id = $1", productPublicID).Scan(&productID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return model.NewValidationError("product_ids", "Không tìm thấy sản phẩm: "+productPublicID)
+			}
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO kitchen_station_products (kitchen_station_id, product_id)
+			VALUES ($1, $2)
+			ON CONFLICT (product_id) DO UPDATE SET kitchen_station_id = EXCLUDED.kitchen_station_id
+		`, stationID, productID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ListQueue lists the tickets of orders being processed, oldest first
+func (r *KitchenRepository) ListQueue(ctx context.Context, stationID *int64, includeBumped bool) ([]*model.KitchenTicket, error) {
+	query := kitchenTicketSelect + " WHERE o.status = $1"
+	args := []any{model.OrderStatusProcessing}
+	if stationID != nil {
+		args = append(args, *stationID)
+		query += fmt.Sprintf(" AND t.kitchen_station_id = $%d", len(args))
+	}
+	if !includeBumped {
+		args = append(args, model.KitchenItemStatusBumped)
+		query += fmt.Sprintf(" AND t.status <> $%d", len(args))
+	}
+	query += " ORDER BY t.created_at ASC, t.id ASC"
+
+	return r.selectTickets(ctx, query, args...)
+}
+
+// ListTicketsByOrder lists all tickets of an order
+func (r *KitchenRepository) ListTicketsByOrder(ctx context.Context, orderID int64) ([]*model.KitchenTicket, error) {
+	return r.selectTickets(ctx, kitchenTicketSelect+" WHERE t.order_id = $1 ORDER BY t.id ASC", orderID)
+}
+
+// GetTicketByPublicID gets a kitchen ticket, nil when not found
+func (r *KitchenRepository) GetTicketByPublicID(ctx context.Context, publicID string) (*model.KitchenTicket, error) {
+	tickets, err := r.selectTickets(ctx, kitchenTicketSelect+" WHERE t.public_id = $1", publicID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tickets) == 0 {
+		return nil, nil
+	}
+	return tickets[0], nil
+}
+
+// UpdateTicketStatus moves a ticket to a new status and reports whether
+// every ticket of its order is now finished
+func (r *KitchenRepository) UpdateTicketStatus(ctx context.Context, ticketID int64, status model.KitchenItemStatus, userID int64) (bool, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var orderID int64
+	err = tx.QueryRowContext(ctx, `
+		UPDATE kitchen_tickets
+		SET status = $1::kitchen_item_status,
+			started_at = CASE WHEN $1 = 'in_progress' AND started_at IS NULL THEN CURRENT_TIMESTAMP ELSE started_at END,
+			completed_at = CASE WHEN $1 IN ('done', 'bumped') THEN COALESCE(completed_at, CURRENT_TIMESTAMP) ELSE NULL END,
+			bumped_at = CASE WHEN $1 = 'bumped' THEN CURRENT_TIMESTAMP ELSE NULL END,
+			updated_by = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING order_id
+	`, status, userID, ticketID).Scan(&orderID)
+	if err != nil {
+		return false, err
+	}
+
+	var pending int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COUNT(*) FROM kitchen_tickets
+		WHERE order_id = $1 AND status NOT IN ('done', 'bumped')
+	`, orderID).Scan(&pending)
+	if err != nil {
+		return false, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return false, err
+	}
+	return pending == 0, nil
+}
+
+// enqueueOrder routes the order's items to their stations; items already
+// queued are left untouched so it is safe to call again after edits
+func (r *KitchenRepository) enqueueOrder(ctx context.Context, tx *sqlx.Tx, orderID int64) error {
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO kitchen_tickets (order_id, order_item_id, kitchen_station_id)
+		SELECT order_id, order_item_id, station_id
+		FROM (
+			SELECT oi.order_id, oi.id AS order_item_id,
+				COALESCE(ks.id, (SELECT id FROM kitchen_stations WHERE is_default = true AND is_active = true)) AS station_id
+			FROM order_items oi
+			JOIN variants v ON oi.variant_id = v.id
+			LEFT JOIN kitchen_station_products ksp ON ksp.product_id = v.product_id
+			LEFT JOIN kitchen_stations ks ON ksp.kitchen_station_id = ks.id AND ks.is_active = true
+			WHERE oi.order_id = $1
+		) routed
+		WHERE station_id IS NOT NULL
+		ON CONFLICT (order_item_id) DO NOTHING
+	`, orderID)
+	return err
+}
+
+// clearOrder removes the order's tickets from the queue
+func (r *KitchenRepository) clearOrder(ctx context.Context, tx *sqlx.Tx, orderID int64) error {
+	_, err := tx.ExecContext(ctx, "DELETE FROM kitchen_tickets WHERE order_id = $1", orderID)
+	return err
+}
+
+func (r *KitchenRepository) clearDefaultStation(ctx context.Context, tx *sqlx.Tx) error {
+	_, err := tx.ExecContext(ctx, "UPDATE kitchen_stations SET is_default = false, updated_at = CURRENT_TIMESTAMP WHERE is_default = true")
+	return err
+}
+
+// loadStationProducts fills the public product IDs routed to a station
+func (r *KitchenRepository) loadStationProducts(ctx context.Context, station *model.KitchenStation) error {
+	station.ProductIDs = make([]string, 0)
+	return r.db.SelectContext(ctx, &station.ProductIDs, `
+		SELECT p.public_id FROM kitchen_station_products ksp
+		JOIN products p ON ksp.product_id = p.id
+		WHERE ksp.kitchen_station_id = $1
+		ORDER BY p.name ASC
+	`, station.ID)
+}
+
+// selectTickets runs a ticket query and attaches the selected modifier options
+func (r *KitchenRepository) selectTickets(ctx context.Context, query string, args ...any) ([]*model.KitchenTicket, error) {
+	tickets := make([]*model.KitchenTicket, 0)
+	if err := r.db.SelectContext(ctx, &tickets, query, args...); err != nil {
+		return nil, err
+	}
+
+	modifiersByOrder := make(map[int64]map[int64][]model.OrderItemModifier)
+	for _, ticket := range tickets {
+		modifiersByItem, ok := modifiersByOrder[ticket.OrderID]
+		if !ok {
+			var err error
+			modifiersByItem, err = r.modifierRepo.getOrderModifiers(ctx, r.db, ticket.OrderID)
+			if err != nil {
+				return nil, err
+			}
+			modifiersByOrder[ticket.OrderID] = modifiersByItem
+		}
+		ticket.Modifiers = modifiersByItem[ticket.OrderItemID]
+		if ticket.Modifiers == nil {
+			ticket.Modifiers = make([]model.OrderItemModifier, 0)
+		}
+	}
+	return tickets, nil
+}
@@ -0,0 +1,478 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const loyaltySettingsColumns = `is_enabled, points_per_vnd, point_value, max_redeem_percent, tier_window_days, updated_at`
+
+const loyaltyProductBonusSelect = `
+	SELECT b.id, b.product_id, p.public_id::text AS product_public_id, p.name AS product_name,
+		b.bonus_points, b.is_active, b.created_at, b.updated_at
+	FROM loyalty_product_bonuses b
+	JOIN products p ON b.product_id = p.id`
+
+// Customers without an account row yet show as members with no points
+const loyaltyAccountSelect = `
+	SELECT u.id AS user_id, u.public_id::text AS user_public_id, COALESCE(u.full_name, '') AS full_name,
+		COALESCE(u.phone, '') AS phone, COALESCE(a.points_balance, 0) AS points_balance,
+		COALESCE(a.lifetime_points, 0) AS lifetime_points, COALESCE(a.tier, 'member') AS tier,
+		t.name AS tier_name, COALESCE(a.tier_spent, 0) AS tier_spent, a.tier_updated_at,
+		(SELECT point_value FROM loyalty_settings WHERE id = 1) AS point_value
+	FROM users u
+	LEFT JOIN loyalty_accounts a ON a.user_id = u.id
+	JOIN loyalty_tiers t ON t.code = COALESCE(a.tier, 'member')`
+
+type LoyaltyRepository struct {
+	db *sqlx.DB
+}
+
+func NewLoyaltyRepository(db *sqlx.DB) *LoyaltyRepository {
+	return &LoyaltyRepository{db: db}
+}
+
+// GetSettings gets the loyalty program settings
+func (r *LoyaltyRepository) GetSettings(ctx context.Context) (*model.LoyaltySettings, error) {
+	return r.getSettings(ctx, r.db)
+}
+
+func (r *LoyaltyRepository) getSettings(ctx context.Context, q sqlx.QueryerContext) (*model.LoyaltySettings, error) {
+	var settings model.LoyaltySettings
+	if err := sqlx.GetContext(ctx, q, &settings, `SELECT `+loyaltySettingsColumns+` FROM loyalty_settings WHERE id = 1`); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateSettings saves the loyalty program settings
+func (r *LoyaltyRepository) UpdateSettings(ctx context.Context, settings *model.LoyaltySettings, userID int64) (*model.LoyaltySettings, error) {
+	var updated model.LoyaltySettings
+	err := r.db.GetContext(ctx, &updated, `
+		UPDATE loyalty_settings SET
+			is_enabled = $1, points_per_vnd = $2, point_value = $3, max_redeem_percent = $4,
+			tier_window_days = $5, updated_by = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = 1
+		RETURNING `+loyaltySettingsColumns,
+		settings.IsEnabled, settings.PointsPerVND, settings.PointValue, settings.MaxRedeemPercent, settings.TierWindowDays, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// ListTiers lists the tier levels, lowest first
+func (r *LoyaltyRepository) ListTiers(ctx context.Context) ([]model.LoyaltyTier, error) {
+	tiers := []model.LoyaltyTier{}
+	query := `SELECT code, name, min_spent, earn_multiplier, updated_at FROM loyalty_tiers ORDER BY min_spent, code`
+	if err := r.db.SelectContext(ctx, &tiers, query); err != nil {
+		return nil, err
+	}
+	return tiers, nil
+}
+
+// UpdateTier updates a tier level, nil if the code does not exist
+func (r *LoyaltyRepository) UpdateTier(ctx context.Context, code string, req *model.UpdateLoyaltyTierRequest) (*model.LoyaltyTier, error) {
+	var tier model.LoyaltyTier
+	err := r.db.GetContext(ctx, &tier, `
+		UPDATE loyalty_tiers SET name = $2, min_spent = $3, earn_multiplier = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE code = $1
+		RETURNING code, name, min_spent, earn_multiplier, updated_at
+	`, code, req.Name, req.MinSpent, req.EarnMultiplier)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &tier, nil
+}
+
+// ListProductBonuses lists the per product bonus points
+func (r *LoyaltyRepository) ListProductBonuses(ctx context.Context) ([]model.LoyaltyProductBonus, error) {
+	bonuses := []model.LoyaltyProductBonus{}
+	if err := r.db.SelectContext(ctx, &bonuses, loyaltyProductBonusSelect+` ORDER BY p.name`); err != nil {
+		return nil, err
+	}
+	return bonuses, nil
+}
+
+// SetProductBonus creates or replaces the bonus points of a product, nil if the product does not exist
+func (r *LoyaltyRepository) SetProductBonus(ctx context.Context, productPublicID string, bonusPoints int, isActive bool, userID int64) (*model.LoyaltyProductBonus, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO loyalty_product_bonuses (product_id, bonus_points, is_active, created_by)
+		SELECT id, $2, $3, $4 FROM products WHERE public_id::text = $1
+		ON CONFLICT (product_id) DO UPDATE SET
+			bonus_points = EXCLUDED.bonus_points, is_active = EXCLUDED.is_active, updated_at = CURRENT_TIMESTAMP
+		RETURNING id
+	`, productPublicID, bonusPoints, isActive, userID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var bonus model.LoyaltyProductBonus
+	if err := r.db.GetContext(ctx, &bonus, loyaltyProductBonusSelect+` WHERE b.id = $1`, id); err != nil {
+		return nil, err
+	}
+	return &bonus, nil
+}
+
+// DeleteProductBonus removes the bonus points of a product, false if it had none
+func (r *LoyaltyRepository) DeleteProductBonus(ctx context.Context, productPublicID string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		DELETE FROM loyalty_product_bonuses
+		WHERE product_id = (SELECT id FROM products WHERE public_id::text = $1)
+	`, productPublicID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// GetAccount gets the loyalty account of a customer by public ID, nil if the user is not a customer
+func (r *LoyaltyRepository) GetAccount(ctx context.Context, userPublicID string) (*model.LoyaltyAccount, error) {
+	var account model.LoyaltyAccount
+	query := loyaltyAccountSelect + ` WHERE u.public_id::text = $1 AND u.role = 'client'`
+	if err := r.db.GetContext(ctx, &account, query, userPublicID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+// ListLedger lists the points ledger of a customer, newest first
+func (r *LoyaltyRepository) ListLedger(ctx context.Context, userID int64, req *model.ListLoyaltyLedgerRequest) (*model.ListLoyaltyLedgerResponse, error) {
+	var total int
+	if err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM loyalty_ledger WHERE user_id = $1", userID); err != nil {
+		return nil, err
+	}
+
+	entries := []model.LoyaltyLedgerEntry{}
+	offset := (req.Page - 1) * req.Limit
+	err := r.db.SelectContext(ctx, &entries, `
+		SELECT l.id, l.public_id, l.user_id, l.order_id, o.order_number, l.entry_type, l.points,
+			l.balance_after, l.note, cu.public_id::text AS created_by, l.created_at
+		FROM loyalty_ledger l
+		LEFT JOIN orders o ON l.order_id = o.id
+		LEFT JOIN users cu ON l.created_by = cu.id
+		WHERE l.user_id = $1
+		ORDER BY l.created_at DESC, l.id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, req.Limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ListLoyaltyLedgerResponse{
+		Entries: entries,
+		Total:   total,
+		Page:    req.Page,
+		Limit:   req.Limit,
+		Pages:   int(math.Ceil(float64(total) / float64(req.Limit))),
+	}, nil
+}
+
+// AdjustPoints adds or removes points of a customer by hand
+func (r *LoyaltyRepository) AdjustPoints(ctx context.Context, userID int64, points int, note string, createdBy int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = r.addEntry(ctx, tx, userID, nil, model.LoyaltyEntryAdjustment, points, &note, &createdBy); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// RecalculateTiers sets the tier of every customer from their completed order
+// spending within the tier window
+func (r *LoyaltyRepository) RecalculateTiers(ctx context.Context) (*model.TierRecalculationResult, error) {
+	return r.recalculateTiers(ctx, r.db, nil)
+}
+
+// recalculateTiers recalculates the tier of one customer, or of every customer
+// with an account or spending when userID is nil
+func (r *LoyaltyRepository) recalculateTiers(ctx context.Context, q sqlx.QueryerContext, userID *int64) (*model.TierRecalculationResult, error) {
+	var result model.TierRecalculationResult
+	err := q.QueryRowxContext(ctx, `
+		WITH spent AS (
+			SELECT customer_id AS user_id, SUM(total_amount) AS amount
+			FROM orders
+			WHERE status = 'completed' AND customer_id IS NOT NULL
+				AND ($1::bigint IS NULL OR customer_id = $1)
+				AND created_at >= CURRENT_TIMESTAMP - make_interval(days => (SELECT tier_window_days FROM loyalty_settings WHERE id = 1))
+			GROUP BY customer_id
+		), evaluated AS (
+			SELECT COALESCE(a.user_id, s.user_id) AS user_id, COALESCE(s.amount, 0) AS amount, a.tier AS old_tier,
+				COALESCE((
+					SELECT t.code FROM loyalty_tiers t
+					WHERE t.min_spent <= COALESCE(s.amount, 0)
+					ORDER BY t.min_spent DESC LIMIT 1
+				), 'member') AS tier
+			FROM loyalty_accounts a
+			FULL JOIN spent s ON s.user_id = a.user_id
+			WHERE $1::bigint IS NULL OR COALESCE(a.user_id, s.user_id) = $1
+		), saved AS (
+			INSERT INTO loyalty_accounts (user_id, tier, tier_spent, tier_updated_at)
+			SELECT user_id, tier, amount, CURRENT_TIMESTAMP FROM evaluated
+			ON CONFLICT (user_id) DO UPDATE SET
+				tier = EXCLUDED.tier, tier_spent = EXCLUDED.tier_spent,
+				tier_updated_at = EXCLUDED.tier_updated_at, updated_at = CURRENT_TIMESTAMP
+			RETURNING user_id
+		)
+		SELECT COUNT(*) AS evaluated, COUNT(*) FILTER (WHERE old_tier IS DISTINCT FROM tier) AS changed
+		FROM evaluated
+	`, userID).StructScan(&result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// addEntry applies signed points to a customer balance and appends the ledger
+// entry. The balance may not go below zero. Returns the new balance.
+func (r *LoyaltyRepository) addEntry(ctx context.Context, tx *sqlx.Tx, userID int64, orderID *int64, entryType model.LoyaltyEntryType, points int, note *string, createdBy *int64) (int, error) {
+	_, err := tx.ExecContext(ctx, "INSERT INTO loyalty_accounts (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING", userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Lifetime points only count what orders earned
+	lifetimeDelta := 0
+	if entryType == model.LoyaltyEntryEarn || entryType == model.LoyaltyEntryEarnReversal {
+		lifetimeDelta = points
+	}
+	var balance int
+	err = tx.QueryRowContext(ctx, `
+		UPDATE loyalty_accounts
+		SET points_balance = points_balance + $2, lifetime_points = lifetime_points + $3, updated_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND points_balance + $2 >= 0
+		RETURNING points_balance
+	`, userID, points, lifetimeDelta).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, model.NewValidationError("points", "Số điểm của khách hàng không đủ")
+		}
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO loyalty_ledger (user_id, order_id, entry_type, points, balance_after, note, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, userID, orderID, entryType, points, balance, note, createdBy)
+	if err != nil {
+		return 0, err
+	}
+	return balance, nil
+}
+
+// orderEntryPoints gets the points of the ledger entry of an order, nil if there is none
+func (r *LoyaltyRepository) orderEntryPoints(ctx context.Context, tx *sqlx.Tx, orderID int64, entryType model.LoyaltyEntryType) (userID int64, points *int, err error) {
+	var p int
+	err = tx.QueryRowContext(ctx, "SELECT user_id, points FROM loyalty_ledger WHERE order_id = $1 AND entry_type = $2", orderID, entryType).Scan(&userID, &p)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil, nil
+		}
+		return 0, nil, err
+	}
+	return userID, &p, nil
+}
+
+// redeemForOrder spends customer points as a discount on a new order. The
+// discount is capped at a share of the item amount after other discounts.
+func (r *LoyaltyRepository) redeemForOrder(ctx context.Context, tx *sqlx.Tx, order *model.Order, points int, userID int64) error {
+	settings, err := r.getSettings(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if !settings.IsEnabled {
+		return model.NewValidationError("redeem_points", "Chương trình tích điểm đang tạm dừng")
+	}
+	if order.CustomerID == nil {
+		return model.NewValidationError("redeem_points", "Không tìm thấy khách hàng thành viên theo số điện thoại để dùng điểm")
+	}
+
+	var balance int
+	err = tx.QueryRowContext(ctx, "SELECT points_balance FROM loyalty_accounts WHERE user_id = $1 FOR UPDATE", *order.CustomerID).Scan(&balance)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if balance < points {
+		return model.NewValidationError("redeem_points", fmt.Sprintf("Khách hàng chỉ có %d điểm", balance))
+	}
+
+	eligible := math.Max(0, order.Subtotal-order.DiscountAmount-order.ManualDiscountAmount-order.PromotionDiscountAmount)
+	maxAmount := eligible * settings.MaxRedeemPercent / 100
+	amount := float64(points) * settings.PointValue
+	if amount > maxAmount {
+		maxPoints := 0
+		if settings.PointValue > 0 {
+			maxPoints = int(maxAmount / settings.PointValue)
+		}
+		return model.NewValidationError("redeem_points", fmt.Sprintf("Chỉ được dùng tối đa %d điểm cho đơn này", maxPoints))
+	}
+
+	if _, err = r.addEntry(ctx, tx, *order.CustomerID, &order.ID, model.LoyaltyEntryRedeem, -points, nil, &userID); err != nil {
+		return err
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE orders
+		SET loyalty_points_redeemed = $1, loyalty_discount_amount = $2,
+			total_amount = GREATEST(0, subtotal - discount_amount - manual_discount_amount - promotion_discount_amount - $2 + shipping_fee),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING total_amount
+	`, points, amount, order.ID).Scan(&order.TotalAmount)
+	if err != nil {
+		return err
+	}
+	order.LoyaltyPointsRedeemed = points
+	order.LoyaltyDiscountAmount = amount
+	return nil
+}
+
+// earnForOrder credits the points of a completed order to its customer: the
+// paid item amount times the rate and tier multiplier, plus product bonuses.
+// It is a no-op when the order already earned.
+func (r *LoyaltyRepository) earnForOrder(ctx context.Context, tx *sqlx.Tx, orderID int64, userID int64) error {
+	_, earned, err := r.orderEntryPoints(ctx, tx, orderID, model.LoyaltyEntryEarn)
+	if err != nil || earned != nil {
+		return err
+	}
+	settings, err := r.getSettings(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if !settings.IsEnabled {
+		return nil
+	}
+
+	var customerID int64
+	var netAmount, multiplier float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT o.customer_id,
+			GREATEST(0, o.subtotal - o.discount_amount - o.manual_discount_amount - o.promotion_discount_amount - o.loyalty_discount_amount),
+			COALESCE(t.earn_multiplier, 1)
+		FROM orders o
+		JOIN users u ON u.id = o.customer_id AND u.role = 'client'
+		LEFT JOIN loyalty_accounts a ON a.user_id = o.customer_id
+		LEFT JOIN loyalty_tiers t ON t.code = a.tier
+		WHERE o.id = $1
+	`, orderID).Scan(&customerID, &netAmount, &multiplier)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil // Đơn không gắn với khách hàng thành viên
+		}
+		return err
+	}
+
+	var bonus int
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(b.bonus_points * oi.quantity), 0)
+		FROM order_items oi
+		JOIN variants v ON oi.variant_id = v.id
+		JOIN loyalty_product_bonuses b ON b.product_id = v.product_id AND b.is_active = true
+		WHERE oi.order_id = $1
+	`, orderID).Scan(&bonus)
+	if err != nil {
+		return err
+	}
+
+	points := int(math.Floor(netAmount*settings.PointsPerVND*multiplier)) + bonus
+	if points <= 0 {
+		return nil
+	}
+	if _, err = r.addEntry(ctx, tx, customerID, &orderID, model.LoyaltyEntryEarn, points, nil, &userID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, "UPDATE orders SET loyalty_points_earned = $1 WHERE id = $2", points, orderID)
+	return err
+}
+
+// reverseForOrder undoes the points of a cancelled order: redeemed points go
+// back to the customer and earned points are taken back, as far as the balance
+// allows. Each is done at most once.
+func (r *LoyaltyRepository) reverseForOrder(ctx context.Context, tx *sqlx.Tx, orderID int64, userID int64) error {
+	customerID, redeemed, err := r.orderEntryPoints(ctx, tx, orderID, model.LoyaltyEntryRedeem)
+	if err != nil {
+		return err
+	}
+	if redeemed != nil {
+		_, refunded, err := r.orderEntryPoints(ctx, tx, orderID, model.LoyaltyEntryRedeemRefund)
+		if err != nil {
+			return err
+		}
+		if refunded == nil {
+			if _, err = r.addEntry(ctx, tx, customerID, &orderID, model.LoyaltyEntryRedeemRefund, -*redeemed, nil, &userID); err != nil {
+				return err
+			}
+		}
+	}
+
+	customerID, earned, err := r.orderEntryPoints(ctx, tx, orderID, model.LoyaltyEntryEarn)
+	if err != nil || earned == nil {
+		return err
+	}
+	_, reversed, err := r.orderEntryPoints(ctx, tx, orderID, model.LoyaltyEntryEarnReversal)
+	if err != nil || reversed != nil {
+		return err
+	}
+
+	var balance int
+	err = tx.QueryRowContext(ctx, "SELECT points_balance FROM loyalty_accounts WHERE user_id = $1 FOR UPDATE", customerID).Scan(&balance)
+	if err != nil {
+		return err
+	}
+	points := min(*earned, balance)
+	var note *string
+	if points < *earned {
+		text := fmt.Sprintf("Khách đã dùng %d điểm của đơn trước khi hủy", *earned-points)
+		note = &text
+	}
+	_, err = r.addEntry(ctx, tx, customerID, &orderID, model.LoyaltyEntryEarnReversal, -points, note, &userID)
+	return err
+}
+
+// moveAccount moves the points and ledger of a merged customer to the customer kept
+func (r *LoyaltyRepository) moveAccount(ctx context.Context, tx *sqlx.Tx, sourceID, targetID int64) error {
+	_, err := tx.ExecContext(ctx, "UPDATE loyalty_ledger SET user_id = $1 WHERE user_id = $2", targetID, sourceID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO loyalty_accounts (user_id, points_balance, lifetime_points)
+		SELECT $1, points_balance, lifetime_points FROM loyalty_accounts WHERE user_id = $2
+		ON CONFLICT (user_id) DO UPDATE SET
+			points_balance = loyalty_accounts.points_balance + EXCLUDED.points_balance,
+			lifetime_points = loyalty_accounts.lifetime_points + EXCLUDED.lifetime_points,
+			updated_at = CURRENT_TIMESTAMP
+	`, targetID, sourceID)
+	if err != nil {
+		return err
+	}
+	if _, err = tx.ExecContext(ctx, "DELETE FROM loyalty_accounts WHERE user_id = $1", sourceID); err != nil {
+		return err
+	}
+
+	// The merged spending may reach a higher tier right away
+	_, err = r.recalculateTiers(ctx, tx, &targetID)
+	return err
+}
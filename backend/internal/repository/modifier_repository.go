@@ -0,0 +1,379 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type ModifierRepository struct {
+	db *sqlx.DB
+}
+
+func NewModifierRepository(db *sqlx.DB) *ModifierRepository {
+	return &ModifierRepository{db: db}
+}
+
+const modifierGroupColumns = `id, public_id, name, description, min_select, max_select, sort_order, is_active, created_at, updated_at`
+
+const modifierOptionSelect = `
+	SELECT o.id, o.public_id, o.modifier_group_id, o.name, o.price_delta, o.ingredient_id, i.public_id AS ingredient_public_id,
+		o.ingredient_quantity, o.is_default, o.sort_order, o.is_active, o.created_at, o.updated_at
+	FROM modifier_options o
+	LEFT JOIN ingredients i ON o.ingredient_id = i.id
+`
+
+// CreateGroup creates a modifier group together with its options
+func (r *ModifierRepository) CreateGroup(ctx context.Context, req *model.CreateModifierGroupRequest) (*model.ModifierGroup, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var groupID int64
+	var publicID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO modifier_groups (name, description, min_select, max_select, sort_order)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, public_id
+	`, req.Name, req.Description, req.MinSelect, req.MaxSelect, req.SortOrder).Scan(&groupID, &publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range req.Options {
+		if _, err := r.insertOption(ctx, tx, groupID, &req.Options[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetGroupByPublicID(ctx, publicID)
+}
+
+// GetGroupByPublicID gets a modifier group with its options, nil when not found
+func (r *ModifierRepository) GetGroupByPublicID(ctx context.Context, publicID string) (*model.ModifierGroup, error) {
+	var group model.ModifierGroup
+	query := fmt.Sprintf("SELECT %s FROM modifier_groups WHERE public_id = $1", modifierGroupColumns)
+	if err := r.db.GetContext(ctx, &group, query, publicID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err := r.loadOptions(ctx, r.db, &group, false); err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ListGroups lists all modifier groups with their options
+func (r *ModifierRepository) ListGroups(ctx context.Context) ([]*model.ModifierGroup, error) {
+	groups := make([]*model.ModifierGroup, 0)
+	query := fmt.Sprintf("SELECT %s FROM modifier_groups ORDER BY sort_order ASC, name ASC", modifierGroupColumns)
+	if err := r.db.SelectContext(ctx, &groups, query); err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if err := r.loadOptions(ctx, r.db, group, false); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// UpdateGroup updates a modifier group (options are managed separately)
+func (r *ModifierRepository) UpdateGroup(ctx context.Context, group *model.ModifierGroup) error {
+	_, err := r.db.ExecContext(ctx, `
+		UPDATE modifier_groups
+		SET name = $1, description = $2, min_select = $3, max_select = $4, sort_order = $5, is_active = $6, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+	`, group.Name, group.Description, group.MinSelect, group.MaxSelect, group.SortOrder, group.IsActive, group.ID)
+	return err
+}
+
+// DeleteGroup deletes a modifier group and its options
+func (r *ModifierRepository) DeleteGroup(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM modifier_groups WHERE id = $1", id)
+	return err
+}
+
+// CreateOption adds an option to a modifier group
+func (r *ModifierRepository) CreateOption(ctx context.Context, groupID int64, req *model.CreateModifierOptionRequest) (*model.ModifierOption, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	publicID, err := r.insertOption(ctx, tx, groupID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetOptionByPublicID(ctx, publicID)
+}
+
+// GetOptionByPublicID gets a modifier option, nil when not found
+func (r *ModifierRepository) GetOptionByPublicID(ctx context.Context, publicID string) (*model.ModifierOption, error) {
+	var option model.ModifierOption
+	if err := r.db.GetContext(ctx, &option, modifierOptionSelect+" WHERE o.public_id = $1", publicID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &option, nil
+}
+
+// UpdateOption updates a modifier option
+func (r *ModifierRepository) UpdateOption(ctx context.Context, option *model.ModifierOption, req *model.UpdateModifierOptionRequest) error {
+	ingredientID, err := r.resolveIngredient(ctx, r.db, req.IngredientID)
+	if err != nil {
+		return err
+	}
+	isActive := option.IsActive
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE modifier_options
+		SET name = $1, price_delta = $2, ingredient_id = $3, ingredient_quantity = $4, is_default = $5, sort_order = $6, is_active = $7,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+	`, req.Name, req.PriceDelta, ingredientID, req.IngredientQuantity, req.IsDefault, req.SortOrder, isActive, option.ID)
+	return err
+}
+
+// DeleteOption deletes a modifier option, order history keeps its snapshot
+func (r *ModifierRepository) DeleteOption(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM modifier_options WHERE id = $1", id)
+	return err
+}
+
+// GetGroupsByProductID gets the modifier groups attached to a product
+func (r *ModifierRepository) GetGroupsByProductID(ctx context.Context, productID int64) ([]*model.ModifierGroup, error) {
+	return r.getGroupsByProductID(ctx, r.db, productID, false)
+}
+
+// SetProductGroups replaces the modifier groups attached to a product
+func (r *ModifierRepository) SetProductGroups(ctx context.Context, productID int64, groupPublicIDs []string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM product_modifier_groups WHERE product_id = $1", productID); err != nil {
+		return err
+	}
+
+	for i, groupPublicID := range groupPublicIDs {
+		var groupID int64
+		err = tx.QueryRowContext(ctx, "SELECT id FROM modifier_groups WHERE public_id = $1", groupPublicID).Scan(&groupID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return model.NewValidationError("modifier_group_ids", "Không tìm thấy nhóm tùy chọn: "+groupPublicID)
+			}
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO product_modifier_groups (product_id, modifier_group_id, sort_order)
+			VALUES ($1, $2, $3)
+		`, productID, groupID, i)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// resolveSelection validates the selected options of an order item against the
+// product's modifier groups and returns the snapshots to store on the item
+func (r *ModifierRepository) resolveSelection(ctx context.Context, q sqlx.QueryerContext, productID int64, optionPublicIDs []string) ([]model.OrderItemModifier, error) {
+	groups, err := r.getGroupsByProductID(ctx, q, productID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	type selectedOption struct {
+		group  *model.ModifierGroup
+		option *model.ModifierOption
+	}
+	available := make(map[string]selectedOption)
+	for _, group := range groups {
+		for i := range group.Options {
+			available[group.Options[i].PublicID] = selectedOption{group: group, option: &group.Options[i]}
+		}
+	}
+
+	selected := make(map[string]bool, len(optionPublicIDs))
+	counts := make(map[int64]int)
+	for _, publicID := range optionPublicIDs {
+		if selected[publicID] {
+			continue
+		}
+		s, ok := available[publicID]
+		if !ok {
+			return nil, model.NewValidationError("modifier_option_ids", "Tùy chọn không hợp lệ cho sản phẩm: "+publicID)
+		}
+		selected[publicID] = true
+		counts[s.group.ID]++
+	}
+
+	modifiers := make([]model.OrderItemModifier, 0, len(selected))
+	for _, group := range groups {
+		// Required groups without a selection fall back to their default options
+		if counts[group.ID] == 0 && group.MinSelect > 0 {
+			for _, option := range group.Options {
+				if option.IsDefault && counts[group.ID] < group.MaxSelect {
+					selected[option.PublicID] = true
+					counts[group.ID]++
+				}
+			}
+		}
+		if counts[group.ID] < group.MinSelect {
+			return nil, model.NewValidationError("modifier_option_ids", fmt.Sprintf("Vui lòng chọn ít nhất %d tùy chọn cho %s", group.MinSelect, group.Name))
+		}
+		if counts[group.ID] > group.MaxSelect {
+			return nil, model.NewValidationError("modifier_option_ids", fmt.Sprintf("Chỉ được chọn tối đa %d tùy chọn cho %s", group.MaxSelect, group.Name))
+		}
+
+		for _, option := range group.Options {
+			if !selected[option.PublicID] {
+				continue
+			}
+			optionID := option.ID
+			modifiers = append(modifiers, model.OrderItemModifier{
+				ModifierOptionID:   &optionID,
+				GroupName:          group.Name,
+				OptionName:         option.Name,
+				PriceDelta:         option.PriceDelta,
+				IngredientID:       option.IngredientID,
+				IngredientQuantity: option.IngredientQuantity,
+			})
+		}
+	}
+	return modifiers, nil
+}
+
+// insertItemModifiers stores the option snapshots of an order item
+func (r *ModifierRepository) insertItemModifiers(ctx context.Context, tx *sqlx.Tx, orderItemID int64, modifiers []model.OrderItemModifier) ([]model.OrderItemModifier, error) {
+	result := make([]model.OrderItemModifier, 0, len(modifiers))
+	for _, m := range modifiers {
+		m.OrderItemID = orderItemID
+		err := tx.QueryRowContext(ctx, `
+			INSERT INTO order_item_modifiers (order_item_id, modifier_option_id, group_name, option_name, price_delta, ingredient_id, ingredient_quantity)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at
+		`, orderItemID, m.ModifierOptionID, m.GroupName, m.OptionName, m.PriceDelta, m.IngredientID, m.IngredientQuantity).Scan(&m.ID, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, m)
+	}
+	return result, nil
+}
+
+// getOrderModifiers gets the option snapshots of all items of an order, keyed by order item
+func (r *ModifierRepository) getOrderModifiers(ctx context.Context, q sqlx.QueryerContext, orderID int64) (map[int64][]model.OrderItemModifier, error) {
+	modifiers := make([]model.OrderItemModifier, 0)
+	err := sqlx.SelectContext(ctx, q, &modifiers, `
+		SELECT m.id, m.order_item_id, m.modifier_option_id, m.group_name, m.option_name, m.price_delta,
+			m.ingredient_id, m.ingredient_quantity, m.created_at
+		FROM order_item_modifiers m
+		JOIN order_items oi ON m.order_item_id = oi.id
+		WHERE oi.order_id = $1
+		ORDER BY m.id ASC
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64][]model.OrderItemModifier)
+	for _, m := range modifiers {
+		result[m.OrderItemID] = append(result[m.OrderItemID], m)
+	}
+	return result, nil
+}
+
+func (r *ModifierRepository) getGroupsByProductID(ctx context.Context, q sqlx.QueryerContext, productID int64, activeOnly bool) ([]*model.ModifierGroup, error) {
+	query := `
+		SELECT g.id, g.public_id, g.name, g.description, g.min_select, g.max_select, g.sort_order, g.is_active, g.created_at, g.updated_at
+		FROM product_modifier_groups pmg
+		JOIN modifier_groups g ON pmg.modifier_group_id = g.id
+		WHERE pmg.product_id = $1
+	`
+	if activeOnly {
+		query += " AND g.is_active = true"
+	}
+	query += " ORDER BY pmg.sort_order ASC, g.sort_order ASC"
+
+	groups := make([]*model.ModifierGroup, 0)
+	if err := sqlx.SelectContext(ctx, q, &groups, query, productID); err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		if err := r.loadOptions(ctx, q, group, activeOnly); err != nil {
+			return nil, err
+		}
+	}
+	return groups, nil
+}
+
+// loadOptions fills the options of a modifier group
+func (r *ModifierRepository) loadOptions(ctx context.Context, q sqlx.QueryerContext, group *model.ModifierGroup, activeOnly bool) error {
+	query := modifierOptionSelect + " WHERE o.modifier_group_id = $1"
+	if activeOnly {
+		query += " AND o.is_active = true"
+	}
+	query += " ORDER BY o.sort_order ASC, o.id ASC"
+
+	group.Options = make([]model.ModifierOption, 0)
+	return sqlx.SelectContext(ctx, q, &group.Options, query, group.ID)
+}
+
+func (r *ModifierRepository) insertOption(ctx context.Context, tx *sqlx.Tx, groupID int64, req *model.CreateModifierOptionRequest) (string, error) {
+	ingredientID, err := r.resolveIngredient(ctx, tx, req.IngredientID)
+	if err != nil {
+		return "", err
+	}
+
+	var publicID string
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO modifier_options (modifier_group_id, name, price_delta, ingredient_id, ingredient_quantity, is_default, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING public_id
+	`, groupID, req.Name, req.PriceDelta, ingredientID, req.IngredientQuantity, req.IsDefault, req.SortOrder).Scan(&publicID)
+	return publicID, err
+}
+
+// resolveIngredient maps an optional ingredient public ID to its internal ID
+func (r *ModifierRepository) resolveIngredient(ctx context.Context, q sqlx.QueryerContext, publicID *string) (*int64, error) {
+	if publicID == nil || *publicID == "" {
+		return nil, nil
+	}
+	var id int64
+	if err := q.QueryRowxContext(ctx, "SELECT id FROM ingredients WHERE public_id = $1", *publicID).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.NewValidationError("ingredient_id", "Không tìm thấy nguyên liệu: "+*publicID)
+		}
+		return nil, err
+	}
+	return &id, nil
+}
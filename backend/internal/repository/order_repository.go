@@ -15,12 +15,30 @@ import (
 )
 
 type OrderRepository struct {
-	db *sqlx.DB
+	db               *sqlx.DB
+	discountRepo     *DiscountRepository
+	stockRepo        *StockRepository
+	modifierRepo     *ModifierRepository
+	kitchenRepo      *KitchenRepository
+	paymentRepo      *PaymentRepository
+	loyaltyRepo      *LoyaltyRepository
+	promotionRepo    *PromotionRepository
+	availabilityRepo *AvailabilityRepository
+	priceListRepo    *PriceListRepository
 }
 
-func NewOrderRepository(db *sqlx.DB) *OrderRepository {
+func NewOrderRepository(db *sqlx.DB, location *time.Location) *OrderRepository {
 	return &OrderRepository{
-		db: db,
+		db:               db,
+		discountRepo:     NewDiscountRepository(db),
+		stockRepo:        NewStockRepository(db),
+		modifierRepo:     NewModifierRepository(db),
+		kitchenRepo:      NewKitchenRepository(db),
+		paymentRepo:      NewPaymentRepository(db),
+		loyaltyRepo:      NewLoyaltyRepository(db),
+		promotionRepo:    NewPromotionRepository(db),
+		availabilityRepo: NewAvailabilityRepository(db),
+		priceListRepo:    NewPriceListRepository(db, location),
 	}
 }
 
@@ -52,13 +70,18 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, req *model.CreateOrde
 		INSERT INTO orders (
 			customer_name, customer_phone, customer_email, 
 			discount_code, discount_type, discount_amount, discount_note, manual_discount_amount, shipping_fee,
-			payment_method, notes, created_by, updated_by, items_count, shipper_id
+			payment_method, notes, created_by, updated_by, items_count, shipper_id, delivery_zone,
+			delivery_address, delivery_latitude, delivery_longitude,
+			customer_address_id, delivery_ward, delivery_district, delivery_province, customer_id
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, NULLIF($16, ''), NULLIF($17, ''), $18, $19,
+			(SELECT id FROM customer_addresses WHERE public_id::text = $20), NULLIF($21, ''), NULLIF($22, ''), NULLIF($23, ''), $24)
 		RETURNING id, public_id, order_number, customer_name, customer_phone, customer_email,
 			status, subtotal, discount_amount, discount_type, discount_code, discount_note, manual_discount_amount, shipping_fee,
 			total_amount, payment_method, payment_status, notes, created_by, updated_by,
-			created_at, updated_at, items_count, shipper_id
+			created_at, updated_at, items_count, shipper_id, delivery_zone,
+			delivery_address, delivery_latitude, delivery_longitude,
+			delivery_ward, delivery_district, delivery_province
 	`
 	var dbShipperID *int64
 	if req.ShipperID != nil && *req.ShipperID != "" {
@@ -72,72 +95,133 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, req *model.CreateOrde
 	err = tx.QueryRowContext(ctx, orderQuery,
 		req.CustomerName, req.CustomerPhone, req.CustomerEmail,
 		req.DiscountCode, req.DiscountType, req.DiscountAmount, req.DiscountNote, req.ManualDiscountAmount, req.ShippingFee,
-		req.PaymentMethod, req.Notes, userID, userID, len(req.Items), dbShipperID,
+		req.PaymentMethod, req.Notes, userID, userID, len(req.Items), dbShipperID, req.DeliveryZone,
+		req.DeliveryAddress, req.DeliveryLatitude, req.DeliveryLongitude,
+		req.CustomerAddressID, req.DeliveryWard, req.DeliveryDistrict, req.DeliveryProvince, req.CustomerID,
 	).Scan(
 		&order.ID, &order.PublicID, &order.OrderNumber, &order.CustomerName, &order.CustomerPhone, &order.CustomerEmail,
 		&order.Status, &order.Subtotal, &order.DiscountAmount, &order.DiscountType, &order.DiscountCode, &order.DiscountNote, &order.ManualDiscountAmount, &order.ShippingFee,
 		&order.TotalAmount, &order.PaymentMethod, &order.PaymentStatus, &order.Notes, &order.CreatedBy, &order.UpdatedBy,
-		&order.CreatedAt, &order.UpdatedAt, &order.ItemsCount, &order.ShipperID,
+		&order.CreatedAt, &order.UpdatedAt, &order.ItemsCount, &order.ShipperID, &order.DeliveryZone,
+		&order.DeliveryAddress, &order.DeliveryLatitude, &order.DeliveryLongitude,
+		&order.DeliveryWard, &order.DeliveryDistrict, &order.DeliveryProvince,
 	)
 	if err != nil {
 		return nil, err
 	}
+	order.CustomerAddressID = req.CustomerAddressID
+	order.CustomerID = req.CustomerID
 
 	// Create order items
 	items := make([]model.OrderItem, 0, len(req.Items))
+	discountLines := make([]model.DiscountLine, 0, len(req.Items))
 	subtotal := 0.0
 
+	// Giá theo bảng giá đang áp dụng lúc tạo đơn
+	pricer, err := r.priceListRepo.newPricer(ctx, tx, r.priceListRepo.now())
+	if err != nil {
+		return nil, err
+	}
+
 	for _, itemReq := range req.Items {
 		// Get variant info
-		var variantID, variantName, productName string
-		var variantPrice float64
+		var variantID, productID int64
+		var variantName, productName string
 		variantQuery := `
-			SELECT v.id, v.name, v.price, p.name as product_name
+			SELECT v.id, v.name, p.id, p.name as product_name
 			FROM variants v
 			JOIN products p ON v.product_id = p.id
 			WHERE v.public_id = $1
 		`
 		err = tx.QueryRowContext(ctx, variantQuery, itemReq.VariantID).Scan(
-			&variantID, &variantName, &variantPrice, &productName,
+			&variantID, &variantName, &productID, &productName,
 		)
 		if err != nil {
 			// Log the variant ID that was not found
 			fmt.Printf("Variant not found: %s, Error: %v\n", itemReq.VariantID, err)
 			return nil, fmt.Errorf("variant not found: %s", itemReq.VariantID)
 		}
+		if err = r.availabilityRepo.ensureAvailable(ctx, tx, variantID); err != nil {
+			return nil, err
+		}
+		price, err := pricer.priceVariant(ctx, tx, variantID)
+		if err != nil {
+			return nil, err
+		}
+
+		// Validate and price selected modifier options
+		modifiers, err := r.modifierRepo.resolveSelection(ctx, tx, productID, itemReq.ModifierOptionIDs)
+		if err != nil {
+			return nil, err
+		}
+		unitPrice := price.Price + model.ModifiersAmount(modifiers)
 
 		// Create order item
 		var item model.OrderItem
 		itemQuery := `
 			INSERT INTO order_items (
 				order_id, variant_id, product_name, variant_name, 
-				quantity, unit_price, total_price, notes
+				quantity, unit_price, total_price, notes,
+				base_price, price_list_id, price_list_name
 			)
-			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 			RETURNING id, order_id, variant_id, product_name, variant_name,
-				quantity, unit_price, total_price, notes, created_at, updated_at
+				quantity, unit_price, total_price, notes, created_at, updated_at,
+				base_price, price_list_id, price_list_name
 		`
-		totalPrice := variantPrice * float64(itemReq.Quantity)
+		totalPrice := unitPrice * float64(itemReq.Quantity)
 		subtotal += totalPrice
 
 		err = tx.QueryRowContext(ctx, itemQuery,
 			order.ID, variantID, productName, variantName,
-			itemReq.Quantity, variantPrice, totalPrice, itemReq.Notes,
+			itemReq.Quantity, unitPrice, totalPrice, itemReq.Notes,
+			price.BasePrice, price.PriceListDBID, price.PriceListName,
 		).Scan(
 			&item.ID, &item.OrderID, &item.VariantID, &item.ProductName, &item.VariantName,
 			&item.Quantity, &item.UnitPrice, &item.TotalPrice, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.BasePrice, &item.PriceListID, &item.PriceListName,
 		)
 		if err != nil {
 			fmt.Printf("Failed to create order item: %v\n", err)
 			return nil, err
 		}
+
+		// Snapshot selected modifier options onto the order item
+		item.Modifiers, err = r.modifierRepo.insertItemModifiers(ctx, tx, item.ID, modifiers)
+		if err != nil {
+			return nil, err
+		}
 		items = append(items, item)
+		discountLines = append(discountLines, model.DiscountLine{
+			ProductID: productID,
+			VariantID: variantID,
+			Amount:    totalPrice,
+		})
+	}
+
+	// Phí giao hàng theo vùng được tính khi đã có tạm tính (miễn phí theo ngưỡng tạm tính)
+	order.ShippingFeeSource = model.ShippingFeeSourceManual
+	var calculatedFee *float64
+	if req.ShippingQuote != nil {
+		fee := req.ShippingQuote.Zone.Fee(req.ShippingQuote.DistanceKm, subtotal)
+		calculatedFee = &fee
+		order.ShippingFee = fee
+		order.DeliveryDistanceKm = &req.ShippingQuote.DistanceKm
+		order.ShippingFeeSource = model.ShippingFeeSourceAuto
+	}
+	if req.ShippingFeeOverride != nil {
+		order.ShippingFee = *req.ShippingFeeOverride
+		order.ShippingFeeSource = model.ShippingFeeSourceOverride
+		if err = r.recordShippingFeeAudit(ctx, tx, order.ID, calculatedFee, nil, order.ShippingFee, req.ShippingFeeReason, userID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Update order with calculated subtotal and total_amount
 	updateOrderQuery := `
 		UPDATE orders 
-		SET subtotal = $1, total_amount = $2, updated_at = CURRENT_TIMESTAMP, updated_by = $3, items_count = $4, shipper_id = $5
+		SET subtotal = $1, total_amount = $2, updated_at = CURRENT_TIMESTAMP, updated_by = $3, items_count = $4, shipper_id = $5,
+			shipping_fee = $7, shipping_fee_source = $8, delivery_distance_km = $9
 		WHERE id = $6
 		RETURNING id, public_id, order_number, customer_name, customer_phone, customer_email,
 			status, subtotal, discount_amount, discount_type, discount_code, discount_note, manual_discount_amount, shipping_fee,
@@ -148,7 +232,8 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, req *model.CreateOrde
 	totalDiscount := order.DiscountAmount + order.ManualDiscountAmount
 	totalAmount := math.Max(0, subtotal-totalDiscount+order.ShippingFee)
 	
-	err = tx.QueryRowContext(ctx, updateOrderQuery, subtotal, totalAmount, userID, len(req.Items), order.ShipperID, order.ID).Scan(
+	err = tx.QueryRowContext(ctx, updateOrderQuery, subtotal, totalAmount, userID, len(req.Items), order.ShipperID, order.ID,
+		order.ShippingFee, order.ShippingFeeSource, order.DeliveryDistanceKm).Scan(
 		&order.ID, &order.PublicID, &order.OrderNumber, &order.CustomerName, &order.CustomerPhone, &order.CustomerEmail,
 		&order.Status, &order.Subtotal, &order.DiscountAmount, &order.DiscountType, &order.DiscountCode, &order.DiscountNote, &order.ManualDiscountAmount, &order.ShippingFee,
 		&order.TotalAmount, &order.PaymentMethod, &order.PaymentStatus, &order.Notes, &order.CreatedBy, &order.UpdatedBy,
@@ -158,14 +243,30 @@ func (r *OrderRepository) CreateOrder(ctx context.Context, req *model.CreateOrde
 		return nil, err
 	}
 
+	// Apply automatic promotions to the order lines
+	promotionsByItem, err := r.promotionRepo.applyToOrder(ctx, tx, &order)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Promotions = promotionsByItem[items[i].ID]
+	}
+
 	// Apply discount if discount code provided
 	if req.DiscountCode != "" {
-		err = r.applyDiscountCode(ctx, tx, &order, req.DiscountCode, userID)
+		err = r.applyDiscountCode(ctx, tx, &order, req.DiscountCode, discountLines, userID)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	// Redeem loyalty points after the other discounts, the cap depends on them
+	if req.RedeemPoints > 0 {
+		if err = r.loyaltyRepo.redeemForOrder(ctx, tx, &order, req.RedeemPoints, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, err
@@ -184,10 +285,14 @@ func (r *OrderRepository) GetOrderByID(ctx context.Context, publicID string) (*m
 		SELECT o.id, o.public_id, o.order_number, o.customer_name, o.customer_phone, o.customer_email,
 			o.status, o.subtotal, o.discount_amount, o.discount_type, o.discount_code, o.discount_note, o.manual_discount_amount, o.shipping_fee,
 			o.total_amount, o.payment_method, o.payment_status, o.notes, o.created_by, o.updated_by,
-			o.created_at, o.updated_at, o.shipper_id, o.items_count,
-			s.public_id, s.name, s.phone, s.email, s.is_active
+			o.created_at, o.updated_at, o.shipper_id, o.items_count, o.delivery_zone,
+			o.delivery_address, o.delivery_latitude, o.delivery_longitude, o.delivery_distance_km, o.shipping_fee_source,
+			ca.public_id, o.delivery_ward, o.delivery_district, o.delivery_province,
+			o.customer_id, o.loyalty_points_redeemed, o.loyalty_discount_amount, o.loyalty_points_earned,
+			o.promotion_discount_amount, s.public_id, s.name, s.phone, s.email, s.is_active
 		FROM orders o
 		LEFT JOIN shippers s ON o.shipper_id = s.id
+		LEFT JOIN customer_addresses ca ON o.customer_address_id = ca.id
 		WHERE o.public_id = $1
 	`
 	var shipperID sql.NullInt64
@@ -200,8 +305,11 @@ func (r *OrderRepository) GetOrderByID(ctx context.Context, publicID string) (*m
 		&order.ID, &order.PublicID, &order.OrderNumber, &order.CustomerName, &order.CustomerPhone, &order.CustomerEmail,
 		&order.Status, &order.Subtotal, &order.DiscountAmount, &order.DiscountType, &order.DiscountCode, &order.DiscountNote, &order.ManualDiscountAmount, &order.ShippingFee,
 		&order.TotalAmount, &order.PaymentMethod, &order.PaymentStatus, &order.Notes, &order.CreatedBy, &order.UpdatedBy,
-		&order.CreatedAt, &order.UpdatedAt, &shipperID, &order.ItemsCount,
-		&shipperPublicID, &shipperName, &shipperPhone, &shipperEmail, &shipperIsActive,
+		&order.CreatedAt, &order.UpdatedAt, &shipperID, &order.ItemsCount, &order.DeliveryZone,
+		&order.DeliveryAddress, &order.DeliveryLatitude, &order.DeliveryLongitude, &order.DeliveryDistanceKm, &order.ShippingFeeSource,
+		&order.CustomerAddressID, &order.DeliveryWard, &order.DeliveryDistrict, &order.DeliveryProvince,
+		&order.CustomerID, &order.LoyaltyPointsRedeemed, &order.LoyaltyDiscountAmount, &order.LoyaltyPointsEarned,
+		&order.PromotionDiscountAmount, &shipperPublicID, &shipperName, &shipperPhone, &shipperEmail, &shipperIsActive,
 	)
 
 	if err != nil {
@@ -226,7 +334,8 @@ func (r *OrderRepository) GetOrderByID(ctx context.Context, publicID string) (*m
 	// Get order items
 	itemsQuery := `
 		SELECT id, order_id, variant_id, product_name, variant_name,
-			quantity, unit_price, total_price, notes, created_at, updated_at
+			quantity, unit_price, total_price, notes, created_at, updated_at,
+			base_price, price_list_id, price_list_name
 		FROM order_items
 		WHERE order_id = $1
 		ORDER BY created_at ASC
@@ -243,12 +352,28 @@ func (r *OrderRepository) GetOrderByID(ctx context.Context, publicID string) (*m
 		err := rows.Scan(
 			&item.ID, &item.OrderID, &item.VariantID, &item.ProductName, &item.VariantName,
 			&item.Quantity, &item.UnitPrice, &item.TotalPrice, &item.Notes, &item.CreatedAt, &item.UpdatedAt,
+			&item.BasePrice, &item.PriceListID, &item.PriceListName,
 		)
 		if err != nil {
 			return nil, err
 		}
 		items = append(items, item)
 	}
+
+	// Get selected modifier options
+	modifiersByItem, err := r.modifierRepo.getOrderModifiers(ctx, r.db, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	// Get applied promotions
+	promotionsByItem, err := r.promotionRepo.getOrderPromotions(ctx, r.db, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Modifiers = modifiersByItem[items[i].ID]
+		items[i].Promotions = promotionsByItem[items[i].ID]
+	}
 	order.Items = items
 
 	// Get status history
@@ -313,6 +438,32 @@ func (r *OrderRepository) UpdateOrderStatus(ctx context.Context, publicID string
 		return nil, err
 	}
 
+	// Deduct ingredient stock and queue the items for the kitchen when the order
+	// starts processing, credit loyalty points when it completes, undo all on cancel
+	switch status {
+	case model.OrderStatusProcessing:
+		if err = r.stockRepo.deductForOrder(ctx, tx, &order, userID); err != nil {
+			return nil, err
+		}
+		if err = r.kitchenRepo.enqueueOrder(ctx, tx, order.ID); err != nil {
+			return nil, err
+		}
+	case model.OrderStatusCompleted:
+		if err = r.loyaltyRepo.earnForOrder(ctx, tx, order.ID, userID); err != nil {
+			return nil, err
+		}
+	case model.OrderStatusCancelled:
+		if err = r.stockRepo.reverseForOrder(ctx, tx, &order, userID); err != nil {
+			return nil, err
+		}
+		if err = r.kitchenRepo.clearOrder(ctx, tx, order.ID); err != nil {
+			return nil, err
+		}
+		if err = r.loyaltyRepo.reverseForOrder(ctx, tx, order.ID, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, err
@@ -432,108 +583,135 @@ func (r *OrderRepository) ListOrders(ctx context.Context, req *model.ListOrdersR
 }
 
 // ValidateDiscountCode validates and returns discount info
-func (r *OrderRepository) ValidateDiscountCode(ctx context.Context, code string, orderAmount float64) (*model.ValidateDiscountCodeResponse, error) {
-	var discountCode model.DiscountCode
-	query := `
-		SELECT id, code, name, discount_type, discount_value, min_order_amount, 
-			max_discount_amount, usage_limit, used_count, is_active, valid_from, valid_until
-		FROM discount_codes
-		WHERE code = $1
-	`
-	err := r.db.QueryRowContext(ctx, query, code).Scan(
-		&discountCode.ID, &discountCode.Code, &discountCode.Name, &discountCode.DiscountType,
-		&discountCode.DiscountValue, &discountCode.MinOrderAmount, &discountCode.MaxDiscountAmount,
-		&discountCode.UsageLimit, &discountCode.UsedCount, &discountCode.IsActive,
-		&discountCode.ValidFrom, &discountCode.ValidUntil,
-	)
+func (r *OrderRepository) ValidateDiscountCode(ctx context.Context, req *model.ValidateDiscountCodeRequest) (*model.ValidateDiscountCodeResponse, error) {
+	// Price the requested items so product/variant scoped codes can be checked
+	pricer, err := r.priceListRepo.newPricer(ctx, r.db, r.priceListRepo.now())
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return &model.ValidateDiscountCodeResponse{
-				IsValid: false,
-				Message: "Mã giảm giá không tồn tại",
-			}, nil
-		}
 		return nil, err
 	}
-
-	// Check if code is active
-	if !discountCode.IsActive {
-		return &model.ValidateDiscountCodeResponse{
-			IsValid: false,
-			Message: "Mã giảm giá đã bị vô hiệu hóa",
-		}, nil
+	lines := make([]model.DiscountLine, 0, len(req.Items))
+	for _, item := range req.Items {
+		var line model.DiscountLine
+		err := r.db.QueryRowContext(ctx, `
+			SELECT v.id, v.product_id FROM variants v WHERE v.public_id = $1
+		`, item.VariantID).Scan(&line.VariantID, &line.ProductID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, model.NewValidationError("items", "variant not found: "+item.VariantID)
+			}
+			return nil, err
+		}
+		price, err := pricer.priceVariant(ctx, r.db, line.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		modifiers, err := r.modifierRepo.resolveSelection(ctx, r.db, line.ProductID, item.ModifierOptionIDs)
+		if err != nil {
+			return nil, err
+		}
+		line.Amount = (price.Price + model.ModifiersAmount(modifiers)) * float64(item.Quantity)
+		lines = append(lines, line)
 	}
 
-	// Check validity period
-	now := time.Now()
-	if now.Before(discountCode.ValidFrom) {
-		return &model.ValidateDiscountCodeResponse{
-			IsValid: false,
-			Message: "Mã giảm giá chưa có hiệu lực",
-		}, nil
-	}
-	if now.After(discountCode.ValidUntil) {
-		return &model.ValidateDiscountCodeResponse{
-			IsValid: false,
-			Message: "Mã giảm giá đã hết hạn",
-		}, nil
-	}
+	validation, _, err := r.discountRepo.EvaluateDiscountCode(ctx, &model.DiscountEvaluation{
+		Code:          req.Code,
+		CustomerPhone: req.CustomerPhone,
+		OrderAmount:   req.OrderAmount,
+		Lines:         lines,
+	})
+	return validation, err
+}
 
-	// Check usage limit
-	if discountCode.UsageLimit != nil && discountCode.UsedCount >= *discountCode.UsageLimit {
-		return &model.ValidateDiscountCodeResponse{
-			IsValid: false,
-			Message: "Mã giảm giá đã hết lượt sử dụng",
-		}, nil
+// PreviewPromotions prices the requested items and evaluates the promotions
+// against them without creating an order
+func (r *OrderRepository) PreviewPromotions(ctx context.Context, items []model.CreateOrderItemRequest, customerID *int64) (*model.PromotionPreview, error) {
+	pricer, err := r.priceListRepo.newPricer(ctx, r.db, r.priceListRepo.now())
+	if err != nil {
+		return nil, err
 	}
-
-	// Check minimum order amount
-	if orderAmount < discountCode.MinOrderAmount {
-		return &model.ValidateDiscountCodeResponse{
-			IsValid: false,
-			Message: fmt.Sprintf("Đơn hàng tối thiểu %.0f VNĐ để áp dụng mã giảm giá", discountCode.MinOrderAmount),
-		}, nil
+	preview := &model.PromotionPreview{Lines: make([]model.PromotionPreviewLine, 0, len(items))}
+	lines := make([]model.PromotionLine, 0, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, model.NewValidationError("items", "Số lượng phải lớn hơn 0")
+		}
+		line := model.PromotionLine{Quantity: item.Quantity}
+		previewLine := model.PromotionPreviewLine{VariantID: item.VariantID, Quantity: item.Quantity}
+		err := r.db.QueryRowContext(ctx, `
+			SELECT v.id, v.product_id, v.name, p.name
+			FROM variants v JOIN products p ON v.product_id = p.id
+			WHERE v.public_id = $1
+		`, item.VariantID).Scan(&line.VariantID, &line.ProductID, &previewLine.VariantName, &previewLine.ProductName)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, model.NewValidationError("items", "variant not found: "+item.VariantID)
+			}
+			return nil, err
+		}
+		price, err := pricer.priceVariant(ctx, r.db, line.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		modifiers, err := r.modifierRepo.resolveSelection(ctx, r.db, line.ProductID, item.ModifierOptionIDs)
+		if err != nil {
+			return nil, err
+		}
+		line.UnitPrice = price.Price + model.ModifiersAmount(modifiers)
+		previewLine.UnitPrice = line.UnitPrice
+		previewLine.TotalPrice = line.UnitPrice * float64(item.Quantity)
+		preview.Subtotal += previewLine.TotalPrice
+		lines = append(lines, line)
+		preview.Lines = append(preview.Lines, previewLine)
 	}
 
-	// Calculate discount amount
-	var discountAmount float64
-	if discountCode.DiscountType == model.DiscountTypePercentage {
-		discountAmount = orderAmount * (discountCode.DiscountValue / 100)
-		if discountCode.MaxDiscountAmount != nil && discountAmount > *discountCode.MaxDiscountAmount {
-			discountAmount = *discountCode.MaxDiscountAmount
+	applied, stampCards, err := r.promotionRepo.Evaluate(ctx, lines, customerID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range preview.Lines {
+		preview.Lines[i].Promotions = make([]model.OrderItemPromotion, 0, len(applied[i]))
+		for _, promotion := range applied[i] {
+			preview.Lines[i].Promotions = append(preview.Lines[i].Promotions, promotion)
+			preview.Lines[i].DiscountAmount += promotion.DiscountAmount
 		}
-	} else {
-		discountAmount = discountCode.DiscountValue
+		preview.PromotionDiscountAmount += preview.Lines[i].DiscountAmount
 	}
-
-	return &model.ValidateDiscountCodeResponse{
-		IsValid:        true,
-		Message:        "Mã giảm giá hợp lệ",
-		DiscountType:   discountCode.DiscountType,
-		DiscountValue:  discountCode.DiscountValue,
-		DiscountAmount: discountAmount,
-	}, nil
+	preview.TotalAmount = math.Max(0, preview.Subtotal-preview.PromotionDiscountAmount)
+	preview.StampCards = stampCards
+	return preview, nil
 }
 
 // applyDiscountCode applies discount to order
-func (r *OrderRepository) applyDiscountCode(ctx context.Context, tx *sqlx.Tx, order *model.Order, code string, userID int64) error {
+func (r *OrderRepository) applyDiscountCode(ctx context.Context, tx *sqlx.Tx, order *model.Order, code string, lines []model.DiscountLine, userID int64) error {
+	// Lock the code first so the usage limits below are checked against committed redemptions
+	if err := r.discountRepo.lockDiscountCode(ctx, tx, code); err != nil {
+		return err
+	}
+
 	// Validate discount code
-	validation, err := r.ValidateDiscountCode(ctx, code, order.Subtotal)
+	validation, discountCode, err := r.discountRepo.evaluateDiscountCode(ctx, tx, &model.DiscountEvaluation{
+		Code:                 code,
+		CustomerPhone:        order.CustomerPhone,
+		OrderAmount:          order.Subtotal,
+		ManualDiscountAmount: order.ManualDiscountAmount,
+		Lines:                lines,
+	})
 	if err != nil {
 		return err
 	}
 	if !validation.IsValid {
-		return fmt.Errorf(validation.Message)
+		return model.NewValidationError("discount_code", validation.Message)
 	}
 
-	// Update order with discount
+	// Update order with discount, keeping manual discount and shipping fee in the total
+	totalAmount := math.Max(0, order.Subtotal-validation.DiscountAmount-order.ManualDiscountAmount-order.PromotionDiscountAmount-order.LoyaltyDiscountAmount+order.ShippingFee)
 	updateQuery := `
 		UPDATE orders 
 		SET discount_amount = $1, discount_type = $2, discount_code = $3,
-			total_amount = subtotal - $1, updated_at = CURRENT_TIMESTAMP, updated_by = $4
-		WHERE id = $5
+			total_amount = $4, updated_at = CURRENT_TIMESTAMP, updated_by = $5
+		WHERE id = $6
 	`
-	_, err = tx.ExecContext(ctx, updateQuery, validation.DiscountAmount, validation.DiscountType, code, userID, order.ID)
+	_, err = tx.ExecContext(ctx, updateQuery, validation.DiscountAmount, validation.DiscountType, code, totalAmount, userID, order.ID)
 	if err != nil {
 		return err
 	}
@@ -542,20 +720,10 @@ func (r *OrderRepository) applyDiscountCode(ctx context.Context, tx *sqlx.Tx, or
 	order.DiscountAmount = validation.DiscountAmount
 	order.DiscountType = &validation.DiscountType
 	order.DiscountCode = code
-	order.TotalAmount = order.Subtotal - validation.DiscountAmount
+	order.TotalAmount = totalAmount
 
-	// Increment usage count
-	incrementQuery := `
-		UPDATE discount_codes 
-		SET used_count = used_count + 1, updated_at = CURRENT_TIMESTAMP
-		WHERE code = $1
-	`
-	_, err = tx.ExecContext(ctx, incrementQuery, code)
-	if err != nil {
-		return err
-	}
-
-	return nil
+	// Increment usage count and record the redemption
+	return r.discountRepo.recordUsage(ctx, tx, discountCode.ID, order.ID, order.CustomerPhone, validation.DiscountAmount, userID)
 }
 
 // UpdateOrder updates an existing order and its items
@@ -576,12 +744,12 @@ func (r *OrderRepository) UpdateOrder(ctx context.Context, publicID string, req
 	// 2. Update bảng orders
 	updateOrderQuery := `
 		UPDATE orders 
-		SET customer_name = $1, customer_phone = $2, customer_email = $3, discount_code = $4, discount_note = $5, payment_method = $6, notes = $7, updated_by = $8, updated_at = CURRENT_TIMESTAMP, shipper_id = $9
-		WHERE id = $10
+		SET customer_name = $1, customer_phone = $2, customer_email = $3, discount_code = $4, discount_note = $5, payment_method = $6, notes = $7, updated_by = $8, updated_at = CURRENT_TIMESTAMP, shipper_id = $9, delivery_zone = NULLIF($10, '')
+		WHERE id = $11
 		RETURNING id, public_id, order_number, customer_name, customer_phone, customer_email,
 			status, subtotal, discount_amount, discount_type, discount_code, discount_note,
 			total_amount, payment_method, payment_status, notes, created_by, updated_by,
-			created_at, updated_at, items_count, shipper_id
+			created_at, updated_at, items_count, shipper_id, delivery_zone, customer_id
 	`
 	var order model.Order
 	var dbShipperID *int64
@@ -592,16 +760,21 @@ func (r *OrderRepository) UpdateOrder(ctx context.Context, publicID string, req
 		}
 	}
 	err = tx.QueryRowContext(ctx, updateOrderQuery,
-		req.CustomerName, req.CustomerPhone, req.CustomerEmail, req.DiscountCode, req.DiscountNote, req.PaymentMethod, req.Notes, userID, dbShipperID, orderID,
+		req.CustomerName, req.CustomerPhone, req.CustomerEmail, req.DiscountCode, req.DiscountNote, req.PaymentMethod, req.Notes, userID, dbShipperID, req.DeliveryZone, orderID,
 	).Scan(
 		&order.ID, &order.PublicID, &order.OrderNumber, &order.CustomerName, &order.CustomerPhone, &order.CustomerEmail,
 		&order.Status, &order.Subtotal, &order.DiscountAmount, &order.DiscountType, &order.DiscountCode, &order.DiscountNote,
 		&order.TotalAmount, &order.PaymentMethod, &order.PaymentStatus, &order.Notes, &order.CreatedBy, &order.UpdatedBy,
-		&order.CreatedAt, &order.UpdatedAt, &order.ItemsCount, &order.ShipperID,
+		&order.CreatedAt, &order.UpdatedAt, &order.ItemsCount, &order.ShipperID, &order.DeliveryZone, &order.CustomerID,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if req.DeliveryAddress != nil {
+		if err = r.updateDeliveryAddress(ctx, tx, &order, req); err != nil {
+			return nil, err
+		}
+	}
 
 	// 3. Lấy danh sách order_items cũ
 	rows, err := tx.QueryContext(ctx, "SELECT id FROM order_items WHERE order_id = $1", orderID)
@@ -617,57 +790,138 @@ func (r *OrderRepository) UpdateOrder(ctx context.Context, publicID string, req
 		}
 	}
 
+	// Item thuộc đơn giao đã kết thúc (delivered/failed/cancelled) không được sửa
+	lockedItems := map[string]int{}
+	lockedRows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT oi.id, oi.quantity
+		FROM order_items oi
+		JOIN delivery_order_items di ON di.order_item_id = oi.id
+		JOIN delivery_orders d ON di.delivery_order_id = d.id
+		WHERE oi.order_id = $1 AND d.status IN ('delivered', 'failed', 'cancelled')
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	for lockedRows.Next() {
+		var id string
+		var quantity int
+		if err := lockedRows.Scan(&id, &quantity); err != nil {
+			lockedRows.Close()
+			return nil, err
+		}
+		lockedItems[id] = quantity
+	}
+	lockedRows.Close()
+
 	// 4. Xử lý items mới
 	newItemIDs := map[string]bool{}
+	var newItemPricer *pricer
 	for _, item := range req.Items {
+		if quantity, locked := lockedItems[item.ID]; locked && item.Quantity != quantity {
+			return nil, model.NewValidationError("items", "Không thể sửa món thuộc đơn giao đã kết thúc")
+		}
 		if item.ID != "" {
 			// Update
 			// Lấy unit_price hiện tại của item
+			var itemID, productID int64
 			var unitPrice float64
-			err := tx.QueryRowContext(ctx, "SELECT unit_price FROM order_items WHERE id = $1", item.ID).Scan(&unitPrice)
+			err := tx.QueryRowContext(ctx, `
+				SELECT oi.id, oi.unit_price, v.product_id
+				FROM order_items oi JOIN variants v ON oi.variant_id = v.id
+				WHERE oi.id = $1
+			`, item.ID).Scan(&itemID, &unitPrice, &productID)
 			if err != nil {
 				return nil, err
 			}
+			// Thay tùy chọn nếu được gửi lên, giữ giá gốc của variant đã lưu
+			if item.ModifierOptionIDs != nil {
+				modifiers, err := r.modifierRepo.resolveSelection(ctx, tx, productID, item.ModifierOptionIDs)
+				if err != nil {
+					return nil, err
+				}
+				var currentModifiersAmount float64
+				err = tx.QueryRowContext(ctx, "SELECT COALESCE(SUM(price_delta), 0) FROM order_item_modifiers WHERE order_item_id = $1", itemID).Scan(&currentModifiersAmount)
+				if err != nil {
+					return nil, err
+				}
+				if _, err = tx.ExecContext(ctx, "DELETE FROM order_item_modifiers WHERE order_item_id = $1", itemID); err != nil {
+					return nil, err
+				}
+				if _, err = r.modifierRepo.insertItemModifiers(ctx, tx, itemID, modifiers); err != nil {
+					return nil, err
+				}
+				unitPrice = unitPrice - currentModifiersAmount + model.ModifiersAmount(modifiers)
+			}
 			totalPrice := float64(item.Quantity) * unitPrice
 			updateItemQuery := `
 				UPDATE order_items SET
-					quantity = $1, notes = $2, total_price = $3, updated_at = CURRENT_TIMESTAMP
-				WHERE id = $4 AND order_id = $5
+					quantity = $1, notes = $2, unit_price = $3, total_price = $4, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $5 AND order_id = $6
 			`
-			_, err = tx.ExecContext(ctx, updateItemQuery, item.Quantity, item.Notes, totalPrice, item.ID, orderID)
+			_, err = tx.ExecContext(ctx, updateItemQuery, item.Quantity, item.Notes, unitPrice, totalPrice, item.ID, orderID)
 			if err != nil {
 				return nil, err
 			}
 			newItemIDs[item.ID] = true
 		} else {
 			// Insert
-			var variantID, productName, variantName string
-			var unitPrice float64
+			var productName, variantName string
+			var variantID, productID int64
 			err := tx.QueryRowContext(ctx, `
-				SELECT v.id, v.name, v.price, p.name
+				SELECT v.id, v.name, p.id, p.name
 				FROM variants v JOIN products p ON v.product_id = p.id
 				WHERE v.public_id = $1
-			`, item.VariantID).Scan(&variantID, &variantName, &unitPrice, &productName)
+			`, item.VariantID).Scan(&variantID, &variantName, &productID, &productName)
 			if err != nil {
 				return nil, err
 			}
+			if err = r.availabilityRepo.ensureAvailable(ctx, tx, variantID); err != nil {
+				return nil, err
+			}
+			// Món thêm vào đơn lấy giá theo bảng giá đang áp dụng lúc thêm
+			if newItemPricer == nil {
+				if newItemPricer, err = r.priceListRepo.newPricer(ctx, tx, r.priceListRepo.now()); err != nil {
+					return nil, err
+				}
+			}
+			price, err := newItemPricer.priceVariant(ctx, tx, variantID)
+			if err != nil {
+				return nil, err
+			}
+			modifiers, err := r.modifierRepo.resolveSelection(ctx, tx, productID, item.ModifierOptionIDs)
+			if err != nil {
+				return nil, err
+			}
+			unitPrice := price.Price + model.ModifiersAmount(modifiers)
 			totalPrice := unitPrice * float64(item.Quantity)
 			insertItemQuery := `
-				INSERT INTO order_items (order_id, variant_id, product_name, variant_name, quantity, unit_price, total_price, notes)
-				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+				INSERT INTO order_items (
+					order_id, variant_id, product_name, variant_name, quantity, unit_price, total_price, notes,
+					base_price, price_list_id, price_list_name
+				)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+				RETURNING id
 			`
-			_, err = tx.ExecContext(ctx, insertItemQuery,
+			var itemID int64
+			err = tx.QueryRowContext(ctx, insertItemQuery,
 				orderID, variantID, productName, variantName, item.Quantity, unitPrice, totalPrice, item.Notes,
-			)
+				price.BasePrice, price.PriceListDBID, price.PriceListName,
+			).Scan(&itemID)
 			if err != nil {
 				return nil, err
 			}
+			if _, err = r.modifierRepo.insertItemModifiers(ctx, tx, itemID, modifiers); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	// 5. Xóa các item không còn nữa
 	for oldID := range oldItemIDs {
 		if !newItemIDs[oldID] {
+			if _, locked := lockedItems[oldID]; locked {
+				return nil, model.NewValidationError("items", "Không thể xóa món thuộc đơn giao đã kết thúc")
+			}
 			_, err := tx.ExecContext(ctx, "DELETE FROM order_items WHERE id = $1", oldID)
 			if err != nil {
 				return nil, err
@@ -675,12 +929,41 @@ func (r *OrderRepository) UpdateOrder(ctx context.Context, publicID string, req
 		}
 	}
 
+	// Tạm tính và địa chỉ có thể đã đổi: tính lại phí giao hàng trước khi tính tổng tiền
+	if err = r.requoteShippingFee(ctx, tx, orderID, req.ShippingQuote, userID); err != nil {
+		return nil, err
+	}
+
+	// Tính lại khuyến mãi theo các món hiện tại của đơn
+	if _, err = r.promotionRepo.applyToOrder(ctx, tx, &order); err != nil {
+		return nil, err
+	}
+
 	// 6. Cập nhật lại items_count
 	_, err = tx.ExecContext(ctx, "UPDATE orders SET items_count = (SELECT COUNT(*) FROM order_items WHERE order_id = $1) WHERE id = $1", orderID)
 	if err != nil {
 		return nil, err
 	}
 
+	// Tổng tiền có thể đã thay đổi: tính lại trạng thái thanh toán từ sổ thanh toán
+	if _, err := r.paymentRepo.syncPaymentStatus(ctx, tx, orderID); err != nil {
+		return nil, err
+	}
+
+	// Đơn đã xuất kho: trừ thêm nguyên liệu cho món thêm/tăng, hoàn kho cho món bớt/xóa
+	if order.Status != model.OrderStatusPending && order.Status != model.OrderStatusCancelled {
+		if err := r.stockRepo.deductForOrder(ctx, tx, &order, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Đơn đang xử lý: đưa các item mới vào hàng đợi bếp
+	if order.Status == model.OrderStatusProcessing {
+		if err := r.kitchenRepo.enqueueOrder(ctx, tx, orderID); err != nil {
+			return nil, err
+		}
+	}
+
 	// 6. Commit transaction
 	if err := tx.Commit(); err != nil {
 		return nil, err
@@ -803,3 +1086,130 @@ func (r *OrderRepository) GetOrderStatistics(ctx context.Context, startDate, end
 
 	return stats, nil
 }
+
+// updateDeliveryAddress replaces the address snapshot of an order and refreshes
+// the snapshot of its deliveries that have not been picked up yet
+func (r *OrderRepository) updateDeliveryAddress(ctx context.Context, tx *sqlx.Tx, order *model.Order, req *model.UpdateOrderRequest) error {
+	err := tx.QueryRowContext(ctx, `
+		UPDATE orders SET
+			customer_address_id = (SELECT id FROM customer_addresses WHERE public_id::text = $1),
+			delivery_address = NULLIF($2, ''), delivery_ward = NULLIF($3, ''), delivery_district = NULLIF($4, ''),
+			delivery_province = NULLIF($5, ''), delivery_latitude = $6, delivery_longitude = $7
+		WHERE id = $8
+		RETURNING delivery_address, delivery_ward, delivery_district, delivery_province, delivery_latitude, delivery_longitude
+	`, req.CustomerAddressID, *req.DeliveryAddress, req.DeliveryWard, req.DeliveryDistrict, req.DeliveryProvince,
+		req.DeliveryLatitude, req.DeliveryLongitude, order.ID,
+	).Scan(&order.DeliveryAddress, &order.DeliveryWard, &order.DeliveryDistrict, &order.DeliveryProvince, &order.DeliveryLatitude, &order.DeliveryLongitude)
+	if err != nil {
+		return err
+	}
+	order.CustomerAddressID = req.CustomerAddressID
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE delivery_orders d SET delivery_address = `+addressSnapshotSQL+`, updated_at = CURRENT_TIMESTAMP
+		FROM orders o
+		WHERE o.id = d.order_id AND d.order_id = $1 AND d.status IN ('pending', 'assigned')
+	`, order.ID)
+	return err
+}
+
+// requoteShippingFee recalculates the zone shipping fee of an edited order from
+// its current subtotal (free shipping threshold). Overridden fees are kept; an
+// order without a quote keeps its fee as a manual one. Fee changes are audited.
+func (r *OrderRepository) requoteShippingFee(ctx context.Context, tx *sqlx.Tx, orderID int64, quote *model.ShippingQuote, userID int64) error {
+	var previousFee, subtotal float64
+	var source string
+	err := tx.QueryRowContext(ctx, "SELECT shipping_fee, shipping_fee_source, subtotal FROM orders WHERE id = $1 FOR UPDATE", orderID).Scan(&previousFee, &source, &subtotal)
+	if err != nil {
+		return err
+	}
+	if source == model.ShippingFeeSourceOverride {
+		return nil
+	}
+	if quote == nil {
+		_, err = tx.ExecContext(ctx, "UPDATE orders SET shipping_fee_source = $1, delivery_distance_km = NULL WHERE id = $2", model.ShippingFeeSourceManual, orderID)
+		return err
+	}
+
+	fee := quote.Zone.Fee(quote.DistanceKm, subtotal)
+	_, err = tx.ExecContext(ctx, `
+		UPDATE orders SET shipping_fee = $1, shipping_fee_source = $2, delivery_distance_km = $3
+		WHERE id = $4
+	`, fee, model.ShippingFeeSourceAuto, quote.DistanceKm, orderID)
+	if err != nil {
+		return err
+	}
+	if roundMoney(fee) == roundMoney(previousFee) {
+		return nil
+	}
+	return r.recordShippingFeeAudit(ctx, tx, orderID, &fee, &previousFee, fee, "Tính lại phí giao hàng khi sửa đơn", userID)
+}
+
+// recordShippingFeeAudit logs a change of the shipping fee of an order
+func (r *OrderRepository) recordShippingFeeAudit(ctx context.Context, q sqlx.ExecerContext, orderID int64, calculatedFee, previousFee *float64, appliedFee float64, reason string, userID int64) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO shipping_fee_audits (order_id, calculated_fee, previous_fee, applied_fee, reason, changed_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, orderID, calculatedFee, previousFee, appliedFee, reason, userID)
+	return err
+}
+
+// UpdateShippingFee overrides the shipping fee of an order, recalculates its total and logs the change
+func (r *OrderRepository) UpdateShippingFee(ctx context.Context, publicID string, fee float64, calculatedFee *float64, reason string, userID int64) (*model.Order, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var orderID int64
+	var previousFee float64
+	err = tx.QueryRowContext(ctx, "SELECT id, shipping_fee FROM orders WHERE public_id = $1 FOR UPDATE", publicID).Scan(&orderID, &previousFee)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE orders
+		SET shipping_fee = $1, shipping_fee_source = $2,
+			total_amount = GREATEST(0, subtotal - discount_amount - manual_discount_amount - promotion_discount_amount - loyalty_discount_amount + $1),
+			updated_by = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+	`, fee, model.ShippingFeeSourceOverride, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.recordShippingFeeAudit(ctx, tx, orderID, calculatedFee, &previousFee, fee, reason, userID); err != nil {
+		return nil, err
+	}
+
+	// Tổng tiền thay đổi nên trạng thái thanh toán có thể thay đổi theo
+	if _, err = r.paymentRepo.syncPaymentStatus(ctx, tx, orderID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.GetOrderByID(ctx, publicID)
+}
+
+// ListShippingFeeAudits lists the shipping fee changes of an order, newest first
+func (r *OrderRepository) ListShippingFeeAudits(ctx context.Context, publicID string) ([]model.ShippingFeeAudit, error) {
+	audits := []model.ShippingFeeAudit{}
+	query := `
+		SELECT a.*
+		FROM shipping_fee_audits a
+		JOIN orders o ON o.id = a.order_id
+		WHERE o.public_id = $1
+		ORDER BY a.created_at DESC, a.id DESC`
+	if err := r.db.SelectContext(ctx, &audits, query, publicID); err != nil {
+		return nil, err
+	}
+	return audits, nil
+}
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type OTPRepository struct {
+	db *sqlx.DB
+}
+
+func NewOTPRepository(db *sqlx.DB) *OTPRepository {
+	return &OTPRepository{db: db}
+}
+
+// GetSendStats gets how long ago the last code was sent to a phone (nil if never)
+// and how many codes were sent to it within the window
+func (r *OTPRepository) GetSendStats(ctx context.Context, purpose, phone string, window time.Duration) (*time.Duration, int, error) {
+	var stats struct {
+		SecondsSinceLast *float64 `db:"seconds_since_last"`
+		SentCount        int      `db:"sent_count"`
+	}
+	err := r.db.GetContext(ctx, &stats, `
+		SELECT EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - MAX(created_at))::FLOAT8 AS seconds_since_last,
+			COUNT(*) FILTER (WHERE created_at >= CURRENT_TIMESTAMP - make_interval(secs => $3)) AS sent_count
+		FROM otp_codes
+		WHERE purpose = $1 AND phone = $2
+	`, purpose, phone, window.Seconds())
+	if err != nil {
+		return nil, 0, err
+	}
+	if stats.SecondsSinceLast == nil {
+		return nil, stats.SentCount, nil
+	}
+	sinceLast := time.Duration(*stats.SecondsSinceLast * float64(time.Second))
+	return &sinceLast, stats.SentCount, nil
+}
+
+// CreateCode stores a new code valid for ttl and invalidates the earlier unused codes of the phone
+func (r *OTPRepository) CreateCode(ctx context.Context, code *model.OTPCode, ttl time.Duration) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE otp_codes SET expires_at = CURRENT_TIMESTAMP
+		WHERE purpose = $1 AND phone = $2 AND consumed_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	`, code.Purpose, code.Phone)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO otp_codes (purpose, phone, code_hash, expires_at)
+		VALUES ($1, $2, $3, CURRENT_TIMESTAMP + make_interval(secs => $4))
+		RETURNING id, expires_at, created_at
+	`, code.Purpose, code.Phone, code.CodeHash, ttl.Seconds()).Scan(&code.ID, &code.ExpiresAt, &code.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetActiveCode gets the latest unused, unexpired code of a phone, nil if none
+func (r *OTPRepository) GetActiveCode(ctx context.Context, purpose, phone string) (*model.OTPCode, error) {
+	var code model.OTPCode
+	err := r.db.GetContext(ctx, &code, `
+		SELECT * FROM otp_codes
+		WHERE purpose = $1 AND phone = $2 AND consumed_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, purpose, phone)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// RecordAttempt counts a verification attempt; returns false when the code has
+// no attempts left, so concurrent guesses cannot exceed the limit
+func (r *OTPRepository) RecordAttempt(ctx context.Context, codeID int64, maxAttempts int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE otp_codes SET attempts = attempts + 1
+		WHERE id = $1 AND attempts < $2 AND consumed_at IS NULL
+	`, codeID, maxAttempts)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
+
+// ConsumeCode marks a code as used; returns false if it was already used
+func (r *OTPRepository) ConsumeCode(ctx context.Context, codeID int64) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE otp_codes SET consumed_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND consumed_at IS NULL
+	`, codeID)
+	if err != nil {
+		return false, err
+	}
+	rows, err := result.RowsAffected()
+	return rows > 0, err
+}
@@ -0,0 +1,589 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PaymentRepository struct {
+	db *sqlx.DB
+}
+
+func NewPaymentRepository(db *sqlx.DB) *PaymentRepository {
+	return &PaymentRepository{db: db}
+}
+
+// pendingPaymentHold is how long a gateway checkout that has not called back
+// yet holds its amount against the order balance. Gateway checkouts expire
+// well within it, a later success is flagged for refund if the order was paid
+// in the meantime.
+const pendingPaymentHold = 30 * time.Minute
+
+const paymentSelectQuery = `
+	SELECT p.id, p.public_id, p.order_id, o.public_id AS order_public_id, p.payment_type, p.payment_method, p.amount, p.status,
+		p.reference, p.provider_transaction_id, p.pay_url, p.refund_of_id, rp.public_id AS refund_of_public_id, p.reason, p.notes,
+		p.needs_refund, p.created_by, p.created_at, p.updated_at,
+		COALESCE((
+			SELECT SUM(r.amount) FROM payments r
+			WHERE r.refund_of_id = p.id AND r.status = 'completed'
+		), 0) AS refunded_amount
+	FROM payments p
+	JOIN orders o ON p.order_id = o.id
+	LEFT JOIN payments rp ON p.refund_of_id = rp.id
+`
+
+// ListPaymentMethods lists payment methods, optionally only the active ones
+func (r *PaymentRepository) ListPaymentMethods(ctx context.Context, activeOnly bool) ([]*model.PaymentMethod, error) {
+	query := `
+		SELECT id, code, name, sort_order, is_active, created_at, updated_at
+		FROM payment_methods
+	`
+	if activeOnly {
+		query += " WHERE is_active = true"
+	}
+	query += " ORDER BY sort_order ASC, id ASC"
+
+	var methods []*model.PaymentMethod
+	if err := r.db.SelectContext(ctx, &methods, query); err != nil {
+		return nil, err
+	}
+	return methods, nil
+}
+
+// GetPaymentByPublicID gets a payment by public ID, returns nil if not found
+func (r *PaymentRepository) GetPaymentByPublicID(ctx context.Context, publicID string) (*model.Payment, error) {
+	var payment model.Payment
+	err := r.db.GetContext(ctx, &payment, paymentSelectQuery+" WHERE p.public_id = $1", publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetOrderPaymentSummary gets the payments ledger and outstanding balance of an order,
+// returns nil if the order does not exist
+func (r *PaymentRepository) GetOrderPaymentSummary(ctx context.Context, orderPublicID string) (*model.OrderPaymentSummary, error) {
+	var orderID int64
+	err := r.db.QueryRowContext(ctx, "SELECT id FROM orders WHERE public_id = $1", orderPublicID).Scan(&orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.getOrderPaymentSummary(ctx, r.db, orderID)
+}
+
+// RecordPayments records one or more tenders against an order and re-derives its payment status
+func (r *PaymentRepository) RecordPayments(ctx context.Context, orderPublicID string, req *model.RecordPaymentRequest, userID int64) (*model.OrderPaymentSummary, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the order so concurrent tenders cannot overpay it
+	var orderID int64
+	var status model.OrderStatus
+	err = tx.QueryRowContext(ctx, "SELECT id, status FROM orders WHERE public_id = $1 FOR UPDATE", orderPublicID).Scan(&orderID, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, err
+	}
+	if status == model.OrderStatusCancelled {
+		return nil, model.NewValidationError("order", "Không thể thanh toán đơn hàng đã hủy")
+	}
+
+	summary, err := r.getOrderPaymentSummary(ctx, tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	var tendered float64
+	for _, tender := range req.Tenders {
+		tendered += tender.Amount
+	}
+	if err = checkPayableAmount(summary, tendered); err != nil {
+		return nil, err
+	}
+
+	for _, tender := range req.Tenders {
+		var isActive bool
+		err = tx.QueryRowContext(ctx, "SELECT is_active FROM payment_methods WHERE code = $1", tender.PaymentMethod).Scan(&isActive)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, model.NewValidationError("payment_method", "Phương thức thanh toán không hợp lệ: "+tender.PaymentMethod)
+			}
+			return nil, err
+		}
+		if !isActive {
+			return nil, model.NewValidationError("payment_method", "Phương thức thanh toán đã ngừng sử dụng: "+tender.PaymentMethod)
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO payments (order_id, payment_type, payment_method, amount, status, reference, notes, created_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, orderID, model.PaymentTypePayment, tender.PaymentMethod, roundMoney(tender.Amount), model.PaymentTransactionCompleted, tender.Reference, req.Notes, userID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err = r.syncPaymentMethod(ctx, tx, orderID); err != nil {
+		return nil, err
+	}
+	if _, err = r.syncPaymentStatus(ctx, tx, orderID); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.getOrderPaymentSummary(ctx, r.db, orderID)
+}
+
+// RefundPayment records a full or partial refund of a payment. Manual refunds are
+// recorded completed; gateway refunds are recorded pending before the gateway is
+// called, so they hold the refundable amount until CompleteRefund settles them
+func (r *PaymentRepository) RefundPayment(ctx context.Context, paymentPublicID string, req *model.RefundPaymentRequest, status string, userID int64) (*model.Payment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var payment model.Payment
+	err = tx.GetContext(ctx, &payment, paymentSelectQuery+" WHERE p.public_id = $1", paymentPublicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("payment not found")
+		}
+		return nil, err
+	}
+
+	// Lock the order before the payment, in the same order as the other payment writes
+	if _, err = tx.ExecContext(ctx, "SELECT id FROM orders WHERE id = $1 FOR UPDATE", payment.OrderID); err != nil {
+		return nil, err
+	}
+	if err = tx.QueryRowContext(ctx, "SELECT status FROM payments WHERE id = $1 FOR UPDATE", payment.ID).Scan(&payment.Status); err != nil {
+		return nil, err
+	}
+	if payment.PaymentType != model.PaymentTypePayment || payment.Status != model.PaymentTransactionCompleted {
+		return nil, model.NewValidationError("payment", "Chỉ có thể hoàn tiền cho giao dịch thanh toán đã hoàn tất")
+	}
+
+	// Refunds still in progress at the gateway count as refunded
+	var refunded float64
+	err = tx.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM payments
+		WHERE refund_of_id = $1 AND status IN ('completed', 'pending')
+	`, payment.ID).Scan(&refunded)
+	if err != nil {
+		return nil, err
+	}
+
+	refundable := roundMoney(payment.Amount - refunded)
+	if refundable <= 0 {
+		return nil, model.NewValidationError("amount", "Giao dịch đã được hoàn tiền toàn bộ")
+	}
+	amount := refundable
+	if req.Amount != nil {
+		amount = roundMoney(*req.Amount)
+	}
+	if amount > refundable {
+		return nil, model.NewValidationError("amount", fmt.Sprintf("Số tiền hoàn vượt quá số tiền có thể hoàn (%.2f)", refundable))
+	}
+
+	var refundID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO payments (order_id, payment_type, payment_method, amount, status, refund_of_id, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, payment.OrderID, model.PaymentTypeRefund, payment.PaymentMethod, amount, status, payment.ID, req.Reason, userID).Scan(&refundID)
+	if err != nil {
+		return nil, err
+	}
+	if status == model.PaymentTransactionCompleted {
+		if err = r.settleRefund(ctx, tx, payment.OrderID, payment.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	var refund model.Payment
+	if err = r.db.GetContext(ctx, &refund, paymentSelectQuery+" WHERE p.id = $1", refundID); err != nil {
+		return nil, err
+	}
+	return &refund, nil
+}
+
+// CompleteRefund settles a pending gateway refund as completed or failed.
+// reference is the gateway refund ID
+func (r *PaymentRepository) CompleteRefund(ctx context.Context, refundID int64, success bool, reference *string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var orderID int64
+	var refundOfID *int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT o.id, p.refund_of_id FROM orders o JOIN payments p ON p.order_id = o.id
+		WHERE p.id = $1
+		FOR UPDATE OF o
+	`, refundID).Scan(&orderID, &refundOfID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("payment not found")
+		}
+		return err
+	}
+
+	status := model.PaymentTransactionFailed
+	if success {
+		status = model.PaymentTransactionCompleted
+	}
+	result, err := tx.ExecContext(ctx, `
+		UPDATE payments SET status = $1, reference = COALESCE($2, reference), updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND payment_type = 'refund' AND status = 'pending'
+	`, status, reference, refundID)
+	if err != nil {
+		return err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return model.NewValidationError("payment", "Giao dịch hoàn tiền đã được xử lý")
+	}
+	if success && refundOfID != nil {
+		if err = r.settleRefund(ctx, tx, orderID, *refundOfID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// settleRefund clears the refund flag of the refunded payment and re-derives the order payment status
+func (r *PaymentRepository) settleRefund(ctx context.Context, tx *sqlx.Tx, orderID, paymentID int64) error {
+	_, err := tx.ExecContext(ctx, "UPDATE payments SET needs_refund = false, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND needs_refund", paymentID)
+	if err != nil {
+		return err
+	}
+	_, err = r.syncPaymentStatus(ctx, tx, orderID)
+	return err
+}
+
+// CreatePendingPayment records a gateway payment awaiting its callback. amount nil pays the outstanding balance
+func (r *PaymentRepository) CreatePendingPayment(ctx context.Context, orderPublicID, paymentMethod string, amount *float64, reference string, userID int64) (*model.Payment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var orderID int64
+	var status model.OrderStatus
+	err = tx.QueryRowContext(ctx, "SELECT id, status FROM orders WHERE public_id = $1 FOR UPDATE", orderPublicID).Scan(&orderID, &status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, err
+	}
+	if status == model.OrderStatusCancelled {
+		return nil, model.NewValidationError("order", "Không thể thanh toán đơn hàng đã hủy")
+	}
+
+	summary, err := r.getOrderPaymentSummary(ctx, tx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if summary.Balance <= 0 {
+		return nil, model.NewValidationError("amount", "Đơn hàng đã được thanh toán đủ")
+	}
+	payAmount := math.Max(roundMoney(summary.Balance-summary.PendingAmount), 0)
+	if amount != nil {
+		payAmount = roundMoney(*amount)
+	}
+	if err = checkPayableAmount(summary, payAmount); err != nil {
+		return nil, err
+	}
+	if payAmount <= 0 {
+		return nil, model.NewValidationError("amount", fmt.Sprintf("Đơn hàng đang chờ thanh toán online %.2f, vui lòng đợi giao dịch hoàn tất", summary.PendingAmount))
+	}
+
+	var paymentID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO payments (order_id, payment_type, payment_method, amount, status, reference, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`, orderID, model.PaymentTypePayment, paymentMethod, payAmount, model.PaymentTransactionPending, reference, userID).Scan(&paymentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	var payment model.Payment
+	if err = r.db.GetContext(ctx, &payment, paymentSelectQuery+" WHERE p.id = $1", paymentID); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// SetPayURL stores the gateway checkout URL of a pending payment
+func (r *PaymentRepository) SetPayURL(ctx context.Context, paymentID int64, payURL string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE payments SET pay_url = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", payURL, paymentID)
+	return err
+}
+
+// GetPaymentByReference gets a gateway payment by method and reference, returns nil if not found
+func (r *PaymentRepository) GetPaymentByReference(ctx context.Context, paymentMethod, reference string) (*model.Payment, error) {
+	var payment model.Payment
+	err := r.db.GetContext(ctx, &payment, paymentSelectQuery+" WHERE p.payment_method = $1 AND p.reference = $2 AND p.payment_type = 'payment'", paymentMethod, reference)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// CompletePayment settles a pending gateway payment as completed or failed. Payments
+// that are already settled are returned unchanged, so repeated IPNs are harmless.
+// A success for more than the order balance left is flagged for refund.
+func (r *PaymentRepository) CompletePayment(ctx context.Context, paymentID int64, success bool, providerTxnID *string) (*model.Payment, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the order before the payment, in the same order as the other payment writes
+	var orderID int64
+	err = tx.QueryRowContext(ctx, `
+		SELECT o.id FROM orders o JOIN payments p ON p.order_id = o.id
+		WHERE p.id = $1
+		FOR UPDATE OF o
+	`, paymentID).Scan(&orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("payment not found")
+		}
+		return nil, err
+	}
+
+	var status string
+	var amount float64
+	err = tx.QueryRowContext(ctx, "SELECT status, amount FROM payments WHERE id = $1 FOR UPDATE", paymentID).Scan(&status, &amount)
+	if err != nil {
+		return nil, err
+	}
+
+	var balance float64
+	if status == model.PaymentTransactionPending && success {
+		summary, err := r.getOrderPaymentSummary(ctx, tx, orderID)
+		if err != nil {
+			return nil, err
+		}
+		balance = summary.Balance
+	}
+
+	settlement := settleGatewayPayment(status, success, amount, balance)
+	if settlement.changed {
+		if settlement.needsRefund {
+			log.Printf("[Payment] Payment %d of %.2f exceeds the order balance %.2f, flagged for refund", paymentID, amount, balance)
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE payments SET status = $1, needs_refund = $2, provider_transaction_id = COALESCE($3, provider_transaction_id), updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4
+		`, settlement.status, settlement.needsRefund, providerTxnID, paymentID)
+		if err != nil {
+			return nil, err
+		}
+		if settlement.status == model.PaymentTransactionCompleted {
+			if err = r.syncPaymentMethod(ctx, tx, orderID); err != nil {
+				return nil, err
+			}
+			if _, err = r.syncPaymentStatus(ctx, tx, orderID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	var payment model.Payment
+	if err = r.db.GetContext(ctx, &payment, paymentSelectQuery+" WHERE p.id = $1", paymentID); err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// syncPaymentMethod keeps orders.payment_method as the single tender method, or "split"
+func (r *PaymentRepository) syncPaymentMethod(ctx context.Context, q sqlx.ExecerContext, orderID int64) error {
+	_, err := q.ExecContext(ctx, `
+		UPDATE orders SET payment_method = (
+			SELECT CASE WHEN COUNT(DISTINCT payment_method) > 1 THEN 'split' ELSE MIN(payment_method) END
+			FROM payments WHERE order_id = $1 AND payment_type = 'payment' AND status = 'completed'
+		)
+		WHERE id = $1
+	`, orderID)
+	return err
+}
+
+// syncPaymentStatus derives orders.payment_status from the payments ledger
+func (r *PaymentRepository) syncPaymentStatus(ctx context.Context, q sqlx.ExtContext, orderID int64) (model.PaymentStatus, error) {
+	var total, paid, refunded float64
+	err := q.QueryRowxContext(ctx, `
+		SELECT o.total_amount,
+			COALESCE(SUM(p.amount) FILTER (WHERE p.payment_type = 'payment'), 0),
+			COALESCE(SUM(p.amount) FILTER (WHERE p.payment_type = 'refund'), 0)
+		FROM orders o
+		LEFT JOIN payments p ON p.order_id = o.id AND p.status = 'completed'
+		WHERE o.id = $1
+		GROUP BY o.id
+	`, orderID).Scan(&total, &paid, &refunded)
+	if err != nil {
+		return "", err
+	}
+
+	status := derivePaymentStatus(total, paid, refunded)
+	_, err = q.ExecContext(ctx, "UPDATE orders SET payment_status = $1 WHERE id = $2 AND payment_status IS DISTINCT FROM $1", status, orderID)
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+func (r *PaymentRepository) getOrderPaymentSummary(ctx context.Context, q sqlx.QueryerContext, orderID int64) (*model.OrderPaymentSummary, error) {
+	summary := &model.OrderPaymentSummary{}
+	err := q.QueryRowxContext(ctx, `
+		SELECT public_id, order_number, total_amount, payment_status
+		FROM orders WHERE id = $1
+	`, orderID).Scan(&summary.OrderID, &summary.OrderNumber, &summary.TotalAmount, &summary.PaymentStatus)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("order not found")
+		}
+		return nil, err
+	}
+
+	var payments []*model.Payment
+	err = sqlx.SelectContext(ctx, q, &payments, paymentSelectQuery+" WHERE p.order_id = $1 ORDER BY p.created_at ASC, p.id ASC", orderID)
+	if err != nil {
+		return nil, err
+	}
+	summary.Payments = payments
+	if summary.Payments == nil {
+		summary.Payments = []*model.Payment{}
+	}
+
+	for _, payment := range payments {
+		if payment.Status != model.PaymentTransactionCompleted {
+			continue
+		}
+		switch payment.PaymentType {
+		case model.PaymentTypePayment:
+			summary.PaidAmount += payment.Amount
+		case model.PaymentTypeRefund:
+			summary.RefundedAmount += payment.Amount
+		}
+	}
+	summary.PaidAmount = roundMoney(summary.PaidAmount)
+	summary.RefundedAmount = roundMoney(summary.RefundedAmount)
+	summary.NetPaid = roundMoney(summary.PaidAmount - summary.RefundedAmount)
+	summary.Balance = math.Max(roundMoney(summary.TotalAmount-summary.NetPaid), 0)
+
+	// Recent gateway checkouts may still be paid, older ones have expired at the gateway
+	err = q.QueryRowxContext(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM payments
+		WHERE order_id = $1 AND payment_type = 'payment' AND status = 'pending'
+			AND created_at > CURRENT_TIMESTAMP - make_interval(secs => $2)
+	`, orderID, pendingPaymentHold.Seconds()).Scan(&summary.PendingAmount)
+	if err != nil {
+		return nil, err
+	}
+	summary.PendingAmount = roundMoney(summary.PendingAmount)
+
+	return summary, nil
+}
+
+// checkPayableAmount rejects payments above the balance left once the pending
+// gateway checkouts are taken into account
+func checkPayableAmount(summary *model.OrderPaymentSummary, amount float64) error {
+	payable := math.Max(roundMoney(summary.Balance-summary.PendingAmount), 0)
+	if roundMoney(amount) <= payable {
+		return nil
+	}
+	if summary.PendingAmount > 0 {
+		return model.NewValidationError("amount", fmt.Sprintf("Đơn hàng đang chờ thanh toán online %.2f, số tiền còn có thể thanh toán là %.2f", summary.PendingAmount, payable))
+	}
+	return model.NewValidationError("amount", fmt.Sprintf("Số tiền thanh toán vượt quá số tiền còn lại (%.2f)", summary.Balance))
+}
+
+// gatewaySettlement is how a gateway callback changes a payment
+type gatewaySettlement struct {
+	changed     bool
+	status      string
+	needsRefund bool
+}
+
+// settleGatewayPayment decides how a callback settles a payment with the given
+// status. Only pending payments change. A success for more than the balance
+// left is still completed, the gateway took the money, but flagged for refund.
+func settleGatewayPayment(status string, success bool, amount, balance float64) gatewaySettlement {
+	if status != model.PaymentTransactionPending {
+		return gatewaySettlement{status: status}
+	}
+	if !success {
+		return gatewaySettlement{changed: true, status: model.PaymentTransactionFailed}
+	}
+	return gatewaySettlement{
+		changed:     true,
+		status:      model.PaymentTransactionCompleted,
+		needsRefund: roundMoney(amount) > roundMoney(balance),
+	}
+}
+
+// derivePaymentStatus maps ledger totals to an order payment status
+func derivePaymentStatus(total, paid, refunded float64) model.PaymentStatus {
+	netPaid := roundMoney(paid - refunded)
+	switch {
+	case netPaid <= 0 && refunded > 0:
+		return model.PaymentStatusRefunded
+	case netPaid <= 0:
+		return model.PaymentStatusPending
+	case netPaid >= roundMoney(total):
+		return model.PaymentStatusPaid
+	default:
+		return model.PaymentStatusPartiallyPaid
+	}
+}
+
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
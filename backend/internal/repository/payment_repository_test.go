@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"testing"
+
+	"food-pos-backend/internal/model"
+)
+
+func TestSettleGatewayPayment(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		success bool
+		amount  float64
+		balance float64
+		want    gatewaySettlement
+	}{
+		{
+			name:    "pending payment succeeds",
+			status:  model.PaymentTransactionPending,
+			success: true,
+			amount:  50000,
+			balance: 50000,
+			want:    gatewaySettlement{changed: true, status: model.PaymentTransactionCompleted},
+		},
+		{
+			name:    "pending payment fails",
+			status:  model.PaymentTransactionPending,
+			amount:  50000,
+			balance: 50000,
+			want:    gatewaySettlement{changed: true, status: model.PaymentTransactionFailed},
+		},
+		{
+			name:    "second IPN for a completed payment",
+			status:  model.PaymentTransactionCompleted,
+			success: true,
+			amount:  50000,
+			balance: 0,
+			want:    gatewaySettlement{status: model.PaymentTransactionCompleted},
+		},
+		{
+			name:   "failure IPN after completion",
+			status: model.PaymentTransactionCompleted,
+			amount: 50000,
+			want:   gatewaySettlement{status: model.PaymentTransactionCompleted},
+		},
+		{
+			name:    "success IPN after failure",
+			status:  model.PaymentTransactionFailed,
+			success: true,
+			amount:  50000,
+			balance: 50000,
+			want:    gatewaySettlement{status: model.PaymentTransactionFailed},
+		},
+		{
+			name:    "order paid by another tender meanwhile",
+			status:  model.PaymentTransactionPending,
+			success: true,
+			amount:  50000,
+			balance: 20000,
+			want:    gatewaySettlement{changed: true, status: model.PaymentTransactionCompleted, needsRefund: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := settleGatewayPayment(tt.status, tt.success, tt.amount, tt.balance); got != tt.want {
+				t.Fatalf("settleGatewayPayment() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPayableAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		balance float64
+		pending float64
+		amount  float64
+		wantErr bool
+	}{
+		{name: "full balance", balance: 100000, amount: 100000},
+		{name: "above balance", balance: 100000, amount: 100000.01, wantErr: true},
+		{name: "balance held by a pending checkout", balance: 100000, pending: 100000, amount: 100000, wantErr: true},
+		{name: "rest of a partly held balance", balance: 100000, pending: 60000, amount: 40000},
+		{name: "above the rest of a partly held balance", balance: 100000, pending: 60000, amount: 50000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			summary := &model.OrderPaymentSummary{Balance: tt.balance, PendingAmount: tt.pending}
+			err := checkPayableAmount(summary, tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkPayableAmount() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
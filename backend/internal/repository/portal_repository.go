@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type PortalRepository struct {
+	db               *sqlx.DB
+	modifierRepo     *ModifierRepository
+	categoryRepo     *CategoryRepository
+	availabilityRepo *AvailabilityRepository
+	priceListRepo    *PriceListRepository
+}
+
+func NewPortalRepository(db *sqlx.DB, location *time.Location) *PortalRepository {
+	return &PortalRepository{
+		db:               db,
+		modifierRepo:     NewModifierRepository(db),
+		categoryRepo:     NewCategoryRepository(db),
+		availabilityRepo: NewAvailabilityRepository(db),
+		priceListRepo:    NewPriceListRepository(db, location),
+	}
+}
+
+// ListMenu lists the products, variants and active modifier groups shown on
+// the customer portal, in menu order: by category, then as arranged within it
+func (r *PortalRepository) ListMenu(ctx context.Context) ([]model.PublicMenuProduct, error) {
+	var rows []struct {
+		ID int64 `db:"id"`
+		model.PublicMenuProduct
+	}
+	err := r.db.SelectContext(ctx, &rows, categoryTreeCTE+`
+		SELECT p.id, p.public_id, p.name, COALESCE(p.description, '') AS description,
+			c.public_id::text AS category_id, c.name AS category_name
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		LEFT JOIN category_tree t ON t.id = p.category_id
+		WHERE p.archived_at IS NULL
+		ORDER BY t.path ASC NULLS LAST, p.sort_order ASC, p.name ASC, p.id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []struct {
+		ProductID  int64  `db:"product_id"`
+		VariantID  int64  `db:"variant_id"`
+		CategoryID *int64 `db:"category_id"`
+		model.PublicMenuVariant
+	}
+	// Sold out variants stay on the menu so customers see when they are back
+	err = r.db.SelectContext(ctx, &variants, `
+		SELECT v.product_id, v.id AS variant_id, p.category_id,
+			v.public_id, v.name, COALESCE(v.description, '') AS description, v.price,
+			va.is_available, va.available_at
+		FROM variants v
+		JOIN products p ON v.product_id = p.id
+		JOIN (`+variantAvailabilitySelect+`) va ON va.variant_id = v.public_id::text
+		WHERE v.archived_at IS NULL
+		ORDER BY v.price ASC, v.id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	// Show the prices of the price lists in effect now. Items of a time-based
+	// menu that is closed stay listed but cannot be ordered.
+	pricer, err := r.priceListRepo.newPricer(ctx, r.db, r.priceListRepo.now())
+	if err != nil {
+		return nil, err
+	}
+	variantsByProduct := make(map[int64][]model.PublicMenuVariant)
+	for _, v := range variants {
+		price := pricer.price(variantPriceInfo{
+			VariantID:  v.VariantID,
+			ProductID:  v.ProductID,
+			CategoryID: v.CategoryID,
+			BasePrice:  v.Price,
+		})
+		v.BasePrice = v.Price
+		v.Price = price.Price
+		v.PriceListName = price.PriceListName
+		v.IsAvailable = v.IsAvailable && price.IsOrderable
+		variantsByProduct[v.ProductID] = append(variantsByProduct[v.ProductID], v.PublicMenuVariant)
+	}
+
+	menu := make([]model.PublicMenuProduct, 0, len(rows))
+	for _, row := range rows {
+		// Món chưa có variant thì không đặt được
+		if len(variantsByProduct[row.ID]) == 0 {
+			continue
+		}
+		product := row.PublicMenuProduct
+		product.Variants = variantsByProduct[row.ID]
+
+		groups, err := r.modifierRepo.getGroupsByProductID(ctx, r.db, row.ID, true)
+		if err != nil {
+			return nil, err
+		}
+		product.ModifierGroups = make([]model.PublicModifierGroup, 0, len(groups))
+		for _, group := range groups {
+			publicGroup := model.PublicModifierGroup{
+				ID:          group.PublicID,
+				Name:        group.Name,
+				Description: group.Description,
+				MinSelect:   group.MinSelect,
+				MaxSelect:   group.MaxSelect,
+				Options:     make([]model.PublicModifierOption, 0, len(group.Options)),
+			}
+			for _, option := range group.Options {
+				publicGroup.Options = append(publicGroup.Options, model.PublicModifierOption{
+					ID:         option.PublicID,
+					Name:       option.Name,
+					PriceDelta: option.PriceDelta,
+					IsDefault:  option.IsDefault,
+				})
+			}
+			product.ModifierGroups = append(product.ModifierGroups, publicGroup)
+		}
+		menu = append(menu, product)
+	}
+	return menu, nil
+}
+
+// ListMenuCategories lists the menu sections as a tree, each level in sort order
+func (r *PortalRepository) ListMenuCategories(ctx context.Context) ([]*model.PublicMenuCategory, error) {
+	categories, err := r.categoryRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Categories are listed parents first, so a parent is always mapped before its children
+	byID := make(map[int64]*model.PublicMenuCategory, len(categories))
+	roots := make([]*model.PublicMenuCategory, 0)
+	for _, category := range categories {
+		section := &model.PublicMenuCategory{
+			ID:       category.PublicID,
+			Name:     category.Name,
+			Children: make([]*model.PublicMenuCategory, 0),
+		}
+		byID[category.ID] = section
+		if category.ParentID == nil {
+			roots = append(roots, section)
+		} else if parent, ok := byID[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, section)
+		}
+	}
+	return roots, nil
+}
+
+// PriceCart prices the cart lines from the variant prices in effect now and
+// the modifier options
+func (r *PortalRepository) PriceCart(ctx context.Context, items []model.CartItemRequest) ([]model.CartLine, float64, error) {
+	pricer, err := r.priceListRepo.newPricer(ctx, r.db, r.priceListRepo.now())
+	if err != nil {
+		return nil, 0, err
+	}
+	lines := make([]model.CartLine, 0, len(items))
+	subtotal := 0.0
+	for _, item := range items {
+		var variantID, productID int64
+		line := model.CartLine{VariantID: item.VariantID, Quantity: item.Quantity, Notes: item.Notes}
+		err := r.db.QueryRowContext(ctx, `
+			SELECT v.id, p.id, p.name, v.name
+			FROM variants v
+			JOIN products p ON v.product_id = p.id
+			WHERE v.public_id::text = $1
+		`, item.VariantID).Scan(&variantID, &productID, &line.ProductName, &line.VariantName)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, 0, model.NewValidationError("items", "Món không còn trong thực đơn: "+item.VariantID)
+			}
+			return nil, 0, err
+		}
+		if err = r.availabilityRepo.ensureAvailable(ctx, r.db, variantID); err != nil {
+			return nil, 0, err
+		}
+		price, err := pricer.priceVariant(ctx, r.db, variantID)
+		if err != nil {
+			return nil, 0, err
+		}
+		line.PriceListName = price.PriceListName
+
+		line.Modifiers, err = r.modifierRepo.resolveSelection(ctx, r.db, productID, item.ModifierOptionIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+		line.UnitPrice = price.Price + model.ModifiersAmount(line.Modifiers)
+		line.TotalPrice = line.UnitPrice * float64(item.Quantity)
+		subtotal += line.TotalPrice
+		lines = append(lines, line)
+	}
+	return lines, subtotal, nil
+}
+
+// TrackOrder gets the public view of an order by order number and customer phone digits, nil if there is no match
+func (r *PortalRepository) TrackOrder(ctx context.Context, orderNumber, phoneDigits string) (*model.OrderTracking, error) {
+	var orderID int64
+	tracking := &model.OrderTracking{}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id, public_id, COALESCE(created_by, 0), order_number, status, COALESCE(payment_method, ''), payment_status, subtotal,
+			discount_amount + manual_discount_amount + promotion_discount_amount + loyalty_discount_amount,
+			shipping_fee, total_amount, created_at
+		FROM orders
+		WHERE order_number = $1 AND regexp_replace(customer_phone, '\D', '', 'g') IN ($2, '84' || substring($2 from 2))
+	`, orderNumber, phoneDigits).Scan(
+		&orderID, &tracking.PublicID, &tracking.CreatedBy, &tracking.OrderNumber, &tracking.Status, &tracking.PaymentMethod, &tracking.PaymentStatus,
+		&tracking.Subtotal, &tracking.DiscountAmount, &tracking.ShippingFee, &tracking.TotalAmount, &tracking.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var items []struct {
+		ID int64 `db:"id"`
+		model.CartLine
+		Notes sql.NullString `db:"notes"`
+	}
+	err = r.db.SelectContext(ctx, &items, `
+		SELECT oi.id, v.public_id AS variant_id, oi.product_name, oi.variant_name, oi.quantity, oi.unit_price, oi.total_price, oi.notes
+		FROM order_items oi
+		JOIN variants v ON oi.variant_id = v.id
+		WHERE oi.order_id = $1
+		ORDER BY oi.id ASC
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	modifiers, err := r.modifierRepo.getOrderModifiers(ctx, r.db, orderID)
+	if err != nil {
+		return nil, err
+	}
+	tracking.Items = make([]model.CartLine, 0, len(items))
+	for _, item := range items {
+		line := item.CartLine
+		line.Notes = item.Notes.String
+		line.Modifiers = modifiers[item.ID]
+		if line.Modifiers == nil {
+			line.Modifiers = []model.OrderItemModifier{}
+		}
+		tracking.Items = append(tracking.Items, line)
+	}
+
+	// The latest online checkout the customer can still pay
+	var payURLs []string
+	err = r.db.SelectContext(ctx, &payURLs, `
+		SELECT pay_url FROM payments
+		WHERE order_id = $1 AND payment_type = 'payment' AND status = 'pending' AND pay_url IS NOT NULL
+			AND created_at > CURRENT_TIMESTAMP - make_interval(secs => $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, orderID, pendingPaymentHold.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	if len(payURLs) > 0 {
+		tracking.PayURL = &payURLs[0]
+	}
+
+	tracking.Deliveries = []model.DeliveryTracking{}
+	err = r.db.SelectContext(ctx, &tracking.Deliveries, `
+		SELECT d.delivery_number, d.status, d.estimated_delivery_time, d.actual_delivery_time,
+			s.name AS shipper_name, s.phone AS shipper_phone
+		FROM delivery_orders d
+		LEFT JOIN shippers s ON d.shipper_id = s.id
+		WHERE d.order_id = $1
+		ORDER BY d.created_at ASC, d.id ASC
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return tracking, nil
+}
@@ -0,0 +1,407 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const priceListColumns = `id, public_id::text AS public_id, name, COALESCE(description, '') AS description,
+	priority, is_exclusive, is_active, valid_from, valid_until, COALESCE(created_by, 0) AS created_by,
+	created_at, updated_at`
+
+type PriceListRepository struct {
+	db       *sqlx.DB
+	location *time.Location // Store timezone, schedules are in store local time
+}
+
+func NewPriceListRepository(db *sqlx.DB, location *time.Location) *PriceListRepository {
+	return &PriceListRepository{db: db, location: location}
+}
+
+// CreatePriceList creates a price list with its schedules and items
+func (r *PriceListRepository) CreatePriceList(ctx context.Context, req *model.CreatePriceListRequest, userID int64) (*model.PriceList, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var priceList model.PriceList
+	err = tx.GetContext(ctx, &priceList, `
+		INSERT INTO price_lists (name, description, priority, is_exclusive, valid_from, valid_until, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+priceListColumns,
+		req.Name, req.Description, req.Priority, req.IsExclusive, req.ValidFrom, req.ValidUntil, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = r.replaceRules(ctx, tx, priceList.ID, req); err != nil {
+		return nil, err
+	}
+	if err = r.loadRules(ctx, tx, &priceList); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// GetPriceListByPublicID gets a price list, nil if it does not exist
+func (r *PriceListRepository) GetPriceListByPublicID(ctx context.Context, publicID string) (*model.PriceList, error) {
+	var priceList model.PriceList
+	err := r.db.GetContext(ctx, &priceList, `SELECT `+priceListColumns+` FROM price_lists WHERE public_id::text = $1`, publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err = r.loadRules(ctx, r.db, &priceList); err != nil {
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// ListPriceLists lists price lists, highest priority first
+func (r *PriceListRepository) ListPriceLists(ctx context.Context, isActive *bool) ([]*model.PriceList, error) {
+	return r.listPriceLists(ctx, r.db, isActive)
+}
+
+// UpdatePriceList updates a price list and replaces its schedules and items,
+// nil if it does not exist
+func (r *PriceListRepository) UpdatePriceList(ctx context.Context, publicID string, req *model.UpdatePriceListRequest) (*model.PriceList, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var priceList model.PriceList
+	err = tx.GetContext(ctx, &priceList, `
+		UPDATE price_lists
+		SET name = $1, description = $2, priority = $3, is_exclusive = $4, is_active = $5,
+			valid_from = $6, valid_until = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE public_id::text = $8
+		RETURNING `+priceListColumns,
+		req.Name, req.Description, req.Priority, req.IsExclusive, req.IsActive, req.ValidFrom, req.ValidUntil, publicID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if err = r.replaceRules(ctx, tx, priceList.ID, &req.CreatePriceListRequest); err != nil {
+		return nil, err
+	}
+	if err = r.loadRules(ctx, tx, &priceList); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &priceList, nil
+}
+
+// DeletePriceList deletes a price list. Order lines keep its name.
+func (r *PriceListRepository) DeletePriceList(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM price_lists WHERE id = $1`, id)
+	return err
+}
+
+// MenuAt prices every variant as it would be sold at the given time
+func (r *PriceListRepository) MenuAt(ctx context.Context, at time.Time) ([]*model.MenuPrice, error) {
+	pricer, err := r.newPricer(ctx, r.db, at)
+	if err != nil {
+		return nil, err
+	}
+
+	var variants []struct {
+		variantPriceInfo
+		VariantPublicID string `db:"variant_public_id"`
+		ProductPublicID string `db:"product_public_id"`
+		ProductName     string `db:"product_name"`
+		VariantName     string `db:"variant_name"`
+	}
+	err = r.db.SelectContext(ctx, &variants, `
+		SELECT v.id AS variant_id, p.id AS product_id, p.category_id, v.price,
+			v.public_id::text AS variant_public_id, p.public_id::text AS product_public_id,
+			p.name AS product_name, v.name AS variant_name
+		FROM variants v
+		JOIN products p ON v.product_id = p.id
+		WHERE v.archived_at IS NULL AND p.archived_at IS NULL
+		ORDER BY p.name ASC, v.price ASC, v.id ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	menu := make([]*model.MenuPrice, 0, len(variants))
+	for _, variant := range variants {
+		price := pricer.price(variant.variantPriceInfo)
+		price.VariantID = variant.VariantPublicID
+		price.ProductID = variant.ProductPublicID
+		price.ProductName = variant.ProductName
+		price.VariantName = variant.VariantName
+		menu = append(menu, price)
+	}
+	return menu, nil
+}
+
+// now is the current time in the store timezone
+func (r *PriceListRepository) now() time.Time {
+	return time.Now().In(r.location)
+}
+
+// variantPriceInfo is what pricing needs to know about a variant
+type variantPriceInfo struct {
+	VariantID  int64   `db:"variant_id"`
+	ProductID  int64   `db:"product_id"`
+	CategoryID *int64  `db:"category_id"`
+	BasePrice  float64 `db:"price"`
+}
+
+// pricer resolves variant prices at one point in time. It loads the active
+// price lists once so an order with many lines is priced consistently.
+type pricer struct {
+	at              time.Time
+	lists           []*model.PriceList // Highest priority first
+	categoryParents map[int64]*int64
+}
+
+// newPricer loads what is needed to price variants at the given time
+func (r *PriceListRepository) newPricer(ctx context.Context, q sqlx.QueryerContext, at time.Time) (*pricer, error) {
+	active := true
+	lists, err := r.listPriceLists(ctx, q, &active)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []struct {
+		ID       int64  `db:"id"`
+		ParentID *int64 `db:"parent_id"`
+	}
+	if err = sqlx.SelectContext(ctx, q, &categories, `SELECT id, parent_id FROM categories`); err != nil {
+		return nil, err
+	}
+	parents := make(map[int64]*int64, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	return &pricer{at: at.In(r.location), lists: lists, categoryParents: parents}, nil
+}
+
+// priceVariant prices a variant and rejects it while it is only sold on a
+// time-based menu that is closed
+func (p *pricer) priceVariant(ctx context.Context, q sqlx.QueryerContext, variantID int64) (*model.MenuPrice, error) {
+	var variant struct {
+		variantPriceInfo
+		ProductName string `db:"product_name"`
+		VariantName string `db:"variant_name"`
+	}
+	err := sqlx.GetContext(ctx, q, &variant, `
+		SELECT v.id AS variant_id, p.id AS product_id, p.category_id, v.price,
+			p.name AS product_name, v.name AS variant_name
+		FROM variants v
+		JOIN products p ON v.product_id = p.id
+		WHERE v.id = $1
+	`, variantID)
+	if err != nil {
+		return nil, err
+	}
+	price := p.price(variant.variantPriceInfo)
+	if !price.IsOrderable {
+		return nil, model.NewValidationError("items", "Món chỉ bán theo khung giờ, hiện chưa mở bán: "+variant.ProductName+" - "+variant.VariantName)
+	}
+	return price, nil
+}
+
+// price applies the highest priority price list in effect that covers the
+// variant. A variant covered by an exclusive list can only be ordered while
+// one of its exclusive lists is in effect.
+func (p *pricer) price(variant variantPriceInfo) *model.MenuPrice {
+	price := &model.MenuPrice{BasePrice: variant.BasePrice, Price: variant.BasePrice, IsOrderable: true}
+
+	// Category of the product first, then its ancestors
+	categories := make([]int64, 0)
+	for id := variant.CategoryID; id != nil && len(categories) <= len(p.categoryParents); id = p.categoryParents[*id] {
+		categories = append(categories, *id)
+	}
+
+	exclusive, exclusiveOpen := false, false
+	applied := false
+	for _, list := range p.lists {
+		item := matchPriceListItem(list, variant, categories)
+		if item == nil || !list.InDateRange(p.at) {
+			continue
+		}
+		inEffect := list.InEffect(p.at)
+		if list.IsExclusive {
+			exclusive = true
+			exclusiveOpen = exclusiveOpen || inEffect
+		}
+		if inEffect && !applied {
+			applied = true
+			price.Price = roundMoney(item.Apply(variant.BasePrice))
+			price.PriceListDBID = &list.ID
+			price.PriceListID = &list.PublicID
+			price.PriceListName = &list.Name
+		}
+	}
+	price.IsOrderable = !exclusive || exclusiveOpen
+	return price
+}
+
+// matchPriceListItem returns the most specific item of a list covering the
+// variant: the variant itself, then its product, then the nearest category
+func matchPriceListItem(list *model.PriceList, variant variantPriceInfo, categories []int64) *model.PriceListItem {
+	var productItem, categoryItem *model.PriceListItem
+	categoryDepth := len(categories)
+	for i := range list.Items {
+		item := &list.Items[i]
+		switch {
+		case item.VariantDBID != nil && *item.VariantDBID == variant.VariantID:
+			return item
+		case item.ProductDBID != nil && *item.ProductDBID == variant.ProductID:
+			productItem = item
+		case item.CategoryDBID != nil:
+			for depth, categoryID := range categories {
+				if categoryID == *item.CategoryDBID && depth < categoryDepth {
+					categoryItem = item
+					categoryDepth = depth
+				}
+			}
+		}
+	}
+	if productItem != nil {
+		return productItem
+	}
+	return categoryItem
+}
+
+func (r *PriceListRepository) listPriceLists(ctx context.Context, q sqlx.QueryerContext, isActive *bool) ([]*model.PriceList, error) {
+	query := `SELECT ` + priceListColumns + ` FROM price_lists`
+	args := []any{}
+	if isActive != nil {
+		query += ` WHERE is_active = $1`
+		args = append(args, *isActive)
+	}
+	query += ` ORDER BY priority DESC, id ASC`
+
+	priceLists := make([]*model.PriceList, 0)
+	if err := sqlx.SelectContext(ctx, q, &priceLists, query, args...); err != nil {
+		return nil, err
+	}
+	for _, priceList := range priceLists {
+		if err := r.loadRules(ctx, q, priceList); err != nil {
+			return nil, err
+		}
+	}
+	return priceLists, nil
+}
+
+// loadRules loads the schedules and items of a price list
+func (r *PriceListRepository) loadRules(ctx context.Context, q sqlx.QueryerContext, priceList *model.PriceList) error {
+	priceList.Schedules = make([]model.PriceListSchedule, 0)
+	err := sqlx.SelectContext(ctx, q, &priceList.Schedules, `
+		SELECT id, price_list_id, days_mask,
+			to_char(start_time, 'HH24:MI') AS start_time, to_char(end_time, 'HH24:MI') AS end_time
+		FROM price_list_schedules
+		WHERE price_list_id = $1
+		ORDER BY id ASC
+	`, priceList.ID)
+	if err != nil {
+		return err
+	}
+	for i := range priceList.Schedules {
+		priceList.Schedules[i].DaysOfWeek = model.DaysOfWeekFromMask(priceList.Schedules[i].DaysMask)
+	}
+
+	priceList.Items = make([]model.PriceListItem, 0)
+	return sqlx.SelectContext(ctx, q, &priceList.Items, `
+		SELECT i.id, i.price_list_id, i.variant_id, i.product_id, i.category_id,
+			v.public_id::text AS variant_public_id, p.public_id::text AS product_public_id,
+			c.public_id::text AS category_public_id,
+			COALESCE(vp.name || ' - ' || v.name, p.name, c.name, '') AS target_name,
+			i.price, i.discount_percent
+		FROM price_list_items i
+		LEFT JOIN variants v ON i.variant_id = v.id
+		LEFT JOIN products vp ON v.product_id = vp.id
+		LEFT JOIN products p ON i.product_id = p.id
+		LEFT JOIN categories c ON i.category_id = c.id
+		WHERE i.price_list_id = $1
+		ORDER BY i.id ASC
+	`, priceList.ID)
+}
+
+// replaceRules replaces the schedules and items of a price list
+func (r *PriceListRepository) replaceRules(ctx context.Context, tx *sqlx.Tx, priceListID int64, req *model.CreatePriceListRequest) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM price_list_schedules WHERE price_list_id = $1`, priceListID); err != nil {
+		return err
+	}
+	for _, schedule := range req.Schedules {
+		mask, err := model.DaysOfWeekMask(schedule.DaysOfWeek)
+		if err != nil {
+			return model.NewValidationError("schedules", "Ngày trong tuần không hợp lệ")
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO price_list_schedules (price_list_id, days_mask, start_time, end_time)
+			VALUES ($1, $2, $3::time, $4::time)
+		`, priceListID, mask, schedule.StartTime, schedule.EndTime)
+		if err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM price_list_items WHERE price_list_id = $1`, priceListID); err != nil {
+		return err
+	}
+	for _, item := range req.Items {
+		var variantID, productID, categoryID *int64
+		var err error
+		switch {
+		case item.VariantID != nil:
+			variantID, err = resolvePriceListTarget(ctx, tx, "variants", *item.VariantID, "Variant không tồn tại: ")
+		case item.ProductID != nil:
+			productID, err = resolvePriceListTarget(ctx, tx, "products", *item.ProductID, "Sản phẩm không tồn tại: ")
+		case item.CategoryID != nil:
+			categoryID, err = resolvePriceListTarget(ctx, tx, "categories", *item.CategoryID, "Danh mục không tồn tại: ")
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO price_list_items (price_list_id, variant_id, product_id, category_id, price, discount_percent)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, priceListID, variantID, productID, categoryID, item.Price, item.DiscountPercent)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvePriceListTarget gets the internal ID of the variant, product or
+// category a price list item points at
+func resolvePriceListTarget(ctx context.Context, tx *sqlx.Tx, table, publicID, notFound string) (*int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM `+table+` WHERE public_id::text = $1`, publicID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.NewValidationError("items", notFound+publicID)
+		}
+		return nil, err
+	}
+	return &id, nil
+}
@@ -7,12 +7,19 @@ import (
 	"food-pos-backend/internal/model"
 	"math"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
 )
 
+// productCategoryReturning returns the category columns of a product written by an INSERT or UPDATE
+const productCategoryReturning = `category_id,
+	(SELECT public_id::text FROM categories WHERE id = category_id),
+	(SELECT name FROM categories WHERE id = category_id),
+	sort_order`
+
 type ProductRepository struct {
 	db *sqlx.DB
 }
@@ -23,7 +30,7 @@ func NewProductRepository(db *sqlx.DB) *ProductRepository {
 	}
 }
 
-func (r *ProductRepository) CreateProduct(ctx context.Context, req *model.CreateProductRequest) (*model.Product, error) {
+func (r *ProductRepository) CreateProduct(ctx context.Context, req *model.CreateProductRequest, changedBy int64) (*model.Product, error) {
 	// Start transaction
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -34,16 +41,24 @@ func (r *ProductRepository) CreateProduct(ctx context.Context, req *model.Create
 	// Create product
 	var product model.Product
 	query := `
-		INSERT INTO products (name, description, private_note)
-		VALUES ($1, $2, $3)
-		RETURNING id, public_id, name, description, private_note, created_at, updated_at
+		INSERT INTO products (name, description, private_note, category_id, sort_order)
+		SELECT $1, $2, $3, c.id, COALESCE((SELECT MAX(sort_order) FROM products WHERE category_id = c.id), 0) + 1
+		FROM (SELECT (SELECT id FROM categories WHERE public_id::text = NULLIF($4::text, '')) AS id) c
+		RETURNING id, public_id, name, description, private_note, ` + productCategoryReturning + `, is_available, available_at, archived_at, created_at, updated_at
 	`
-	err = tx.QueryRowContext(ctx, query, req.Name, req.Description, req.PrivateNote).Scan(
+	err = tx.QueryRowContext(ctx, query, req.Name, req.Description, req.PrivateNote, req.CategoryID).Scan(
 		&product.ID,
 		&product.PublicID,
 		&product.Name,
 		&product.Description,
 		&product.PrivateNote,
+		&product.CategoryID,
+		&product.CategoryPublicID,
+		&product.CategoryName,
+		&product.SortOrder,
+		&product.IsAvailable,
+		&product.AvailableAt,
+		&product.ArchivedAt,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 	)
@@ -58,7 +73,7 @@ func (r *ProductRepository) CreateProduct(ctx context.Context, req *model.Create
 		variantQuery := `
 			INSERT INTO variants (product_id, name, description, private_note, price)
 			VALUES ($1, $2, $3, $4, $5)
-			RETURNING id, public_id, product_id, name, description, private_note, price, created_at, updated_at
+			RETURNING id, public_id, product_id, name, description, private_note, price, is_available, available_at, auto_availability, created_at, updated_at
 		`
 		err = tx.QueryRowContext(ctx, variantQuery, product.ID, variantReq.Name, variantReq.Description, variantReq.PrivateNote, variantReq.Price).Scan(
 			&variant.ID,
@@ -68,12 +83,18 @@ func (r *ProductRepository) CreateProduct(ctx context.Context, req *model.Create
 			&variant.Description,
 			&variant.PrivateNote,
 			&variant.Price,
+			&variant.IsAvailable,
+			&variant.AvailableAt,
+			&variant.AutoAvailability,
 			&variant.CreatedAt,
 			&variant.UpdatedAt,
 		)
 		if err != nil {
 			return nil, err
 		}
+		if err = recordPriceChange(ctx, tx, variant.ID, nil, variant.Price, changedBy); err != nil {
+			return nil, err
+		}
 		variants = append(variants, variant)
 	}
 
@@ -86,7 +107,7 @@ func (r *ProductRepository) CreateProduct(ctx context.Context, req *model.Create
 	return &product, nil
 }
 
-func (r *ProductRepository) UpdateProductByPublicID(ctx context.Context, publicID string, req *model.UpdateProductRequest) (*model.Product, error) {
+func (r *ProductRepository) UpdateProductByPublicID(ctx context.Context, publicID string, req *model.UpdateProductRequest, changedBy int64) (*model.Product, error) {
 	// Start transaction
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -108,17 +129,37 @@ func (r *ProductRepository) UpdateProductByPublicID(ctx context.Context, publicI
 	now := time.Now()
 	productQuery := `
 		UPDATE products 
-		SET name = $1, description = $2, private_note = $3, updated_at = $4
-		WHERE id = $5
-		RETURNING id, public_id, name, description, private_note, created_at, updated_at
+		SET name = $1, description = $2, private_note = $3, updated_at = $4,
+			category_id = n.new_category_id,
+			sort_order = CASE
+				WHEN n.new_category_id IS DISTINCT FROM products.category_id
+				THEN COALESCE((SELECT MAX(sort_order) FROM products WHERE category_id = n.new_category_id), 0) + 1
+				ELSE products.sort_order
+			END
+		FROM (
+			SELECT CASE WHEN $6
+				THEN (SELECT id FROM categories WHERE public_id::text = NULLIF($7::text, ''))
+				ELSE (SELECT category_id FROM products WHERE id = $5)
+			END AS new_category_id
+		) n
+		WHERE products.id = $5
+		RETURNING products.id, products.public_id, products.name, products.description, products.private_note,
+			` + productCategoryReturning + `, products.is_available, products.available_at, products.archived_at, products.created_at, products.updated_at
 	`
 	var product model.Product
-	err = tx.QueryRowContext(ctx, productQuery, req.Name, req.Description, req.PrivateNote, now, productID).Scan(
+	err = tx.QueryRowContext(ctx, productQuery, req.Name, req.Description, req.PrivateNote, now, productID, req.CategoryID != nil, req.CategoryID).Scan(
 		&product.ID,
 		&product.PublicID,
 		&product.Name,
 		&product.Description,
 		&product.PrivateNote,
+		&product.CategoryID,
+		&product.CategoryPublicID,
+		&product.CategoryName,
+		&product.SortOrder,
+		&product.IsAvailable,
+		&product.AvailableAt,
+		&product.ArchivedAt,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 	)
@@ -129,7 +170,7 @@ func (r *ProductRepository) UpdateProductByPublicID(ctx context.Context, publicI
 		return nil, err
 	}
 
-	// Delete existing variant ingredients first (due to foreign key constraint)
+	// Recipes are replaced by the ingredients sent with each variant
 	_, err = tx.ExecContext(ctx, `
 		DELETE FROM variant_ingredients 
 		WHERE variant_id IN (SELECT id FROM variants WHERE product_id = $1)
@@ -138,38 +179,12 @@ func (r *ProductRepository) UpdateProductByPublicID(ctx context.Context, publicI
 		return nil, err
 	}
 
-	// Delete existing variants
-	_, err = tx.ExecContext(ctx, "DELETE FROM variants WHERE product_id = $1", productID)
+	// Update variants in place so order lines, price lists and promotions keep pointing at them
+	variants, err := r.syncVariants(ctx, tx, productID, req.Variants, changedBy)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create new variants
-	variants := make([]model.Variant, 0, len(req.Variants))
-	for _, variantReq := range req.Variants {
-		var variant model.Variant
-		variantQuery := `
-			INSERT INTO variants (product_id, name, description, private_note, price)
-			VALUES ($1, $2, $3, $4, $5)
-			RETURNING id, public_id, product_id, name, description, private_note, price, created_at, updated_at
-		`
-		err = tx.QueryRowContext(ctx, variantQuery, productID, variantReq.Name, variantReq.Description, variantReq.PrivateNote, variantReq.Price).Scan(
-			&variant.ID,
-			&variant.PublicID,
-			&variant.ProductID,
-			&variant.Name,
-			&variant.Description,
-			&variant.PrivateNote,
-			&variant.Price,
-			&variant.CreatedAt,
-			&variant.UpdatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		variants = append(variants, variant)
-	}
-
 	// Commit transaction
 	if err = tx.Commit(); err != nil {
 		return nil, err
@@ -179,7 +194,7 @@ func (r *ProductRepository) UpdateProductByPublicID(ctx context.Context, publicI
 	return &product, nil
 }
 
-func (r *ProductRepository) UpdateProduct(ctx context.Context, productID int64, req *model.UpdateProductRequest) (*model.Product, error) {
+func (r *ProductRepository) UpdateProduct(ctx context.Context, productID int64, req *model.UpdateProductRequest, changedBy int64) (*model.Product, error) {
 	// Start transaction
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -191,17 +206,37 @@ func (r *ProductRepository) UpdateProduct(ctx context.Context, productID int64,
 	now := time.Now()
 	productQuery := `
 		UPDATE products 
-		SET name = $1, description = $2, private_note = $3, updated_at = $4
-		WHERE id = $5
-		RETURNING id, public_id, name, description, private_note, created_at, updated_at
+		SET name = $1, description = $2, private_note = $3, updated_at = $4,
+			category_id = n.new_category_id,
+			sort_order = CASE
+				WHEN n.new_category_id IS DISTINCT FROM products.category_id
+				THEN COALESCE((SELECT MAX(sort_order) FROM products WHERE category_id = n.new_category_id), 0) + 1
+				ELSE products.sort_order
+			END
+		FROM (
+			SELECT CASE WHEN $6
+				THEN (SELECT id FROM categories WHERE public_id::text = NULLIF($7::text, ''))
+				ELSE (SELECT category_id FROM products WHERE id = $5)
+			END AS new_category_id
+		) n
+		WHERE products.id = $5
+		RETURNING products.id, products.public_id, products.name, products.description, products.private_note,
+			` + productCategoryReturning + `, products.is_available, products.available_at, products.archived_at, products.created_at, products.updated_at
 	`
 	var product model.Product
-	err = tx.QueryRowContext(ctx, productQuery, req.Name, req.Description, req.PrivateNote, now, productID).Scan(
+	err = tx.QueryRowContext(ctx, productQuery, req.Name, req.Description, req.PrivateNote, now, productID, req.CategoryID != nil, req.CategoryID).Scan(
 		&product.ID,
 		&product.PublicID,
 		&product.Name,
 		&product.Description,
 		&product.PrivateNote,
+		&product.CategoryID,
+		&product.CategoryPublicID,
+		&product.CategoryName,
+		&product.SortOrder,
+		&product.IsAvailable,
+		&product.AvailableAt,
+		&product.ArchivedAt,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 	)
@@ -212,7 +247,7 @@ func (r *ProductRepository) UpdateProduct(ctx context.Context, productID int64,
 		return nil, err
 	}
 
-	// Delete existing variant ingredients first (due to foreign key constraint)
+	// Recipes are replaced by the ingredients sent with each variant
 	_, err = tx.ExecContext(ctx, `
 		DELETE FROM variant_ingredients 
 		WHERE variant_id IN (SELECT id FROM variants WHERE product_id = $1)
@@ -221,55 +256,109 @@ func (r *ProductRepository) UpdateProduct(ctx context.Context, productID int64,
 		return nil, err
 	}
 
-	// Delete existing variants
-	_, err = tx.ExecContext(ctx, "DELETE FROM variants WHERE product_id = $1", productID)
+	// Update variants in place so order lines, price lists and promotions keep pointing at them
+	variants, err := r.syncVariants(ctx, tx, productID, req.Variants, changedBy)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create new variants
-	variants := make([]model.Variant, 0, len(req.Variants))
-	for _, variantReq := range req.Variants {
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	product.Variants = variants
+	return &product, nil
+}
+
+// syncVariants makes the live variants of a product match the request. Variants
+// sent back with their ID are updated, new ones are created and the ones left
+// out are archived rather than deleted, since order lines may point at them.
+// Price changes are added to the price history with the acting user.
+func (r *ProductRepository) syncVariants(ctx context.Context, tx *sqlx.Tx, productID int64, reqs []model.UpdateVariantRequest, changedBy int64) ([]model.Variant, error) {
+	type liveVariant struct {
+		ID       int64   `db:"id"`
+		PublicID string  `db:"public_id"`
+		Price    float64 `db:"price"`
+	}
+	var rows []liveVariant
+	err := tx.SelectContext(ctx, &rows, `SELECT id, public_id, price FROM variants WHERE product_id = $1 AND archived_at IS NULL FOR UPDATE`, productID)
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]liveVariant, len(rows))
+	for _, row := range rows {
+		existing[row.PublicID] = row
+	}
+
+	variants := make([]model.Variant, 0, len(reqs))
+	for _, variantReq := range reqs {
 		var variant model.Variant
-		variantQuery := `
-			INSERT INTO variants (product_id, name, description, private_note, price)
-			VALUES ($1, $2, $3, $4, $5)
-			RETURNING id, public_id, product_id, name, description, private_note, price, created_at, updated_at
-		`
-		err = tx.QueryRowContext(ctx, variantQuery, productID, variantReq.Name, variantReq.Description, variantReq.PrivateNote, variantReq.Price).Scan(
-			&variant.ID,
-			&variant.PublicID,
-			&variant.ProductID,
-			&variant.Name,
-			&variant.Description,
-			&variant.PrivateNote,
-			&variant.Price,
-			&variant.CreatedAt,
-			&variant.UpdatedAt,
-		)
+		var oldPrice *float64
+		if current, ok := existing[variantReq.ID]; ok {
+			delete(existing, variantReq.ID)
+			oldPrice = &current.Price
+			err = tx.GetContext(ctx, &variant, `
+				UPDATE variants
+				SET name = $1, description = $2, private_note = $3, price = $4, updated_at = CURRENT_TIMESTAMP
+				WHERE id = $5
+				RETURNING `+variantColumns,
+				variantReq.Name, variantReq.Description, variantReq.PrivateNote, variantReq.Price, current.ID,
+			)
+		} else {
+			err = tx.GetContext(ctx, &variant, `
+				INSERT INTO variants (product_id, name, description, private_note, price)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING `+variantColumns,
+				productID, variantReq.Name, variantReq.Description, variantReq.PrivateNote, variantReq.Price,
+			)
+		}
 		if err != nil {
 			return nil, err
 		}
+		if err = recordPriceChange(ctx, tx, variant.ID, oldPrice, variant.Price, changedBy); err != nil {
+			return nil, err
+		}
 		variants = append(variants, variant)
 	}
 
-	// Commit transaction
-	if err = tx.Commit(); err != nil {
-		return nil, err
+	for _, current := range existing {
+		if _, err = tx.ExecContext(ctx, `UPDATE variants SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, current.ID); err != nil {
+			return nil, err
+		}
 	}
+	return variants, nil
+}
 
-	product.Variants = variants
-	return &product, nil
+// SetProductArchived archives or restores a product. An archived product and
+// its variants leave the menu and cannot be ordered, but the rows are kept so
+// past orders keep resolving.
+func (r *ProductRepository) SetProductArchived(ctx context.Context, publicID string, archived bool) (*model.Product, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE products
+		SET archived_at = CASE WHEN $1 THEN COALESCE(archived_at, CURRENT_TIMESTAMP) END, updated_at = CURRENT_TIMESTAMP
+		WHERE public_id = $2
+	`, archived, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return nil, errors.New("product not found")
+	}
+	return r.GetProductByPublicID(ctx, publicID)
 }
 
 func (r *ProductRepository) ListProducts(ctx context.Context) ([]*model.Product, error) {
 	// Get all products with variants using LEFT JOIN
 	query := `
 		SELECT 
-			p.id, p.public_id, p.name, p.description, p.private_note, p.created_at, p.updated_at,
-			v.id, v.public_id, v.product_id, v.name, v.description, v.private_note, v.price, v.created_at, v.updated_at
+			p.id, p.public_id, p.name, p.description, p.private_note,
+			p.category_id, c.public_id::text, c.name, p.sort_order, p.is_available, p.available_at, p.archived_at, p.created_at, p.updated_at,
+			v.id, v.public_id, v.product_id, v.name, v.description, v.private_note, v.price, v.is_available, v.available_at, v.auto_availability, v.created_at, v.updated_at
 		FROM products p
-		LEFT JOIN variants v ON p.id = v.product_id
+		LEFT JOIN categories c ON p.category_id = c.id
+		LEFT JOIN variants v ON p.id = v.product_id AND v.archived_at IS NULL
+		WHERE p.archived_at IS NULL
 		ORDER BY p.created_at DESC, v.created_at ASC
 	`
 	rows, err := r.db.QueryContext(ctx, query)
@@ -289,6 +378,8 @@ func (r *ProductRepository) ListProducts(ctx context.Context) ([]*model.Product,
 		var variantProductID sql.NullInt64
 		var variantName, variantDescription, variantPrivateNote sql.NullString
 		var variantPrice sql.NullFloat64
+		var variantIsAvailable, variantAutoAvailability sql.NullBool
+		var variantAvailableAt sql.NullTime
 		var variantCreatedAt, variantUpdatedAt sql.NullTime
 
 		err := rows.Scan(
@@ -297,6 +388,13 @@ func (r *ProductRepository) ListProducts(ctx context.Context) ([]*model.Product,
 			&product.Name,
 			&product.Description,
 			&product.PrivateNote,
+			&product.CategoryID,
+			&product.CategoryPublicID,
+			&product.CategoryName,
+			&product.SortOrder,
+			&product.IsAvailable,
+			&product.AvailableAt,
+			&product.ArchivedAt,
 			&product.CreatedAt,
 			&product.UpdatedAt,
 			&variantID,
@@ -306,6 +404,9 @@ func (r *ProductRepository) ListProducts(ctx context.Context) ([]*model.Product,
 			&variantDescription,
 			&variantPrivateNote,
 			&variantPrice,
+			&variantIsAvailable,
+			&variantAvailableAt,
+			&variantAutoAvailability,
 			&variantCreatedAt,
 			&variantUpdatedAt,
 		)
@@ -324,6 +425,11 @@ func (r *ProductRepository) ListProducts(ctx context.Context) ([]*model.Product,
 				variant.Description = variantDescription.String
 				variant.PrivateNote = variantPrivateNote.String
 				variant.Price = variantPrice.Float64
+				variant.IsAvailable = variantIsAvailable.Bool
+				if variantAvailableAt.Valid {
+					variant.AvailableAt = &variantAvailableAt.Time
+				}
+				variant.AutoAvailability = variantAutoAvailability.Bool
 				variant.CreatedAt = variantCreatedAt.Time
 				variant.UpdatedAt = variantUpdatedAt.Time
 				existingProduct.Variants = append(existingProduct.Variants, variant)
@@ -339,6 +445,11 @@ func (r *ProductRepository) ListProducts(ctx context.Context) ([]*model.Product,
 				variant.Description = variantDescription.String
 				variant.PrivateNote = variantPrivateNote.String
 				variant.Price = variantPrice.Float64
+				variant.IsAvailable = variantIsAvailable.Bool
+				if variantAvailableAt.Valid {
+					variant.AvailableAt = &variantAvailableAt.Time
+				}
+				variant.AutoAvailability = variantAutoAvailability.Bool
 				variant.CreatedAt = variantCreatedAt.Time
 				variant.UpdatedAt = variantUpdatedAt.Time
 				product.Variants = append(product.Variants, variant)
@@ -357,20 +468,50 @@ func (r *ProductRepository) ListProducts(ctx context.Context) ([]*model.Product,
 }
 
 func (r *ProductRepository) ListProductsWithPagination(ctx context.Context, filter *model.ProductFilter) (*model.PaginatedProducts, error) {
-	// Build WHERE clause for search
-	whereClause := ""
+	// Build WHERE clause for search and category
+	conditions := []string{}
 	args := []interface{}{}
 	argIndex := 1
 
 	if filter.Search != "" {
-		whereClause = "WHERE p.name ILIKE $1 OR p.description ILIKE $1"
+		conditions = append(conditions, "(p.name ILIKE $"+strconv.Itoa(argIndex)+" OR p.description ILIKE $"+strconv.Itoa(argIndex)+")")
 		args = append(args, "%"+filter.Search+"%")
-		argIndex = 2
+		argIndex++
+	}
+
+	if filter.CategoryID == model.ProductCategoryNone {
+		conditions = append(conditions, "p.category_id IS NULL")
+	} else if filter.CategoryID != "" {
+		// Products of the category and of all its subcategories
+		conditions = append(conditions, `p.category_id IN (
+			WITH RECURSIVE subtree AS (
+				SELECT id FROM categories WHERE public_id::text = $`+strconv.Itoa(argIndex)+`
+				UNION ALL
+				SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id
+			)
+			SELECT id FROM subtree
+		)`)
+		args = append(args, filter.CategoryID)
+		argIndex++
+	}
+
+	if !filter.IncludeArchived {
+		conditions = append(conditions, "p.archived_at IS NULL")
+	}
+
+	whereClause := ""
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
 	// Build ORDER BY clause
+	withClause := ""
 	orderBy := "ORDER BY p.created_at DESC"
-	if filter.SortBy != "" {
+	if filter.SortBy == "menu" {
+		// Menu order: categories as arranged, then products as arranged within each category
+		withClause = categoryTreeCTE
+		orderBy = "ORDER BY (SELECT path FROM category_tree WHERE id = p.category_id) ASC NULLS LAST, p.sort_order ASC, LOWER(p.name) ASC"
+	} else if filter.SortBy != "" {
 		validSortFields := map[string]string{
 			"name":        "LOWER(p.name)",
 			"created_at":  "p.created_at",
@@ -398,12 +539,14 @@ func (r *ProductRepository) ListProductsWithPagination(ctx context.Context, filt
 	totalPages := int(math.Ceil(float64(total) / float64(filter.Limit)))
 
 	// Get products with pagination
-	query := `
+	query := withClause + `
 		SELECT 
-			p.id, p.public_id, p.name, p.description, p.private_note, p.created_at, p.updated_at,
-			v.id, v.public_id, v.product_id, v.name, v.description, v.private_note, v.price, v.created_at, v.updated_at
+			p.id, p.public_id, p.name, p.description, p.private_note,
+			p.category_id, c.public_id::text, c.name, p.sort_order, p.is_available, p.available_at, p.archived_at, p.created_at, p.updated_at,
+			v.id, v.public_id, v.product_id, v.name, v.description, v.private_note, v.price, v.is_available, v.available_at, v.auto_availability, v.created_at, v.updated_at
 		FROM products p
-		LEFT JOIN variants v ON p.id = v.product_id
+		LEFT JOIN categories c ON p.category_id = c.id
+		LEFT JOIN variants v ON p.id = v.product_id AND v.archived_at IS NULL
 		` + whereClause + `
 		` + orderBy + `
 		LIMIT $` + strconv.Itoa(argIndex) + ` OFFSET $` + strconv.Itoa(argIndex+1)
@@ -429,6 +572,8 @@ func (r *ProductRepository) ListProductsWithPagination(ctx context.Context, filt
 		var variantProductID sql.NullInt64
 		var variantName, variantDescription, variantPrivateNote sql.NullString
 		var variantPrice sql.NullFloat64
+		var variantIsAvailable, variantAutoAvailability sql.NullBool
+		var variantAvailableAt sql.NullTime
 		var variantCreatedAt, variantUpdatedAt sql.NullTime
 
 		err := rows.Scan(
@@ -437,6 +582,13 @@ func (r *ProductRepository) ListProductsWithPagination(ctx context.Context, filt
 			&product.Name,
 			&product.Description,
 			&product.PrivateNote,
+			&product.CategoryID,
+			&product.CategoryPublicID,
+			&product.CategoryName,
+			&product.SortOrder,
+			&product.IsAvailable,
+			&product.AvailableAt,
+			&product.ArchivedAt,
 			&product.CreatedAt,
 			&product.UpdatedAt,
 			&variantID,
@@ -446,6 +598,9 @@ func (r *ProductRepository) ListProductsWithPagination(ctx context.Context, filt
 			&variantDescription,
 			&variantPrivateNote,
 			&variantPrice,
+			&variantIsAvailable,
+			&variantAvailableAt,
+			&variantAutoAvailability,
 			&variantCreatedAt,
 			&variantUpdatedAt,
 		)
@@ -464,6 +619,11 @@ func (r *ProductRepository) ListProductsWithPagination(ctx context.Context, filt
 				variant.Description = variantDescription.String
 				variant.PrivateNote = variantPrivateNote.String
 				variant.Price = variantPrice.Float64
+				variant.IsAvailable = variantIsAvailable.Bool
+				if variantAvailableAt.Valid {
+					variant.AvailableAt = &variantAvailableAt.Time
+				}
+				variant.AutoAvailability = variantAutoAvailability.Bool
 				variant.CreatedAt = variantCreatedAt.Time
 				variant.UpdatedAt = variantUpdatedAt.Time
 				existingProduct.Variants = append(existingProduct.Variants, variant)
@@ -479,6 +639,11 @@ func (r *ProductRepository) ListProductsWithPagination(ctx context.Context, filt
 				variant.Description = variantDescription.String
 				variant.PrivateNote = variantPrivateNote.String
 				variant.Price = variantPrice.Float64
+				variant.IsAvailable = variantIsAvailable.Bool
+				if variantAvailableAt.Valid {
+					variant.AvailableAt = &variantAvailableAt.Time
+				}
+				variant.AutoAvailability = variantAutoAvailability.Bool
 				variant.CreatedAt = variantCreatedAt.Time
 				variant.UpdatedAt = variantUpdatedAt.Time
 				product.Variants = append(product.Variants, variant)
@@ -491,7 +656,7 @@ func (r *ProductRepository) ListProductsWithPagination(ctx context.Context, filt
 	products := make([]*model.Product, 0, len(productMap))
 	
 	// Get products in the correct order by doing a separate query
-	productOrderQuery := `
+	productOrderQuery := withClause + `
 		SELECT p.id
 		FROM products p
 		` + whereClause + `
@@ -531,9 +696,11 @@ func (r *ProductRepository) GetProductByPublicID(ctx context.Context, publicID s
 	// Get product
 	var product model.Product
 	productQuery := `
-		SELECT id, public_id, name, description, private_note, created_at, updated_at
-		FROM products
-		WHERE public_id = $1
+		SELECT p.id, p.public_id, p.name, p.description, p.private_note,
+			p.category_id, c.public_id::text, c.name, p.sort_order, p.is_available, p.available_at, p.archived_at, p.created_at, p.updated_at
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.public_id = $1
 	`
 	err := r.db.QueryRowContext(ctx, productQuery, publicID).Scan(
 		&product.ID,
@@ -541,6 +708,13 @@ func (r *ProductRepository) GetProductByPublicID(ctx context.Context, publicID s
 		&product.Name,
 		&product.Description,
 		&product.PrivateNote,
+		&product.CategoryID,
+		&product.CategoryPublicID,
+		&product.CategoryName,
+		&product.SortOrder,
+		&product.IsAvailable,
+		&product.AvailableAt,
+		&product.ArchivedAt,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 	)
@@ -553,9 +727,9 @@ func (r *ProductRepository) GetProductByPublicID(ctx context.Context, publicID s
 
 	// Get variants with ingredients
 	variantsQuery := `
-		SELECT v.id, v.public_id, v.product_id, v.name, v.description, v.private_note, v.price, v.created_at, v.updated_at
+		SELECT v.id, v.public_id, v.product_id, v.name, v.description, v.private_note, v.price, v.is_available, v.available_at, v.auto_availability, v.created_at, v.updated_at
 		FROM variants v
-		WHERE v.product_id = $1
+		WHERE v.product_id = $1 AND v.archived_at IS NULL
 		ORDER BY v.created_at ASC
 	`
 	rows, err := r.db.QueryContext(ctx, variantsQuery, product.ID)
@@ -575,6 +749,9 @@ func (r *ProductRepository) GetProductByPublicID(ctx context.Context, publicID s
 			&variant.Description,
 			&variant.PrivateNote,
 			&variant.Price,
+			&variant.IsAvailable,
+			&variant.AvailableAt,
+			&variant.AutoAvailability,
 			&variant.CreatedAt,
 			&variant.UpdatedAt,
 		)
@@ -636,9 +813,11 @@ func (r *ProductRepository) GetProductByID(ctx context.Context, id int64) (*mode
 	// Get product
 	var product model.Product
 	productQuery := `
-		SELECT id, public_id, name, description, private_note, created_at, updated_at
-		FROM products
-		WHERE id = $1
+		SELECT p.id, p.public_id, p.name, p.description, p.private_note,
+			p.category_id, c.public_id::text, c.name, p.sort_order, p.is_available, p.available_at, p.archived_at, p.created_at, p.updated_at
+		FROM products p
+		LEFT JOIN categories c ON p.category_id = c.id
+		WHERE p.id = $1
 	`
 	err := r.db.QueryRowContext(ctx, productQuery, id).Scan(
 		&product.ID,
@@ -646,6 +825,13 @@ func (r *ProductRepository) GetProductByID(ctx context.Context, id int64) (*mode
 		&product.Name,
 		&product.Description,
 		&product.PrivateNote,
+		&product.CategoryID,
+		&product.CategoryPublicID,
+		&product.CategoryName,
+		&product.SortOrder,
+		&product.IsAvailable,
+		&product.AvailableAt,
+		&product.ArchivedAt,
 		&product.CreatedAt,
 		&product.UpdatedAt,
 	)
@@ -658,9 +844,9 @@ func (r *ProductRepository) GetProductByID(ctx context.Context, id int64) (*mode
 
 	// Get variants with ingredients
 	variantsQuery := `
-		SELECT v.id, v.public_id, v.product_id, v.name, v.description, v.private_note, v.price, v.created_at, v.updated_at
+		SELECT v.id, v.public_id, v.product_id, v.name, v.description, v.private_note, v.price, v.is_available, v.available_at, v.auto_availability, v.created_at, v.updated_at
 		FROM variants v
-		WHERE v.product_id = $1
+		WHERE v.product_id = $1 AND v.archived_at IS NULL
 		ORDER BY v.created_at ASC
 	`
 	rows, err := r.db.QueryContext(ctx, variantsQuery, id)
@@ -680,6 +866,9 @@ func (r *ProductRepository) GetProductByID(ctx context.Context, id int64) (*mode
 			&variant.Description,
 			&variant.PrivateNote,
 			&variant.Price,
+			&variant.IsAvailable,
+			&variant.AvailableAt,
+			&variant.AutoAvailability,
 			&variant.CreatedAt,
 			&variant.UpdatedAt,
 		)
@@ -0,0 +1,450 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const promotionColumns = `
+	id, public_id, name, COALESCE(description, '') AS description, promotion_type, buy_quantity, get_quantity,
+	discount_percent, priority, is_active, valid_from, valid_until, COALESCE(created_by, 0) AS created_by,
+	created_at, updated_at
+`
+
+type PromotionRepository struct {
+	db *sqlx.DB
+}
+
+func NewPromotionRepository(db *sqlx.DB) *PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+// CreatePromotion creates a promotion with its product/variant scope
+func (r *PromotionRepository) CreatePromotion(ctx context.Context, req *model.CreatePromotionRequest, userID int64) (*model.Promotion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var promotion model.Promotion
+	err = tx.GetContext(ctx, &promotion, `
+		INSERT INTO promotions (
+			name, description, promotion_type, buy_quantity, get_quantity, discount_percent,
+			priority, valid_from, valid_until, created_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING `+promotionColumns,
+		req.Name, req.Description, req.PromotionType, req.BuyQuantity, req.GetQuantity, req.DiscountPercent,
+		req.Priority, req.ValidFrom, req.ValidUntil, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = r.replaceScope(ctx, tx, promotion.ID, req.ProductIDs, req.VariantIDs); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	promotion.ProductIDs = req.ProductIDs
+	promotion.VariantIDs = req.VariantIDs
+	return &promotion, nil
+}
+
+// GetPromotionByPublicID gets a promotion by public ID, nil if it does not exist
+func (r *PromotionRepository) GetPromotionByPublicID(ctx context.Context, publicID string) (*model.Promotion, error) {
+	var promotion model.Promotion
+	err := r.db.GetContext(ctx, &promotion, `SELECT `+promotionColumns+` FROM promotions WHERE public_id::text = $1`, publicID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err = r.loadScope(ctx, r.db, &promotion); err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+// ListPromotions lists promotions, highest priority first
+func (r *PromotionRepository) ListPromotions(ctx context.Context, isActive *bool) ([]*model.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions`
+	args := []any{}
+	if isActive != nil {
+		query += ` WHERE is_active = $1`
+		args = append(args, *isActive)
+	}
+	query += ` ORDER BY priority DESC, id ASC`
+
+	promotions := make([]*model.Promotion, 0)
+	if err := r.db.SelectContext(ctx, &promotions, query, args...); err != nil {
+		return nil, err
+	}
+	for _, promotion := range promotions {
+		if err := r.loadScope(ctx, r.db, promotion); err != nil {
+			return nil, err
+		}
+	}
+	return promotions, nil
+}
+
+// UpdatePromotion updates a promotion and replaces its scope, nil if it does not exist
+func (r *PromotionRepository) UpdatePromotion(ctx context.Context, publicID string, req *model.UpdatePromotionRequest) (*model.Promotion, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var promotion model.Promotion
+	err = tx.GetContext(ctx, &promotion, `
+		UPDATE promotions
+		SET name = $1, description = $2, promotion_type = $3, buy_quantity = $4, get_quantity = $5,
+			discount_percent = $6, priority = $7, is_active = $8, valid_from = $9, valid_until = $10,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE public_id::text = $11
+		RETURNING `+promotionColumns,
+		req.Name, req.Description, req.PromotionType, req.BuyQuantity, req.GetQuantity,
+		req.DiscountPercent, req.Priority, req.IsActive, req.ValidFrom, req.ValidUntil, publicID,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err = r.replaceScope(ctx, tx, promotion.ID, req.ProductIDs, req.VariantIDs); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	promotion.ProductIDs = req.ProductIDs
+	promotion.VariantIDs = req.VariantIDs
+	return &promotion, nil
+}
+
+// IsPromotionUsed reports whether a promotion was applied to any order
+func (r *PromotionRepository) IsPromotionUsed(ctx context.Context, promotionID int64) (bool, error) {
+	var used bool
+	err := r.db.GetContext(ctx, &used, `SELECT EXISTS (SELECT 1 FROM order_item_promotions WHERE promotion_id = $1)`, promotionID)
+	return used, err
+}
+
+// DeletePromotion deletes a promotion
+func (r *PromotionRepository) DeletePromotion(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM promotions WHERE id = $1`, id)
+	return err
+}
+
+// Evaluate works out the promotions of a cart without saving anything
+func (r *PromotionRepository) Evaluate(ctx context.Context, lines []model.PromotionLine, customerID *int64) ([][]model.OrderItemPromotion, []model.StampCardProgress, error) {
+	return r.evaluate(ctx, r.db, lines, customerID, 0)
+}
+
+// evaluate works out the promotions of a cart, returned per line. Promotions
+// are tried by priority, each unit is free under at most one promotion and the
+// cheapest qualifying units are the free ones. Stamp cards count the earlier
+// orders of the customer, except excludeOrderID.
+func (r *PromotionRepository) evaluate(ctx context.Context, q sqlx.QueryerContext, lines []model.PromotionLine, customerID *int64, excludeOrderID int64) ([][]model.OrderItemPromotion, []model.StampCardProgress, error) {
+	promotions := make([]*model.Promotion, 0)
+	err := sqlx.SelectContext(ctx, q, &promotions, `
+		SELECT `+promotionColumns+`
+		FROM promotions
+		WHERE is_active = true
+			AND (valid_from IS NULL OR valid_from <= CURRENT_TIMESTAMP)
+			AND (valid_until IS NULL OR valid_until >= CURRENT_TIMESTAMP)
+		ORDER BY priority DESC, id ASC
+	`)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	applied := make([][]model.OrderItemPromotion, len(lines))
+	remaining := make([]int, len(lines))
+	for i, line := range lines {
+		remaining[i] = line.Quantity
+	}
+	stampCards := make([]model.StampCardProgress, 0)
+
+	type unit struct {
+		line  int
+		price float64
+	}
+	for _, promotion := range promotions {
+		if promotion.PromotionType == model.PromotionTypeStampCard && customerID == nil {
+			continue // Thẻ tích ly chỉ dành cho khách hàng đã xác định
+		}
+		productIDs, variantIDs, err := r.getScopeIDs(ctx, q, promotion.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		scoped := len(productIDs) > 0 || len(variantIDs) > 0
+
+		units := make([]unit, 0)
+		for i, line := range lines {
+			if scoped && !productIDs[line.ProductID] && !variantIDs[line.VariantID] {
+				continue
+			}
+			for n := 0; n < remaining[i]; n++ {
+				units = append(units, unit{line: i, price: line.UnitPrice})
+			}
+		}
+
+		var paid, free int
+		if promotion.PromotionType == model.PromotionTypeStampCard {
+			paid, free, err = r.getStampHistory(ctx, q, promotion.ID, *customerID, excludeOrderID)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		freeUnits := promotion.FreeUnits(len(units), paid, free)
+		if promotion.PromotionType == model.PromotionTypeStampCard {
+			stampCards = append(stampCards, promotion.StampProgress(paid+len(units)-freeUnits, free+freeUnits))
+		}
+		if freeUnits == 0 {
+			continue
+		}
+
+		sort.SliceStable(units, func(a, b int) bool { return units[a].price < units[b].price })
+		freeByLine := map[int]int{}
+		for _, u := range units[:freeUnits] {
+			freeByLine[u.line]++
+		}
+		for i := range lines {
+			quantity := freeByLine[i]
+			if quantity == 0 {
+				continue
+			}
+			remaining[i] -= quantity
+			applied[i] = append(applied[i], model.OrderItemPromotion{
+				OrderItemID:       lines[i].OrderItemID,
+				PromotionID:       promotion.ID,
+				PromotionPublicID: promotion.PublicID,
+				PromotionName:     promotion.Name,
+				FreeQuantity:      quantity,
+				DiscountAmount:    float64(quantity) * lines[i].UnitPrice * promotion.DiscountPercent / 100,
+			})
+		}
+	}
+
+	return applied, stampCards, nil
+}
+
+// getStampHistory counts the qualifying units a customer paid for and got free
+// on a stamp card in earlier orders since the promotion started
+func (r *PromotionRepository) getStampHistory(ctx context.Context, q sqlx.QueryerContext, promotionID, customerID, excludeOrderID int64) (int, int, error) {
+	var paid, free int
+	err := q.QueryRowxContext(ctx, `
+		WITH history AS (
+			SELECT oi.quantity,
+				COALESCE((SELECT SUM(free_quantity) FROM order_item_promotions WHERE order_item_id = oi.id), 0) AS free_any,
+				COALESCE((SELECT SUM(free_quantity) FROM order_item_promotions WHERE order_item_id = oi.id AND promotion_id = $1), 0) AS free_this
+			FROM order_items oi
+			JOIN orders o ON oi.order_id = o.id
+			JOIN variants v ON oi.variant_id = v.id
+			JOIN promotions pr ON pr.id = $1
+			WHERE o.customer_id = $2 AND o.id <> $3 AND o.status <> 'cancelled'
+				AND o.created_at >= COALESCE(pr.valid_from, pr.created_at)
+				AND (
+					(NOT EXISTS (SELECT 1 FROM promotion_products WHERE promotion_id = $1)
+						AND NOT EXISTS (SELECT 1 FROM promotion_variants WHERE promotion_id = $1))
+					OR v.product_id IN (SELECT product_id FROM promotion_products WHERE promotion_id = $1)
+					OR oi.variant_id IN (SELECT variant_id FROM promotion_variants WHERE promotion_id = $1)
+				)
+		)
+		SELECT COALESCE(SUM(quantity - free_any), 0), COALESCE(SUM(free_this), 0) FROM history
+	`, promotionID, customerID, excludeOrderID).Scan(&paid, &free)
+	return paid, free, err
+}
+
+// applyToOrder evaluates the promotions against the current items of an order,
+// replaces the applied promotions of its lines and updates the order totals.
+// It returns the applied promotions keyed by order item.
+func (r *PromotionRepository) applyToOrder(ctx context.Context, tx *sqlx.Tx, order *model.Order) (map[int64][]model.OrderItemPromotion, error) {
+	lines := make([]model.PromotionLine, 0)
+	err := tx.SelectContext(ctx, &lines, `
+		SELECT oi.id AS order_item_id, v.product_id, oi.variant_id, oi.quantity, oi.unit_price
+		FROM order_items oi
+		JOIN variants v ON oi.variant_id = v.id
+		WHERE oi.order_id = $1
+		ORDER BY oi.id ASC
+	`, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		DELETE FROM order_item_promotions
+		WHERE order_item_id IN (SELECT id FROM order_items WHERE order_id = $1)
+	`, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, _, err := r.evaluate(ctx, tx, lines, order.CustomerID, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64][]model.OrderItemPromotion)
+	amount := 0.0
+	for i := range lines {
+		for _, promotion := range applied[i] {
+			err = tx.QueryRowContext(ctx, `
+				INSERT INTO order_item_promotions (order_item_id, promotion_id, promotion_name, free_quantity, discount_amount)
+				VALUES ($1, $2, $3, $4, $5)
+				RETURNING id, created_at
+			`, promotion.OrderItemID, promotion.PromotionID, promotion.PromotionName, promotion.FreeQuantity, promotion.DiscountAmount,
+			).Scan(&promotion.ID, &promotion.CreatedAt)
+			if err != nil {
+				return nil, err
+			}
+			amount += promotion.DiscountAmount
+			result[promotion.OrderItemID] = append(result[promotion.OrderItemID], promotion)
+		}
+	}
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE orders
+		SET promotion_discount_amount = $1,
+			total_amount = GREATEST(0, subtotal - discount_amount - manual_discount_amount - $1 - loyalty_discount_amount + shipping_fee),
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING total_amount
+	`, amount, order.ID).Scan(&order.TotalAmount)
+	if err != nil {
+		return nil, err
+	}
+	order.PromotionDiscountAmount = amount
+	return result, nil
+}
+
+// getOrderPromotions gets the applied promotions of all items of an order, keyed by order item
+func (r *PromotionRepository) getOrderPromotions(ctx context.Context, q sqlx.QueryerContext, orderID int64) (map[int64][]model.OrderItemPromotion, error) {
+	promotions := make([]model.OrderItemPromotion, 0)
+	err := sqlx.SelectContext(ctx, q, &promotions, `
+		SELECT ip.id, ip.order_item_id, ip.promotion_id, pr.public_id::text AS promotion_public_id,
+			ip.promotion_name, ip.free_quantity, ip.discount_amount, ip.created_at
+		FROM order_item_promotions ip
+		JOIN order_items oi ON ip.order_item_id = oi.id
+		JOIN promotions pr ON ip.promotion_id = pr.id
+		WHERE oi.order_id = $1
+		ORDER BY ip.id ASC
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64][]model.OrderItemPromotion)
+	for _, p := range promotions {
+		result[p.OrderItemID] = append(result[p.OrderItemID], p)
+	}
+	return result, nil
+}
+
+// loadScope fills the public product/variant IDs a promotion is restricted to
+func (r *PromotionRepository) loadScope(ctx context.Context, q sqlx.QueryerContext, promotion *model.Promotion) error {
+	productIDs := make([]string, 0)
+	err := sqlx.SelectContext(ctx, q, &productIDs, `
+		SELECT p.public_id FROM promotion_products pp
+		JOIN products p ON pp.product_id = p.id
+		WHERE pp.promotion_id = $1
+	`, promotion.ID)
+	if err != nil {
+		return err
+	}
+
+	variantIDs := make([]string, 0)
+	err = sqlx.SelectContext(ctx, q, &variantIDs, `
+		SELECT v.public_id FROM promotion_variants pv
+		JOIN variants v ON pv.variant_id = v.id
+		WHERE pv.promotion_id = $1
+	`, promotion.ID)
+	if err != nil {
+		return err
+	}
+
+	promotion.ProductIDs = productIDs
+	promotion.VariantIDs = variantIDs
+	return nil
+}
+
+// getScopeIDs returns the internal product/variant IDs a promotion is restricted to
+func (r *PromotionRepository) getScopeIDs(ctx context.Context, q sqlx.QueryerContext, promotionID int64) (map[int64]bool, map[int64]bool, error) {
+	var productIDs, variantIDs []int64
+	err := sqlx.SelectContext(ctx, q, &productIDs, `SELECT product_id FROM promotion_products WHERE promotion_id = $1`, promotionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = sqlx.SelectContext(ctx, q, &variantIDs, `SELECT variant_id FROM promotion_variants WHERE promotion_id = $1`, promotionID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	products := make(map[int64]bool, len(productIDs))
+	for _, id := range productIDs {
+		products[id] = true
+	}
+	variants := make(map[int64]bool, len(variantIDs))
+	for _, id := range variantIDs {
+		variants[id] = true
+	}
+	return products, variants, nil
+}
+
+// replaceScope replaces the product/variant restrictions of a promotion
+func (r *PromotionRepository) replaceScope(ctx context.Context, tx *sqlx.Tx, promotionID int64, productIDs, variantIDs []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM promotion_products WHERE promotion_id = $1`, promotionID); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM promotion_variants WHERE promotion_id = $1`, promotionID); err != nil {
+		return err
+	}
+
+	for _, productID := range productIDs {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO promotion_products (promotion_id, product_id)
+			SELECT $1, id FROM products WHERE public_id::text = $2
+			ON CONFLICT (promotion_id, product_id) DO NOTHING
+		`, promotionID, productID)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return model.NewValidationError("product_ids", "Sản phẩm không tồn tại: "+productID)
+		}
+	}
+
+	for _, variantID := range variantIDs {
+		result, err := tx.ExecContext(ctx, `
+			INSERT INTO promotion_variants (promotion_id, variant_id)
+			SELECT $1, id FROM variants WHERE public_id::text = $2
+			ON CONFLICT (promotion_id, variant_id) DO NOTHING
+		`, promotionID, variantID)
+		if err != nil {
+			return err
+		}
+		if rows, _ := result.RowsAffected(); rows == 0 {
+			return model.NewValidationError("variant_ids", "Biến thể không tồn tại: "+variantID)
+		}
+	}
+
+	return nil
+}
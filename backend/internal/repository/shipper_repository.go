@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
 	"food-pos-backend/internal/model"
@@ -62,6 +64,10 @@ func (r *ShipperRepository) ListShippers(ctx context.Context, filters map[string
 	argCount := 1
 
 	// Add filters
+	if includeArchived, _ := filters["include_archived"].(bool); !includeArchived {
+		whereClause += " AND archived_at IS NULL"
+	}
+
 	if isActive, ok := filters["is_active"].(bool); ok {
 		whereClause += fmt.Sprintf(" AND is_active = $%d", argCount)
 		args = append(args, isActive)
@@ -104,7 +110,7 @@ func (r *ShipperRepository) ListShippers(ctx context.Context, filters map[string
 func (r *ShipperRepository) UpdateShipper(ctx context.Context, shipper *model.Shipper) error {
 	query := `
 		UPDATE shippers SET 
-			name = $1, phone = $2, email = $3, is_active = $4,
+			name = $1, phone = $2, email = $3, is_active = $4 AND archived_at IS NULL,
 			updated_at = $5
 		WHERE id = $6`
 
@@ -115,13 +121,33 @@ func (r *ShipperRepository) UpdateShipper(ctx context.Context, shipper *model.Sh
 	return err
 }
 
-// DeleteShipper deletes shipper
-func (r *ShipperRepository) DeleteShipper(ctx context.Context, id int64) error {
-	query := `DELETE FROM shippers WHERE id = $1`
+// ArchiveShipper archives shipper, the row stays for delivery and COD history.
+// Archived shippers are inactive and off shift so they cannot log in or get deliveries.
+func (r *ShipperRepository) ArchiveShipper(ctx context.Context, id int64) error {
+	query := `
+		UPDATE shippers SET
+			archived_at = COALESCE(archived_at, CURRENT_TIMESTAMP), is_active = false, availability = 'off_shift',
+			shift_started_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1`
+	_, err := r.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// RestoreShipper restores an archived shipper as active, off shift
+func (r *ShipperRepository) RestoreShipper(ctx context.Context, id int64) error {
+	query := `UPDATE shippers SET archived_at = NULL, is_active = true, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
+// CountActiveDeliveries counts the deliveries a shipper still has to finish
+func (r *ShipperRepository) CountActiveDeliveries(ctx context.Context, id int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM delivery_orders WHERE shipper_id = $1 AND status IN ('assigned', 'picked_up', 'in_transit')`
+	err := r.db.GetContext(ctx, &count, query, id)
+	return count, err
+}
+
 // GetActiveShippers gets all active shippers
 func (r *ShipperRepository) GetActiveShippers(ctx context.Context) ([]model.Shipper, error) {
 	var shippers []model.Shipper
@@ -132,4 +158,156 @@ func (r *ShipperRepository) GetActiveShippers(ctx context.Context) ([]model.Ship
 		return nil, err
 	}
 	return shippers, nil
-} 
\ No newline at end of file
+} 
+
+// SetAccount creates or updates the login account linked to a shipper
+func (r *ShipperRepository) SetAccount(ctx context.Context, shipper *model.Shipper, username, passwordHash string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var userID int64
+	if shipper.UserID == nil {
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO users (username, password_hash, full_name, role, is_active)
+			VALUES ($1, $2, $3, 'shipper', true)
+			RETURNING id
+		`, username, passwordHash, shipper.Name).Scan(&userID)
+		if err != nil {
+			return err
+		}
+		if _, err = tx.ExecContext(ctx, "UPDATE shippers SET user_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2", userID, shipper.ID); err != nil {
+			return err
+		}
+	} else {
+		_, err = tx.ExecContext(ctx, `
+			UPDATE users SET username = $1, password_hash = $2, full_name = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE id = $4
+		`, username, passwordHash, shipper.Name, *shipper.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAccountByUsername gets a shipper by the username of its login account
+func (r *ShipperRepository) GetAccountByUsername(ctx context.Context, username string) (*model.ShipperAccount, error) {
+	var account model.ShipperAccount
+	query := `
+		SELECT s.*, u.public_id AS user_public_id, u.username, COALESCE(u.password_hash, '') AS password_hash, u.is_active AS user_active
+		FROM shippers s
+		JOIN users u ON s.user_id = u.id
+		WHERE u.username = $1 AND u.role = 'shipper'`
+	err := r.db.GetContext(ctx, &account, query, username)
+	if err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// IsUsernameTaken checks whether a username belongs to another user
+func (r *ShipperRepository) IsUsernameTaken(ctx context.Context, username string, exceptUserID *int64) (bool, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, "SELECT COUNT(*) FROM users WHERE username = $1 AND ($2::BIGINT IS NULL OR id <> $2)", username, exceptUserID)
+	return count > 0, err
+}
+
+// UpdateAvailability updates the availability settings of a shipper
+func (r *ShipperRepository) UpdateAvailability(ctx context.Context, shipper *model.Shipper) error {
+	query := `
+		UPDATE shippers SET
+			availability = $1, max_concurrent_deliveries = $2, zone = $3,
+			shift_started_at = CASE
+				WHEN $1 = 'on_shift' THEN COALESCE(CASE WHEN availability = 'on_shift' THEN shift_started_at END, CURRENT_TIMESTAMP)
+				ELSE NULL
+			END,
+			updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4
+		RETURNING shift_started_at, updated_at`
+
+	return r.db.QueryRowContext(ctx, query,
+		shipper.Availability, shipper.MaxConcurrentDeliveries, shipper.Zone, shipper.ID,
+	).Scan(&shipper.ShiftStartedAt, &shipper.UpdatedAt)
+}
+
+// ListAssignmentCandidates lists the active, on shift shippers below their concurrent delivery limit
+func (r *ShipperRepository) ListAssignmentCandidates(ctx context.Context) ([]model.ShipperCandidate, error) {
+	candidates := []model.ShipperCandidate{}
+	query := `
+		SELECT s.*, COUNT(d.id) AS active_deliveries
+		FROM shippers s
+		LEFT JOIN delivery_orders d ON d.shipper_id = s.id AND d.status IN ('assigned', 'picked_up', 'in_transit')
+		WHERE s.is_active = true AND s.availability = 'on_shift'
+		GROUP BY s.id
+		HAVING COUNT(d.id) < s.max_concurrent_deliveries
+		ORDER BY s.id`
+	if err := r.db.SelectContext(ctx, &candidates, query); err != nil {
+		return nil, err
+	}
+	return candidates, nil
+}
+
+// GetShipperByPhone gets a shipper by normalized phone digits, nil if none
+func (r *ShipperRepository) GetShipperByPhone(ctx context.Context, phoneDigits string) (*model.Shipper, error) {
+	var shipper model.Shipper
+	query := `SELECT * FROM shippers WHERE regexp_replace(phone, '\D', '', 'g') IN ($1, '84' || substring($1 from 2)) LIMIT 1`
+	if err := r.db.GetContext(ctx, &shipper, query, phoneDigits); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &shipper, nil
+}
+
+// EnsureAccount gets the login account of a shipper, creating one without password
+// under the given username if the shipper has none yet (phone OTP login)
+func (r *ShipperRepository) EnsureAccount(ctx context.Context, shipper *model.Shipper, username string) (*model.ShipperAccount, error) {
+	if shipper.UserID == nil {
+		tx, err := r.db.BeginTxx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		defer tx.Rollback()
+
+		var userID int64
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO users (username, full_name, role, is_active)
+			VALUES ($1, $2, 'shipper', true)
+			RETURNING id
+		`, username, shipper.Name).Scan(&userID)
+		if err != nil {
+			return nil, err
+		}
+		result, err := tx.ExecContext(ctx, "UPDATE shippers SET user_id = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2 AND user_id IS NULL", userID, shipper.ID)
+		if err != nil {
+			return nil, err
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		// A concurrent login may have linked an account first, then ours is rolled back
+		if rows > 0 {
+			if err := tx.Commit(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var account model.ShipperAccount
+	query := `
+		SELECT s.*, u.public_id AS user_public_id, COALESCE(u.username, '') AS username,
+			COALESCE(u.password_hash, '') AS password_hash, u.is_active AS user_active
+		FROM shippers s
+		JOIN users u ON s.user_id = u.id
+		WHERE s.id = $1`
+	if err := r.db.GetContext(ctx, &account, query, shipper.ID); err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"food-pos-backend/internal/model"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type StockRepository struct {
+	db *sqlx.DB
+}
+
+func NewStockRepository(db *sqlx.DB) *StockRepository {
+	return &StockRepository{db: db}
+}
+
+// RecordMovement applies a stock movement to an ingredient and appends it to the ledger
+func (r *StockRepository) RecordMovement(ctx context.Context, ingredientID int64, movementType model.StockMovementType, quantity float64, unitCost *float64, notes *string, userID int64) (*model.StockMovement, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Manual adjustments must not push the stock below zero
+	if movementType == model.StockMovementAdjustment {
+		var current float64
+		err = tx.QueryRowContext(ctx, "SELECT stock_quantity FROM ingredients WHERE id = $1 FOR UPDATE", ingredientID).Scan(&current)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return nil, fmt.Errorf("ingredient not found")
+			}
+			return nil, err
+		}
+		if current+quantity < 0 {
+			return nil, model.NewValidationError("quantity", fmt.Sprintf("Tồn kho không đủ để điều chỉnh (hiện có %.3f)", current))
+		}
+	}
+
+	movementID, err := r.recordMovement(ctx, tx, ingredientID, movementType, quantity, unitCost, nil, notes, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.getMovementByID(ctx, movementID)
+}
+
+// ListMovements lists the stock ledger of an ingredient with filtering and pagination
+func (r *StockRepository) ListMovements(ctx context.Context, ingredientID int64, req *model.ListStockMovementsRequest) (*model.ListStockMovementsResponse, error) {
+	whereConditions := []string{"m.ingredient_id = $1"}
+	args := []any{ingredientID}
+	argIndex := 2
+
+	if req.MovementType != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.movement_type = $%d", argIndex))
+		args = append(args, *req.MovementType)
+		argIndex++
+	}
+
+	if req.DateFrom != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.created_at >= $%d", argIndex))
+		args = append(args, *req.DateFrom)
+		argIndex++
+	}
+
+	if req.DateTo != nil {
+		whereConditions = append(whereConditions, fmt.Sprintf("m.created_at <= $%d", argIndex))
+		args = append(args, *req.DateTo)
+		argIndex++
+	}
+
+	whereClause := strings.Join(whereConditions, " AND ")
+
+	// Count total
+	var total int
+	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM ingredient_stock_movements m WHERE %s", whereClause)
+	if err := r.db.GetContext(ctx, &total, countQuery, args...); err != nil {
+		return nil, err
+	}
+
+	offset := (req.Page - 1) * req.Limit
+	query := fmt.Sprintf(`
+		%s
+		WHERE %s
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $%d OFFSET $%d
+	`, stockMovementSelect, whereClause, argIndex, argIndex+1)
+	args = append(args, req.Limit, offset)
+
+	movements := make([]*model.StockMovement, 0)
+	if err := r.db.SelectContext(ctx, &movements, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &model.ListStockMovementsResponse{
+		Movements: movements,
+		Total:     total,
+		Page:      req.Page,
+		Limit:     req.Limit,
+		Pages:     int(math.Ceil(float64(total) / float64(req.Limit))),
+	}, nil
+}
+
+// ListLowStockIngredients lists ingredients at or below their low stock threshold
+func (r *StockRepository) ListLowStockIngredients(ctx context.Context) ([]*model.Ingredient, error) {
+	query := `
+		SELECT id, public_id, name, unit_price, unit, stock_quantity, low_stock_threshold, archived_at, created_at, updated_at
+		FROM ingredients
+		WHERE stock_quantity <= low_stock_threshold AND archived_at IS NULL
+		ORDER BY (stock_quantity - low_stock_threshold) ASC, name ASC
+	`
+	ingredients := make([]*model.Ingredient, 0)
+	if err := r.db.SelectContext(ctx, &ingredients, query); err != nil {
+		return nil, err
+	}
+	return ingredients, nil
+}
+
+const stockMovementSelect = `
+	SELECT m.id, m.public_id, m.ingredient_id, i.public_id AS ingredient_public_id, i.name AS ingredient_name,
+		m.movement_type, m.quantity, m.balance_after, m.unit_cost, m.order_id, o.order_number,
+		m.notes, m.created_by, m.created_at
+	FROM ingredient_stock_movements m
+	JOIN ingredients i ON m.ingredient_id = i.id
+	LEFT JOIN orders o ON m.order_id = o.id
+`
+
+// getMovementByID gets a single ledger entry
+func (r *StockRepository) getMovementByID(ctx context.Context, id int64) (*model.StockMovement, error) {
+	var movement model.StockMovement
+	if err := r.db.GetContext(ctx, &movement, stockMovementSelect+" WHERE m.id = $1", id); err != nil {
+		return nil, err
+	}
+	return &movement, nil
+}
+
+// recordMovement updates the cached stock quantity and inserts the ledger entry
+func (r *StockRepository) recordMovement(ctx context.Context, tx *sqlx.Tx, ingredientID int64, movementType model.StockMovementType, quantity float64, unitCost *float64, orderID *int64, notes *string, userID *int64) (int64, error) {
+	var balance float64
+	err := tx.QueryRowContext(ctx, `
+		UPDATE ingredients
+		SET stock_quantity = stock_quantity + $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING stock_quantity
+	`, quantity, ingredientID).Scan(&balance)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("ingredient not found")
+		}
+		return 0, err
+	}
+
+	var movementID int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO ingredient_stock_movements (ingredient_id, movement_type, quantity, balance_after, unit_cost, order_id, notes, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id
+	`, ingredientID, movementType, quantity, balance, unitCost, orderID, notes, userID).Scan(&movementID)
+	return movementID, err
+}
+
+// deductForOrder brings the stock deducted for an order in line with its current
+// items: missing quantities are consumed and quantities no longer ordered are
+// reversed. It is a no-op when nothing changed since the last call.
+func (r *StockRepository) deductForOrder(ctx context.Context, tx *sqlx.Tx, order *model.Order, userID int64) error {
+	usage, err := r.usageForOrder(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+	outstanding, err := r.outstandingForOrder(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	required := make(map[int64]float64, len(usage))
+	ingredientIDs := make([]int64, 0, len(usage)+len(outstanding))
+	for _, u := range usage {
+		required[u.ingredientID] = u.quantity
+		ingredientIDs = append(ingredientIDs, u.ingredientID)
+	}
+	deducted := make(map[int64]float64, len(outstanding))
+	for _, o := range outstanding {
+		deducted[o.ingredientID] = o.quantity
+		if _, ok := required[o.ingredientID]; !ok {
+			ingredientIDs = append(ingredientIDs, o.ingredientID)
+		}
+	}
+	// Keep row locks in a stable order
+	sort.Slice(ingredientIDs, func(i, j int) bool { return ingredientIDs[i] < ingredientIDs[j] })
+
+	consumeNotes := "Xuất kho cho đơn hàng " + order.OrderNumber
+	reverseNotes := "Hoàn kho do sửa đơn hàng " + order.OrderNumber
+	for _, ingredientID := range ingredientIDs {
+		diff := math.Max(required[ingredientID], 0) - deducted[ingredientID]
+		switch {
+		case diff > 0:
+			_, err = r.recordMovement(ctx, tx, ingredientID, model.StockMovementConsumption, -diff, nil, &order.ID, &consumeNotes, &userID)
+		case diff < 0:
+			_, err = r.recordMovement(ctx, tx, ingredientID, model.StockMovementReversal, -diff, nil, &order.ID, &reverseNotes, &userID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// usageForOrder returns the recipe plus modifier consumption per ingredient for the current items of an order
+func (r *StockRepository) usageForOrder(ctx context.Context, tx *sqlx.Tx, orderID int64) ([]ingredientQuantity, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ingredient_id, SUM(quantity)
+		FROM (
+			SELECT vi.ingredient_id, vi.quantity * oi.quantity AS quantity
+			FROM order_items oi
+			JOIN variant_ingredients vi ON vi.variant_id = oi.variant_id
+			WHERE oi.order_id = $1
+			UNION ALL
+			SELECT m.ingredient_id, m.ingredient_quantity * oi.quantity AS quantity
+			FROM order_items oi
+			JOIN order_item_modifiers m ON m.order_item_id = oi.id
+			WHERE oi.order_id = $1 AND m.ingredient_id IS NOT NULL
+		) usage
+		GROUP BY ingredient_id
+		ORDER BY ingredient_id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return scanIngredientQuantities(rows)
+}
+
+// reverseForOrder puts back whatever is still deducted for an order
+func (r *StockRepository) reverseForOrder(ctx context.Context, tx *sqlx.Tx, order *model.Order, userID int64) error {
+	outstanding, err := r.outstandingForOrder(ctx, tx, order.ID)
+	if err != nil {
+		return err
+	}
+
+	notes := "Hoàn kho do hủy đơn hàng " + order.OrderNumber
+	for _, o := range outstanding {
+		if _, err := r.recordMovement(ctx, tx, o.ingredientID, model.StockMovementReversal, o.quantity, nil, &order.ID, &notes, &userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type ingredientQuantity struct {
+	ingredientID int64
+	quantity     float64
+}
+
+// outstandingForOrder returns the net quantity still deducted per ingredient for an order
+func (r *StockRepository) outstandingForOrder(ctx context.Context, tx *sqlx.Tx, orderID int64) ([]ingredientQuantity, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT ingredient_id, -SUM(quantity)
+		FROM ingredient_stock_movements
+		WHERE order_id = $1 AND movement_type IN ('consumption', 'reversal')
+		GROUP BY ingredient_id
+		HAVING SUM(quantity) < 0
+		ORDER BY ingredient_id
+	`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return scanIngredientQuantities(rows)
+}
+
+func scanIngredientQuantities(rows *sql.Rows) ([]ingredientQuantity, error) {
+	defer rows.Close()
+	var result []ingredientQuantity
+	for rows.Next() {
+		var iq ingredientQuantity
+		if err := rows.Scan(&iq.ingredientID, &iq.quantity); err != nil {
+			return nil, err
+		}
+		result = append(result, iq)
+	}
+	return result, rows.Err()
+}
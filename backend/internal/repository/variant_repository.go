@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"food-pos-backend/internal/model"
 
@@ -10,6 +11,10 @@ import (
 	"github.com/jmoiron/sqlx"
 )
 
+// variantColumns are the columns scanned into model.Variant
+const variantColumns = `id, public_id, product_id, name, description, private_note, price,
+	is_available, available_at, auto_availability, archived_at, created_at, updated_at`
+
 type VariantRepository struct {
 	db *sqlx.DB
 }
@@ -18,14 +23,20 @@ func NewVariantRepository(db *sqlx.DB) *VariantRepository {
 	return &VariantRepository{db: db}
 }
 
-func (r *VariantRepository) CreateVariant(ctx context.Context, variant *model.Variant) error {
+func (r *VariantRepository) CreateVariant(ctx context.Context, variant *model.Variant, changedBy int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO variants (public_id, product_id, name, description, private_note, price, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id
 	`
 	variant.PublicID = uuid.New().String()
-	return r.db.QueryRowContext(ctx, query,
+	err = tx.QueryRowContext(ctx, query,
 		variant.PublicID,
 		variant.ProductID,
 		variant.Name,
@@ -35,11 +46,18 @@ func (r *VariantRepository) CreateVariant(ctx context.Context, variant *model.Va
 		variant.CreatedAt,
 		variant.UpdatedAt,
 	).Scan(&variant.ID)
+	if err != nil {
+		return err
+	}
+	if err = recordPriceChange(ctx, tx, variant.ID, nil, variant.Price, changedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *VariantRepository) GetByPublicID(ctx context.Context, publicID string) (*model.Variant, error) {
 	query := `
-		SELECT id, public_id, product_id, name, description, private_note, price, created_at, updated_at
+		SELECT `+variantColumns+`
 		FROM variants
 		WHERE public_id = $1
 	`
@@ -56,7 +74,7 @@ func (r *VariantRepository) GetByPublicID(ctx context.Context, publicID string)
 
 func (r *VariantRepository) GetByID(ctx context.Context, id int64) (*model.Variant, error) {
 	query := `
-		SELECT id, public_id, product_id, name, description, private_note, price, created_at, updated_at
+		SELECT `+variantColumns+`
 		FROM variants
 		WHERE id = $1
 	`
@@ -71,28 +89,40 @@ func (r *VariantRepository) GetByID(ctx context.Context, id int64) (*model.Varia
 	return &variant, nil
 }
 
-func (r *VariantRepository) ListVariantsByProduct(ctx context.Context, productID int64) ([]*model.Variant, error) {
+// ListVariantsByProduct lists the variants of a product, archived ones only when asked
+func (r *VariantRepository) ListVariantsByProduct(ctx context.Context, productID int64, includeArchived bool) ([]*model.Variant, error) {
 	query := `
-		SELECT id, public_id, product_id, name, description, private_note, price, created_at, updated_at
+		SELECT `+variantColumns+`
 		FROM variants
-		WHERE product_id = $1
+		WHERE product_id = $1 AND ($2 OR archived_at IS NULL)
 		ORDER BY created_at ASC
 	`
 	var variants []*model.Variant
-	err := r.db.SelectContext(ctx, &variants, query, productID)
+	err := r.db.SelectContext(ctx, &variants, query, productID, includeArchived)
 	if err != nil {
 		return nil, err
 	}
 	return variants, nil
 }
 
-func (r *VariantRepository) Update(ctx context.Context, variant *model.Variant) error {
+func (r *VariantRepository) Update(ctx context.Context, variant *model.Variant, changedBy int64) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldPrice float64
+	if err = tx.GetContext(ctx, &oldPrice, `SELECT price FROM variants WHERE id = $1 FOR UPDATE`, variant.ID); err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE variants
 		SET name = $1, description = $2, private_note = $3, price = $4, updated_at = $5
 		WHERE id = $6
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err = tx.ExecContext(ctx, query,
 		variant.Name,
 		variant.Description,
 		variant.PrivateNote,
@@ -100,11 +130,129 @@ func (r *VariantRepository) Update(ctx context.Context, variant *model.Variant)
 		variant.UpdatedAt,
 		variant.ID,
 	)
+	if err != nil {
+		return err
+	}
+	if err = recordPriceChange(ctx, tx, variant.ID, &oldPrice, variant.Price, changedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Archive hides a variant from the menu and stops it from being ordered. The
+// row is kept because order lines point at it.
+func (r *VariantRepository) Archive(ctx context.Context, id int64) error {
+	query := `UPDATE variants SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP WHERE id = $1 AND archived_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, id)
 	return err
 }
 
-func (r *VariantRepository) Delete(ctx context.Context, id int64) error {
-	query := `DELETE FROM variants WHERE id = $1`
+// Restore puts an archived variant back on the menu
+func (r *VariantRepository) Restore(ctx context.Context, id int64) error {
+	query := `UPDATE variants SET archived_at = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
 	_, err := r.db.ExecContext(ctx, query, id)
 	return err
-} 
\ No newline at end of file
+}
+
+// CountLiveVariants counts the variants of a product that are not archived
+func (r *VariantRepository) CountLiveVariants(ctx context.Context, productID int64) (int, error) {
+	var count int
+	err := r.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM variants WHERE product_id = $1 AND archived_at IS NULL`, productID)
+	return count, err
+}
+
+// ListPriceHistory lists the price changes of a variant, newest first
+func (r *VariantRepository) ListPriceHistory(ctx context.Context, variantID int64) ([]*model.VariantPriceChange, error) {
+	query := `
+		SELECT h.id, h.variant_id, h.old_price, h.new_price, h.changed_by, u.full_name AS changed_by_name, h.changed_at
+		FROM variant_price_history h
+		LEFT JOIN users u ON h.changed_by = u.id
+		WHERE h.variant_id = $1
+		ORDER BY h.changed_at DESC, h.id DESC
+	`
+	changes := []*model.VariantPriceChange{}
+	if err := r.db.SelectContext(ctx, &changes, query, variantID); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// GetPriceAt gets the price change in effect for a variant at the given time,
+// nil if the variant had no recorded price yet
+func (r *VariantRepository) GetPriceAt(ctx context.Context, variantID int64, at time.Time) (*model.VariantPriceChange, error) {
+	query := `
+		SELECT h.id, h.variant_id, h.old_price, h.new_price, h.changed_by, u.full_name AS changed_by_name, h.changed_at
+		FROM variant_price_history h
+		LEFT JOIN users u ON h.changed_by = u.id
+		WHERE h.variant_id = $1 AND h.changed_at <= $2
+		ORDER BY h.changed_at DESC, h.id DESC
+		LIMIT 1
+	`
+	var change model.VariantPriceChange
+	err := r.db.GetContext(ctx, &change, query, variantID, at)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ListPriceChangeImpact compares, for each price change of a variant, the
+// sales in the days before and after it. The periods stop at the neighbouring
+// price changes so each one is sold at a single regular price.
+func (r *VariantRepository) ListPriceChangeImpact(ctx context.Context, variantID int64, days int) ([]*model.PriceChangeImpact, error) {
+	query := `
+		WITH changes AS (
+			SELECT h.id, h.old_price, h.new_price, h.changed_by, h.changed_at,
+				LAG(h.changed_at) OVER w AS previous_change_at,
+				LEAD(h.changed_at) OVER w AS next_change_at
+			FROM variant_price_history h
+			WHERE h.variant_id = $1
+			WINDOW w AS (ORDER BY h.changed_at, h.id)
+		), periods AS (
+			SELECT c.id, c.old_price, c.new_price, c.changed_by, c.changed_at,
+				GREATEST(c.changed_at - $2::int * INTERVAL '1 day', COALESCE(c.previous_change_at, '-infinity')) AS before_from,
+				LEAST(c.changed_at + $2::int * INTERVAL '1 day', COALESCE(c.next_change_at, 'infinity'), LOCALTIMESTAMP) AS after_until
+			FROM changes c
+			WHERE c.old_price IS NOT NULL
+		)
+		SELECT p.old_price, p.new_price, u.full_name AS changed_by_name, p.changed_at,
+			p.before_from AS "before.from", p.changed_at AS "before.until",
+			COALESCE(SUM(s.quantity) FILTER (WHERE s.created_at < p.changed_at), 0) AS "before.quantity",
+			COALESCE(SUM(s.quantity * s.unit_price) FILTER (WHERE s.created_at < p.changed_at), 0) AS "before.revenue",
+			p.changed_at AS "after.from", p.after_until AS "after.until",
+			COALESCE(SUM(s.quantity) FILTER (WHERE s.created_at >= p.changed_at), 0) AS "after.quantity",
+			COALESCE(SUM(s.quantity * s.unit_price) FILTER (WHERE s.created_at >= p.changed_at), 0) AS "after.revenue"
+		FROM periods p
+		LEFT JOIN users u ON p.changed_by = u.id
+		LEFT JOIN (
+			SELECT oi.quantity, oi.unit_price, o.created_at
+			FROM order_items oi
+			JOIN orders o ON oi.order_id = o.id
+			WHERE oi.variant_id = $1 AND o.status <> 'cancelled'
+		) s ON s.created_at >= p.before_from AND s.created_at < p.after_until
+		GROUP BY p.id, p.old_price, p.new_price, u.full_name, p.changed_at, p.before_from, p.after_until
+		ORDER BY p.changed_at DESC, p.id DESC
+	`
+	impacts := []*model.PriceChangeImpact{}
+	if err := r.db.SelectContext(ctx, &impacts, query, variantID, days); err != nil {
+		return nil, err
+	}
+	return impacts, nil
+}
+
+// recordPriceChange adds a row to the price history of a variant when its
+// price changed. oldPrice is nil for a new variant.
+func recordPriceChange(ctx context.Context, q sqlx.ExecerContext, variantID int64, oldPrice *float64, newPrice float64, changedBy int64) error {
+	if oldPrice != nil && *oldPrice == newPrice {
+		return nil
+	}
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO variant_price_history (variant_id, old_price, new_price, changed_by)
+		VALUES ($1, $2, $3, $4)`,
+		variantID, oldPrice, newPrice, changedBy,
+	)
+	return err
+}
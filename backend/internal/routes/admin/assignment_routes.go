@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAssignmentRoutes configures shipper availability and automatic assignment routes
+func SetupAssignmentRoutes(adminProtected *gin.RouterGroup, assignmentHandler *handler.AssignmentHandler) {
+	adminProtected.GET("/shippers/eligible", assignmentHandler.ListEligibleShippers)
+	adminProtected.PUT("/shippers/:id/availability", assignmentHandler.UpdateShipperAvailability)
+	adminProtected.GET("/orders/:id/shipper-proposal", assignmentHandler.ProposeShipper)
+	adminProtected.POST("/orders/:id/auto-assign", assignmentHandler.AutoAssign)
+}
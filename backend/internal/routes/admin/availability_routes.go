@@ -0,0 +1,14 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupAvailabilityRoutes configures sold out ("86") routes for products and variants
+func SetupAvailabilityRoutes(adminProtected *gin.RouterGroup, availabilityHandler *handler.AvailabilityHandler) {
+	adminProtected.GET("/availability", availabilityHandler.ListAvailability)
+	adminProtected.PUT("/products/:id/availability", availabilityHandler.SetProductAvailability)
+	adminProtected.PUT("/variants/:id/availability", availabilityHandler.SetVariantAvailability)
+}
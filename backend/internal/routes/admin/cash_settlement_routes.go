@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCashSettlementRoutes configures shipper COD cash and settlement routes
+func SetupCashSettlementRoutes(adminProtected *gin.RouterGroup, settlementHandler *handler.CashSettlementHandler) {
+	adminProtected.GET("/shippers/cash-balances", settlementHandler.ListShipperCashBalances)
+	adminProtected.GET("/shippers/:id/cash", settlementHandler.GetShipperCashBalance)
+	adminProtected.POST("/shippers/:id/settlements", settlementHandler.SettleShipperCash)
+	adminProtected.GET("/cash-settlements", settlementHandler.ListSettlements)
+	adminProtected.GET("/cash-settlements/:id", settlementHandler.GetSettlement)
+}
@@ -0,0 +1,18 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCategoryRoutes configures menu category routes
+func SetupCategoryRoutes(adminProtected *gin.RouterGroup, categoryHandler *handler.CategoryHandler) {
+	adminProtected.POST("/categories", categoryHandler.CreateCategory)
+	adminProtected.GET("/categories", categoryHandler.ListCategories)
+	adminProtected.PUT("/categories/reorder", categoryHandler.ReorderCategories)
+	adminProtected.GET("/categories/:id", categoryHandler.GetCategory)
+	adminProtected.PUT("/categories/:id", categoryHandler.UpdateCategory)
+	adminProtected.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+	adminProtected.PUT("/categories/:id/products", categoryHandler.SetCategoryProducts)
+}
@@ -0,0 +1,16 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCustomerAddressRoutes configures saved customer address routes
+func SetupCustomerAddressRoutes(adminProtected *gin.RouterGroup, customerAddressHandler *handler.CustomerAddressHandler) {
+	adminProtected.GET("/users/:id/addresses", customerAddressHandler.ListAddresses)
+	adminProtected.POST("/users/:id/addresses", customerAddressHandler.CreateAddress)
+	adminProtected.PUT("/users/:id/addresses/:addressId", customerAddressHandler.UpdateAddress)
+	adminProtected.PUT("/users/:id/addresses/:addressId/default", customerAddressHandler.SetDefaultAddress)
+	adminProtected.DELETE("/users/:id/addresses/:addressId", customerAddressHandler.DeleteAddress)
+}
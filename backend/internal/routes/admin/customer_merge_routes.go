@@ -0,0 +1,14 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupCustomerMergeRoutes configures duplicate customer detection and merge routes
+func SetupCustomerMergeRoutes(adminProtected *gin.RouterGroup, customerMergeHandler *handler.CustomerMergeHandler) {
+	adminProtected.GET("/customers/duplicates", customerMergeHandler.FindDuplicates)
+	adminProtected.POST("/customers/merge", customerMergeHandler.MergeCustomers)
+	adminProtected.GET("/customers/merges", customerMergeHandler.ListMerges)
+}
@@ -14,6 +14,10 @@ func SetupDeliveryRoutes(adminProtected *gin.RouterGroup, deliveryHandler *handl
 	adminProtected.GET("/deliveries/:id", deliveryHandler.GetDeliveryOrderByID)
 	adminProtected.PUT("/deliveries/:id", deliveryHandler.UpdateDeliveryOrder)
 	adminProtected.PUT("/deliveries/:id/status", deliveryHandler.UpdateDeliveryStatus)
+	adminProtected.GET("/deliveries/:id/proofs", deliveryHandler.ListDeliveryProofs)
+	adminProtected.POST("/deliveries/:id/proofs", deliveryHandler.AddDeliveryProof)
+	adminProtected.GET("/deliveries/:id/proofs/:proofId/file", deliveryHandler.GetDeliveryProofFile)
+	adminProtected.GET("/deliveries/proof-policy", deliveryHandler.GetProofPolicy)
 	adminProtected.GET("/deliveries/statuses", deliveryHandler.GetDeliveryStatuses)
 	adminProtected.GET("/deliveries/shippers", deliveryHandler.GetAvailableShippers)
 
@@ -0,0 +1,19 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDeliveryZoneRoutes configures delivery zone and shipping fee routes
+func SetupDeliveryZoneRoutes(adminProtected *gin.RouterGroup, deliveryZoneHandler *handler.DeliveryZoneHandler) {
+	adminProtected.POST("/delivery-zones", deliveryZoneHandler.CreateZone)
+	adminProtected.GET("/delivery-zones", deliveryZoneHandler.ListZones)
+	adminProtected.POST("/delivery-zones/quote", deliveryZoneHandler.QuoteShippingFee)
+	adminProtected.GET("/delivery-zones/:id", deliveryZoneHandler.GetZone)
+	adminProtected.PUT("/delivery-zones/:id", deliveryZoneHandler.UpdateZone)
+	adminProtected.DELETE("/delivery-zones/:id", deliveryZoneHandler.DeleteZone)
+	adminProtected.PUT("/orders/:id/shipping-fee", deliveryZoneHandler.UpdateOrderShippingFee)
+	adminProtected.GET("/orders/:id/shipping-fee-audits", deliveryZoneHandler.ListOrderShippingFeeAudits)
+}
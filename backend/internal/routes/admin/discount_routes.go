@@ -0,0 +1,18 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupDiscountRoutes configures discount code routes
+func SetupDiscountRoutes(adminProtected *gin.RouterGroup, discountHandler *handler.DiscountHandler) {
+	// Discount code routes
+	adminProtected.POST("/discount-codes", discountHandler.CreateDiscountCode)
+	adminProtected.GET("/discount-codes", discountHandler.ListDiscountCodes)
+	adminProtected.GET("/discount-codes/:id", discountHandler.GetDiscountCode)
+	adminProtected.PUT("/discount-codes/:id", discountHandler.UpdateDiscountCode)
+	adminProtected.DELETE("/discount-codes/:id", discountHandler.DeleteDiscountCode)
+	adminProtected.GET("/discount-codes/:id/usages", discountHandler.ListDiscountCodeUsages)
+}
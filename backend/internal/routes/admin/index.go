@@ -9,20 +9,50 @@ import (
 // SetupAllAdminRoutes configures all admin routes
 func SetupAllAdminRoutes(adminProtected *gin.RouterGroup, handlers *AdminHandlers) {
 	SetupProductRoutes(adminProtected, handlers.ProductHandler, handlers.VariantHandler)
+	SetupCategoryRoutes(adminProtected, handlers.CategoryHandler)
+	SetupAvailabilityRoutes(adminProtected, handlers.AvailabilityHandler)
 	SetupIngredientRoutes(adminProtected, handlers.IngredientHandler)
 	SetupOrderRoutes(adminProtected, handlers.OrderHandler)
 	SetupShipperRoutes(adminProtected, handlers.ShipperHandler)
 	SetupDeliveryRoutes(adminProtected, handlers.DeliveryHandler)
 	SetupUserRoutes(adminProtected, handlers.AdminUserHandler)
+	SetupDiscountRoutes(adminProtected, handlers.DiscountHandler)
+	SetupInventoryRoutes(adminProtected, handlers.InventoryHandler)
+	SetupModifierRoutes(adminProtected, handlers.ModifierHandler)
+	SetupKitchenRoutes(adminProtected, handlers.KitchenHandler)
+	SetupPaymentRoutes(adminProtected, handlers.PaymentHandler)
+	SetupCashSettlementRoutes(adminProtected, handlers.CashSettlementHandler)
+	SetupAssignmentRoutes(adminProtected, handlers.AssignmentHandler)
+	SetupDeliveryZoneRoutes(adminProtected, handlers.DeliveryZoneHandler)
+	SetupCustomerAddressRoutes(adminProtected, handlers.CustomerAddressHandler)
+	SetupCustomerMergeRoutes(adminProtected, handlers.CustomerMergeHandler)
+	SetupLoyaltyRoutes(adminProtected, handlers.LoyaltyHandler)
+	SetupPromotionRoutes(adminProtected, handlers.PromotionHandler)
+	SetupPriceListRoutes(adminProtected, handlers.PriceListHandler)
 }
 
 // AdminHandlers contains all admin handlers
 type AdminHandlers struct {
-	ProductHandler    *handler.ProductHandler
-	VariantHandler    *handler.VariantHandler
-	IngredientHandler *handler.IngredientHandler
-	OrderHandler      *handler.OrderHandler
-	ShipperHandler    *handler.ShipperHandler
-	DeliveryHandler   *handler.DeliveryHandler
-	AdminUserHandler  *handler.AdminUserHandler
-} 
\ No newline at end of file
+	ProductHandler         *handler.ProductHandler
+	VariantHandler         *handler.VariantHandler
+	CategoryHandler        *handler.CategoryHandler
+	AvailabilityHandler    *handler.AvailabilityHandler
+	IngredientHandler      *handler.IngredientHandler
+	OrderHandler           *handler.OrderHandler
+	ShipperHandler         *handler.ShipperHandler
+	DeliveryHandler        *handler.DeliveryHandler
+	AdminUserHandler       *handler.AdminUserHandler
+	DiscountHandler        *handler.DiscountHandler
+	InventoryHandler       *handler.InventoryHandler
+	ModifierHandler        *handler.ModifierHandler
+	KitchenHandler         *handler.KitchenHandler
+	PaymentHandler         *handler.PaymentHandler
+	CashSettlementHandler  *handler.CashSettlementHandler
+	AssignmentHandler      *handler.AssignmentHandler
+	DeliveryZoneHandler    *handler.DeliveryZoneHandler
+	CustomerAddressHandler *handler.CustomerAddressHandler
+	CustomerMergeHandler   *handler.CustomerMergeHandler
+	LoyaltyHandler         *handler.LoyaltyHandler
+	PromotionHandler       *handler.PromotionHandler
+	PriceListHandler       *handler.PriceListHandler
+}
@@ -13,7 +13,8 @@ func SetupIngredientRoutes(adminProtected *gin.RouterGroup, ingredientHandler *h
 	adminProtected.GET("/ingredients", ingredientHandler.GetAllIngredients)
 	adminProtected.GET("/ingredients/:public_id", ingredientHandler.GetIngredient)
 	adminProtected.PUT("/ingredients/:public_id", ingredientHandler.UpdateIngredient)
-	adminProtected.DELETE("/ingredients/:public_id", ingredientHandler.DeleteIngredient)
+	adminProtected.DELETE("/ingredients/:public_id", ingredientHandler.ArchiveIngredient)
+	adminProtected.POST("/ingredients/:public_id/restore", ingredientHandler.RestoreIngredient)
 
 	// Variant-Ingredient routes
 	adminProtected.GET("/variants/:variant_public_id/ingredients", ingredientHandler.GetVariantIngredients)
@@ -0,0 +1,15 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupInventoryRoutes configures ingredient stock routes
+func SetupInventoryRoutes(adminProtected *gin.RouterGroup, inventoryHandler *handler.InventoryHandler) {
+	adminProtected.GET("/inventory/low-stock", inventoryHandler.ListLowStockIngredients)
+	adminProtected.GET("/ingredients/:public_id/stock-movements", inventoryHandler.ListStockMovements)
+	adminProtected.POST("/ingredients/:public_id/stock-receipts", inventoryHandler.ReceiveStock)
+	adminProtected.POST("/ingredients/:public_id/stock-adjustments", inventoryHandler.AdjustStock)
+}
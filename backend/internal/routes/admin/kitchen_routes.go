@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupKitchenRoutes configures kitchen station management routes
+func SetupKitchenRoutes(adminProtected *gin.RouterGroup, kitchenHandler *handler.KitchenHandler) {
+	adminProtected.POST("/kitchen-stations", kitchenHandler.CreateStation)
+	adminProtected.GET("/kitchen-stations", kitchenHandler.ListStations)
+	adminProtected.GET("/kitchen-stations/:id", kitchenHandler.GetStation)
+	adminProtected.PUT("/kitchen-stations/:id", kitchenHandler.UpdateStation)
+	adminProtected.DELETE("/kitchen-stations/:id", kitchenHandler.DeleteStation)
+	adminProtected.PUT("/kitchen-stations/:id/products", kitchenHandler.SetStationProducts)
+}
@@ -0,0 +1,22 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupLoyaltyRoutes configures loyalty program rules and customer points routes
+func SetupLoyaltyRoutes(adminProtected *gin.RouterGroup, loyaltyHandler *handler.LoyaltyHandler) {
+	adminProtected.GET("/loyalty/settings", loyaltyHandler.GetSettings)
+	adminProtected.PUT("/loyalty/settings", loyaltyHandler.UpdateSettings)
+	adminProtected.GET("/loyalty/tiers", loyaltyHandler.ListTiers)
+	adminProtected.PUT("/loyalty/tiers/:code", loyaltyHandler.UpdateTier)
+	adminProtected.POST("/loyalty/tiers/recalculate", loyaltyHandler.RecalculateTiers)
+	adminProtected.GET("/loyalty/product-bonuses", loyaltyHandler.ListProductBonuses)
+	adminProtected.PUT("/loyalty/product-bonuses/:productId", loyaltyHandler.SetProductBonus)
+	adminProtected.DELETE("/loyalty/product-bonuses/:productId", loyaltyHandler.DeleteProductBonus)
+	adminProtected.GET("/users/:id/loyalty", loyaltyHandler.GetAccount)
+	adminProtected.GET("/users/:id/loyalty/ledger", loyaltyHandler.ListLedger)
+	adminProtected.POST("/users/:id/loyalty/adjustments", loyaltyHandler.AdjustPoints)
+}
@@ -0,0 +1,26 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupModifierRoutes configures modifier group routes
+func SetupModifierRoutes(adminProtected *gin.RouterGroup, modifierHandler *handler.ModifierHandler) {
+	// Modifier group routes
+	adminProtected.POST("/modifier-groups", modifierHandler.CreateModifierGroup)
+	adminProtected.GET("/modifier-groups", modifierHandler.ListModifierGroups)
+	adminProtected.GET("/modifier-groups/:id", modifierHandler.GetModifierGroup)
+	adminProtected.PUT("/modifier-groups/:id", modifierHandler.UpdateModifierGroup)
+	adminProtected.DELETE("/modifier-groups/:id", modifierHandler.DeleteModifierGroup)
+
+	// Modifier option routes
+	adminProtected.POST("/modifier-groups/:id/options", modifierHandler.AddModifierOption)
+	adminProtected.PUT("/modifier-options/:option_id", modifierHandler.UpdateModifierOption)
+	adminProtected.DELETE("/modifier-options/:option_id", modifierHandler.DeleteModifierOption)
+
+	// Product-Modifier group routes
+	adminProtected.GET("/products/:id/modifier-groups", modifierHandler.GetProductModifierGroups)
+	adminProtected.PUT("/products/:id/modifier-groups", modifierHandler.SetProductModifierGroups)
+}
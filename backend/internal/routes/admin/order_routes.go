@@ -16,6 +16,5 @@ func SetupOrderRoutes(adminProtected *gin.RouterGroup, orderHandler *handler.Ord
 	adminProtected.PUT("/orders/:id/status", orderHandler.UpdateOrderStatus)
 	adminProtected.POST("/orders/validate-discount", orderHandler.ValidateDiscountCode)
 	adminProtected.GET("/orders/statuses", orderHandler.GetOrderStatuses)
-	adminProtected.GET("/orders/payment-methods", orderHandler.GetPaymentMethods)
 	adminProtected.GET("/orders/statistics", orderHandler.GetOrderStatistics)
 } 
\ No newline at end of file
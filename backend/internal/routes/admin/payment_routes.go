@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPaymentRoutes configures payment and refund routes
+func SetupPaymentRoutes(adminProtected *gin.RouterGroup, paymentHandler *handler.PaymentHandler) {
+	adminProtected.GET("/orders/payment-methods", paymentHandler.GetPaymentMethods)
+	adminProtected.GET("/orders/:id/payments", paymentHandler.GetOrderPayments)
+	adminProtected.POST("/orders/:id/payments", paymentHandler.RecordPayment)
+	adminProtected.POST("/orders/:id/payment-intents", paymentHandler.CreatePaymentIntent)
+	adminProtected.POST("/payments/:id/refunds", paymentHandler.RefundPayment)
+	adminProtected.POST("/payments/:id/sync", paymentHandler.SyncPaymentStatus)
+}
@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPriceListRoutes configures scheduled price list (happy hour, time-based menu) routes
+func SetupPriceListRoutes(adminProtected *gin.RouterGroup, priceListHandler *handler.PriceListHandler) {
+	adminProtected.POST("/price-lists", priceListHandler.CreatePriceList)
+	adminProtected.GET("/price-lists", priceListHandler.ListPriceLists)
+	adminProtected.GET("/price-lists/menu", priceListHandler.PreviewMenu)
+	adminProtected.GET("/price-lists/:id", priceListHandler.GetPriceList)
+	adminProtected.PUT("/price-lists/:id", priceListHandler.UpdatePriceList)
+	adminProtected.DELETE("/price-lists/:id", priceListHandler.DeletePriceList)
+}
@@ -13,8 +13,15 @@ func SetupProductRoutes(adminProtected *gin.RouterGroup, productHandler *handler
 	adminProtected.GET("/products", productHandler.ListProducts)
 	adminProtected.GET("/products/:id", productHandler.GetProductByID)
 	adminProtected.PUT("/products/:id", productHandler.UpdateProduct)
+	adminProtected.DELETE("/products/:id", productHandler.ArchiveProduct)
+	adminProtected.POST("/products/:id/restore", productHandler.RestoreProduct)
 
 	// Variant routes
 	adminProtected.POST("/variants", variantHandler.CreateVariant)
 	adminProtected.GET("/variants", variantHandler.ListVariantsByProduct)
+	adminProtected.DELETE("/variants/:variant_public_id", variantHandler.ArchiveVariant)
+	adminProtected.POST("/variants/:variant_public_id/restore", variantHandler.RestoreVariant)
+	adminProtected.GET("/variants/:variant_public_id/price-history", variantHandler.GetPriceHistory)
+	adminProtected.GET("/variants/:variant_public_id/price", variantHandler.GetPriceAt)
+	adminProtected.GET("/variants/:variant_public_id/price-impact", variantHandler.GetPriceChangeImpact)
 } 
\ No newline at end of file
@@ -0,0 +1,17 @@
+package admin
+
+import (
+	"food-pos-backend/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPromotionRoutes configures automatic promotion routes
+func SetupPromotionRoutes(adminProtected *gin.RouterGroup, promotionHandler *handler.PromotionHandler) {
+	adminProtected.POST("/promotions", promotionHandler.CreatePromotion)
+	adminProtected.GET("/promotions", promotionHandler.ListPromotions)
+	adminProtected.POST("/promotions/preview", promotionHandler.PreviewPromotions)
+	adminProtected.GET("/promotions/:id", promotionHandler.GetPromotion)
+	adminProtected.PUT("/promotions/:id", promotionHandler.UpdatePromotion)
+	adminProtected.DELETE("/promotions/:id", promotionHandler.DeletePromotion)
+}
@@ -13,6 +13,8 @@ func SetupShipperRoutes(adminProtected *gin.RouterGroup, shipperHandler *handler
 	adminProtected.GET("/shippers", shipperHandler.ListShippers)
 	adminProtected.GET("/shippers/:id", shipperHandler.GetShipper)
 	adminProtected.PUT("/shippers/:id", shipperHandler.UpdateShipper)
-	adminProtected.DELETE("/shippers/:id", shipperHandler.DeleteShipper)
+	adminProtected.DELETE("/shippers/:id", shipperHandler.ArchiveShipper)
+	adminProtected.POST("/shippers/:id/restore", shipperHandler.RestoreShipper)
 	adminProtected.GET("/shippers/active", shipperHandler.GetActiveShippers)
+	adminProtected.PUT("/shippers/:id/account", shipperHandler.SetShipperAccount)
 } 
\ No newline at end of file
@@ -1,6 +1,9 @@
 package routes
 
 import (
+	"time"
+
+	"food-pos-backend/config"
 	"food-pos-backend/internal/handler"
 	"food-pos-backend/internal/jwt"
 	"food-pos-backend/internal/middleware"
@@ -11,8 +14,8 @@ import (
 )
 
 // SetupRoutes configures all routes for the application
-func SetupRoutes(r *gin.Engine, jwtService *jwt.JWTService, adminHandler *handler.AdminHandler, productHandler *handler.ProductHandler, variantHandler *handler.VariantHandler, ingredientHandler *handler.IngredientHandler, orderHandler *handler.OrderHandler, shipperHandler *handler.ShipperHandler, deliveryHandler *handler.DeliveryHandler, adminUserHandler *handler.AdminUserHandler, wsHandler *handler.WebSocketHandler) {
-	// Add WebSocket route (public, can add auth later)
+func SetupRoutes(r *gin.Engine, jwtService *jwt.JWTService, adminHandler *handler.AdminHandler, productHandler *handler.ProductHandler, variantHandler *handler.VariantHandler, categoryHandler *handler.CategoryHandler, availabilityHandler *handler.AvailabilityHandler, ingredientHandler *handler.IngredientHandler, orderHandler *handler.OrderHandler, shipperHandler *handler.ShipperHandler, deliveryHandler *handler.DeliveryHandler, adminUserHandler *handler.AdminUserHandler, discountHandler *handler.DiscountHandler, inventoryHandler *handler.InventoryHandler, modifierHandler *handler.ModifierHandler, kitchenHandler *handler.KitchenHandler, paymentHandler *handler.PaymentHandler, cashSettlementHandler *handler.CashSettlementHandler, assignmentHandler *handler.AssignmentHandler, deliveryZoneHandler *handler.DeliveryZoneHandler, customerAddressHandler *handler.CustomerAddressHandler, customerMergeHandler *handler.CustomerMergeHandler, loyaltyHandler *handler.LoyaltyHandler, promotionHandler *handler.PromotionHandler, priceListHandler *handler.PriceListHandler, portalHandler *handler.PortalHandler, customerAuthHandler *handler.CustomerAuthHandler, shipperAppHandler *handler.ShipperAppHandler, wsHandler *handler.WebSocketHandler, portalConfig config.PortalConfig, mockPaymentsEnabled bool) {
+	// Add WebSocket route (JWT is validated by the handler during the upgrade)
 	r.GET("/ws", wsHandler.HandleWebSocket)
 
 	// API routes group
@@ -33,23 +36,114 @@ func SetupRoutes(r *gin.Engine, jwtService *jwt.JWTService, adminHandler *handle
 			{
 				// Setup all admin routes
 				adminHandlers := &admin.AdminHandlers{
-					ProductHandler:    productHandler,
-					VariantHandler:    variantHandler,
-					IngredientHandler: ingredientHandler,
-					OrderHandler:      orderHandler,
-					ShipperHandler:    shipperHandler,
-					DeliveryHandler:   deliveryHandler,
-					AdminUserHandler:  adminUserHandler,
+					ProductHandler:         productHandler,
+					VariantHandler:         variantHandler,
+					CategoryHandler:        categoryHandler,
+					AvailabilityHandler:    availabilityHandler,
+					IngredientHandler:      ingredientHandler,
+					OrderHandler:           orderHandler,
+					ShipperHandler:         shipperHandler,
+					DeliveryHandler:        deliveryHandler,
+					AdminUserHandler:       adminUserHandler,
+					DiscountHandler:        discountHandler,
+					InventoryHandler:       inventoryHandler,
+					ModifierHandler:        modifierHandler,
+					KitchenHandler:         kitchenHandler,
+					PaymentHandler:         paymentHandler,
+					CashSettlementHandler:  cashSettlementHandler,
+					AssignmentHandler:      assignmentHandler,
+					DeliveryZoneHandler:    deliveryZoneHandler,
+					CustomerAddressHandler: customerAddressHandler,
+					CustomerMergeHandler:   customerMergeHandler,
+					LoyaltyHandler:         loyaltyHandler,
+					PromotionHandler:       promotionHandler,
+					PriceListHandler:       priceListHandler,
 				}
 				admin.SetupAllAdminRoutes(adminProtected, adminHandlers)
 			}
 		}
 
-		// Public routes (if any)
-		_ = api.Group("/public")
+		// Kitchen display routes (kitchen staff and admins)
+		kitchenGroup := api.Group("/kitchen")
+		kitchenGroup.Use(middleware.AuthMiddleware(jwtService))
+		kitchenGroup.Use(middleware.RoleMiddleware("kitchen", "admin", "super_admin"))
+		{
+			kitchenGroup.GET("/stations", kitchenHandler.ListStations)
+			kitchenGroup.GET("/queue", kitchenHandler.GetQueue)
+			kitchenGroup.PUT("/tickets/:id/status", kitchenHandler.UpdateTicketStatus)
+		}
+
+		// Stricter per client IP limit for logins, OTP codes and checkout
+		checkoutLimit := middleware.RateLimitMiddleware(middleware.NewRateLimiter(portalConfig.CheckoutRateLimit, time.Minute))
+
+		// Shipper mobile app routes
+		shipperGroup := api.Group("/shipper")
+		{
+			// Login and OTP share the stricter limit against password and code guessing
+			shipperGroup.POST("/login", checkoutLimit, shipperAppHandler.Login)
+			shipperGroup.POST("/otp/request", checkoutLimit, shipperAppHandler.RequestLoginOTP)
+			shipperGroup.POST("/otp/verify", checkoutLimit, shipperAppHandler.LoginWithOTP)
+
+			shipperProtected := shipperGroup.Group("")
+			shipperProtected.Use(middleware.AuthMiddleware(jwtService))
+			shipperProtected.Use(middleware.RoleMiddleware("shipper"))
+			{
+				shipperProtected.GET("/me", shipperAppHandler.GetProfile)
+				shipperProtected.GET("/deliveries", shipperAppHandler.ListDeliveries)
+				shipperProtected.GET("/deliveries/:id", shipperAppHandler.GetDelivery)
+				shipperProtected.POST("/deliveries/:id/accept", shipperAppHandler.AcceptDelivery)
+				shipperProtected.POST("/deliveries/:id/reject", shipperAppHandler.RejectDelivery)
+				shipperProtected.PUT("/deliveries/:id/status", shipperAppHandler.UpdateDeliveryStatus)
+				shipperProtected.POST("/deliveries/:id/proofs", shipperAppHandler.AddDeliveryProof)
+				shipperProtected.GET("/deliveries/:id/proofs/:proofId/file", shipperAppHandler.GetDeliveryProofFile)
+				shipperProtected.GET("/proof-policy", shipperAppHandler.GetProofPolicy)
+				shipperProtected.GET("/cash", shipperAppHandler.GetCashBalance)
+				shipperProtected.PUT("/shift", shipperAppHandler.SetShift)
+			}
+		}
+
+		// Payment gateway callbacks (verified by signature, no JWT)
+		paymentGroup := api.Group("/payments")
+		{
+			paymentGroup.GET("/:provider/ipn", paymentHandler.HandleIPN)
+			paymentGroup.POST("/:provider/ipn", paymentHandler.HandleIPN)
+			paymentGroup.GET("/:provider/return", paymentHandler.HandleReturn)
+			// Local gateway simulator, paying needs no credentials so it is never served in production
+			if mockPaymentsEnabled {
+				paymentGroup.GET("/mock/:provider/checkout", paymentHandler.MockCheckout)
+			}
+		}
+
+		// Public customer portal routes (no login, rate limited per client IP)
+		publicGroup := api.Group("/public")
+		publicGroup.Use(middleware.RateLimitMiddleware(middleware.NewRateLimiter(portalConfig.RateLimit, time.Minute)))
+		{
+			publicGroup.GET("/menu", portalHandler.GetMenu)
+			publicGroup.GET("/categories", portalHandler.GetMenuCategories)
+			publicGroup.GET("/ws", wsHandler.HandlePublicWebSocket)
+			publicGroup.POST("/cart/quote", portalHandler.QuoteCart)
+
+			// Checkout and tracking get the stricter limit against spam orders and order number guessing
+			publicGroup.POST("/checkout", checkoutLimit, portalHandler.Checkout)
+			publicGroup.GET("/orders/track", checkoutLimit, portalHandler.TrackOrder)
+			publicGroup.POST("/orders/pay", checkoutLimit, portalHandler.PayOrder)
+
+			// Customer accounts share the stricter limit against password guessing
+			publicGroup.POST("/auth/register", checkoutLimit, customerAuthHandler.Register)
+			publicGroup.POST("/auth/register/otp", checkoutLimit, customerAuthHandler.RequestRegisterOTP)
+			publicGroup.POST("/auth/login", checkoutLimit, customerAuthHandler.Login)
+			publicGroup.POST("/auth/otp/request", checkoutLimit, customerAuthHandler.RequestLoginOTP)
+			publicGroup.POST("/auth/otp/verify", checkoutLimit, customerAuthHandler.LoginWithOTP)
+		}
+
+		// Logged in customer routes
+		customerGroup := api.Group("/customer")
+		customerGroup.Use(middleware.AuthMiddleware(jwtService))
+		customerGroup.Use(middleware.RoleMiddleware(jwt.RoleClient))
 		{
-			// Add public routes here when needed
-			// public.GET("/products", productHandler.ListPublicProducts)
+			customerGroup.GET("/me", customerAuthHandler.GetProfile)
+			customerGroup.GET("/loyalty", loyaltyHandler.GetMyAccount)
+			customerGroup.GET("/loyalty/ledger", loyaltyHandler.ListMyLedger)
 		}
 	}
 
@@ -25,7 +25,7 @@ func (s *AdminService) Login(username, password string) (string, error) {
 	if err != nil {
 		return "", errors.New("invalid username or password")
 	}
-	if !user.IsActive || user.Role != "super_admin" {
+	if !user.IsActive || (user.Role != "super_admin" && user.Role != "kitchen") {
 		return "", errors.New("unauthorized")
 	}
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
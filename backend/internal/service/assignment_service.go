@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+
+	"food-pos-backend/internal/assignment"
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/ws"
+)
+
+// AssignmentService picks shippers for orders that are ready for delivery
+type AssignmentService struct {
+	shipperRepo     *repository.ShipperRepository
+	orderRepo       *repository.OrderRepository
+	deliveryRepo    *repository.DeliveryRepository
+	paymentRepo     *repository.PaymentRepository
+	deliveryService *DeliveryService
+	strategy        assignment.Strategy
+	mode            string
+	hub             *ws.Hub
+}
+
+func NewAssignmentService(shipperRepo *repository.ShipperRepository, orderRepo *repository.OrderRepository, deliveryRepo *repository.DeliveryRepository, paymentRepo *repository.PaymentRepository, deliveryService *DeliveryService, strategy assignment.Strategy, mode string, hub *ws.Hub) *AssignmentService {
+	return &AssignmentService{
+		shipperRepo:     shipperRepo,
+		orderRepo:       orderRepo,
+		deliveryRepo:    deliveryRepo,
+		paymentRepo:     paymentRepo,
+		deliveryService: deliveryService,
+		strategy:        strategy,
+		mode:            mode,
+		hub:             hub,
+	}
+}
+
+// ListEligibleShippers lists the shippers the engine may pick right now
+func (s *AssignmentService) ListEligibleShippers(ctx context.Context) ([]model.ShipperCandidate, error) {
+	return s.shipperRepo.ListAssignmentCandidates(ctx)
+}
+
+// ProposeShipper runs the strategy for an order without assigning anyone
+func (s *AssignmentService) ProposeShipper(ctx context.Context, orderPublicID string) (*model.ShipperProposal, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, orderPublicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return s.propose(ctx, order)
+}
+
+// AutoAssign creates a delivery for the whole order with the shipper picked by the strategy
+func (s *AssignmentService) AutoAssign(ctx context.Context, orderPublicID string, userID int64) (*model.ShipperProposal, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, orderPublicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return s.autoAssign(ctx, order, userID)
+}
+
+// HandleOrderReady runs the engine in the configured mode when an order becomes ready_for_delivery.
+// Failures are only reported to the admins, they never block the order status change
+func (s *AssignmentService) HandleOrderReady(ctx context.Context, orderPublicID string, userID int64) {
+	if s.mode != assignment.ModePropose && s.mode != assignment.ModeAuto {
+		return
+	}
+	// Reload the order with its items and delivery zone
+	order, err := s.orderRepo.GetOrderByID(ctx, orderPublicID)
+	if err != nil {
+		log.Printf("Shipper assignment for order %s failed: %v", orderPublicID, err)
+		return
+	}
+
+	var proposal *model.ShipperProposal
+	if s.mode == assignment.ModeAuto {
+		proposal, err = s.autoAssign(ctx, order, userID)
+	} else {
+		proposal, err = s.propose(ctx, order)
+	}
+	if err != nil {
+		log.Printf("Shipper assignment for order %s failed: %v", order.OrderNumber, err)
+		if validationErr, ok := err.(*model.ValidationError); ok {
+			s.broadcast("shipper_assignment_failed", map[string]interface{}{"order_id": order.PublicID, "message": validationErr.Message})
+		}
+		return
+	}
+	s.broadcast("shipper_proposal", map[string]interface{}{"proposal": proposal})
+}
+
+func (s *AssignmentService) propose(ctx context.Context, order *model.Order) (*model.ShipperProposal, error) {
+	candidates, err := s.shipperRepo.ListAssignmentCandidates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal := &model.ShipperProposal{
+		OrderID:       order.PublicID,
+		Strategy:      s.strategy.Name(),
+		EligibleCount: len(candidates),
+	}
+	if picked := s.strategy.Pick(order, candidates); picked != nil {
+		shipper := picked.Shipper
+		proposal.Shipper = &shipper
+		proposal.ActiveDeliveries = picked.ActiveDeliveries
+	}
+	return proposal, nil
+}
+
+func (s *AssignmentService) autoAssign(ctx context.Context, order *model.Order, userID int64) (*model.ShipperProposal, error) {
+	if order.Status != model.OrderStatusReadyForDelivery {
+		return nil, model.NewValidationError("status", "Đơn hàng phải ở trạng thái sẵn sàng giao hàng")
+	}
+	hasDelivery, err := s.deliveryRepo.HasActiveDelivery(ctx, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hasDelivery {
+		return nil, model.NewValidationError("order_id", "Đơn hàng đã có đơn giao đang xử lý")
+	}
+
+	proposal, err := s.propose(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Shipper == nil {
+		return nil, model.NewValidationError("shipper_id", "Không có shipper phù hợp đang trong ca")
+	}
+
+	items := make([]model.DeliveryItemRequest, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, model.DeliveryItemRequest{
+			OrderItemID: strconv.FormatInt(item.ID, 10),
+			Quantity:    item.Quantity,
+		})
+	}
+	codAmount, err := s.codAmount(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	deliveryOrder, err := s.deliveryService.CreateDeliveryOrder(ctx, &model.CreateDeliveryOrderRequest{
+		OrderID:       order.PublicID,
+		ShipperID:     proposal.Shipper.PublicID.String(),
+		DeliveryNotes: "Tự động gán shipper (" + s.strategy.Name() + ")",
+		CodAmount:     codAmount,
+		Items:         items,
+	}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal.Assigned = true
+	proposal.DeliveryID = deliveryOrder.PublicID
+	return proposal, nil
+}
+
+// codAmount is the cash the shipper collects: the unpaid balance of cash orders, nothing for prepaid ones
+func (s *AssignmentService) codAmount(ctx context.Context, order *model.Order) (float64, error) {
+	if order.PaymentMethod != "cash" {
+		return 0, nil
+	}
+	summary, err := s.paymentRepo.GetOrderPaymentSummary(ctx, order.PublicID)
+	if err != nil {
+		return 0, err
+	}
+	if summary == nil {
+		return 0, ErrNotFound
+	}
+	return summary.Balance, nil
+}
+
+func (s *AssignmentService) broadcast(action string, payload map[string]interface{}) {
+	if s.hub == nil {
+		return
+	}
+	payload["action"] = action
+	event := ws.Event{
+		Type:    ws.EventNotification,
+		Payload: payload,
+	}
+	if data, err := json.Marshal(event); err == nil {
+		s.hub.BroadcastToGroup("admin", data)
+	}
+}
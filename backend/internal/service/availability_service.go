@@ -0,0 +1,144 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/ws"
+)
+
+// AvailabilityService marks products and variants as sold out and pushes every
+// change to POS terminals and the public menu
+type AvailabilityService struct {
+	availabilityRepo *repository.AvailabilityRepository
+	hub              *ws.Hub
+
+	mu    sync.Mutex
+	known map[string]bool // Variant public ID -> last broadcast availability
+}
+
+func NewAvailabilityService(availabilityRepo *repository.AvailabilityRepository, hub *ws.Hub) *AvailabilityService {
+	return &AvailabilityService{
+		availabilityRepo: availabilityRepo,
+		hub:              hub,
+	}
+}
+
+// ListAvailability lists the current availability of every variant
+func (s *AvailabilityService) ListAvailability(ctx context.Context) ([]*model.VariantAvailability, error) {
+	return s.availabilityRepo.ListVariantAvailability(ctx)
+}
+
+// SetProductAvailability marks a whole product as available or sold out
+func (s *AvailabilityService) SetProductAvailability(ctx context.Context, publicID string, req *model.UpdateAvailabilityRequest) ([]*model.VariantAvailability, error) {
+	if err := validateAvailability(req); err != nil {
+		return nil, err
+	}
+	variants, err := s.availabilityRepo.SetProductAvailability(ctx, publicID, req)
+	if err != nil {
+		return nil, err
+	}
+	if variants == nil {
+		return nil, ErrNotFound
+	}
+	s.publish(variants)
+	return variants, nil
+}
+
+// SetVariantAvailability marks a single variant as available or sold out
+func (s *AvailabilityService) SetVariantAvailability(ctx context.Context, publicID string, req *model.UpdateVariantAvailabilityRequest) (*model.VariantAvailability, error) {
+	if err := validateAvailability(&req.UpdateAvailabilityRequest); err != nil {
+		return nil, err
+	}
+	variant, err := s.availabilityRepo.SetVariantAvailability(ctx, publicID, req)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, ErrNotFound
+	}
+	s.publish([]*model.VariantAvailability{variant})
+	return variant, nil
+}
+
+// CheckAvailability puts items whose back-at time has passed back on sale and
+// broadcasts every variant whose availability changed since the last check,
+// which also covers sell outs driven by ingredient stock
+func (s *AvailabilityService) CheckAvailability(ctx context.Context) error {
+	if _, err := s.availabilityRepo.RestoreDue(ctx); err != nil {
+		return err
+	}
+	variants, err := s.availabilityRepo.ListVariantAvailability(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	first := s.known == nil
+	if first {
+		s.known = make(map[string]bool, len(variants))
+	}
+	changed := make([]*model.VariantAvailability, 0)
+	for _, variant := range variants {
+		if previous, ok := s.known[variant.VariantID]; !first && (!ok || previous != variant.IsAvailable) {
+			changed = append(changed, variant)
+		}
+		s.known[variant.VariantID] = variant.IsAvailable
+	}
+	s.mu.Unlock()
+
+	s.broadcast(changed)
+	return nil
+}
+
+// RunAvailabilityChecks runs CheckAvailability every interval, it blocks and
+// is meant to run in its own goroutine
+func (s *AvailabilityService) RunAvailabilityChecks(interval time.Duration) {
+	if err := s.CheckAvailability(context.Background()); err != nil {
+		log.Printf("Availability check failed: %v", err)
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := s.CheckAvailability(context.Background()); err != nil {
+			log.Printf("Availability check failed: %v", err)
+		}
+	}
+}
+
+// publish records and broadcasts availability changed by staff
+func (s *AvailabilityService) publish(variants []*model.VariantAvailability) {
+	s.mu.Lock()
+	if s.known != nil {
+		for _, variant := range variants {
+			s.known[variant.VariantID] = variant.IsAvailable
+		}
+	}
+	s.mu.Unlock()
+	s.broadcast(variants)
+}
+
+func (s *AvailabilityService) broadcast(variants []*model.VariantAvailability) {
+	if s.hub == nil || len(variants) == 0 {
+		return
+	}
+	event := ws.Event{
+		Type:    ws.EventAvailabilityUpdate,
+		Payload: model.AvailabilityUpdate{Variants: variants},
+	}
+	if data, err := json.Marshal(event); err == nil {
+		s.hub.Broadcast(data)
+	}
+}
+
+func validateAvailability(req *model.UpdateAvailabilityRequest) error {
+	if !*req.IsAvailable && req.AvailableAt != nil && !req.AvailableAt.After(time.Now()) {
+		return model.NewValidationError("available_at", "Giờ có lại món phải ở tương lai")
+	}
+	return nil
+}
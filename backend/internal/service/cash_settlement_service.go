@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/ws"
+
+	"github.com/google/uuid"
+)
+
+// CashSettlementService tracks the cash on delivery held by shippers and its hand-over
+type CashSettlementService struct {
+	settlementRepo *repository.CashSettlementRepository
+	shipperRepo    *repository.ShipperRepository
+	hub            *ws.Hub
+}
+
+func NewCashSettlementService(settlementRepo *repository.CashSettlementRepository, shipperRepo *repository.ShipperRepository, hub *ws.Hub) *CashSettlementService {
+	return &CashSettlementService{
+		settlementRepo: settlementRepo,
+		shipperRepo:    shipperRepo,
+		hub:            hub,
+	}
+}
+
+// ListShipperCashBalances lists the shippers currently holding COD cash
+func (s *CashSettlementService) ListShipperCashBalances(ctx context.Context) ([]*model.ShipperCashBalance, error) {
+	return s.settlementRepo.ListShipperCashBalances(ctx)
+}
+
+// GetShipperCashBalance gets the COD cash a shipper has to hand over
+func (s *CashSettlementService) GetShipperCashBalance(ctx context.Context, shipperPublicID string) (*model.ShipperCashBalance, error) {
+	shipper, err := s.getShipper(ctx, shipperPublicID)
+	if err != nil {
+		return nil, err
+	}
+	return s.settlementRepo.GetShipperCashBalance(ctx, shipper)
+}
+
+// GetOwnCashBalance gets the COD cash held by the logged in shipper
+func (s *CashSettlementService) GetOwnCashBalance(ctx context.Context, shipper *model.Shipper) (*model.ShipperCashBalance, error) {
+	return s.settlementRepo.GetShipperCashBalance(ctx, shipper)
+}
+
+// SettleShipperCash confirms the cash received from a shipper against their delivered COD orders
+func (s *CashSettlementService) SettleShipperCash(ctx context.Context, shipperPublicID string, req *model.CreateCashSettlementRequest, userID int64) (*model.CashSettlement, error) {
+	if req.ReceivedAmount == nil || *req.ReceivedAmount < 0 {
+		return nil, model.NewValidationError("received_amount", "Số tiền nhận không hợp lệ")
+	}
+	shipper, err := s.getShipper(ctx, shipperPublicID)
+	if err != nil {
+		return nil, err
+	}
+
+	receivedAmount := math.Round(*req.ReceivedAmount*100) / 100
+	publicID, err := s.settlementRepo.CreateSettlement(ctx, shipper.ID, req.DeliveryIDs, receivedAmount, req.Notes, userID)
+	if err != nil {
+		return nil, err
+	}
+	settlement, err := s.settlementRepo.GetSettlementByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	if settlement.Discrepancy != 0 {
+		s.reportDiscrepancy(settlement)
+	}
+	return settlement, nil
+}
+
+// GetSettlement gets a settlement with the deliveries it covers
+func (s *CashSettlementService) GetSettlement(ctx context.Context, publicID string) (*model.CashSettlement, error) {
+	settlement, err := s.settlementRepo.GetSettlementByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if settlement == nil {
+		return nil, ErrNotFound
+	}
+	return settlement, nil
+}
+
+// ListSettlements lists settlements, has_discrepancy=true gives the discrepancy report
+func (s *CashSettlementService) ListSettlements(ctx context.Context, req *model.ListCashSettlementsRequest) (*model.ListCashSettlementsResponse, error) {
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+	return s.settlementRepo.ListSettlements(ctx, req)
+}
+
+func (s *CashSettlementService) getShipper(ctx context.Context, publicID string) (*model.Shipper, error) {
+	shipperUUID, err := uuid.Parse(publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	shipper, err := s.shipperRepo.GetShipperByPublicID(ctx, shipperUUID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return shipper, nil
+}
+
+// reportDiscrepancy notifies the admins that a shipper handed over the wrong amount
+func (s *CashSettlementService) reportDiscrepancy(settlement *model.CashSettlement) {
+	if s.hub == nil {
+		return
+	}
+	event := ws.Event{
+		Type: ws.EventNotification,
+		Payload: map[string]interface{}{
+			"action":     "cash_settlement_discrepancy",
+			"settlement": settlement,
+		},
+	}
+	if data, err := json.Marshal(event); err == nil {
+		s.hub.BroadcastToGroup("admin", data)
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+// CategoryService manages the menu categories products are grouped into
+type CategoryService struct {
+	categoryRepo *repository.CategoryRepository
+}
+
+func NewCategoryService(categoryRepo *repository.CategoryRepository) *CategoryService {
+	return &CategoryService{categoryRepo: categoryRepo}
+}
+
+// ListCategories returns the categories as a tree, each level in sort order
+func (s *CategoryService) ListCategories(ctx context.Context) ([]*model.Category, error) {
+	categories, err := s.categoryRepo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return buildCategoryTree(categories), nil
+}
+
+// GetCategory gets a category by public ID
+func (s *CategoryService) GetCategory(ctx context.Context, publicID string) (*model.Category, error) {
+	category, err := s.categoryRepo.GetCategoryByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, ErrNotFound
+	}
+	return category, nil
+}
+
+// CreateCategory creates a new category
+func (s *CategoryService) CreateCategory(ctx context.Context, req *model.CreateCategoryRequest) (*model.Category, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, model.NewValidationError("name", "Vui lòng nhập tên danh mục")
+	}
+	parentID, err := s.resolveParent(ctx, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.categoryRepo.CreateCategory(ctx, req, parentID)
+}
+
+// UpdateCategory updates a category. A category cannot be moved under itself
+// or one of its subcategories.
+func (s *CategoryService) UpdateCategory(ctx context.Context, publicID string, req *model.UpdateCategoryRequest) (*model.Category, error) {
+	category, err := s.GetCategory(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, model.NewValidationError("name", "Vui lòng nhập tên danh mục")
+	}
+	parentID, err := s.resolveParent(ctx, req.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	if parentID != nil {
+		cycle, err := s.categoryRepo.IsDescendant(ctx, category.ID, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		if cycle {
+			return nil, model.NewValidationError("parent_id", "Không thể chuyển danh mục vào chính nó hoặc danh mục con của nó")
+		}
+	}
+	return s.categoryRepo.UpdateCategory(ctx, category.ID, req, parentID)
+}
+
+// DeleteCategory deletes a category without subcategories. Its products
+// become uncategorized.
+func (s *CategoryService) DeleteCategory(ctx context.Context, publicID string) error {
+	category, err := s.GetCategory(ctx, publicID)
+	if err != nil {
+		return err
+	}
+	hasChildren, err := s.categoryRepo.HasChildren(ctx, category.ID)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return model.NewValidationError("id", "Danh mục còn danh mục con, hãy chuyển hoặc xóa danh mục con trước")
+	}
+	return s.categoryRepo.DeleteCategory(ctx, category.ID)
+}
+
+// ReorderCategories sets the order of the children of a category, or of the
+// root categories when no parent is given
+func (s *CategoryService) ReorderCategories(ctx context.Context, req *model.ReorderCategoriesRequest) error {
+	parentID, err := s.resolveParent(ctx, req.ParentID)
+	if err != nil {
+		return err
+	}
+	return s.categoryRepo.ReorderCategories(ctx, parentID, uniqueStrings(req.CategoryIDs))
+}
+
+// SetCategoryProducts sets the products of a category in menu order
+func (s *CategoryService) SetCategoryProducts(ctx context.Context, publicID string, req *model.SetCategoryProductsRequest) (*model.Category, error) {
+	category, err := s.GetCategory(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.categoryRepo.SetCategoryProducts(ctx, category.ID, uniqueStrings(req.ProductIDs)); err != nil {
+		return nil, err
+	}
+	return s.GetCategory(ctx, publicID)
+}
+
+// resolveParent returns the internal ID of a parent category, nil for the root
+func (s *CategoryService) resolveParent(ctx context.Context, parentPublicID *string) (*int64, error) {
+	if parentPublicID == nil || *parentPublicID == "" {
+		return nil, nil
+	}
+	parent, err := s.categoryRepo.GetCategoryByPublicID(ctx, *parentPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if parent == nil {
+		return nil, model.NewValidationError("parent_id", "Danh mục cha không tồn tại")
+	}
+	return &parent.ID, nil
+}
+
+// buildCategoryTree nests a flat list of categories, parents listed before
+// their children, under their parents
+func buildCategoryTree(categories []*model.Category) []*model.Category {
+	byID := make(map[int64]*model.Category, len(categories))
+	roots := make([]*model.Category, 0)
+	for _, category := range categories {
+		category.Children = make([]*model.Category, 0)
+		byID[category.ID] = category
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else if parent, ok := byID[*category.ParentID]; ok {
+			parent.Children = append(parent.Children, category)
+		}
+	}
+	return roots
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"food-pos-backend/internal/geo"
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+type CustomerAddressService struct {
+	addressRepo *repository.CustomerAddressRepository
+}
+
+func NewCustomerAddressService(addressRepo *repository.CustomerAddressRepository) *CustomerAddressService {
+	return &CustomerAddressService{addressRepo: addressRepo}
+}
+
+// ListAddresses lists the saved addresses of a user
+func (s *CustomerAddressService) ListAddresses(ctx context.Context, userPublicID string) ([]model.CustomerAddress, error) {
+	userID, err := s.getUserID(ctx, userPublicID)
+	if err != nil {
+		return nil, err
+	}
+	return s.addressRepo.ListAddresses(ctx, userID)
+}
+
+// CreateAddress saves a new address for a user
+func (s *CustomerAddressService) CreateAddress(ctx context.Context, userPublicID string, req *model.CustomerAddressRequest) (*model.CustomerAddress, error) {
+	userID, err := s.getUserID(ctx, userPublicID)
+	if err != nil {
+		return nil, err
+	}
+
+	address := &model.CustomerAddress{UserID: userID}
+	if err := applyAddressRequest(address, req); err != nil {
+		return nil, err
+	}
+	if err := s.addressRepo.CreateAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// UpdateAddress updates a saved address of a user
+func (s *CustomerAddressService) UpdateAddress(ctx context.Context, userPublicID, addressPublicID string, req *model.CustomerAddressRequest) (*model.CustomerAddress, error) {
+	address, err := s.getAddress(ctx, userPublicID, addressPublicID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Không bỏ mặc định trực tiếp, hãy chọn địa chỉ khác làm mặc định
+	wasDefault := address.IsDefault
+	if err := applyAddressRequest(address, req); err != nil {
+		return nil, err
+	}
+	address.IsDefault = address.IsDefault || wasDefault
+
+	if err := s.addressRepo.UpdateAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// SetDefaultAddress makes a saved address the default address of a user
+func (s *CustomerAddressService) SetDefaultAddress(ctx context.Context, userPublicID, addressPublicID string) (*model.CustomerAddress, error) {
+	address, err := s.getAddress(ctx, userPublicID, addressPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if address.IsDefault {
+		return address, nil
+	}
+
+	address.IsDefault = true
+	if err := s.addressRepo.UpdateAddress(ctx, address); err != nil {
+		return nil, err
+	}
+	return address, nil
+}
+
+// DeleteAddress deletes a saved address of a user
+func (s *CustomerAddressService) DeleteAddress(ctx context.Context, userPublicID, addressPublicID string) error {
+	address, err := s.getAddress(ctx, userPublicID, addressPublicID)
+	if err != nil {
+		return err
+	}
+	return s.addressRepo.DeleteAddress(ctx, address)
+}
+
+func (s *CustomerAddressService) getUserID(ctx context.Context, userPublicID string) (int64, error) {
+	userID, err := s.addressRepo.GetUserDBID(ctx, userPublicID)
+	if err != nil {
+		return 0, err
+	}
+	if userID == nil {
+		return 0, ErrNotFound
+	}
+	return *userID, nil
+}
+
+// getAddress gets a saved address, making sure it belongs to the user
+func (s *CustomerAddressService) getAddress(ctx context.Context, userPublicID, addressPublicID string) (*model.CustomerAddress, error) {
+	userID, err := s.getUserID(ctx, userPublicID)
+	if err != nil {
+		return nil, err
+	}
+	address, err := s.addressRepo.GetAddressByPublicID(ctx, addressPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if address == nil || address.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return address, nil
+}
+
+// applyAddressRequest validates an address request and copies it onto the address
+func applyAddressRequest(address *model.CustomerAddress, req *model.CustomerAddressRequest) error {
+	addressLine := strings.TrimSpace(req.AddressLine)
+	if addressLine == "" {
+		return model.NewValidationError("address_line", "Địa chỉ không được để trống")
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return model.NewValidationError("latitude", "Phải nhập đủ vĩ độ và kinh độ")
+	}
+	if req.Latitude != nil && !(geo.Point{Lat: *req.Latitude, Lng: *req.Longitude}).Valid() {
+		return model.NewValidationError("latitude", "Tọa độ không hợp lệ")
+	}
+
+	address.Label = strings.TrimSpace(req.Label)
+	address.AddressLine = addressLine
+	address.Ward = strings.TrimSpace(req.Ward)
+	address.District = strings.TrimSpace(req.District)
+	address.Province = strings.TrimSpace(req.Province)
+	address.Latitude = req.Latitude
+	address.Longitude = req.Longitude
+	address.IsDefault = req.IsDefault
+	return nil
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"food-pos-backend/internal/jwt"
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrInvalidCredentials is returned when a customer login does not match an account
+var ErrInvalidCredentials = errors.New("invalid login or password")
+
+// CustomerAuthService registers and logs in portal customers
+type CustomerAuthService struct {
+	accountRepo *repository.CustomerAccountRepository
+	otpService  *OTPService
+	jwtService  *jwt.JWTService
+}
+
+func NewCustomerAuthService(accountRepo *repository.CustomerAccountRepository, otpService *OTPService, jwtService *jwt.JWTService) *CustomerAuthService {
+	return &CustomerAuthService{
+		accountRepo: accountRepo,
+		otpService:  otpService,
+		jwtService:  jwtService,
+	}
+}
+
+// Register creates a customer account once the phone is verified with an OTP,
+// so nobody can register a phone that is not theirs. A guest user created by
+// earlier orders with the same phone is upgraded instead, so the customer keeps
+// their order history.
+func (s *CustomerAuthService) Register(ctx context.Context, req *model.CustomerRegisterRequest) (*model.CustomerAuthResponse, error) {
+	fullName := strings.TrimSpace(req.FullName)
+	phone := normalizePhone(req.Phone)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if fullName == "" {
+		return nil, model.NewValidationError("full_name", "Vui lòng nhập họ tên")
+	}
+	if !isValidPhone(phone) {
+		return nil, model.NewValidationError("phone", "Số điện thoại không hợp lệ")
+	}
+	if email != "" && !strings.Contains(email, "@") {
+		return nil, model.NewValidationError("email", "Email không hợp lệ")
+	}
+	if len(req.Password) < 6 {
+		return nil, model.NewValidationError("password", "Mật khẩu phải có ít nhất 6 ký tự")
+	}
+	code := strings.TrimSpace(req.OTP)
+	if code == "" {
+		return nil, model.NewValidationError("otp", "Vui lòng nhập mã OTP được gửi tới số điện thoại để xác minh")
+	}
+
+	matches, err := s.accountRepo.FindByContact(ctx, phone, email)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) > 1 {
+		return nil, model.NewValidationError("phone", "Số điện thoại và email đang thuộc hai khách hàng khác nhau, vui lòng liên hệ cửa hàng")
+	}
+
+	if len(matches) == 1 {
+		guest := matches[0]
+		if !guest.IsGuest || guest.Role != "client" {
+			return nil, model.NewValidationError("phone", "Số điện thoại hoặc email đã được đăng ký")
+		}
+		// The OTP proves the phone, a guest matched by email only is not proven to be theirs
+		if normalizePhone(guest.Phone) != phone {
+			return nil, model.NewValidationError("phone", "Email đã được dùng với số điện thoại khác, vui lòng đăng ký bằng số điện thoại đó hoặc liên hệ cửa hàng")
+		}
+	}
+	if err := s.otpService.Verify(ctx, model.OTPPurposeCustomerRegister, phone, code); err != nil {
+		return nil, err
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	var account *model.CustomerAccount
+	claimed := false
+	if len(matches) == 1 {
+		guest := matches[0]
+		ok, err := s.accountRepo.ClaimGuest(ctx, guest.ID, fullName, phone, email, string(passwordHash))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, model.NewValidationError("phone", "Số điện thoại hoặc email đã được đăng ký")
+		}
+		if account, err = s.accountRepo.GetByPublicID(ctx, guest.PublicID); err != nil {
+			return nil, err
+		}
+		claimed = true
+	} else {
+		if account, err = s.accountRepo.CreateCustomer(ctx, fullName, phone, email, string(passwordHash)); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.authResponse(account, claimed)
+}
+
+// RequestRegisterOTP sends the code that proves ownership of a phone when registering
+func (s *CustomerAuthService) RequestRegisterOTP(ctx context.Context, req *model.OTPSendRequest) (*model.OTPSentResponse, error) {
+	phone := normalizePhone(req.Phone)
+	if !isValidPhone(phone) {
+		return nil, model.NewValidationError("phone", "Số điện thoại không hợp lệ")
+	}
+	return s.otpService.Send(ctx, model.OTPPurposeCustomerRegister, phone)
+}
+
+// Login authenticates a registered customer by phone or email
+func (s *CustomerAuthService) Login(ctx context.Context, req *model.CustomerLoginRequest) (*model.CustomerAuthResponse, error) {
+	login := strings.TrimSpace(req.Login)
+
+	var account *model.CustomerAccount
+	var err error
+	if strings.Contains(login, "@") {
+		account, err = s.accountRepo.GetRegisteredByEmail(ctx, login)
+	} else {
+		account, err = s.accountRepo.GetRegisteredByPhone(ctx, normalizePhone(login))
+	}
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.PasswordHash == "" {
+		return nil, ErrInvalidCredentials
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, ErrInvalidCredentials
+	}
+	if !account.IsActive {
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.authResponse(account, false)
+}
+
+// RequestLoginOTP sends a login code to a customer phone
+func (s *CustomerAuthService) RequestLoginOTP(ctx context.Context, req *model.OTPSendRequest) (*model.OTPSentResponse, error) {
+	phone := normalizePhone(req.Phone)
+	if !isValidPhone(phone) {
+		return nil, model.NewValidationError("phone", "Số điện thoại không hợp lệ")
+	}
+	return s.otpService.Send(ctx, model.OTPPurposeCustomerLogin, phone)
+}
+
+// LoginWithOTP logs a customer in with a code sent to their phone. The verified
+// phone proves ownership, so a guest user with that phone is upgraded to a
+// registered customer and a new phone is registered without password.
+func (s *CustomerAuthService) LoginWithOTP(ctx context.Context, req *model.OTPVerifyRequest) (*model.CustomerAuthResponse, error) {
+	phone := normalizePhone(req.Phone)
+	if !isValidPhone(phone) {
+		return nil, model.NewValidationError("phone", "Số điện thoại không hợp lệ")
+	}
+	if err := s.otpService.Verify(ctx, model.OTPPurposeCustomerLogin, phone, strings.TrimSpace(req.Code)); err != nil {
+		return nil, err
+	}
+
+	matches, err := s.accountRepo.FindByContact(ctx, phone, "")
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		account, err := s.accountRepo.CreateCustomer(ctx, "", phone, "", "")
+		if err != nil {
+			return nil, err
+		}
+		return s.authResponse(account, false)
+	}
+
+	account := matches[0]
+	if account.Role != "client" {
+		return nil, model.NewValidationError("phone", "Số điện thoại không thuộc tài khoản khách hàng")
+	}
+	if !account.IsActive {
+		return nil, model.NewValidationError("phone", "Tài khoản đã bị khóa")
+	}
+	claimed := false
+	if account.IsGuest {
+		if _, err := s.accountRepo.ClaimGuest(ctx, account.ID, "", phone, "", ""); err != nil {
+			return nil, err
+		}
+		claimed = true
+	}
+
+	refreshed, err := s.accountRepo.GetByPublicID(ctx, account.PublicID)
+	if err != nil {
+		return nil, err
+	}
+	return s.authResponse(refreshed, claimed)
+}
+
+// GetProfile gets the customer behind a customer token
+func (s *CustomerAuthService) GetProfile(ctx context.Context, publicID string) (*model.CustomerAccount, error) {
+	account, err := s.accountRepo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.IsGuest || !account.IsActive {
+		return nil, ErrNotFound
+	}
+	return account, nil
+}
+
+func (s *CustomerAuthService) authResponse(account *model.CustomerAccount, claimed bool) (*model.CustomerAuthResponse, error) {
+	token, err := s.jwtService.GenerateToken(account.PublicID, account.Phone, jwt.RoleClient)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+	return &model.CustomerAuthResponse{Token: token, Customer: *account, ClaimedGuest: claimed}, nil
+}
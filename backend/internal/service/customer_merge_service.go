@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+// Weight of each duplicate reason; the reasons of a pair are combined as
+// independent evidence, score = 1 - Π(1 - weight)
+var duplicateReasonWeights = map[string]float64{
+	model.DuplicateSamePhone:   0.7,
+	model.DuplicateSameEmail:   0.7,
+	model.DuplicatePhoneTypo:   0.6,
+	model.DuplicateSimilarName: 0.4,
+}
+
+// nameSimilarityThreshold is the minimum similarity of two folded names to count as the same name
+const nameSimilarityThreshold = 0.85
+
+var vietnameseFolder = strings.NewReplacer(
+	"à", "a", "á", "a", "ạ", "a", "ả", "a", "ã", "a",
+	"â", "a", "ầ", "a", "ấ", "a", "ậ", "a", "ẩ", "a", "ẫ", "a",
+	"ă", "a", "ằ", "a", "ắ", "a", "ặ", "a", "ẳ", "a", "ẵ", "a",
+	"è", "e", "é", "e", "ẹ", "e", "ẻ", "e", "ẽ", "e",
+	"ê", "e", "ề", "e", "ế", "e", "ệ", "e", "ể", "e", "ễ", "e",
+	"ì", "i", "í", "i", "ị", "i", "ỉ", "i", "ĩ", "i",
+	"ò", "o", "ó", "o", "ọ", "o", "ỏ", "o", "õ", "o",
+	"ô", "o", "ồ", "o", "ố", "o", "ộ", "o", "ổ", "o", "ỗ", "o",
+	"ơ", "o", "ờ", "o", "ớ", "o", "ợ", "o", "ở", "o", "ỡ", "o",
+	"ù", "u", "ú", "u", "ụ", "u", "ủ", "u", "ũ", "u",
+	"ư", "u", "ừ", "u", "ứ", "u", "ự", "u", "ử", "u", "ữ", "u",
+	"ỳ", "y", "ý", "y", "ỵ", "y", "ỷ", "y", "ỹ", "y",
+	"đ", "d",
+)
+
+type CustomerMergeService struct {
+	mergeRepo *repository.CustomerMergeRepository
+}
+
+func NewCustomerMergeService(mergeRepo *repository.CustomerMergeRepository) *CustomerMergeService {
+	return &CustomerMergeService{mergeRepo: mergeRepo}
+}
+
+// FindDuplicates lists pairs of customers that are probably the same person, most
+// likely first. Pairs match by normalized phone, by email, or by the same name
+// with a phone that differs by a typo.
+func (s *CustomerMergeService) FindDuplicates(ctx context.Context, minScore float64, limit int) ([]model.DuplicateCandidate, error) {
+	customers, err := s.mergeRepo.ListActiveCustomers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	phones := make([]string, len(customers))
+	names := make([]string, len(customers))
+	byPhone := map[string][]int{}
+	byEmail := map[string][]int{}
+	byName := map[string][]int{}
+	for i, customer := range customers {
+		phones[i] = normalizePhone(customer.Phone)
+		names[i] = foldName(customer.FullName)
+		if phones[i] != "" {
+			byPhone[phones[i]] = append(byPhone[phones[i]], i)
+		}
+		if email := strings.ToLower(strings.TrimSpace(customer.Email)); email != "" {
+			byEmail[email] = append(byEmail[email], i)
+		}
+		if nameKey := strings.ReplaceAll(names[i], " ", ""); nameKey != "" {
+			byName[nameKey] = append(byName[nameKey], i)
+		}
+	}
+
+	reasons := map[[2]int]map[string]bool{}
+	addReason := func(a, b int, reason string) {
+		key := [2]int{a, b}
+		if a > b {
+			key = [2]int{b, a}
+		}
+		if reasons[key] == nil {
+			reasons[key] = map[string]bool{}
+		}
+		reasons[key][reason] = true
+	}
+	forEachPair := func(bucket []int, fn func(a, b int)) {
+		for i := 0; i < len(bucket); i++ {
+			for j := i + 1; j < len(bucket); j++ {
+				fn(bucket[i], bucket[j])
+			}
+		}
+	}
+
+	for _, bucket := range byPhone {
+		forEachPair(bucket, func(a, b int) { addReason(a, b, model.DuplicateSamePhone) })
+	}
+	for _, bucket := range byEmail {
+		forEachPair(bucket, func(a, b int) { addReason(a, b, model.DuplicateSameEmail) })
+	}
+	for _, bucket := range byName {
+		forEachPair(bucket, func(a, b int) {
+			switch {
+			case phones[a] != "" && phones[b] != "":
+				// Common names are only duplicates when the phones are nearly the same
+				if phones[a] != phones[b] && editDistance(phones[a], phones[b]) <= 2 {
+					addReason(a, b, model.DuplicatePhoneTypo)
+				}
+			case customers[a].Email != "" && customers[b].Email != "":
+				// Different emails and no phone to compare, not enough evidence
+			default:
+				addReason(a, b, model.DuplicateSimilarName)
+			}
+		})
+	}
+
+	candidates := []model.DuplicateCandidate{}
+	for pair, pairReasons := range reasons {
+		a, b := pair[0], pair[1]
+		if !pairReasons[model.DuplicateSimilarName] && nameSimilarity(names[a], names[b]) >= nameSimilarityThreshold {
+			pairReasons[model.DuplicateSimilarName] = true
+		}
+
+		candidate := model.DuplicateCandidate{
+			Customers: [2]model.MergeCustomer{customers[a], customers[b]},
+			Reasons:   []string{},
+		}
+		missing := 1.0
+		for reason := range pairReasons {
+			candidate.Reasons = append(candidate.Reasons, reason)
+			missing *= 1 - duplicateReasonWeights[reason]
+		}
+		candidate.Score = float64(int((1-missing)*100+0.5)) / 100
+		if candidate.Score < minScore {
+			continue
+		}
+		sort.Strings(candidate.Reasons)
+		candidates = append(candidates, candidate)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Customers[0].ID < candidates[j].Customers[0].ID
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	return candidates, nil
+}
+
+// MergeCustomers merges a guest customer into another customer
+func (s *CustomerMergeService) MergeCustomers(ctx context.Context, req *model.MergeCustomersRequest, mergedBy int64) (*model.CustomerMerge, error) {
+	if req.SourceUserID == req.TargetUserID {
+		return nil, model.NewValidationError("target_user_id", "Không thể gộp khách hàng với chính nó")
+	}
+
+	source, err := s.mergeRepo.GetActiveCustomer(ctx, req.SourceUserID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.mergeRepo.GetActiveCustomer(ctx, req.TargetUserID)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil || target == nil {
+		return nil, ErrNotFound
+	}
+	// A registered account has its own login, so it is always the one kept
+	if !source.IsGuest {
+		return nil, model.NewValidationError("source_user_id", "Chỉ có thể gộp khách vãng lai vào khách hàng khác")
+	}
+
+	var reason *string
+	if trimmed := strings.TrimSpace(req.Reason); trimmed != "" {
+		reason = &trimmed
+	}
+	return s.mergeRepo.MergeCustomers(ctx, source, target, reason, mergedBy)
+}
+
+// ListMerges lists the merge audit records
+func (s *CustomerMergeService) ListMerges(ctx context.Context, targetPublicID string) ([]model.CustomerMerge, error) {
+	return s.mergeRepo.ListMerges(ctx, targetPublicID)
+}
+
+// foldName lowercases a name, strips Vietnamese diacritics and collapses spaces
+func foldName(name string) string {
+	return strings.Join(strings.Fields(vietnameseFolder.Replace(strings.ToLower(name))), " ")
+}
+
+// nameSimilarity returns 1 for equal names down to 0 for completely different names
+func nameSimilarity(a, b string) float64 {
+	if a == "" || b == "" {
+		return 0
+	}
+	longest := len([]rune(a))
+	if n := len([]rune(b)); n > longest {
+		longest = n
+	}
+	return 1 - float64(editDistance(a, b))/float64(longest)
+}
+
+// editDistance is the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -1,26 +1,51 @@
 package service
 
 import (
+	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"food-pos-backend/internal/model"
 	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/storage"
 	"food-pos-backend/internal/ws"
+	"io"
+	"io/fs"
+	"net/http"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
+// Allowed content types of proof files, with the file extension they are stored under
+var deliveryProofContentTypes = map[model.DeliveryProofType]map[string]string{
+	model.DeliveryProofPhoto: {
+		"image/jpeg": ".jpg",
+		"image/png":  ".png",
+		"image/webp": ".webp",
+	},
+	model.DeliveryProofSignature: {
+		"image/png": ".png",
+	},
+}
+
 type DeliveryService struct {
 	deliveryRepo *repository.DeliveryRepository
 	orderRepo    *repository.OrderRepository
 	hub          *ws.Hub
+	storage      storage.Storage
+	proofPolicy  model.DeliveryProofPolicy
 }
 
-func NewDeliveryService(deliveryRepo *repository.DeliveryRepository, orderRepo *repository.OrderRepository, hub *ws.Hub) *DeliveryService {
+func NewDeliveryService(deliveryRepo *repository.DeliveryRepository, orderRepo *repository.OrderRepository, hub *ws.Hub, storage storage.Storage, proofPolicy model.DeliveryProofPolicy) *DeliveryService {
 	return &DeliveryService{
 		deliveryRepo: deliveryRepo,
 		orderRepo:    orderRepo,
 		hub:          hub,
+		storage:      storage,
+		proofPolicy:  proofPolicy,
 	}
 }
 
@@ -58,13 +83,26 @@ func (s *DeliveryService) GetDeliveryOrderByID(ctx context.Context, publicID str
 
 // UpdateDeliveryOrder updates delivery order
 func (s *DeliveryService) UpdateDeliveryOrder(ctx context.Context, publicID string, req *model.UpdateDeliveryOrderRequest, userID int64) (*model.DeliveryOrder, error) {
+	return s.updateDeliveryOrder(ctx, publicID, req, userID, nil)
+}
+
+// updateDeliveryOrder updates a delivery order; check, when set, runs against
+// the locked row before the state machine
+func (s *DeliveryService) updateDeliveryOrder(ctx context.Context, publicID string, req *model.UpdateDeliveryOrderRequest, userID int64, check func(current *model.DeliveryOrder) error) (*model.DeliveryOrder, error) {
 	// Validate request
 	if err := s.validateUpdateDeliveryOrderRequest(req); err != nil {
 		return nil, err
 	}
 
-	// Update delivery order
-	deliveryOrder, err := s.deliveryRepo.UpdateDeliveryOrder(ctx, publicID, req, userID)
+	// Update delivery order, the state machine is checked against the locked row
+	deliveryOrder, err := s.deliveryRepo.UpdateDeliveryOrder(ctx, publicID, req, userID, func(current *model.DeliveryOrder) error {
+		if check != nil {
+			if err := check(current); err != nil {
+				return err
+			}
+		}
+		return s.validateDeliveryTransition(current, req)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -200,6 +238,10 @@ func (s *DeliveryService) GetAvailableShippers(ctx context.Context) ([]*model.Sh
 
 // UpdateDeliveryStatus updates delivery status
 func (s *DeliveryService) UpdateDeliveryStatus(ctx context.Context, deliveryID string, status model.DeliveryStatus, notes string, userID int64) (*model.DeliveryOrder, error) {
+	return s.UpdateDeliveryOrder(ctx, deliveryID, newDeliveryStatusRequest(status, notes), userID)
+}
+
+func newDeliveryStatusRequest(status model.DeliveryStatus, notes string) *model.UpdateDeliveryOrderRequest {
 	req := &model.UpdateDeliveryOrderRequest{
 		Status:        &status,
 		DeliveryNotes: notes,
@@ -210,8 +252,130 @@ func (s *DeliveryService) UpdateDeliveryStatus(ctx context.Context, deliveryID s
 		now := time.Now()
 		req.ActualDeliveryTime = &now
 	}
+	return req
+}
+
+// GetProofPolicy returns the proof required before a delivery can be marked delivered
+func (s *DeliveryService) GetProofPolicy() model.DeliveryProofPolicy {
+	return s.proofPolicy
+}
+
+// AddDeliveryProof attaches a photo or signature to a delivery order
+func (s *DeliveryService) AddDeliveryProof(ctx context.Context, publicID string, req *model.AddDeliveryProofRequest, file io.Reader, fileSize int64, userID int64) (*model.DeliveryProof, error) {
+	deliveryOrder, err := s.deliveryRepo.GetDeliveryOrderByID(ctx, publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return s.addDeliveryProof(ctx, deliveryOrder, req, file, fileSize, userID)
+}
+
+// AddShipperDeliveryProof attaches a proof to a delivery of the logged in shipper
+func (s *DeliveryService) AddShipperDeliveryProof(ctx context.Context, shipper *model.Shipper, publicID string, req *model.AddDeliveryProofRequest, file io.Reader, fileSize int64, userID int64) (*model.DeliveryProof, error) {
+	deliveryOrder, err := s.GetShipperDelivery(ctx, shipper, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if deliveryOrder.AcceptedAt == nil {
+		return nil, model.NewValidationError("status", "Shipper cần nhận đơn trước khi gửi bằng chứng giao hàng")
+	}
+	return s.addDeliveryProof(ctx, deliveryOrder, req, file, fileSize, userID)
+}
+
+// ListDeliveryProofs lists the proofs attached to a delivery order
+func (s *DeliveryService) ListDeliveryProofs(ctx context.Context, publicID string) ([]model.DeliveryProof, error) {
+	deliveryOrder, err := s.deliveryRepo.GetDeliveryOrderByID(ctx, publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return deliveryOrder.Proofs, nil
+}
+
+// OpenDeliveryProof opens the file of a proof attached to a delivery order, the caller closes it
+func (s *DeliveryService) OpenDeliveryProof(ctx context.Context, publicID, proofID string) (*model.DeliveryProof, io.ReadCloser, error) {
+	deliveryOrder, err := s.deliveryRepo.GetDeliveryOrderByID(ctx, publicID)
+	if err != nil {
+		return nil, nil, ErrNotFound
+	}
+	return s.openDeliveryProof(ctx, deliveryOrder, proofID)
+}
 
-	return s.UpdateDeliveryOrder(ctx, deliveryID, req, userID)
+// OpenShipperDeliveryProof opens the file of a proof attached to a delivery of the logged in shipper
+func (s *DeliveryService) OpenShipperDeliveryProof(ctx context.Context, shipper *model.Shipper, publicID, proofID string) (*model.DeliveryProof, io.ReadCloser, error) {
+	deliveryOrder, err := s.GetShipperDelivery(ctx, shipper, publicID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return s.openDeliveryProof(ctx, deliveryOrder, proofID)
+}
+
+func (s *DeliveryService) openDeliveryProof(ctx context.Context, deliveryOrder *model.DeliveryOrder, proofID string) (*model.DeliveryProof, io.ReadCloser, error) {
+	for i := range deliveryOrder.Proofs {
+		proof := &deliveryOrder.Proofs[i]
+		if proof.PublicID != proofID {
+			continue
+		}
+		file, err := s.storage.Open(ctx, proof.FileKey)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, nil, ErrNotFound
+			}
+			return nil, nil, err
+		}
+		return proof, file, nil
+	}
+	return nil, nil, ErrNotFound
+}
+
+func (s *DeliveryService) addDeliveryProof(ctx context.Context, deliveryOrder *model.DeliveryOrder, req *model.AddDeliveryProofRequest, file io.Reader, fileSize int64, userID int64) (*model.DeliveryProof, error) {
+	contentTypes, ok := deliveryProofContentTypes[req.Type]
+	if !ok {
+		return nil, model.NewValidationError("type", "Loại bằng chứng giao hàng không hợp lệ")
+	}
+	if deliveryOrder.Status.IsTerminal() {
+		return nil, model.NewValidationError("status", "Đơn giao đã kết thúc, không thể thêm bằng chứng giao hàng")
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, model.NewValidationError("location", "Vui lòng gửi đủ vĩ độ và kinh độ")
+	}
+	if req.Latitude != nil && (*req.Latitude < -90 || *req.Latitude > 90 || *req.Longitude < -180 || *req.Longitude > 180) {
+		return nil, model.NewValidationError("location", "Vị trí giao hàng không hợp lệ")
+	}
+
+	// Nhận diện định dạng theo nội dung file, không tin Content-Type client gửi lên
+	reader := bufio.NewReader(file)
+	head, _ := reader.Peek(512)
+	contentType := http.DetectContentType(head)
+	ext, ok := contentTypes[contentType]
+	if !ok {
+		if req.Type == model.DeliveryProofSignature {
+			return nil, model.NewValidationError("file", "Chữ ký phải là ảnh PNG")
+		}
+		return nil, model.NewValidationError("file", "Ảnh giao hàng phải là JPEG, PNG hoặc WebP")
+	}
+
+	key := "deliveries/" + deliveryOrder.PublicID + "/" + string(req.Type) + "-" + uuid.New().String() + ext
+	if err := s.storage.Save(ctx, key, reader); err != nil {
+		return nil, err
+	}
+
+	proof := &model.DeliveryProof{
+		DeliveryOrderID: deliveryOrder.ID,
+		ProofType:       req.Type,
+		FileKey:         key,
+		ContentType:     contentType,
+		FileSize:        fileSize,
+		Latitude:        req.Latitude,
+		Longitude:       req.Longitude,
+		UploadedBy:      &userID,
+	}
+	if err := s.deliveryRepo.CreateDeliveryProof(ctx, proof); err != nil {
+		s.storage.Delete(ctx, key)
+		return nil, err
+	}
+
+	deliveryOrder.Proofs = append(deliveryOrder.Proofs, *proof)
+	s.broadcastDeliveryUpdate(deliveryOrder)
+	return proof, nil
 }
 
 // Helper methods
@@ -226,11 +390,53 @@ func (s *DeliveryService) validateCreateDeliveryOrderRequest(req *model.CreateDe
 	if len(req.Items) == 0 {
 		return model.NewValidationError("items", "At least one item is required")
 	}
+	if req.CodAmount < 0 {
+		return model.NewValidationError("cod_amount", "Tiền thu hộ không được âm")
+	}
 	return nil
 }
 
 func (s *DeliveryService) validateUpdateDeliveryOrderRequest(req *model.UpdateDeliveryOrderRequest) error {
-	// Add validation logic if needed
+	if req.CodAmount != nil && *req.CodAmount < 0 {
+		return model.NewValidationError("cod_amount", "Tiền thu hộ không được âm")
+	}
+	if req.Status != nil && !req.Status.IsValid() {
+		return model.NewValidationError("status", "Trạng thái giao hàng không hợp lệ")
+	}
+	return nil
+}
+
+// validateDeliveryTransition enforces the delivery state machine and locks finished deliveries
+func (s *DeliveryService) validateDeliveryTransition(current *model.DeliveryOrder, req *model.UpdateDeliveryOrderRequest) error {
+	statusChanged := req.Status != nil && *req.Status != current.Status
+	if current.Status.IsTerminal() {
+		if req.ShipperID != nil {
+			return model.NewValidationError("shipper_id", "Đơn giao đã kết thúc, không thể đổi shipper")
+		}
+		if req.CodAmount != nil && *req.CodAmount != current.CodAmount {
+			return model.NewValidationError("cod_amount", "Đơn giao đã kết thúc, không thể đổi tiền thu hộ")
+		}
+		if statusChanged {
+			return model.NewValidationError("status", "Đơn giao đã kết thúc, không thể đổi trạng thái")
+		}
+	}
+
+	if statusChanged {
+		if !current.Status.CanTransitionTo(*req.Status) {
+			return model.NewValidationError("status", "Không thể chuyển đơn giao từ trạng thái "+string(current.Status)+" sang "+string(*req.Status))
+		}
+		if *req.Status == model.DeliveryStatusPending && req.ShipperID != nil && *req.ShipperID != "" {
+			return model.NewValidationError("shipper_id", "Đơn giao trở về chờ xử lý sẽ bỏ gán shipper, không thể chọn shipper cùng lúc")
+		}
+		if *req.Status == model.DeliveryStatusAssigned && current.ShipperID == nil && req.ShipperID == nil {
+			return model.NewValidationError("shipper_id", "Cần chọn shipper trước khi gán đơn giao")
+		}
+		if *req.Status == model.DeliveryStatusDelivered {
+			if err := s.proofPolicy.Check(current.Proofs); err != nil {
+				return err
+			}
+		}
+	}
 	return nil
 }
 
@@ -238,6 +444,9 @@ func (s *DeliveryService) validateAssignShipperRequest(req *model.AssignShipperR
 	if req.ShipperID == "" {
 		return model.NewValidationError("shipper_id", "Shipper ID is required")
 	}
+	if req.CodAmount < 0 {
+		return model.NewValidationError("cod_amount", "Tiền thu hộ không được âm")
+	}
 	return nil
 }
 
@@ -269,9 +478,120 @@ func (s *DeliveryService) createSplitDeliveries(ctx context.Context, orderID str
 		ShipperID:             req.ShipperID,
 		EstimatedDeliveryTime: req.EstimatedDeliveryTime,
 		DeliveryNotes:         req.DeliveryNotes,
+		CodAmount:             req.CodAmount,
 		Items:                 items,
 	}
 
 	_, err = s.deliveryRepo.CreateDeliveryOrder(ctx, deliveryReq, userID)
 	return err
 }
+
+// ListShipperDeliveries lists the deliveries assigned to a shipper, by default only the ones still in progress
+func (s *DeliveryService) ListShipperDeliveries(ctx context.Context, shipper *model.Shipper, status *model.DeliveryStatus) ([]*model.DeliveryOrder, error) {
+	return s.deliveryRepo.ListShipperDeliveries(ctx, shipper.ID, status, true)
+}
+
+// GetShipperDelivery gets a delivery order that belongs to the shipper
+func (s *DeliveryService) GetShipperDelivery(ctx context.Context, shipper *model.Shipper, publicID string) (*model.DeliveryOrder, error) {
+	deliveryOrder, err := s.deliveryRepo.GetDeliveryOrderByID(ctx, publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if deliveryOrder.ShipperID == nil || *deliveryOrder.ShipperID != shipper.ID {
+		return nil, ErrNotFound
+	}
+	return deliveryOrder, nil
+}
+
+// AcceptDelivery lets a shipper accept an assigned delivery
+func (s *DeliveryService) AcceptDelivery(ctx context.Context, shipper *model.Shipper, publicID string, userID int64) (*model.DeliveryOrder, error) {
+	deliveryOrder, err := s.GetShipperDelivery(ctx, shipper, publicID)
+	if err != nil {
+		return nil, err
+	}
+	accepted, err := s.deliveryRepo.AcceptDelivery(ctx, deliveryOrder.ID, shipper.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !accepted {
+		return nil, model.NewValidationError("status", "Chỉ có thể nhận đơn giao đang chờ shipper xác nhận")
+	}
+
+	deliveryOrder, err = s.deliveryRepo.GetDeliveryOrderByID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	s.broadcastDeliveryUpdate(deliveryOrder)
+	return deliveryOrder, nil
+}
+
+// RejectDelivery lets a shipper hand an assigned delivery back for reassignment
+func (s *DeliveryService) RejectDelivery(ctx context.Context, shipper *model.Shipper, publicID string, req *model.RejectDeliveryRequest, userID int64) (*model.DeliveryOrder, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, model.NewValidationError("reason", "Lý do từ chối không được để trống")
+	}
+	deliveryOrder, err := s.GetShipperDelivery(ctx, shipper, publicID)
+	if err != nil {
+		return nil, err
+	}
+	rejected, err := s.deliveryRepo.RejectDelivery(ctx, deliveryOrder.ID, shipper.ID, reason, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !rejected {
+		return nil, model.NewValidationError("status", "Không thể từ chối đơn giao đã nhận")
+	}
+
+	deliveryOrder, err = s.deliveryRepo.GetDeliveryOrderByID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	s.broadcastDeliveryUpdate(deliveryOrder)
+	return deliveryOrder, nil
+}
+
+// UpdateShipperDeliveryStatus moves an accepted delivery through picked_up -> in_transit -> delivered/failed
+func (s *DeliveryService) UpdateShipperDeliveryStatus(ctx context.Context, shipper *model.Shipper, publicID string, req *model.ShipperUpdateDeliveryStatusRequest, userID int64) (*model.DeliveryOrder, error) {
+	deliveryOrder, err := s.GetShipperDelivery(ctx, shipper, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status == model.DeliveryStatusFailed && strings.TrimSpace(req.Notes) == "" {
+		return nil, model.NewValidationError("notes", "Vui lòng ghi chú lý do giao hàng thất bại")
+	}
+
+	// Giữ ghi chú cũ nếu shipper không nhập ghi chú mới
+	notes := strings.TrimSpace(req.Notes)
+	if notes == "" && deliveryOrder.DeliveryNotes.Valid {
+		notes = deliveryOrder.DeliveryNotes.String
+	}
+
+	// Shipper, việc nhận đơn và trạng thái được kiểm tra trên dòng đã khóa,
+	// đơn có thể vừa được gán lại hoặc bị từ chối
+	return s.updateDeliveryOrder(ctx, publicID, newDeliveryStatusRequest(req.Status, notes), userID, func(current *model.DeliveryOrder) error {
+		if current.ShipperID == nil || *current.ShipperID != shipper.ID {
+			return ErrNotFound
+		}
+		if current.AcceptedAt == nil {
+			return model.NewValidationError("status", "Shipper cần nhận đơn trước khi cập nhật trạng thái")
+		}
+		if !model.ShipperCanSetDeliveryStatus(current.Status, req.Status) {
+			return model.NewValidationError("status", "Không thể chuyển đơn giao từ trạng thái "+string(current.Status)+" sang "+string(req.Status))
+		}
+		return nil
+	})
+}
+
+func (s *DeliveryService) broadcastDeliveryUpdate(deliveryOrder *model.DeliveryOrder) {
+	if s.hub == nil {
+		return
+	}
+	event := ws.Event{
+		Type:    ws.EventDeliveryUpdate,
+		Payload: deliveryOrder,
+	}
+	if data, err := json.Marshal(event); err == nil {
+		s.hub.BroadcastToGroup("admin", data)
+	}
+}
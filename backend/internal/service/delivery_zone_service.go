@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"food-pos-backend/internal/geo"
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+type DeliveryZoneService struct {
+	zoneRepo *repository.DeliveryZoneRepository
+	store    geo.Point
+	distance geo.DistanceFunc
+}
+
+func NewDeliveryZoneService(zoneRepo *repository.DeliveryZoneRepository, store geo.Point, distance geo.DistanceFunc) *DeliveryZoneService {
+	return &DeliveryZoneService{
+		zoneRepo: zoneRepo,
+		store:    store,
+		distance: distance,
+	}
+}
+
+// CreateZone creates a new delivery zone
+func (s *DeliveryZoneService) CreateZone(ctx context.Context, req *model.DeliveryZoneRequest, userID int64) (*model.DeliveryZone, error) {
+	zone := &model.DeliveryZone{IsActive: true, CreatedBy: &userID}
+	if err := s.applyZoneRequest(ctx, zone, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.zoneRepo.CreateZone(ctx, zone); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// GetZone gets a delivery zone by public ID
+func (s *DeliveryZoneService) GetZone(ctx context.Context, publicID string) (*model.DeliveryZone, error) {
+	zone, err := s.zoneRepo.GetZoneByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if zone == nil {
+		return nil, ErrNotFound
+	}
+	return zone, nil
+}
+
+// ListZones lists all delivery zones
+func (s *DeliveryZoneService) ListZones(ctx context.Context) ([]model.DeliveryZone, error) {
+	return s.zoneRepo.ListZones(ctx)
+}
+
+// UpdateZone updates a delivery zone
+func (s *DeliveryZoneService) UpdateZone(ctx context.Context, publicID string, req *model.DeliveryZoneRequest) (*model.DeliveryZone, error) {
+	zone, err := s.GetZone(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyZoneRequest(ctx, zone, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.zoneRepo.UpdateZone(ctx, zone); err != nil {
+		return nil, err
+	}
+	return zone, nil
+}
+
+// DeleteZone deletes a delivery zone
+func (s *DeliveryZoneService) DeleteZone(ctx context.Context, publicID string) error {
+	zone, err := s.GetZone(ctx, publicID)
+	if err != nil {
+		return err
+	}
+	return s.zoneRepo.DeleteZone(ctx, zone.ID)
+}
+
+// Quote finds the delivery zone of a coordinate and computes its shipping fee.
+// Zones are matched by priority; the first active zone containing the point wins.
+// Returns a nil quote when no zone contains the point
+func (s *DeliveryZoneService) Quote(ctx context.Context, lat, lng, subtotal float64) (*model.ShippingQuote, error) {
+	point := geo.Point{Lat: lat, Lng: lng}
+	if !point.Valid() {
+		return nil, model.NewValidationError("delivery_latitude", "Tọa độ giao hàng không hợp lệ")
+	}
+
+	zones, err := s.zoneRepo.ListActiveZones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	distanceKm := math.Round(s.distance(s.store, point)*100) / 100
+	for i := range zones {
+		zone := &zones[i]
+		if zone.Contains(point, distanceKm) {
+			return &model.ShippingQuote{
+				Zone:       zone,
+				DistanceKm: distanceKm,
+				Fee:        zone.Fee(distanceKm, subtotal),
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+// QuoteInZone is Quote for callers that only deliver inside the configured zones
+func (s *DeliveryZoneService) QuoteInZone(ctx context.Context, lat, lng, subtotal float64) (*model.ShippingQuote, error) {
+	quote, err := s.Quote(ctx, lat, lng, subtotal)
+	if err != nil {
+		return nil, err
+	}
+	if quote == nil {
+		return nil, model.NewValidationError("delivery_address", "Địa chỉ nằm ngoài khu vực giao hàng")
+	}
+	return quote, nil
+}
+
+// applyZoneRequest validates a zone request and copies it onto the zone
+func (s *DeliveryZoneService) applyZoneRequest(ctx context.Context, zone *model.DeliveryZone, req *model.DeliveryZoneRequest) error {
+	code := strings.TrimSpace(req.Code)
+	if code == "" {
+		return model.NewValidationError("code", "Mã vùng không được để trống")
+	}
+
+	switch req.ZoneType {
+	case model.ZoneTypeRadius:
+		if req.RadiusKm == nil || *req.RadiusKm <= 0 {
+			return model.NewValidationError("radius_km", "Bán kính phải lớn hơn 0")
+		}
+		req.Polygon = nil
+	case model.ZoneTypePolygon:
+		if len(req.Polygon) < 3 {
+			return model.NewValidationError("polygon", "Vùng đa giác cần ít nhất 3 điểm")
+		}
+		for _, point := range req.Polygon {
+			if !point.Valid() {
+				return model.NewValidationError("polygon", "Tọa độ đa giác không hợp lệ")
+			}
+		}
+		req.RadiusKm = nil
+	default:
+		return model.NewValidationError("zone_type", "Loại vùng không hợp lệ")
+	}
+
+	if req.FeeType != model.ZoneFeeFlat && req.FeeType != model.ZoneFeePerKm {
+		return model.NewValidationError("fee_type", "Cách tính phí không hợp lệ")
+	}
+	if req.BaseFee < 0 || req.PerKmFee < 0 {
+		return model.NewValidationError("base_fee", "Phí giao hàng không được âm")
+	}
+	if req.FreeAboveSubtotal != nil && *req.FreeAboveSubtotal < 0 {
+		return model.NewValidationError("free_above_subtotal", "Ngưỡng miễn phí giao hàng không được âm")
+	}
+
+	var exceptID *int64
+	if zone.ID != 0 {
+		exceptID = &zone.ID
+	}
+	taken, err := s.zoneRepo.IsCodeTaken(ctx, code, exceptID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return model.NewValidationError("code", "Mã vùng đã tồn tại")
+	}
+
+	zone.Code = code
+	zone.Name = strings.TrimSpace(req.Name)
+	zone.ZoneType = req.ZoneType
+	zone.RadiusKm = req.RadiusKm
+	zone.Polygon = req.Polygon
+	zone.FeeType = req.FeeType
+	zone.BaseFee = req.BaseFee
+	zone.PerKmFee = req.PerKmFee
+	zone.FreeAboveSubtotal = req.FreeAboveSubtotal
+	zone.Priority = req.Priority
+	if req.IsActive != nil {
+		zone.IsActive = *req.IsActive
+	}
+	return nil
+}
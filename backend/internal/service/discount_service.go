@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+type DiscountService struct {
+	discountRepo *repository.DiscountRepository
+}
+
+func NewDiscountService(discountRepo *repository.DiscountRepository) *DiscountService {
+	return &DiscountService{
+		discountRepo: discountRepo,
+	}
+}
+
+// CreateDiscountCode creates a new discount code
+func (s *DiscountService) CreateDiscountCode(ctx context.Context, req *model.CreateDiscountCodeRequest, userID int64) (*model.DiscountCode, error) {
+	req.Code = strings.TrimSpace(req.Code)
+	if req.Code == "" {
+		return nil, model.NewValidationError("code", "Mã giảm giá không được để trống")
+	}
+	if err := s.validateDiscountRules(req.DiscountType, req.DiscountValue, req.ValidFrom.Before(req.ValidUntil)); err != nil {
+		return nil, err
+	}
+	req.ProductIDs = uniqueStrings(req.ProductIDs)
+	req.VariantIDs = uniqueStrings(req.VariantIDs)
+
+	return s.discountRepo.CreateDiscountCode(ctx, req, userID)
+}
+
+// GetDiscountCode gets discount code by public ID
+func (s *DiscountService) GetDiscountCode(ctx context.Context, publicID string) (*model.DiscountCode, error) {
+	return s.discountRepo.GetDiscountCodeByPublicID(ctx, publicID)
+}
+
+// ListDiscountCodes lists discount codes with filtering and pagination
+func (s *DiscountService) ListDiscountCodes(ctx context.Context, req *model.ListDiscountCodesRequest) (*model.ListDiscountCodesResponse, error) {
+	// Set default values
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 10
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	return s.discountRepo.ListDiscountCodes(ctx, req)
+}
+
+// UpdateDiscountCode updates discount code
+func (s *DiscountService) UpdateDiscountCode(ctx context.Context, publicID string, req *model.UpdateDiscountCodeRequest) (*model.DiscountCode, error) {
+	if err := s.validateDiscountRules(req.DiscountType, req.DiscountValue, req.ValidFrom.Before(req.ValidUntil)); err != nil {
+		return nil, err
+	}
+	req.ProductIDs = uniqueStrings(req.ProductIDs)
+	req.VariantIDs = uniqueStrings(req.VariantIDs)
+
+	return s.discountRepo.UpdateDiscountCode(ctx, publicID, req)
+}
+
+// DeleteDiscountCode deletes a discount code that has never been used
+func (s *DiscountService) DeleteDiscountCode(ctx context.Context, publicID string) error {
+	discountCode, err := s.discountRepo.GetDiscountCodeByPublicID(ctx, publicID)
+	if err != nil {
+		return err
+	}
+	if discountCode.UsedCount > 0 {
+		return model.NewValidationError("code", "Mã giảm giá đã được sử dụng, hãy vô hiệu hóa thay vì xóa")
+	}
+
+	return s.discountRepo.DeleteDiscountCode(ctx, discountCode.ID)
+}
+
+// ListDiscountCodeUsages lists redemptions of a discount code
+func (s *DiscountService) ListDiscountCodeUsages(ctx context.Context, publicID string) ([]*model.DiscountCodeUsage, error) {
+	discountCode, err := s.discountRepo.GetDiscountCodeByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.discountRepo.ListDiscountCodeUsages(ctx, discountCode.ID)
+}
+
+// validateDiscountRules validates discount type, value and validity period
+func (s *DiscountService) validateDiscountRules(discountType model.DiscountType, value float64, validPeriod bool) error {
+	if discountType != model.DiscountTypePercentage && discountType != model.DiscountTypeFixedAmount {
+		return model.NewValidationError("discount_type", "Loại giảm giá không hợp lệ")
+	}
+	if value <= 0 {
+		return model.NewValidationError("discount_value", "Giá trị giảm giá phải lớn hơn 0")
+	}
+	if discountType == model.DiscountTypePercentage && value > 100 {
+		return model.NewValidationError("discount_value", "Giảm giá theo phần trăm không được vượt quá 100")
+	}
+	if !validPeriod {
+		return model.NewValidationError("valid_until", "Ngày kết thúc phải sau ngày bắt đầu")
+	}
+	return nil
+}
+
+// uniqueStrings removes empty and duplicate values while keeping order
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
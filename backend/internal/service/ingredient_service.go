@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"food-pos-backend/internal/model"
@@ -23,11 +24,12 @@ func NewIngredientService(ingredientRepo *repository.IngredientRepository, varia
 func (s *IngredientService) CreateIngredient(ctx context.Context, req *model.CreateIngredientRequest) (*model.Ingredient, error) {
 	now := time.Now()
 	ingredient := &model.Ingredient{
-		Name:      req.Name,
-		UnitPrice: req.UnitPrice,
-		Unit:      req.Unit,
-		CreatedAt: now,
-		UpdatedAt: now,
+		Name:              req.Name,
+		UnitPrice:         req.UnitPrice,
+		Unit:              req.Unit,
+		LowStockThreshold: req.LowStockThreshold,
+		CreatedAt:         now,
+		UpdatedAt:         now,
 	}
 
 	err := s.ingredientRepo.Create(ctx, ingredient)
@@ -42,8 +44,8 @@ func (s *IngredientService) GetIngredient(ctx context.Context, publicID string)
 	return s.ingredientRepo.GetByPublicID(ctx, publicID)
 }
 
-func (s *IngredientService) GetAllIngredients(ctx context.Context) ([]*model.Ingredient, error) {
-	return s.ingredientRepo.GetAll(ctx)
+func (s *IngredientService) GetAllIngredients(ctx context.Context, includeArchived bool) ([]*model.Ingredient, error) {
+	return s.ingredientRepo.GetAll(ctx, includeArchived)
 }
 
 func (s *IngredientService) UpdateIngredient(ctx context.Context, publicID string, req *model.UpdateIngredientRequest) (*model.Ingredient, error) {
@@ -58,6 +60,7 @@ func (s *IngredientService) UpdateIngredient(ctx context.Context, publicID strin
 	ingredient.Name = req.Name
 	ingredient.UnitPrice = req.UnitPrice
 	ingredient.Unit = req.Unit
+	ingredient.LowStockThreshold = req.LowStockThreshold
 	ingredient.UpdatedAt = time.Now()
 
 	err = s.ingredientRepo.Update(ctx, ingredient)
@@ -68,15 +71,41 @@ func (s *IngredientService) UpdateIngredient(ctx context.Context, publicID strin
 	return ingredient, nil
 }
 
-func (s *IngredientService) DeleteIngredient(ctx context.Context, publicID string) error {
+// ArchiveIngredient archives an ingredient. Ingredients still used in the
+// recipe of a variant on the menu must be removed from those recipes first.
+func (s *IngredientService) ArchiveIngredient(ctx context.Context, publicID string) (*model.Ingredient, error) {
 	ingredient, err := s.ingredientRepo.GetByPublicID(ctx, publicID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if ingredient == nil {
-		return ErrNotFound
+		return nil, ErrNotFound
+	}
+	recipes, err := s.ingredientRepo.CountLiveRecipes(ctx, ingredient.ID)
+	if err != nil {
+		return nil, err
+	}
+	if recipes > 0 {
+		return nil, model.NewValidationError("public_id", fmt.Sprintf("Nguyên liệu đang dùng trong công thức của %d món, hãy gỡ khỏi công thức trước khi lưu trữ", recipes))
+	}
+	if err = s.ingredientRepo.Archive(ctx, ingredient.ID); err != nil {
+		return nil, err
+	}
+	return s.ingredientRepo.GetByID(ctx, ingredient.ID)
+}
+
+func (s *IngredientService) RestoreIngredient(ctx context.Context, publicID string) (*model.Ingredient, error) {
+	ingredient, err := s.ingredientRepo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
 	}
-	return s.ingredientRepo.Delete(ctx, ingredient.ID)
+	if ingredient == nil {
+		return nil, ErrNotFound
+	}
+	if err = s.ingredientRepo.Restore(ctx, ingredient.ID); err != nil {
+		return nil, err
+	}
+	return s.ingredientRepo.GetByID(ctx, ingredient.ID)
 }
 
 func (s *IngredientService) GetVariantIngredients(ctx context.Context, variantPublicID string) ([]*model.VariantIngredient, error) {
@@ -105,6 +134,9 @@ func (s *IngredientService) AddIngredientToVariant(ctx context.Context, variantP
 	if ingredient == nil {
 		return ErrNotFound
 	}
+	if ingredient.ArchivedAt != nil {
+		return model.NewValidationError("ingredient_id", "Nguyên liệu đã được lưu trữ")
+	}
 	return s.ingredientRepo.AddToVariant(ctx, variant.ID, ingredient.ID, req.Quantity)
 }
 
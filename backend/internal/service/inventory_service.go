@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+type InventoryService struct {
+	stockRepo      *repository.StockRepository
+	ingredientRepo *repository.IngredientRepository
+}
+
+func NewInventoryService(stockRepo *repository.StockRepository, ingredientRepo *repository.IngredientRepository) *InventoryService {
+	return &InventoryService{
+		stockRepo:      stockRepo,
+		ingredientRepo: ingredientRepo,
+	}
+}
+
+// ReceiveStock records goods received for an ingredient
+func (s *InventoryService) ReceiveStock(ctx context.Context, ingredientPublicID string, req *model.CreateStockReceiptRequest, userID int64) (*model.StockMovement, error) {
+	if req.Quantity <= 0 {
+		return nil, model.NewValidationError("quantity", "Số lượng nhập phải lớn hơn 0")
+	}
+	ingredient, err := s.getIngredient(ctx, ingredientPublicID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.stockRepo.RecordMovement(ctx, ingredient.ID, model.StockMovementReceipt, req.Quantity, req.UnitCost, req.Notes, userID)
+}
+
+// AdjustStock records a stock count correction for an ingredient
+func (s *InventoryService) AdjustStock(ctx context.Context, ingredientPublicID string, req *model.CreateStockAdjustmentRequest, userID int64) (*model.StockMovement, error) {
+	if req.Quantity == 0 {
+		return nil, model.NewValidationError("quantity", "Số lượng điều chỉnh phải khác 0")
+	}
+	ingredient, err := s.getIngredient(ctx, ingredientPublicID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.stockRepo.RecordMovement(ctx, ingredient.ID, model.StockMovementAdjustment, req.Quantity, nil, req.Notes, userID)
+}
+
+// ListStockMovements lists the stock ledger of an ingredient
+func (s *InventoryService) ListStockMovements(ctx context.Context, ingredientPublicID string, req *model.ListStockMovementsRequest) (*model.ListStockMovementsResponse, error) {
+	ingredient, err := s.getIngredient(ctx, ingredientPublicID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Set default values
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = 20
+	}
+	if req.Limit > 100 {
+		req.Limit = 100
+	}
+
+	return s.stockRepo.ListMovements(ctx, ingredient.ID, req)
+}
+
+// ListLowStockIngredients lists ingredients that need restocking
+func (s *InventoryService) ListLowStockIngredients(ctx context.Context) ([]*model.Ingredient, error) {
+	return s.stockRepo.ListLowStockIngredients(ctx)
+}
+
+func (s *InventoryService) getIngredient(ctx context.Context, publicID string) (*model.Ingredient, error) {
+	ingredient, err := s.ingredientRepo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if ingredient == nil {
+		return nil, ErrNotFound
+	}
+	return ingredient, nil
+}
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/ws"
+)
+
+type KitchenService struct {
+	kitchenRepo  *repository.KitchenRepository
+	orderService *OrderService
+	hub          *ws.Hub
+}
+
+func NewKitchenService(kitchenRepo *repository.KitchenRepository, orderService *OrderService, hub *ws.Hub) *KitchenService {
+	return &KitchenService{
+		kitchenRepo:  kitchenRepo,
+		orderService: orderService,
+		hub:          hub,
+	}
+}
+
+// CreateStation creates a kitchen station
+func (s *KitchenService) CreateStation(ctx context.Context, req *model.CreateKitchenStationRequest) (*model.KitchenStation, error) {
+	station := &model.KitchenStation{
+		Name:      strings.TrimSpace(req.Name),
+		Code:      strings.ToLower(strings.TrimSpace(req.Code)),
+		IsDefault: req.IsDefault,
+		SortOrder: req.SortOrder,
+		IsActive:  true,
+	}
+	if err := s.validateStation(ctx, station); err != nil {
+		return nil, err
+	}
+
+	if err := s.kitchenRepo.CreateStation(ctx, station); err != nil {
+		return nil, err
+	}
+	return station, nil
+}
+
+// GetStation gets a kitchen station by public ID
+func (s *KitchenService) GetStation(ctx context.Context, publicID string) (*model.KitchenStation, error) {
+	station, err := s.kitchenRepo.GetStationByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if station == nil {
+		return nil, ErrNotFound
+	}
+	return station, nil
+}
+
+// ListStations lists all kitchen stations
+func (s *KitchenService) ListStations(ctx context.Context) ([]*model.KitchenStation, error) {
+	return s.kitchenRepo.ListStations(ctx)
+}
+
+// UpdateStation updates a kitchen station
+func (s *KitchenService) UpdateStation(ctx context.Context, publicID string, req *model.UpdateKitchenStationRequest) (*model.KitchenStation, error) {
+	station, err := s.GetStation(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+
+	station.Name = strings.TrimSpace(req.Name)
+	station.Code = strings.ToLower(strings.TrimSpace(req.Code))
+	station.IsDefault = req.IsDefault
+	station.SortOrder = req.SortOrder
+	if req.IsActive != nil {
+		station.IsActive = *req.IsActive
+	}
+	if err := s.validateStation(ctx, station); err != nil {
+		return nil, err
+	}
+
+	if err := s.kitchenRepo.UpdateStation(ctx, station); err != nil {
+		return nil, err
+	}
+	return s.GetStation(ctx, publicID)
+}
+
+// DeleteStation deletes a kitchen station that never received tickets
+func (s *KitchenService) DeleteStation(ctx context.Context, publicID string) error {
+	station, err := s.GetStation(ctx, publicID)
+	if err != nil {
+		return err
+	}
+	count, err := s.kitchenRepo.CountStationTickets(ctx, station.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return model.NewValidationError("station", "Quầy đã có món được xử lý, hãy tắt quầy thay vì xóa")
+	}
+	return s.kitchenRepo.DeleteStation(ctx, station.ID)
+}
+
+// SetStationProducts routes products to a kitchen station
+func (s *KitchenService) SetStationProducts(ctx context.Context, publicID string, req *model.SetKitchenStationProductsRequest) (*model.KitchenStation, error) {
+	station, err := s.GetStation(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.kitchenRepo.SetStationProducts(ctx, station.ID, uniqueStrings(req.ProductIDs)); err != nil {
+		return nil, err
+	}
+	return s.GetStation(ctx, publicID)
+}
+
+// GetQueue gets the live kitchen queue, optionally for a single station
+func (s *KitchenService) GetQueue(ctx context.Context, req *model.KitchenQueueRequest) ([]*model.KitchenTicket, error) {
+	var stationID *int64
+	if req.StationID != "" {
+		station, err := s.GetStation(ctx, req.StationID)
+		if err != nil {
+			return nil, err
+		}
+		stationID = &station.ID
+	}
+	return s.kitchenRepo.ListQueue(ctx, stationID, req.IncludeBumped)
+}
+
+// UpdateTicketStatus moves a ticket through queued/in_progress/done/bumped and
+// advances the order once every item is done
+func (s *KitchenService) UpdateTicketStatus(ctx context.Context, ticketPublicID string, req *model.UpdateKitchenTicketStatusRequest, userID int64) (*model.KitchenTicket, error) {
+	ticket, err := s.kitchenRepo.GetTicketByPublicID(ctx, ticketPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if ticket == nil {
+		return nil, ErrNotFound
+	}
+	if ticket.OrderStatus != model.OrderStatusProcessing {
+		return nil, model.NewValidationError("status", "Đơn hàng không còn ở trạng thái đang xử lý")
+	}
+	if !ticket.Status.CanTransitionTo(req.Status) {
+		return nil, model.NewValidationError("status", "Không thể chuyển món từ trạng thái "+string(ticket.Status)+" sang "+string(req.Status))
+	}
+
+	allDone, err := s.kitchenRepo.UpdateTicketStatus(ctx, ticket.ID, req.Status, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderService.GetOrderByID(ctx, ticket.OrderPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if allDone && order.Status == model.OrderStatusProcessing {
+		// Đơn có shipper thì chuyển sang chờ giao, còn lại hoàn thành
+		nextStatus := model.OrderStatusCompleted
+		if order.Shipper != nil {
+			nextStatus = model.OrderStatusReadyForDelivery
+		}
+		// UpdateOrderStatus also broadcasts the kitchen update
+		_, err = s.orderService.UpdateOrderStatus(ctx, order.PublicID, &model.UpdateOrderStatusRequest{
+			Status: nextStatus,
+			Notes:  "Bếp đã hoàn thành tất cả món",
+		}, userID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		broadcastKitchenUpdate(ctx, s.hub, s.kitchenRepo, order)
+	}
+
+	return s.kitchenRepo.GetTicketByPublicID(ctx, ticketPublicID)
+}
+
+func (s *KitchenService) validateStation(ctx context.Context, station *model.KitchenStation) error {
+	if station.Name == "" {
+		return model.NewValidationError("name", "Tên quầy không được để trống")
+	}
+	if station.Code == "" {
+		return model.NewValidationError("code", "Mã quầy không được để trống")
+	}
+	stations, err := s.kitchenRepo.ListStations(ctx)
+	if err != nil {
+		return err
+	}
+	for _, existing := range stations {
+		if existing.Code == station.Code && existing.ID != station.ID {
+			return model.NewValidationError("code", "Mã quầy đã tồn tại")
+		}
+	}
+	return nil
+}
+
+// broadcastKitchenUpdate sends the current tickets of an order to KDS screens
+func broadcastKitchenUpdate(ctx context.Context, hub *ws.Hub, kitchenRepo *repository.KitchenRepository, order *model.Order) {
+	if hub == nil || kitchenRepo == nil {
+		return
+	}
+	tickets, err := kitchenRepo.ListTicketsByOrder(ctx, order.ID)
+	if err != nil {
+		log.Printf("[Kitchen] Failed to load tickets for order %s: %v", order.OrderNumber, err)
+		return
+	}
+	event := ws.Event{
+		Type: ws.EventKitchenUpdate,
+		Payload: model.KitchenUpdate{
+			OrderID:     order.PublicID,
+			OrderNumber: order.OrderNumber,
+			OrderStatus: order.Status,
+			Tickets:     tickets,
+		},
+	}
+	if data, err := json.Marshal(event); err == nil {
+		hub.BroadcastToGroup("kitchen", data)
+	}
+}
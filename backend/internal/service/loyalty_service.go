@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+// LoyaltyService manages the loyalty program rules, customer points and tiers
+type LoyaltyService struct {
+	loyaltyRepo *repository.LoyaltyRepository
+}
+
+func NewLoyaltyService(loyaltyRepo *repository.LoyaltyRepository) *LoyaltyService {
+	return &LoyaltyService{loyaltyRepo: loyaltyRepo}
+}
+
+// GetSettings gets the loyalty program settings
+func (s *LoyaltyService) GetSettings(ctx context.Context) (*model.LoyaltySettings, error) {
+	return s.loyaltyRepo.GetSettings(ctx)
+}
+
+// UpdateSettings updates the given loyalty program settings
+func (s *LoyaltyService) UpdateSettings(ctx context.Context, req *model.UpdateLoyaltySettingsRequest, userID int64) (*model.LoyaltySettings, error) {
+	settings, err := s.loyaltyRepo.GetSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.IsEnabled != nil {
+		settings.IsEnabled = *req.IsEnabled
+	}
+	if req.PointsPerVND != nil {
+		if *req.PointsPerVND < 0 {
+			return nil, model.NewValidationError("points_per_vnd", "Tỷ lệ tích điểm không được âm")
+		}
+		settings.PointsPerVND = *req.PointsPerVND
+	}
+	if req.PointValue != nil {
+		if *req.PointValue < 0 {
+			return nil, model.NewValidationError("point_value", "Giá trị quy đổi điểm không được âm")
+		}
+		settings.PointValue = *req.PointValue
+	}
+	if req.MaxRedeemPercent != nil {
+		if *req.MaxRedeemPercent < 0 || *req.MaxRedeemPercent > 100 {
+			return nil, model.NewValidationError("max_redeem_percent", "Tỷ lệ dùng điểm tối đa phải từ 0 đến 100")
+		}
+		settings.MaxRedeemPercent = *req.MaxRedeemPercent
+	}
+	if req.TierWindowDays != nil {
+		if *req.TierWindowDays <= 0 {
+			return nil, model.NewValidationError("tier_window_days", "Số ngày xét hạng phải lớn hơn 0")
+		}
+		settings.TierWindowDays = *req.TierWindowDays
+	}
+
+	return s.loyaltyRepo.UpdateSettings(ctx, settings, userID)
+}
+
+// ListTiers lists the tier levels, lowest first
+func (s *LoyaltyService) ListTiers(ctx context.Context) ([]model.LoyaltyTier, error) {
+	return s.loyaltyRepo.ListTiers(ctx)
+}
+
+// UpdateTier updates a tier level. Members need no spending and each higher
+// tier needs more than the one below it.
+func (s *LoyaltyService) UpdateTier(ctx context.Context, code string, req *model.UpdateLoyaltyTierRequest) (*model.LoyaltyTier, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return nil, model.NewValidationError("name", "Vui lòng nhập tên hạng")
+	}
+
+	minSpent := map[string]float64{}
+	tiers, err := s.loyaltyRepo.ListTiers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tier := range tiers {
+		minSpent[tier.Code] = tier.MinSpent
+	}
+	if _, ok := minSpent[code]; !ok {
+		return nil, ErrNotFound
+	}
+	minSpent[code] = req.MinSpent
+
+	if minSpent[model.LoyaltyTierMember] != 0 {
+		return nil, model.NewValidationError("min_spent", "Hạng thành viên không yêu cầu chi tiêu tối thiểu")
+	}
+	if minSpent[model.LoyaltyTierSilver] <= minSpent[model.LoyaltyTierMember] || minSpent[model.LoyaltyTierGold] <= minSpent[model.LoyaltyTierSilver] {
+		return nil, model.NewValidationError("min_spent", "Chi tiêu tối thiểu của hạng cao hơn phải lớn hơn hạng thấp hơn")
+	}
+
+	tier, err := s.loyaltyRepo.UpdateTier(ctx, code, req)
+	if err != nil {
+		return nil, err
+	}
+	if tier == nil {
+		return nil, ErrNotFound
+	}
+	return tier, nil
+}
+
+// ListProductBonuses lists the per product bonus points
+func (s *LoyaltyService) ListProductBonuses(ctx context.Context) ([]model.LoyaltyProductBonus, error) {
+	return s.loyaltyRepo.ListProductBonuses(ctx)
+}
+
+// SetProductBonus sets the bonus points earned per unit of a product
+func (s *LoyaltyService) SetProductBonus(ctx context.Context, productPublicID string, req *model.SetLoyaltyProductBonusRequest, userID int64) (*model.LoyaltyProductBonus, error) {
+	isActive := true
+	if req.IsActive != nil {
+		isActive = *req.IsActive
+	}
+	bonus, err := s.loyaltyRepo.SetProductBonus(ctx, productPublicID, req.BonusPoints, isActive, userID)
+	if err != nil {
+		return nil, err
+	}
+	if bonus == nil {
+		return nil, ErrNotFound
+	}
+	return bonus, nil
+}
+
+// DeleteProductBonus removes the bonus points of a product
+func (s *LoyaltyService) DeleteProductBonus(ctx context.Context, productPublicID string) error {
+	deleted, err := s.loyaltyRepo.DeleteProductBonus(ctx, productPublicID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetAccount gets the points balance and tier of a customer
+func (s *LoyaltyService) GetAccount(ctx context.Context, userPublicID string) (*model.LoyaltyAccount, error) {
+	account, err := s.loyaltyRepo.GetAccount(ctx, userPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrNotFound
+	}
+	return account, nil
+}
+
+// ListLedger lists the points ledger of a customer
+func (s *LoyaltyService) ListLedger(ctx context.Context, userPublicID string, req *model.ListLoyaltyLedgerRequest) (*model.ListLoyaltyLedgerResponse, error) {
+	account, err := s.GetAccount(ctx, userPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Page < 1 {
+		req.Page = 1
+	}
+	if req.Limit < 1 || req.Limit > 100 {
+		req.Limit = 20
+	}
+	return s.loyaltyRepo.ListLedger(ctx, account.UserID, req)
+}
+
+// AdjustPoints adds or removes points of a customer by hand
+func (s *LoyaltyService) AdjustPoints(ctx context.Context, userPublicID string, req *model.AdjustLoyaltyPointsRequest, userID int64) (*model.LoyaltyAccount, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, model.NewValidationError("note", "Vui lòng nhập lý do điều chỉnh điểm")
+	}
+	if req.Points == 0 {
+		return nil, model.NewValidationError("points", "Số điểm điều chỉnh phải khác 0")
+	}
+
+	account, err := s.GetAccount(ctx, userPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.loyaltyRepo.AdjustPoints(ctx, account.UserID, req.Points, note, userID); err != nil {
+		return nil, err
+	}
+	return s.GetAccount(ctx, userPublicID)
+}
+
+// RecalculateTiers recalculates the tier of every customer now
+func (s *LoyaltyService) RecalculateTiers(ctx context.Context) (*model.TierRecalculationResult, error) {
+	return s.loyaltyRepo.RecalculateTiers(ctx)
+}
+
+// RunTierRecalculation recalculates the tiers every interval, it blocks and is
+// meant to run in its own goroutine
+func (s *LoyaltyService) RunTierRecalculation(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		result, err := s.RecalculateTiers(context.Background())
+		if err != nil {
+			log.Printf("Loyalty tier recalculation failed: %v", err)
+			continue
+		}
+		log.Printf("Loyalty tiers recalculated: %d customers, %d changed", result.Evaluated, result.Changed)
+	}
+}
@@ -0,0 +1,184 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+type ModifierService struct {
+	modifierRepo *repository.ModifierRepository
+	productRepo  *repository.ProductRepository
+}
+
+func NewModifierService(modifierRepo *repository.ModifierRepository, productRepo *repository.ProductRepository) *ModifierService {
+	return &ModifierService{
+		modifierRepo: modifierRepo,
+		productRepo:  productRepo,
+	}
+}
+
+// CreateModifierGroup creates a modifier group with its options
+func (s *ModifierService) CreateModifierGroup(ctx context.Context, req *model.CreateModifierGroupRequest) (*model.ModifierGroup, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.MaxSelect == 0 {
+		req.MaxSelect = 1
+	}
+	if err := validateModifierGroup(req.Name, req.MinSelect, req.MaxSelect); err != nil {
+		return nil, err
+	}
+	for i := range req.Options {
+		if err := validateModifierOption(req.Options[i].Name, req.Options[i].PriceDelta, req.Options[i].IngredientQuantity); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.modifierRepo.CreateGroup(ctx, req)
+}
+
+// GetModifierGroup gets a modifier group by public ID
+func (s *ModifierService) GetModifierGroup(ctx context.Context, publicID string) (*model.ModifierGroup, error) {
+	group, err := s.modifierRepo.GetGroupByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if group == nil {
+		return nil, ErrNotFound
+	}
+	return group, nil
+}
+
+// ListModifierGroups lists all modifier groups
+func (s *ModifierService) ListModifierGroups(ctx context.Context) ([]*model.ModifierGroup, error) {
+	return s.modifierRepo.ListGroups(ctx)
+}
+
+// UpdateModifierGroup updates a modifier group
+func (s *ModifierService) UpdateModifierGroup(ctx context.Context, publicID string, req *model.UpdateModifierGroupRequest) (*model.ModifierGroup, error) {
+	group, err := s.GetModifierGroup(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validateModifierGroup(req.Name, req.MinSelect, req.MaxSelect); err != nil {
+		return nil, err
+	}
+
+	group.Name = req.Name
+	group.Description = req.Description
+	group.MinSelect = req.MinSelect
+	group.MaxSelect = req.MaxSelect
+	group.SortOrder = req.SortOrder
+	if req.IsActive != nil {
+		group.IsActive = *req.IsActive
+	}
+	if err := s.modifierRepo.UpdateGroup(ctx, group); err != nil {
+		return nil, err
+	}
+
+	return s.modifierRepo.GetGroupByPublicID(ctx, publicID)
+}
+
+// DeleteModifierGroup deletes a modifier group
+func (s *ModifierService) DeleteModifierGroup(ctx context.Context, publicID string) error {
+	group, err := s.GetModifierGroup(ctx, publicID)
+	if err != nil {
+		return err
+	}
+	return s.modifierRepo.DeleteGroup(ctx, group.ID)
+}
+
+// AddModifierOption adds an option to a modifier group
+func (s *ModifierService) AddModifierOption(ctx context.Context, groupPublicID string, req *model.CreateModifierOptionRequest) (*model.ModifierOption, error) {
+	group, err := s.GetModifierGroup(ctx, groupPublicID)
+	if err != nil {
+		return nil, err
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validateModifierOption(req.Name, req.PriceDelta, req.IngredientQuantity); err != nil {
+		return nil, err
+	}
+
+	return s.modifierRepo.CreateOption(ctx, group.ID, req)
+}
+
+// UpdateModifierOption updates a modifier option
+func (s *ModifierService) UpdateModifierOption(ctx context.Context, optionPublicID string, req *model.UpdateModifierOptionRequest) (*model.ModifierOption, error) {
+	option, err := s.modifierRepo.GetOptionByPublicID(ctx, optionPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if option == nil {
+		return nil, ErrNotFound
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := validateModifierOption(req.Name, req.PriceDelta, req.IngredientQuantity); err != nil {
+		return nil, err
+	}
+
+	if err := s.modifierRepo.UpdateOption(ctx, option, req); err != nil {
+		return nil, err
+	}
+	return s.modifierRepo.GetOptionByPublicID(ctx, optionPublicID)
+}
+
+// DeleteModifierOption deletes a modifier option
+func (s *ModifierService) DeleteModifierOption(ctx context.Context, optionPublicID string) error {
+	option, err := s.modifierRepo.GetOptionByPublicID(ctx, optionPublicID)
+	if err != nil {
+		return err
+	}
+	if option == nil {
+		return ErrNotFound
+	}
+	return s.modifierRepo.DeleteOption(ctx, option.ID)
+}
+
+// GetProductModifierGroups gets the modifier groups attached to a product
+func (s *ModifierService) GetProductModifierGroups(ctx context.Context, productPublicID string) ([]*model.ModifierGroup, error) {
+	product, err := s.productRepo.GetProductByPublicID(ctx, productPublicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	return s.modifierRepo.GetGroupsByProductID(ctx, product.ID)
+}
+
+// SetProductModifierGroups replaces the modifier groups attached to a product
+func (s *ModifierService) SetProductModifierGroups(ctx context.Context, productPublicID string, req *model.SetProductModifierGroupsRequest) ([]*model.ModifierGroup, error) {
+	product, err := s.productRepo.GetProductByPublicID(ctx, productPublicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if err := s.modifierRepo.SetProductGroups(ctx, product.ID, uniqueStrings(req.ModifierGroupIDs)); err != nil {
+		return nil, err
+	}
+	return s.modifierRepo.GetGroupsByProductID(ctx, product.ID)
+}
+
+func validateModifierGroup(name string, minSelect, maxSelect int) error {
+	if name == "" {
+		return model.NewValidationError("name", "Tên nhóm tùy chọn không được để trống")
+	}
+	if minSelect < 0 || maxSelect < 1 {
+		return model.NewValidationError("max_select", "Số lượng chọn không hợp lệ")
+	}
+	if minSelect > maxSelect {
+		return model.NewValidationError("min_select", "Số lượng chọn tối thiểu không được lớn hơn tối đa")
+	}
+	return nil
+}
+
+func validateModifierOption(name string, priceDelta, ingredientQuantity float64) error {
+	if strings.TrimSpace(name) == "" {
+		return model.NewValidationError("name", "Tên tùy chọn không được để trống")
+	}
+	if priceDelta < 0 {
+		return model.NewValidationError("price_delta", "Giá cộng thêm không được âm")
+	}
+	if ingredientQuantity < 0 {
+		return model.NewValidationError("ingredient_quantity", "Định lượng nguyên liệu không được âm")
+	}
+	return nil
+}
@@ -7,20 +7,31 @@ import (
 	"food-pos-backend/internal/model"
 	"food-pos-backend/internal/repository"
 	"food-pos-backend/internal/ws"
+	"strings"
 	"time"
 )
 
 type OrderService struct {
-	orderRepo *repository.OrderRepository
-	hub       *ws.Hub
-	userRepo  *repository.UserRepository
+	orderRepo         *repository.OrderRepository
+	kitchenRepo       *repository.KitchenRepository
+	hub               *ws.Hub
+	userRepo          *repository.UserRepository
+	assignmentService *AssignmentService
+	zoneService       *DeliveryZoneService
+	addressRepo       *repository.CustomerAddressRepository
+	accountRepo       *repository.CustomerAccountRepository
 }
 
-func NewOrderService(orderRepo *repository.OrderRepository, userRepo *repository.UserRepository, hub *ws.Hub) *OrderService {
+func NewOrderService(orderRepo *repository.OrderRepository, kitchenRepo *repository.KitchenRepository, userRepo *repository.UserRepository, addressRepo *repository.CustomerAddressRepository, accountRepo *repository.CustomerAccountRepository, assignmentService *AssignmentService, zoneService *DeliveryZoneService, hub *ws.Hub) *OrderService {
 	return &OrderService{
-		orderRepo: orderRepo,
-		hub:       hub,
-		userRepo:  userRepo,
+		orderRepo:         orderRepo,
+		kitchenRepo:       kitchenRepo,
+		hub:               hub,
+		userRepo:          userRepo,
+		assignmentService: assignmentService,
+		zoneService:       zoneService,
+		addressRepo:       addressRepo,
+		accountRepo:       accountRepo,
 	}
 }
 
@@ -32,6 +43,36 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *model.CreateOrderRe
 		return nil, err
 	}
 
+	// Chụp địa chỉ đã lưu vào đơn hàng
+	if req.CustomerAddressID != nil && *req.CustomerAddressID != "" {
+		address, err := s.getCustomerAddress(ctx, *req.CustomerAddressID, req.CustomerPhone)
+		if err != nil {
+			return nil, err
+		}
+		req.DeliveryAddress = address.AddressLine
+		req.DeliveryWard = address.Ward
+		req.DeliveryDistrict = address.District
+		req.DeliveryProvince = address.Province
+		req.DeliveryLatitude = address.Latitude
+		req.DeliveryLongitude = address.Longitude
+	} else {
+		req.CustomerAddressID = nil
+	}
+
+	// Tính phí giao hàng theo vùng khi có tọa độ giao hàng; phí được tính lại
+	// theo tạm tính thực tế khi lưu đơn (ngưỡng miễn phí). Ngoài các vùng đã
+	// cấu hình thì dùng phí nhập tay
+	if req.DeliveryLatitude != nil && req.DeliveryLongitude != nil {
+		quote, err := s.zoneService.Quote(ctx, *req.DeliveryLatitude, *req.DeliveryLongitude, 0)
+		if err != nil {
+			return nil, err
+		}
+		req.ShippingQuote = quote
+		if quote != nil && (req.DeliveryZone == nil || *req.DeliveryZone == "") {
+			req.DeliveryZone = &quote.Zone.Code
+		}
+	}
+
 	// Nếu userID rỗng, tìm hoặc tạo user guest
 	if userID == 0 {
 		user, err := s.userRepo.FindOrCreateUserByInfo(req.CustomerName, req.CustomerPhone, req.CustomerEmail)
@@ -39,6 +80,16 @@ func (s *OrderService) CreateOrder(ctx context.Context, req *model.CreateOrderRe
 			return nil, err
 		}
 		userID = user.ID
+		if user.Role == "client" {
+			req.CustomerID = &user.ID
+		}
+	} else {
+		// Đơn nhân viên tạo thuộc về khách hàng có cùng số điện thoại (tích và đổi điểm)
+		customerID, err := s.findCustomerID(ctx, req.CustomerPhone)
+		if err != nil {
+			return nil, err
+		}
+		req.CustomerID = customerID
 	}
 
 	// Create order
@@ -92,12 +143,66 @@ func (s *OrderService) UpdateOrderStatus(ctx context.Context, publicID string, r
 			s.hub.BroadcastToGroup("admin", data)
 		}
 	}
+	// Emit event kitchen_update so KDS screens pick up or drop the order
+	broadcastKitchenUpdate(ctx, s.hub, s.kitchenRepo, order)
+
+	// Propose or auto-assign a shipper once the order can be delivered
+	if order.Status == model.OrderStatusReadyForDelivery && s.assignmentService != nil {
+		s.assignmentService.HandleOrderReady(ctx, order.PublicID, userID)
+	}
 	return order, nil
 }
 
 // UpdateOrder updates an existing order
 func (s *OrderService) UpdateOrder(ctx context.Context, publicID string, req *model.UpdateOrderRequest, userID int64) (*model.Order, error) {
-	return s.orderRepo.UpdateOrder(ctx, publicID, req, userID)
+	// Chụp địa chỉ đã lưu vào đơn hàng
+	if req.CustomerAddressID != nil && *req.CustomerAddressID != "" {
+		address, err := s.getCustomerAddress(ctx, *req.CustomerAddressID, req.CustomerPhone)
+		if err != nil {
+			return nil, err
+		}
+		req.DeliveryAddress = &address.AddressLine
+		req.DeliveryWard = address.Ward
+		req.DeliveryDistrict = address.District
+		req.DeliveryProvince = address.Province
+		req.DeliveryLatitude = address.Latitude
+		req.DeliveryLongitude = address.Longitude
+	} else {
+		req.CustomerAddressID = nil
+	}
+	if req.DeliveryAddress != nil && (req.DeliveryLatitude == nil) != (req.DeliveryLongitude == nil) {
+		return nil, model.NewValidationError("delivery_latitude", "Phải nhập đủ vĩ độ và kinh độ giao hàng")
+	}
+
+	// Địa chỉ hoặc món có thể đã đổi: tính lại phí theo vùng của tọa độ giao
+	// hàng, trừ khi phí đã được sửa tay (xem OrderRepository.UpdateOrder)
+	latitude, longitude := req.DeliveryLatitude, req.DeliveryLongitude
+	if req.DeliveryAddress == nil {
+		current, err := s.orderRepo.GetOrderByID(ctx, publicID)
+		if err != nil {
+			return nil, ErrNotFound
+		}
+		latitude, longitude = current.DeliveryLatitude, current.DeliveryLongitude
+	}
+	if latitude != nil && longitude != nil {
+		quote, err := s.zoneService.Quote(ctx, *latitude, *longitude, 0)
+		if err != nil {
+			return nil, err
+		}
+		req.ShippingQuote = quote
+		if quote != nil && (req.DeliveryZone == nil || *req.DeliveryZone == "") {
+			req.DeliveryZone = &quote.Zone.Code
+		}
+	}
+
+	order, err := s.orderRepo.UpdateOrder(ctx, publicID, req, userID)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == model.OrderStatusProcessing {
+		broadcastKitchenUpdate(ctx, s.hub, s.kitchenRepo, order)
+	}
+	return order, nil
 }
 
 // ListOrders lists orders with filtering and pagination
@@ -124,7 +229,7 @@ func (s *OrderService) ListOrders(ctx context.Context, req *model.ListOrdersRequ
 
 // ValidateDiscountCode validates discount code
 func (s *OrderService) ValidateDiscountCode(ctx context.Context, req *model.ValidateDiscountCodeRequest) (*model.ValidateDiscountCodeResponse, error) {
-	validation, err := s.orderRepo.ValidateDiscountCode(ctx, req.Code, req.OrderAmount)
+	validation, err := s.orderRepo.ValidateDiscountCode(ctx, req)
 	if err != nil {
 		return nil, err
 	}
@@ -132,6 +237,19 @@ func (s *OrderService) ValidateDiscountCode(ctx context.Context, req *model.Vali
 	return validation, nil
 }
 
+// PreviewPromotions shows the promotions a cart would get, the customer phone
+// is only needed for stamp cards
+func (s *OrderService) PreviewPromotions(ctx context.Context, req *model.PromotionPreviewRequest) (*model.PromotionPreview, error) {
+	var customerID *int64
+	if phone := strings.TrimSpace(req.CustomerPhone); phone != "" {
+		var err error
+		if customerID, err = s.findCustomerID(ctx, phone); err != nil {
+			return nil, err
+		}
+	}
+	return s.orderRepo.PreviewPromotions(ctx, req.Items, customerID)
+}
+
 // GetOrderStatistics gets order statistics
 func (s *OrderService) GetOrderStatistics(ctx context.Context, startDate, endDate *time.Time) (*model.OrderStatistics, error) {
 	stats, err := s.orderRepo.GetOrderStatistics(ctx, startDate, endDate)
@@ -141,6 +259,103 @@ func (s *OrderService) GetOrderStatistics(ctx context.Context, startDate, endDat
 	return stats, nil
 }
 
+// UpdateShippingFee overrides the shipping fee of an order; the fee calculated
+// from the delivery zone is kept in the audit log next to the applied fee
+func (s *OrderService) UpdateShippingFee(ctx context.Context, publicID string, req *model.UpdateShippingFeeRequest, userID int64) (*model.Order, error) {
+	if *req.ShippingFee < 0 {
+		return nil, model.NewValidationError("shipping_fee", "Phí giao hàng không được âm")
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, model.NewValidationError("reason", "Vui lòng nhập lý do sửa phí giao hàng")
+	}
+
+	order, err := s.orderRepo.GetOrderByID(ctx, publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if order.Status == model.OrderStatusCancelled {
+		return nil, model.NewValidationError("status", "Không thể sửa phí giao hàng của đơn đã hủy")
+	}
+
+	var calculatedFee *float64
+	if order.DeliveryLatitude != nil && order.DeliveryLongitude != nil {
+		// Vùng giao hàng có thể đã thay đổi, không chặn việc sửa phí nếu không tính được
+		if quote, err := s.zoneService.Quote(ctx, *order.DeliveryLatitude, *order.DeliveryLongitude, order.Subtotal); err == nil && quote != nil {
+			calculatedFee = &quote.Fee
+		}
+	}
+
+	order, err = s.orderRepo.UpdateShippingFee(ctx, publicID, *req.ShippingFee, calculatedFee, reason, userID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrNotFound
+	}
+	// Emit event order_update
+	if s.hub != nil {
+		event := ws.Event{
+			Type:    ws.EventOrderUpdate,
+			Payload: order,
+		}
+		if data, err := json.Marshal(event); err == nil {
+			s.hub.BroadcastToGroup("admin", data)
+		}
+	}
+	return order, nil
+}
+
+// ListShippingFeeAudits lists the shipping fee changes of an order
+func (s *OrderService) ListShippingFeeAudits(ctx context.Context, publicID string) ([]model.ShippingFeeAudit, error) {
+	if _, err := s.orderRepo.GetOrderByID(ctx, publicID); err != nil {
+		return nil, ErrNotFound
+	}
+	return s.orderRepo.ListShippingFeeAudits(ctx, publicID)
+}
+
+// getCustomerAddress gets the saved address selected for an order, it must
+// belong to the customer with the order's phone
+func (s *OrderService) getCustomerAddress(ctx context.Context, publicID, customerPhone string) (*model.CustomerAddress, error) {
+	address, err := s.addressRepo.GetAddressByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if address == nil {
+		return nil, model.NewValidationError("customer_address_id", "Địa chỉ giao hàng không tồn tại")
+	}
+	customerID, err := s.findCustomerID(ctx, customerPhone)
+	if err != nil {
+		return nil, err
+	}
+	if customerID == nil || *customerID != address.UserID {
+		return nil, model.NewValidationError("customer_address_id", "Địa chỉ giao hàng không thuộc khách hàng của đơn")
+	}
+	return address, nil
+}
+
+// findCustomerID finds the active customer with a phone, preferring a registered
+// account over a guest; nil if there is none
+func (s *OrderService) findCustomerID(ctx context.Context, phone string) (*int64, error) {
+	matches, err := s.accountRepo.FindByContact(ctx, normalizePhone(phone), "")
+	if err != nil {
+		return nil, err
+	}
+	var customerID *int64
+	for i := range matches {
+		if matches[i].Role != "client" || !matches[i].IsActive {
+			continue
+		}
+		if customerID == nil || !matches[i].IsGuest {
+			customerID = &matches[i].ID
+		}
+		if !matches[i].IsGuest {
+			break
+		}
+	}
+	return customerID, nil
+}
+
 // validateCreateOrderRequest validates create order request
 func (s *OrderService) validateCreateOrderRequest(req *model.CreateOrderRequest) error {
 	// Check if items are provided
@@ -148,10 +363,8 @@ func (s *OrderService) validateCreateOrderRequest(req *model.CreateOrderRequest)
 		return model.NewValidationError("items", "Phải có ít nhất 1 sản phẩm trong đơn hàng")
 	}
 
-	// Validate discount logic
-	if req.DiscountCode != "" && req.ManualDiscountAmount > 0 {
-		return model.NewValidationError("discount", "Không thể áp dụng cả mã giảm giá và giảm giá thủ công cùng lúc")
-	}
+	// Whether a discount code may be combined with a manual discount is decided
+	// by the code's stacking rule when it is applied (see OrderRepository.applyDiscountCode)
 
 	// Validate manual discount
 	if req.ManualDiscountAmount > 0 {
@@ -163,6 +376,22 @@ func (s *OrderService) validateCreateOrderRequest(req *model.CreateOrderRequest)
 		}
 	}
 
+	// Validate delivery coordinates and shipping fee override
+	if (req.DeliveryLatitude == nil) != (req.DeliveryLongitude == nil) {
+		return model.NewValidationError("delivery_latitude", "Phải nhập đủ vĩ độ và kinh độ giao hàng")
+	}
+	if req.ShippingFeeOverride != nil {
+		if *req.ShippingFeeOverride < 0 {
+			return model.NewValidationError("shipping_fee_override", "Phí giao hàng không được âm")
+		}
+		if strings.TrimSpace(req.ShippingFeeReason) == "" {
+			return model.NewValidationError("shipping_fee_reason", "Vui lòng nhập lý do sửa phí giao hàng")
+		}
+	}
+	if req.RedeemPoints < 0 {
+		return model.NewValidationError("redeem_points", "Số điểm sử dụng không được âm")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+	"food-pos-backend/internal/sms"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// OTPService sends one-time codes by SMS and verifies them
+type OTPService struct {
+	otpRepo *repository.OTPRepository
+	sender  sms.SMSSender
+	policy  model.OTPPolicy
+}
+
+func NewOTPService(otpRepo *repository.OTPRepository, sender sms.SMSSender, policy model.OTPPolicy) *OTPService {
+	return &OTPService{
+		otpRepo: otpRepo,
+		sender:  sender,
+		policy:  policy,
+	}
+}
+
+// Send generates a code for a normalized phone, stores its hash and sends it by SMS
+func (s *OTPService) Send(ctx context.Context, purpose, phone string) (*model.OTPSentResponse, error) {
+	sinceLast, sentCount, err := s.otpRepo.GetSendStats(ctx, purpose, phone, time.Hour)
+	if err != nil {
+		return nil, err
+	}
+	if sinceLast != nil && *sinceLast < s.policy.ResendInterval {
+		wait := int((s.policy.ResendInterval - *sinceLast).Seconds()) + 1
+		return nil, model.NewValidationError("phone", fmt.Sprintf("Vui lòng đợi %d giây trước khi yêu cầu mã mới", wait))
+	}
+	if sentCount >= s.policy.MaxSendsPerHour {
+		return nil, model.NewValidationError("phone", "Bạn đã yêu cầu quá nhiều mã, vui lòng thử lại sau")
+	}
+
+	code, err := generateOTP(s.policy.Length)
+	if err != nil {
+		return nil, err
+	}
+	codeHash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	otp := &model.OTPCode{Purpose: purpose, Phone: phone, CodeHash: string(codeHash)}
+	if err := s.otpRepo.CreateCode(ctx, otp, s.policy.TTL); err != nil {
+		return nil, err
+	}
+
+	message := fmt.Sprintf("Ma xac thuc 3 O'CLOCK cua ban la %s, hieu luc %d phut. Khong chia se ma nay cho bat ky ai.", code, int(s.policy.TTL.Minutes()))
+	if err := s.sender.Send(ctx, phone, message); err != nil {
+		return nil, fmt.Errorf("failed to send OTP: %w", err)
+	}
+
+	return s.sentResponse(), nil
+}
+
+// sentResponse is the response of a send request, also returned when no code
+// was sent so callers do not reveal which phones have accounts
+func (s *OTPService) sentResponse() *model.OTPSentResponse {
+	return &model.OTPSentResponse{
+		ExpiresIn:   int(s.policy.TTL.Seconds()),
+		ResendAfter: int(s.policy.ResendInterval.Seconds()),
+	}
+}
+
+// Verify checks a code against the latest code sent to a normalized phone and
+// uses it up on success
+func (s *OTPService) Verify(ctx context.Context, purpose, phone, code string) error {
+	otp, err := s.otpRepo.GetActiveCode(ctx, purpose, phone)
+	if err != nil {
+		return err
+	}
+	if otp == nil {
+		return model.NewValidationError("code", "Mã OTP đã hết hạn, vui lòng yêu cầu mã mới")
+	}
+
+	ok, err := s.otpRepo.RecordAttempt(ctx, otp.ID, s.policy.MaxAttempts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return model.NewValidationError("code", "Bạn đã nhập sai quá nhiều lần, vui lòng yêu cầu mã mới")
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(otp.CodeHash), []byte(code)); err != nil {
+		return model.NewValidationError("code", "Mã OTP không đúng")
+	}
+
+	consumed, err := s.otpRepo.ConsumeCode(ctx, otp.ID)
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return model.NewValidationError("code", "Mã OTP đã được sử dụng, vui lòng yêu cầu mã mới")
+	}
+	return nil
+}
+
+// generateOTP returns a random numeric code of the given length
+func generateOTP(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		digit, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + digit.Int64())
+	}
+	return string(code), nil
+}
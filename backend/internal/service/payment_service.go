@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/payment"
+	"food-pos-backend/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// paymentStore is the payments ledger, implemented by repository.PaymentRepository
+type paymentStore interface {
+	ListPaymentMethods(ctx context.Context, activeOnly bool) ([]*model.PaymentMethod, error)
+	GetPaymentByPublicID(ctx context.Context, publicID string) (*model.Payment, error)
+	GetOrderPaymentSummary(ctx context.Context, orderPublicID string) (*model.OrderPaymentSummary, error)
+	RecordPayments(ctx context.Context, orderPublicID string, req *model.RecordPaymentRequest, userID int64) (*model.OrderPaymentSummary, error)
+	RefundPayment(ctx context.Context, paymentPublicID string, req *model.RefundPaymentRequest, status string, userID int64) (*model.Payment, error)
+	CompleteRefund(ctx context.Context, refundID int64, success bool, reference *string) error
+	CreatePendingPayment(ctx context.Context, orderPublicID, paymentMethod string, amount *float64, reference string, userID int64) (*model.Payment, error)
+	SetPayURL(ctx context.Context, paymentID int64, payURL string) error
+	GetPaymentByReference(ctx context.Context, paymentMethod, reference string) (*model.Payment, error)
+	CompletePayment(ctx context.Context, paymentID int64, success bool, providerTxnID *string) (*model.Payment, error)
+}
+
+type PaymentService struct {
+	paymentRepo paymentStore
+	providers   *payment.Registry
+	publicURL   string
+}
+
+func NewPaymentService(paymentRepo *repository.PaymentRepository, providers *payment.Registry, publicURL string) *PaymentService {
+	return &PaymentService{
+		paymentRepo: paymentRepo,
+		providers:   providers,
+		publicURL:   strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// ListPaymentMethods lists the active payment methods
+func (s *PaymentService) ListPaymentMethods(ctx context.Context) ([]*model.PaymentMethod, error) {
+	return s.paymentRepo.ListPaymentMethods(ctx, true)
+}
+
+// GetOrderPayments gets the payments and outstanding balance of an order
+func (s *PaymentService) GetOrderPayments(ctx context.Context, orderPublicID string) (*model.OrderPaymentSummary, error) {
+	summary, err := s.paymentRepo.GetOrderPaymentSummary(ctx, orderPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if summary == nil {
+		return nil, ErrNotFound
+	}
+	return summary, nil
+}
+
+// RecordPayment records one or more tenders (e.g. half cash, half MoMo) against an order
+func (s *PaymentService) RecordPayment(ctx context.Context, orderPublicID string, req *model.RecordPaymentRequest, userID int64) (*model.OrderPaymentSummary, error) {
+	if len(req.Tenders) == 0 {
+		return nil, model.NewValidationError("tenders", "Cần ít nhất một khoản thanh toán")
+	}
+	for i := range req.Tenders {
+		tender := &req.Tenders[i]
+		tender.PaymentMethod = strings.ToLower(strings.TrimSpace(tender.PaymentMethod))
+		if tender.PaymentMethod == "" {
+			return nil, model.NewValidationError("payment_method", "Phương thức thanh toán không được để trống")
+		}
+		if tender.Amount <= 0 {
+			return nil, model.NewValidationError("amount", "Số tiền thanh toán phải lớn hơn 0")
+		}
+	}
+
+	if _, err := s.GetOrderPayments(ctx, orderPublicID); err != nil {
+		return nil, err
+	}
+	return s.paymentRepo.RecordPayments(ctx, orderPublicID, req, userID)
+}
+
+// RefundPayment refunds a payment fully or partially
+func (s *PaymentService) RefundPayment(ctx context.Context, paymentPublicID string, req *model.RefundPaymentRequest, userID int64) (*model.OrderPaymentSummary, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return nil, model.NewValidationError("reason", "Lý do hoàn tiền không được để trống")
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return nil, model.NewValidationError("amount", "Số tiền hoàn phải lớn hơn 0")
+	}
+
+	existing, err := s.paymentRepo.GetPaymentByPublicID(ctx, paymentPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrNotFound
+	}
+
+	// Gateway payments are recorded as a pending refund first, so concurrent
+	// refunds cannot both pass the refundable check, then refunded at the provider
+	provider, ok := s.providers.Get(existing.PaymentMethod)
+	if !ok || existing.Reference == nil || existing.PayURL == nil {
+		if _, err := s.paymentRepo.RefundPayment(ctx, paymentPublicID, req, model.PaymentTransactionCompleted, userID); err != nil {
+			return nil, err
+		}
+		return s.GetOrderPayments(ctx, existing.OrderPublicID)
+	}
+
+	refund, err := s.paymentRepo.RefundPayment(ctx, paymentPublicID, req, model.PaymentTransactionPending, userID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := provider.Refund(ctx, &payment.RefundRequest{
+		Reference: *existing.Reference,
+		Amount:    refund.Amount,
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		if failErr := s.paymentRepo.CompleteRefund(ctx, refund.ID, false, nil); failErr != nil {
+			log.Printf("[Payment] Failed to mark refund %s as failed: %v", refund.PublicID, failErr)
+		}
+		return nil, fmt.Errorf("gateway refund failed: %w", err)
+	}
+	if err := s.paymentRepo.CompleteRefund(ctx, refund.ID, true, &result.RefundID); err != nil {
+		// The gateway refunded, the pending row keeps holding the amount until reconciled
+		log.Printf("[Payment] Gateway refund %s of refund %s not recorded: %v", result.RefundID, refund.PublicID, err)
+		return nil, err
+	}
+
+	return s.GetOrderPayments(ctx, existing.OrderPublicID)
+}
+
+// SupportsOnline reports whether a payment method is paid through a configured gateway
+func (s *PaymentService) SupportsOnline(paymentMethod string) bool {
+	_, ok := s.providers.Get(strings.ToLower(strings.TrimSpace(paymentMethod)))
+	return ok
+}
+
+// CreatePaymentIntent starts a gateway checkout (MoMo, VNPay, ...) for an order and
+// returns the pending payment with the URL to redirect the customer to
+func (s *PaymentService) CreatePaymentIntent(ctx context.Context, orderPublicID string, req *model.CreatePaymentIntentRequest, userID int64) (*model.Payment, error) {
+	req.PaymentMethod = strings.ToLower(strings.TrimSpace(req.PaymentMethod))
+	provider, ok := s.providers.Get(req.PaymentMethod)
+	if !ok {
+		return nil, model.NewValidationError("payment_method", "Phương thức thanh toán không hỗ trợ thanh toán online: "+req.PaymentMethod)
+	}
+	if req.Amount != nil && *req.Amount <= 0 {
+		return nil, model.NewValidationError("amount", "Số tiền thanh toán phải lớn hơn 0")
+	}
+
+	summary, err := s.GetOrderPayments(ctx, orderPublicID)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", "")[:20])
+	pending, err := s.paymentRepo.CreatePendingPayment(ctx, orderPublicID, req.PaymentMethod, req.Amount, reference, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := provider.CreateIntent(ctx, &payment.IntentRequest{
+		Reference:   reference,
+		Amount:      pending.Amount,
+		OrderNumber: summary.OrderNumber,
+		Description: "Thanh toán đơn hàng " + summary.OrderNumber,
+		ReturnURL:   fmt.Sprintf("%s/api/payments/%s/return", s.publicURL, provider.Code()),
+		IPNURL:      fmt.Sprintf("%s/api/payments/%s/ipn", s.publicURL, provider.Code()),
+	})
+	if err != nil {
+		if _, failErr := s.paymentRepo.CompletePayment(ctx, pending.ID, false, nil); failErr != nil {
+			log.Printf("[Payment] Failed to mark payment %s as failed: %v", pending.PublicID, failErr)
+		}
+		return nil, fmt.Errorf("gateway create payment failed: %w", err)
+	}
+
+	if err := s.paymentRepo.SetPayURL(ctx, pending.ID, intent.PayURL); err != nil {
+		return nil, err
+	}
+	pending.PayURL = &intent.PayURL
+	return pending, nil
+}
+
+// HandleProviderCallback verifies a signed return redirect or IPN and settles the payment
+func (s *PaymentService) HandleProviderCallback(ctx context.Context, providerCode string, params url.Values) (*model.Payment, error) {
+	provider, ok := s.providers.Get(providerCode)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	result, err := provider.VerifyCallback(ctx, params)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			return nil, model.NewValidationError("signature", "Chữ ký không hợp lệ")
+		}
+		return nil, err
+	}
+
+	existing, err := s.paymentRepo.GetPaymentByReference(ctx, provider.Code(), result.Reference)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrNotFound
+	}
+
+	// Số tiền phải khớp với số tiền đã tạo giao dịch
+	success := result.Success && roundAmount(result.Amount) == roundAmount(existing.Amount)
+	if result.Success && !success {
+		log.Printf("[Payment] Amount mismatch for %s: expected %.2f, got %.2f", result.Reference, existing.Amount, result.Amount)
+	}
+	return s.paymentRepo.CompletePayment(ctx, existing.ID, success, nonEmpty(result.TransactionID))
+}
+
+// SyncPaymentStatus queries the gateway for a pending payment whose IPN never arrived
+func (s *PaymentService) SyncPaymentStatus(ctx context.Context, paymentPublicID string) (*model.Payment, error) {
+	existing, err := s.paymentRepo.GetPaymentByPublicID(ctx, paymentPublicID)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		return nil, ErrNotFound
+	}
+	provider, ok := s.providers.Get(existing.PaymentMethod)
+	if !ok || existing.Reference == nil || existing.Status != model.PaymentTransactionPending {
+		return existing, nil
+	}
+
+	result, err := provider.QueryStatus(ctx, *existing.Reference)
+	if err != nil {
+		return nil, fmt.Errorf("gateway query failed: %w", err)
+	}
+	switch result.Status {
+	case payment.StatusCompleted:
+		return s.paymentRepo.CompletePayment(ctx, existing.ID, roundAmount(result.Amount) == roundAmount(existing.Amount), nonEmpty(result.TransactionID))
+	case payment.StatusFailed:
+		return s.paymentRepo.CompletePayment(ctx, existing.ID, false, nonEmpty(result.TransactionID))
+	default:
+		return existing, nil
+	}
+}
+
+// Provider returns the gateway provider for a payment method code
+func (s *PaymentService) Provider(code string) (payment.Provider, bool) {
+	return s.providers.Get(code)
+}
+
+func roundAmount(amount float64) int64 {
+	return int64(amount*100 + 0.5)
+}
+
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,278 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/payment"
+)
+
+// memoryPaymentStore keeps the gateway payments of a single order in memory
+type memoryPaymentStore struct {
+	paymentStore
+	summary     model.OrderPaymentSummary
+	payments    map[string]*model.Payment
+	refunds     []*model.Payment
+	completions int
+}
+
+func newMemoryPaymentStore(total float64) *memoryPaymentStore {
+	return &memoryPaymentStore{
+		summary:  model.OrderPaymentSummary{OrderID: "order-1", OrderNumber: "ORD-20250101-001", TotalAmount: total, Balance: total},
+		payments: make(map[string]*model.Payment),
+	}
+}
+
+func (s *memoryPaymentStore) GetOrderPaymentSummary(ctx context.Context, orderPublicID string) (*model.OrderPaymentSummary, error) {
+	summary := s.summary
+	return &summary, nil
+}
+
+func (s *memoryPaymentStore) CreatePendingPayment(ctx context.Context, orderPublicID, paymentMethod string, amount *float64, reference string, userID int64) (*model.Payment, error) {
+	payAmount := s.summary.Balance
+	if amount != nil {
+		payAmount = *amount
+	}
+	p := &model.Payment{
+		ID:            int64(len(s.payments) + 1),
+		PublicID:      "payment-" + reference,
+		OrderPublicID: orderPublicID,
+		PaymentType:   model.PaymentTypePayment,
+		PaymentMethod: paymentMethod,
+		Amount:        payAmount,
+		Status:        model.PaymentTransactionPending,
+		Reference:     &reference,
+	}
+	s.payments[reference] = p
+	return p, nil
+}
+
+func (s *memoryPaymentStore) SetPayURL(ctx context.Context, paymentID int64, payURL string) error {
+	for _, p := range s.payments {
+		if p.ID == paymentID {
+			p.PayURL = &payURL
+		}
+	}
+	return nil
+}
+
+func (s *memoryPaymentStore) GetPaymentByReference(ctx context.Context, paymentMethod, reference string) (*model.Payment, error) {
+	p, ok := s.payments[reference]
+	if !ok || p.PaymentMethod != paymentMethod {
+		return nil, nil
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (s *memoryPaymentStore) CompletePayment(ctx context.Context, paymentID int64, success bool, providerTxnID *string) (*model.Payment, error) {
+	for _, p := range s.payments {
+		if p.ID != paymentID {
+			continue
+		}
+		if p.Status == model.PaymentTransactionPending {
+			p.Status = model.PaymentTransactionFailed
+			if success {
+				p.Status = model.PaymentTransactionCompleted
+				s.completions++
+				s.summary.PaidAmount += p.Amount
+				s.summary.Balance -= p.Amount
+			}
+			p.ProviderTxnID = providerTxnID
+		}
+		copied := *p
+		return &copied, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (s *memoryPaymentStore) GetPaymentByPublicID(ctx context.Context, publicID string) (*model.Payment, error) {
+	for _, p := range s.payments {
+		if p.PublicID == publicID {
+			copied := *p
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryPaymentStore) RefundPayment(ctx context.Context, paymentPublicID string, req *model.RefundPaymentRequest, status string, userID int64) (*model.Payment, error) {
+	original, _ := s.GetPaymentByPublicID(ctx, paymentPublicID)
+	if original == nil || original.Status != model.PaymentTransactionCompleted {
+		return nil, model.NewValidationError("payment", "not refundable")
+	}
+	refundable := original.Amount
+	for _, refund := range s.refunds {
+		if *refund.RefundOfPublicID == paymentPublicID && refund.Status != model.PaymentTransactionFailed {
+			refundable -= refund.Amount
+		}
+	}
+	amount := refundable
+	if req.Amount != nil {
+		amount = *req.Amount
+	}
+	if refundable <= 0 || amount > refundable {
+		return nil, model.NewValidationError("amount", "above refundable")
+	}
+	refund := &model.Payment{
+		ID:               int64(100 + len(s.refunds)),
+		PublicID:         fmt.Sprintf("refund-%d", len(s.refunds)+1),
+		PaymentType:      model.PaymentTypeRefund,
+		Amount:           amount,
+		Status:           status,
+		RefundOfPublicID: &paymentPublicID,
+	}
+	s.refunds = append(s.refunds, refund)
+	copied := *refund
+	return &copied, nil
+}
+
+func (s *memoryPaymentStore) CompleteRefund(ctx context.Context, refundID int64, success bool, reference *string) error {
+	for _, refund := range s.refunds {
+		if refund.ID == refundID && refund.Status == model.PaymentTransactionPending {
+			refund.Status = model.PaymentTransactionFailed
+			if success {
+				refund.Status = model.PaymentTransactionCompleted
+				refund.Reference = reference
+			}
+			return nil
+		}
+	}
+	return model.NewValidationError("payment", "already settled")
+}
+
+func newTestPaymentService(store *memoryPaymentStore) (*PaymentService, *payment.MockProvider) {
+	provider := payment.NewMockProvider("momo", "secret", "http://localhost:8080")
+	return &PaymentService{
+		paymentRepo: store,
+		providers:   payment.NewRegistry(provider),
+		publicURL:   "http://localhost:8080",
+	}, provider
+}
+
+// checkout creates an intent and pays it on the mock gateway, returning the signed IPN params
+func checkout(t *testing.T, s *PaymentService, provider *payment.MockProvider) (*model.Payment, url.Values) {
+	t.Helper()
+	pending, err := s.CreatePaymentIntent(context.Background(), "order-1", &model.CreatePaymentIntentRequest{PaymentMethod: "MoMo"}, 1)
+	if err != nil {
+		t.Fatalf("CreatePaymentIntent() error: %v", err)
+	}
+	if pending.PayURL == nil {
+		t.Fatal("CreatePaymentIntent() returned no pay URL")
+	}
+	payURL, err := url.Parse(*pending.PayURL)
+	if err != nil {
+		t.Fatalf("invalid pay URL: %v", err)
+	}
+	callback, _, err := provider.Checkout(payURL.Query(), true)
+	if err != nil {
+		t.Fatalf("Checkout() error: %v", err)
+	}
+	return pending, callback
+}
+
+func TestHandleProviderCallbackIsIdempotent(t *testing.T) {
+	store := newMemoryPaymentStore(85000)
+	s, provider := newTestPaymentService(store)
+	_, callback := checkout(t, s, provider)
+
+	for i := 0; i < 2; i++ {
+		settled, err := s.HandleProviderCallback(context.Background(), "momo", callback)
+		if err != nil {
+			t.Fatalf("IPN %d: HandleProviderCallback() error: %v", i+1, err)
+		}
+		if settled.Status != model.PaymentTransactionCompleted {
+			t.Fatalf("IPN %d: status = %q, want %q", i+1, settled.Status, model.PaymentTransactionCompleted)
+		}
+	}
+	if store.completions != 1 {
+		t.Fatalf("payment completed %d times, want 1", store.completions)
+	}
+	if store.summary.PaidAmount != 85000 || store.summary.Balance != 0 {
+		t.Fatalf("paid = %.2f, balance = %.2f", store.summary.PaidAmount, store.summary.Balance)
+	}
+}
+
+func TestHandleProviderCallbackRejectsAmountMismatch(t *testing.T) {
+	store := newMemoryPaymentStore(85000)
+	s, provider := newTestPaymentService(store)
+	_, callback := checkout(t, s, provider)
+
+	// A correctly signed IPN for another amount than the intent is not a payment of the order
+	callback.Set("amount", "1000.00")
+	callback.Set("signature", provider.Sign(callback))
+
+	settled, err := s.HandleProviderCallback(context.Background(), "momo", callback)
+	if err != nil {
+		t.Fatalf("HandleProviderCallback() error: %v", err)
+	}
+	if settled.Status != model.PaymentTransactionFailed {
+		t.Fatalf("status = %q, want %q", settled.Status, model.PaymentTransactionFailed)
+	}
+	if store.completions != 0 || store.summary.Balance != 85000 {
+		t.Fatalf("mismatched IPN paid the order: completions = %d, balance = %.2f", store.completions, store.summary.Balance)
+	}
+}
+
+func TestHandleProviderCallbackRejectsTamperedIPN(t *testing.T) {
+	store := newMemoryPaymentStore(85000)
+	s, provider := newTestPaymentService(store)
+	pending, callback := checkout(t, s, provider)
+
+	callback.Set("amount", "1000.00")
+	_, err := s.HandleProviderCallback(context.Background(), "momo", callback)
+	if _, ok := err.(*model.ValidationError); !ok {
+		t.Fatalf("HandleProviderCallback() error = %v, want a validation error", err)
+	}
+	if got := store.payments[*pending.Reference].Status; got != model.PaymentTransactionPending {
+		t.Fatalf("status = %q, want the payment left pending", got)
+	}
+}
+
+func TestRefundPaymentRecordsGatewayRefund(t *testing.T) {
+	store := newMemoryPaymentStore(85000)
+	s, provider := newTestPaymentService(store)
+	pending, callback := checkout(t, s, provider)
+	if _, err := s.HandleProviderCallback(context.Background(), "momo", callback); err != nil {
+		t.Fatalf("HandleProviderCallback() error: %v", err)
+	}
+
+	if _, err := s.RefundPayment(context.Background(), pending.PublicID, &model.RefundPaymentRequest{Reason: "Khách hủy"}, 1); err != nil {
+		t.Fatalf("RefundPayment() error: %v", err)
+	}
+	if len(store.refunds) != 1 || store.refunds[0].Status != model.PaymentTransactionCompleted || store.refunds[0].Reference == nil {
+		t.Fatalf("refund not recorded as completed with the gateway refund ID: %+v", store.refunds)
+	}
+
+	// The refunded amount is held, a second refund is rejected before reaching the gateway
+	_, err := s.RefundPayment(context.Background(), pending.PublicID, &model.RefundPaymentRequest{Reason: "Khách hủy"}, 1)
+	if _, ok := err.(*model.ValidationError); !ok {
+		t.Fatalf("second RefundPayment() error = %v, want a validation error", err)
+	}
+	if len(store.refunds) != 1 {
+		t.Fatalf("second refund recorded: %d refunds", len(store.refunds))
+	}
+}
+
+func TestRefundPaymentMarksFailedGatewayRefund(t *testing.T) {
+	store := newMemoryPaymentStore(85000)
+	s, provider := newTestPaymentService(store)
+	pending, callback := checkout(t, s, provider)
+	if _, err := s.HandleProviderCallback(context.Background(), "momo", callback); err != nil {
+		t.Fatalf("HandleProviderCallback() error: %v", err)
+	}
+
+	// The gateway does not know the transaction, so it refuses the refund
+	unknown := "UNKNOWN"
+	store.payments[*pending.Reference].Reference = &unknown
+
+	if _, err := s.RefundPayment(context.Background(), pending.PublicID, &model.RefundPaymentRequest{Reason: "Khách hủy"}, 1); err == nil {
+		t.Fatal("RefundPayment() succeeded, want the gateway error")
+	}
+	if len(store.refunds) != 1 || store.refunds[0].Status != model.PaymentTransactionFailed {
+		t.Fatalf("failed gateway refund not recorded as failed: %+v", store.refunds)
+	}
+}
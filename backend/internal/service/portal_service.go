@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+// portalPaymentMethods are the payment methods customers can pick on the portal
+var portalPaymentMethods = map[string]bool{"cash": true, "momo": true, "vnpay": true}
+
+type PortalService struct {
+	portalRepo     *repository.PortalRepository
+	orderRepo      *repository.OrderRepository
+	paymentRepo    *repository.PaymentRepository
+	orderService   *OrderService
+	zoneService    *DeliveryZoneService
+	paymentService *PaymentService
+}
+
+func NewPortalService(portalRepo *repository.PortalRepository, orderRepo *repository.OrderRepository, paymentRepo *repository.PaymentRepository, orderService *OrderService, zoneService *DeliveryZoneService, paymentService *PaymentService) *PortalService {
+	return &PortalService{
+		portalRepo:     portalRepo,
+		orderRepo:      orderRepo,
+		paymentRepo:    paymentRepo,
+		orderService:   orderService,
+		zoneService:    zoneService,
+		paymentService: paymentService,
+	}
+}
+
+// GetMenu lists the menu shown on the customer portal
+func (s *PortalService) GetMenu(ctx context.Context) ([]model.PublicMenuProduct, error) {
+	return s.portalRepo.ListMenu(ctx)
+}
+
+// GetMenuCategories lists the menu sections shown on the customer portal
+func (s *PortalService) GetMenuCategories(ctx context.Context) ([]*model.PublicMenuCategory, error) {
+	return s.portalRepo.ListMenuCategories(ctx)
+}
+
+// QuoteCart prices a cart on the server: item prices, discount code and shipping fee
+func (s *PortalService) QuoteCart(ctx context.Context, req *model.CartRequest) (*model.CartQuote, error) {
+	if (req.DeliveryLatitude == nil) != (req.DeliveryLongitude == nil) {
+		return nil, model.NewValidationError("delivery_latitude", "Phải nhập đủ vĩ độ và kinh độ giao hàng")
+	}
+
+	lines, subtotal, err := s.portalRepo.PriceCart(ctx, req.Items)
+	if err != nil {
+		return nil, err
+	}
+	quote := &model.CartQuote{Items: lines, Subtotal: subtotal}
+
+	items := make([]model.CreateOrderItemRequest, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, model.CreateOrderItemRequest{
+			VariantID:         item.VariantID,
+			Quantity:          item.Quantity,
+			ModifierOptionIDs: item.ModifierOptionIDs,
+		})
+	}
+
+	// Same automatic promotions as checkout
+	promotions, err := s.orderService.PreviewPromotions(ctx, &model.PromotionPreviewRequest{
+		CustomerPhone: normalizePhone(req.CustomerPhone),
+		Items:         items,
+	})
+	if err != nil {
+		return nil, err
+	}
+	quote.PromotionDiscountAmount = promotions.PromotionDiscountAmount
+
+	if code := strings.TrimSpace(req.DiscountCode); code != "" {
+		validation, err := s.orderRepo.ValidateDiscountCode(ctx, &model.ValidateDiscountCodeRequest{
+			Code:          code,
+			OrderAmount:   subtotal,
+			CustomerPhone: normalizePhone(req.CustomerPhone),
+			Items:         items,
+		})
+		if err != nil {
+			return nil, err
+		}
+		quote.DiscountCode = code
+		if validation.IsValid {
+			quote.DiscountAmount = validation.DiscountAmount
+		} else {
+			quote.DiscountError = validation.Message
+		}
+	}
+
+	if req.DeliveryLatitude != nil {
+		shipping, err := s.zoneService.QuoteInZone(ctx, *req.DeliveryLatitude, *req.DeliveryLongitude, subtotal)
+		if err != nil {
+			return nil, err
+		}
+		quote.ShippingFee = shipping.Fee
+		quote.DeliveryZone = shipping.Zone.Code
+	}
+
+	quote.TotalAmount = math.Max(0, quote.Subtotal-quote.DiscountAmount-quote.PromotionDiscountAmount+quote.ShippingFee)
+	return quote, nil
+}
+
+// Checkout places a guest order from the portal; the customer is matched to an
+// existing user by phone/email or a guest user is created. Orders paid online
+// get a gateway checkout, returned as pay_url
+func (s *PortalService) Checkout(ctx context.Context, req *model.CheckoutRequest) (*model.OrderTracking, error) {
+	if err := s.validateCheckoutRequest(ctx, req); err != nil {
+		return nil, err
+	}
+
+	items := make([]model.CreateOrderItemRequest, 0, len(req.Items))
+	for _, item := range req.Items {
+		items = append(items, model.CreateOrderItemRequest{
+			VariantID:         item.VariantID,
+			Quantity:          item.Quantity,
+			Notes:             strings.TrimSpace(item.Notes),
+			ModifierOptionIDs: item.ModifierOptionIDs,
+		})
+	}
+	phone := normalizePhone(req.CustomerPhone)
+	order, err := s.orderService.CreateOrder(ctx, &model.CreateOrderRequest{
+		CustomerName:      strings.TrimSpace(req.CustomerName),
+		CustomerPhone:     phone,
+		CustomerEmail:     strings.TrimSpace(req.CustomerEmail),
+		Items:             items,
+		DiscountCode:      strings.TrimSpace(req.DiscountCode),
+		PaymentMethod:     req.PaymentMethod,
+		Notes:             strings.TrimSpace(req.Notes),
+		DeliveryAddress:   strings.TrimSpace(req.DeliveryAddress),
+		DeliveryWard:      strings.TrimSpace(req.DeliveryWard),
+		DeliveryDistrict:  strings.TrimSpace(req.DeliveryDistrict),
+		DeliveryProvince:  strings.TrimSpace(req.DeliveryProvince),
+		DeliveryLatitude:  req.DeliveryLatitude,
+		DeliveryLongitude: req.DeliveryLongitude,
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// The order is placed either way, the customer can retry paying with PayOrder
+	if s.paymentService.SupportsOnline(order.PaymentMethod) {
+		if _, err := s.paymentService.CreatePaymentIntent(ctx, order.PublicID, &model.CreatePaymentIntentRequest{PaymentMethod: order.PaymentMethod}, order.CreatedBy); err != nil {
+			log.Printf("[Portal] Failed to start online payment for order %s: %v", order.OrderNumber, err)
+		}
+	}
+
+	return s.portalRepo.TrackOrder(ctx, order.OrderNumber, phone)
+}
+
+// PayOrder opens an online checkout for a portal order paid by MoMo/VNPay, or
+// returns the one still open
+func (s *PortalService) PayOrder(ctx context.Context, req *model.PayOrderRequest) (*model.OrderTracking, error) {
+	tracking, err := s.TrackOrder(ctx, req.OrderNumber, req.CustomerPhone)
+	if err != nil {
+		return nil, err
+	}
+	if tracking.PayURL != nil {
+		return tracking, nil
+	}
+	if !s.paymentService.SupportsOnline(tracking.PaymentMethod) {
+		return nil, model.NewValidationError("payment_method", "Đơn hàng không thanh toán online")
+	}
+	if tracking.Status == model.OrderStatusCancelled {
+		return nil, model.NewValidationError("status", "Đơn hàng đã bị hủy")
+	}
+	if tracking.PaymentStatus == model.PaymentStatusPaid {
+		return nil, model.NewValidationError("payment_status", "Đơn hàng đã được thanh toán")
+	}
+
+	if _, err := s.paymentService.CreatePaymentIntent(ctx, tracking.PublicID, &model.CreatePaymentIntentRequest{PaymentMethod: tracking.PaymentMethod}, tracking.CreatedBy); err != nil {
+		return nil, err
+	}
+	return s.TrackOrder(ctx, req.OrderNumber, req.CustomerPhone)
+}
+
+// TrackOrder gets the public view of an order; both the order number and the phone must match
+func (s *PortalService) TrackOrder(ctx context.Context, orderNumber, phone string) (*model.OrderTracking, error) {
+	orderNumber = strings.TrimSpace(orderNumber)
+	phone = normalizePhone(phone)
+	if orderNumber == "" || phone == "" {
+		return nil, model.NewValidationError("order_number", "Vui lòng nhập mã đơn hàng và số điện thoại")
+	}
+
+	tracking, err := s.portalRepo.TrackOrder(ctx, orderNumber, phone)
+	if err != nil {
+		return nil, err
+	}
+	if tracking == nil {
+		return nil, ErrNotFound
+	}
+	return tracking, nil
+}
+
+// validateCheckoutRequest applies the portal rules, stricter than staff order entry
+func (s *PortalService) validateCheckoutRequest(ctx context.Context, req *model.CheckoutRequest) error {
+	if strings.TrimSpace(req.CustomerName) == "" {
+		return model.NewValidationError("customer_name", "Vui lòng nhập họ tên")
+	}
+	if !isValidPhone(normalizePhone(req.CustomerPhone)) {
+		return model.NewValidationError("customer_phone", "Số điện thoại không hợp lệ")
+	}
+	if strings.TrimSpace(req.DeliveryAddress) == "" {
+		return model.NewValidationError("delivery_address", "Vui lòng nhập địa chỉ giao hàng")
+	}
+	if (req.DeliveryLatitude == nil) != (req.DeliveryLongitude == nil) {
+		return model.NewValidationError("delivery_latitude", "Phải nhập đủ vĩ độ và kinh độ giao hàng")
+	}
+	// Cửa hàng chỉ nhận đơn online trong khu vực giao hàng
+	if req.DeliveryLatitude != nil {
+		if _, err := s.zoneService.QuoteInZone(ctx, *req.DeliveryLatitude, *req.DeliveryLongitude, 0); err != nil {
+			return err
+		}
+	}
+
+	if req.PaymentMethod == "" {
+		req.PaymentMethod = "cash"
+	}
+	if !portalPaymentMethods[req.PaymentMethod] {
+		return model.NewValidationError("payment_method", "Phương thức thanh toán không hợp lệ")
+	}
+	if req.PaymentMethod != "cash" && !s.paymentService.SupportsOnline(req.PaymentMethod) {
+		return model.NewValidationError("payment_method", "Phương thức thanh toán đang tạm ngưng")
+	}
+	methods, err := s.paymentRepo.ListPaymentMethods(ctx, true)
+	if err != nil {
+		return err
+	}
+	for _, method := range methods {
+		if method.Code == req.PaymentMethod {
+			return nil
+		}
+	}
+	return model.NewValidationError("payment_method", "Phương thức thanh toán đang tạm ngưng")
+}
+
+// normalizePhone keeps the digits of a phone number, with +84 written as a leading 0
+func normalizePhone(phone string) string {
+	return model.NormalizePhone(phone)
+}
+
+// isValidPhone reports whether a normalized phone is a Vietnamese phone number
+func isValidPhone(phone string) bool {
+	return len(phone) == 10 && strings.HasPrefix(phone, "0")
+}
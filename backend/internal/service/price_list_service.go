@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+// PriceListService manages scheduled prices such as happy hours and time-based menus
+type PriceListService struct {
+	priceListRepo *repository.PriceListRepository
+}
+
+func NewPriceListService(priceListRepo *repository.PriceListRepository) *PriceListService {
+	return &PriceListService{priceListRepo: priceListRepo}
+}
+
+// CreatePriceList creates a new price list
+func (s *PriceListService) CreatePriceList(ctx context.Context, req *model.CreatePriceListRequest, userID int64) (*model.PriceList, error) {
+	if err := s.normalizePriceList(req); err != nil {
+		return nil, err
+	}
+	return s.priceListRepo.CreatePriceList(ctx, req, userID)
+}
+
+// GetPriceList gets a price list by public ID
+func (s *PriceListService) GetPriceList(ctx context.Context, publicID string) (*model.PriceList, error) {
+	priceList, err := s.priceListRepo.GetPriceListByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if priceList == nil {
+		return nil, ErrNotFound
+	}
+	return priceList, nil
+}
+
+// ListPriceLists lists price lists, highest priority first
+func (s *PriceListService) ListPriceLists(ctx context.Context, isActive *bool) ([]*model.PriceList, error) {
+	return s.priceListRepo.ListPriceLists(ctx, isActive)
+}
+
+// UpdatePriceList updates a price list. Orders already placed keep the prices
+// they got.
+func (s *PriceListService) UpdatePriceList(ctx context.Context, publicID string, req *model.UpdatePriceListRequest) (*model.PriceList, error) {
+	if err := s.normalizePriceList(&req.CreatePriceListRequest); err != nil {
+		return nil, err
+	}
+	priceList, err := s.priceListRepo.UpdatePriceList(ctx, publicID, req)
+	if err != nil {
+		return nil, err
+	}
+	if priceList == nil {
+		return nil, ErrNotFound
+	}
+	return priceList, nil
+}
+
+// DeletePriceList deletes a price list
+func (s *PriceListService) DeletePriceList(ctx context.Context, publicID string) error {
+	priceList, err := s.GetPriceList(ctx, publicID)
+	if err != nil {
+		return err
+	}
+	return s.priceListRepo.DeletePriceList(ctx, priceList.ID)
+}
+
+// PreviewMenu prices every variant as it would be sold at the given time
+func (s *PriceListService) PreviewMenu(ctx context.Context, at time.Time) ([]*model.MenuPrice, error) {
+	return s.priceListRepo.MenuAt(ctx, at)
+}
+
+// normalizePriceList validates the schedules and items and fills the defaults
+func (s *PriceListService) normalizePriceList(req *model.CreatePriceListRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return model.NewValidationError("name", "Vui lòng nhập tên bảng giá")
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidFrom.Before(*req.ValidUntil) {
+		return model.NewValidationError("valid_until", "Ngày kết thúc phải sau ngày bắt đầu")
+	}
+
+	for i := range req.Schedules {
+		schedule := &req.Schedules[i]
+		if _, err := model.DaysOfWeekMask(schedule.DaysOfWeek); err != nil {
+			return model.NewValidationError("schedules", "Ngày trong tuần phải từ 0 (Chủ nhật) đến 6 (Thứ bảy)")
+		}
+		if schedule.StartTime == "" {
+			schedule.StartTime = "00:00"
+		}
+		if schedule.EndTime == "" {
+			schedule.EndTime = "00:00"
+		}
+		if _, err := model.ParseClock(schedule.StartTime); err != nil {
+			return model.NewValidationError("schedules", "Giờ bắt đầu phải có dạng HH:MM")
+		}
+		if _, err := model.ParseClock(schedule.EndTime); err != nil {
+			return model.NewValidationError("schedules", "Giờ kết thúc phải có dạng HH:MM")
+		}
+	}
+
+	for i := range req.Items {
+		item := &req.Items[i]
+		targets := 0
+		for _, target := range []**string{&item.VariantID, &item.ProductID, &item.CategoryID} {
+			if *target != nil && **target == "" {
+				*target = nil
+			}
+			if *target != nil {
+				targets++
+			}
+		}
+		if targets != 1 {
+			return model.NewValidationError("items", "Mỗi dòng bảng giá phải chọn đúng một variant, sản phẩm hoặc danh mục")
+		}
+		if item.Price != nil && item.DiscountPercent != nil {
+			return model.NewValidationError("items", "Chỉ nhập giá cố định hoặc phần trăm giảm, không nhập cả hai")
+		}
+		if item.Price == nil && item.DiscountPercent == nil && !req.IsExclusive {
+			return model.NewValidationError("items", "Vui lòng nhập giá hoặc phần trăm giảm")
+		}
+	}
+	return nil
+}
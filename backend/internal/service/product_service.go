@@ -9,16 +9,18 @@ import (
 type ProductService struct {
 	productRepo    *repository.ProductRepository
 	ingredientRepo *repository.IngredientRepository
+	categoryRepo   *repository.CategoryRepository
 }
 
-func NewProductService(productRepo *repository.ProductRepository, ingredientRepo *repository.IngredientRepository) *ProductService {
+func NewProductService(productRepo *repository.ProductRepository, ingredientRepo *repository.IngredientRepository, categoryRepo *repository.CategoryRepository) *ProductService {
 	return &ProductService{
 		productRepo:    productRepo,
 		ingredientRepo: ingredientRepo,
+		categoryRepo:   categoryRepo,
 	}
 }
 
-func (s *ProductService) CreateProduct(ctx context.Context, req *model.CreateProductRequest) (*model.Product, error) {
+func (s *ProductService) CreateProduct(ctx context.Context, req *model.CreateProductRequest, changedBy int64) (*model.Product, error) {
 	// Validate request
 	if req.Name == "" {
 		return nil, &ValidationError{Message: "Product name is required"}
@@ -49,8 +51,12 @@ func (s *ProductService) CreateProduct(ctx context.Context, req *model.CreatePro
 		}
 	}
 
+	if err := s.validateCategory(ctx, req.CategoryID); err != nil {
+		return nil, err
+	}
+
 	// Create product
-	product, err := s.productRepo.CreateProduct(ctx, req)
+	product, err := s.productRepo.CreateProduct(ctx, req, changedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +71,7 @@ func (s *ProductService) CreateProduct(ctx context.Context, req *model.CreatePro
 				if err != nil {
 					return nil, err
 				}
-				if ingredient == nil {
+				if ingredient == nil || ingredient.ArchivedAt != nil {
 					return nil, &ValidationError{Message: "Ingredient not found: " + ingredientReq.IngredientID}
 			}
 			
@@ -80,7 +86,7 @@ func (s *ProductService) CreateProduct(ctx context.Context, req *model.CreatePro
 	return product, nil
 }
 
-func (s *ProductService) UpdateProductByPublicID(ctx context.Context, publicID string, req *model.UpdateProductRequest) (*model.Product, error) {
+func (s *ProductService) UpdateProductByPublicID(ctx context.Context, publicID string, req *model.UpdateProductRequest, changedBy int64) (*model.Product, error) {
 	// Validate request
 	if req.Name == "" {
 		return nil, &ValidationError{Message: "Product name is required"}
@@ -111,8 +117,12 @@ func (s *ProductService) UpdateProductByPublicID(ctx context.Context, publicID s
 		}
 	}
 
+	if err := s.validateCategory(ctx, req.CategoryID); err != nil {
+		return nil, err
+	}
+
 	// Update product
-	product, err := s.productRepo.UpdateProductByPublicID(ctx, publicID, req)
+	product, err := s.productRepo.UpdateProductByPublicID(ctx, publicID, req, changedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -127,7 +137,7 @@ func (s *ProductService) UpdateProductByPublicID(ctx context.Context, publicID s
 				if err != nil {
 					return nil, err
 				}
-				if ingredient == nil {
+				if ingredient == nil || ingredient.ArchivedAt != nil {
 					return nil, &ValidationError{Message: "Ingredient not found: " + ingredientReq.IngredientID}
 				}
 				
@@ -142,7 +152,7 @@ func (s *ProductService) UpdateProductByPublicID(ctx context.Context, publicID s
 	return product, nil
 }
 
-func (s *ProductService) UpdateProduct(ctx context.Context, productID int64, req *model.UpdateProductRequest) (*model.Product, error) {
+func (s *ProductService) UpdateProduct(ctx context.Context, productID int64, req *model.UpdateProductRequest, changedBy int64) (*model.Product, error) {
 	// Validate request
 	if req.Name == "" {
 		return nil, &ValidationError{Message: "Product name is required"}
@@ -173,8 +183,12 @@ func (s *ProductService) UpdateProduct(ctx context.Context, productID int64, req
 		}
 	}
 
+	if err := s.validateCategory(ctx, req.CategoryID); err != nil {
+		return nil, err
+	}
+
 	// Update product
-	product, err := s.productRepo.UpdateProduct(ctx, productID, req)
+	product, err := s.productRepo.UpdateProduct(ctx, productID, req, changedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +203,7 @@ func (s *ProductService) UpdateProduct(ctx context.Context, productID int64, req
 				if err != nil {
 					return nil, err
 				}
-				if ingredient == nil {
+				if ingredient == nil || ingredient.ArchivedAt != nil {
 					return nil, &ValidationError{Message: "Ingredient not found: " + ingredientReq.IngredientID}
 				}
 				
@@ -220,6 +234,32 @@ func (s *ProductService) GetProductByID(ctx context.Context, id int64) (*model.P
 	return s.productRepo.GetProductByID(ctx, id)
 }
 
+// ArchiveProduct takes a product and its variants off the menu without
+// deleting anything past orders point at
+func (s *ProductService) ArchiveProduct(ctx context.Context, publicID string) (*model.Product, error) {
+	return s.productRepo.SetProductArchived(ctx, publicID, true)
+}
+
+// RestoreProduct puts an archived product back on the menu
+func (s *ProductService) RestoreProduct(ctx context.Context, publicID string) (*model.Product, error) {
+	return s.productRepo.SetProductArchived(ctx, publicID, false)
+}
+
+// validateCategory checks that the category a product is assigned to exists
+func (s *ProductService) validateCategory(ctx context.Context, categoryID *string) error {
+	if categoryID == nil || *categoryID == "" {
+		return nil
+	}
+	category, err := s.categoryRepo.GetCategoryByPublicID(ctx, *categoryID)
+	if err != nil {
+		return err
+	}
+	if category == nil {
+		return &ValidationError{Message: "Category not found: " + *categoryID}
+	}
+	return nil
+}
+
 // ValidationError represents a validation error
 type ValidationError struct {
 	Message string
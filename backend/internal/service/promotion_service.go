@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/repository"
+)
+
+// PromotionService manages the automatic promotions applied to orders
+type PromotionService struct {
+	promotionRepo *repository.PromotionRepository
+}
+
+func NewPromotionService(promotionRepo *repository.PromotionRepository) *PromotionService {
+	return &PromotionService{promotionRepo: promotionRepo}
+}
+
+// CreatePromotion creates a new promotion
+func (s *PromotionService) CreatePromotion(ctx context.Context, req *model.CreatePromotionRequest, userID int64) (*model.Promotion, error) {
+	if err := s.normalizePromotion(req); err != nil {
+		return nil, err
+	}
+	return s.promotionRepo.CreatePromotion(ctx, req, userID)
+}
+
+// GetPromotion gets a promotion by public ID
+func (s *PromotionService) GetPromotion(ctx context.Context, publicID string) (*model.Promotion, error) {
+	promotion, err := s.promotionRepo.GetPromotionByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if promotion == nil {
+		return nil, ErrNotFound
+	}
+	return promotion, nil
+}
+
+// ListPromotions lists promotions, highest priority first
+func (s *PromotionService) ListPromotions(ctx context.Context, isActive *bool) ([]*model.Promotion, error) {
+	return s.promotionRepo.ListPromotions(ctx, isActive)
+}
+
+// UpdatePromotion updates a promotion. Orders already placed keep the
+// promotions they got.
+func (s *PromotionService) UpdatePromotion(ctx context.Context, publicID string, req *model.UpdatePromotionRequest) (*model.Promotion, error) {
+	if err := s.normalizePromotion(&req.CreatePromotionRequest); err != nil {
+		return nil, err
+	}
+	promotion, err := s.promotionRepo.UpdatePromotion(ctx, publicID, req)
+	if err != nil {
+		return nil, err
+	}
+	if promotion == nil {
+		return nil, ErrNotFound
+	}
+	return promotion, nil
+}
+
+// DeletePromotion deletes a promotion that was never applied to an order
+func (s *PromotionService) DeletePromotion(ctx context.Context, publicID string) error {
+	promotion, err := s.GetPromotion(ctx, publicID)
+	if err != nil {
+		return err
+	}
+	used, err := s.promotionRepo.IsPromotionUsed(ctx, promotion.ID)
+	if err != nil {
+		return err
+	}
+	if used {
+		return model.NewValidationError("id", "Khuyến mãi đã được áp dụng cho đơn hàng, hãy tắt khuyến mãi thay vì xóa")
+	}
+	return s.promotionRepo.DeletePromotion(ctx, promotion.ID)
+}
+
+// normalizePromotion validates the promotion rules and fills the defaults
+func (s *PromotionService) normalizePromotion(req *model.CreatePromotionRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return model.NewValidationError("name", "Vui lòng nhập tên khuyến mãi")
+	}
+	switch req.PromotionType {
+	case model.PromotionTypeBuyXGetY, model.PromotionTypeStampCard, model.PromotionTypeFreeCheapest:
+	default:
+		return model.NewValidationError("promotion_type", "Loại khuyến mãi không hợp lệ")
+	}
+	if req.BuyQuantity <= 0 {
+		return model.NewValidationError("buy_quantity", "Số lượng mua phải lớn hơn 0")
+	}
+	if req.GetQuantity == 0 {
+		req.GetQuantity = 1
+	}
+	if req.GetQuantity < 0 {
+		return model.NewValidationError("get_quantity", "Số lượng tặng phải lớn hơn 0")
+	}
+	if req.PromotionType == model.PromotionTypeFreeCheapest && req.GetQuantity >= req.BuyQuantity {
+		return model.NewValidationError("get_quantity", "Số món tặng phải nhỏ hơn số món tối thiểu")
+	}
+	if req.DiscountPercent == 0 {
+		req.DiscountPercent = 100
+	}
+	if req.DiscountPercent < 0 || req.DiscountPercent > 100 {
+		return model.NewValidationError("discount_percent", "Phần trăm giảm phải từ 0 đến 100")
+	}
+	if req.ValidFrom != nil && req.ValidUntil != nil && !req.ValidFrom.Before(*req.ValidUntil) {
+		return model.NewValidationError("valid_until", "Ngày kết thúc phải sau ngày bắt đầu")
+	}
+	req.ProductIDs = uniqueStrings(req.ProductIDs)
+	req.VariantIDs = uniqueStrings(req.VariantIDs)
+	return nil
+}
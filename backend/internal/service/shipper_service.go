@@ -2,35 +2,47 @@ package service
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"food-pos-backend/internal/jwt"
 	"food-pos-backend/internal/model"
 	"food-pos-backend/internal/repository"
 
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type ShipperService struct {
 	shipperRepo *repository.ShipperRepository
+	otpService  *OTPService
+	jwtService  *jwt.JWTService
 }
 
-func NewShipperService(shipperRepo *repository.ShipperRepository) *ShipperService {
+func NewShipperService(shipperRepo *repository.ShipperRepository, otpService *OTPService, jwtService *jwt.JWTService) *ShipperService {
 	return &ShipperService{
 		shipperRepo: shipperRepo,
+		otpService:  otpService,
+		jwtService:  jwtService,
 	}
 }
 
 // CreateShipper creates a new shipper
 func (s *ShipperService) CreateShipper(ctx context.Context, req *model.CreateShipperRequest, createdBy int64) (*model.Shipper, error) {
 	shipper := &model.Shipper{
-		PublicID:  uuid.New(),
-		Name:      req.Name,
-		Phone:     req.Phone,
-		Email:     req.Email,
-		IsActive:  true,
-		CreatedBy: &createdBy,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		PublicID:                uuid.New(),
+		Name:                    req.Name,
+		Phone:                   req.Phone,
+		Email:                   req.Email,
+		IsActive:                true,
+		Availability:            model.ShipperOffShift,
+		MaxConcurrentDeliveries: 3,
+		CreatedBy:               &createdBy,
+		CreatedAt:               time.Now(),
+		UpdatedAt:               time.Now(),
 	}
 
 	err := s.shipperRepo.CreateShipper(ctx, shipper)
@@ -103,22 +115,216 @@ func (s *ShipperService) UpdateShipper(ctx context.Context, publicID string, req
 	return shipper, nil
 }
 
-// DeleteShipper deletes shipper
-func (s *ShipperService) DeleteShipper(ctx context.Context, publicID string) error {
-	uuid, err := uuid.Parse(publicID)
+// ArchiveShipper archives shipper instead of deleting it, past deliveries keep
+// pointing at the row. Shippers with unfinished deliveries cannot be archived.
+func (s *ShipperService) ArchiveShipper(ctx context.Context, publicID string) (*model.Shipper, error) {
+	shipper, err := s.GetShipper(ctx, publicID)
 	if err != nil {
-		return err
+		return nil, ErrNotFound
 	}
-	
-	shipper, err := s.shipperRepo.GetShipperByPublicID(ctx, uuid)
+
+	activeDeliveries, err := s.shipperRepo.CountActiveDeliveries(ctx, shipper.ID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if activeDeliveries > 0 {
+		return nil, model.NewValidationError("shipper", fmt.Sprintf("Shipper còn %d đơn đang giao, hãy chuyển cho shipper khác trước khi lưu trữ", activeDeliveries))
 	}
 
-	return s.shipperRepo.DeleteShipper(ctx, shipper.ID)
+	if err := s.shipperRepo.ArchiveShipper(ctx, shipper.ID); err != nil {
+		return nil, err
+	}
+	return s.GetShipper(ctx, publicID)
+}
+
+// RestoreShipper restores an archived shipper
+func (s *ShipperService) RestoreShipper(ctx context.Context, publicID string) (*model.Shipper, error) {
+	shipper, err := s.GetShipper(ctx, publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if err := s.shipperRepo.RestoreShipper(ctx, shipper.ID); err != nil {
+		return nil, err
+	}
+	return s.GetShipper(ctx, publicID)
 }
 
 // GetActiveShippers gets all active shippers
 func (s *ShipperService) GetActiveShippers(ctx context.Context) ([]model.Shipper, error) {
 	return s.shipperRepo.GetActiveShippers(ctx)
-} 
\ No newline at end of file
+}
+
+// SetAccount sets the login credentials a shipper uses in the shipper app
+func (s *ShipperService) SetAccount(ctx context.Context, publicID string, req *model.SetShipperAccountRequest) (*model.Shipper, error) {
+	shipper, err := s.GetShipper(ctx, publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	username := strings.TrimSpace(req.Username)
+	if username == "" {
+		return nil, model.NewValidationError("username", "Tên đăng nhập không được để trống")
+	}
+	if len(req.Password) < 6 {
+		return nil, model.NewValidationError("password", "Mật khẩu phải có ít nhất 6 ký tự")
+	}
+	taken, err := s.shipperRepo.IsUsernameTaken(ctx, username, shipper.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, model.NewValidationError("username", "Tên đăng nhập đã tồn tại")
+	}
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.shipperRepo.SetAccount(ctx, shipper, username, string(passwordHash)); err != nil {
+		return nil, err
+	}
+	return s.GetShipper(ctx, publicID)
+}
+
+// Login authenticates a shipper and returns a shipper-role token
+func (s *ShipperService) Login(ctx context.Context, req *model.ShipperLoginRequest) (*model.ShipperLoginResponse, error) {
+	account, err := s.shipperRepo.GetAccountByUsername(ctx, strings.TrimSpace(req.Username))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.New("invalid username or password")
+		}
+		return nil, err
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(account.PasswordHash), []byte(req.Password)); err != nil {
+		return nil, errors.New("invalid username or password")
+	}
+	if !account.IsActive || !account.UserActive {
+		return nil, errors.New("unauthorized")
+	}
+
+	token, err := s.jwtService.GenerateShipperToken(account.UserPublicID, account.Username, account.PublicID.String())
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+	return &model.ShipperLoginResponse{Token: token, Shipper: account.Shipper}, nil
+}
+
+// RequestLoginOTP sends a login code to the phone of an active shipper. Unknown
+// phones get the same response without a code, so the endpoint does not reveal
+// who the shippers are.
+func (s *ShipperService) RequestLoginOTP(ctx context.Context, req *model.OTPSendRequest) (*model.OTPSentResponse, error) {
+	phone := normalizePhone(req.Phone)
+	if !isValidPhone(phone) {
+		return nil, model.NewValidationError("phone", "Số điện thoại không hợp lệ")
+	}
+
+	shipper, err := s.shipperRepo.GetShipperByPhone(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+	if shipper == nil || !shipper.IsActive {
+		return s.otpService.sentResponse(), nil
+	}
+	return s.otpService.Send(ctx, model.OTPPurposeShipperLogin, phone)
+}
+
+// LoginWithOTP logs a shipper in with a code sent to their phone, creating the
+// login account on first use
+func (s *ShipperService) LoginWithOTP(ctx context.Context, req *model.OTPVerifyRequest) (*model.ShipperLoginResponse, error) {
+	phone := normalizePhone(req.Phone)
+	if !isValidPhone(phone) {
+		return nil, model.NewValidationError("phone", "Số điện thoại không hợp lệ")
+	}
+	if err := s.otpService.Verify(ctx, model.OTPPurposeShipperLogin, phone, strings.TrimSpace(req.Code)); err != nil {
+		return nil, err
+	}
+
+	shipper, err := s.shipperRepo.GetShipperByPhone(ctx, phone)
+	if err != nil {
+		return nil, err
+	}
+	if shipper == nil || !shipper.IsActive {
+		return nil, errors.New("unauthorized")
+	}
+
+	username := "shipper_" + phone
+	if shipper.UserID == nil {
+		taken, err := s.shipperRepo.IsUsernameTaken(ctx, username, nil)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, model.NewValidationError("phone", "Không thể tạo tài khoản đăng nhập, vui lòng liên hệ quản lý")
+		}
+	}
+	account, err := s.shipperRepo.EnsureAccount(ctx, shipper, username)
+	if err != nil {
+		return nil, err
+	}
+	if !account.UserActive {
+		return nil, errors.New("unauthorized")
+	}
+
+	token, err := s.jwtService.GenerateShipperToken(account.UserPublicID, account.Username, account.PublicID.String())
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+	return &model.ShipperLoginResponse{Token: token, Shipper: account.Shipper}, nil
+}
+
+// GetActiveShipper gets the shipper behind a shipper token, it must still be active
+func (s *ShipperService) GetActiveShipper(ctx context.Context, publicID string) (*model.Shipper, error) {
+	shipper, err := s.GetShipper(ctx, publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	if !shipper.IsActive {
+		return nil, model.NewValidationError("shipper", "Tài khoản shipper đã bị khóa")
+	}
+	return shipper, nil
+}
+
+// UpdateAvailability updates the shift, concurrent delivery limit and zone of a shipper
+func (s *ShipperService) UpdateAvailability(ctx context.Context, publicID string, req *model.UpdateShipperAvailabilityRequest) (*model.Shipper, error) {
+	shipper, err := s.GetShipper(ctx, publicID)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+
+	if req.Availability != nil {
+		if *req.Availability != model.ShipperOnShift && *req.Availability != model.ShipperOffShift {
+			return nil, model.NewValidationError("availability", "Trạng thái ca làm việc không hợp lệ")
+		}
+		shipper.Availability = *req.Availability
+	}
+	if req.MaxConcurrentDeliveries != nil {
+		if *req.MaxConcurrentDeliveries < 1 {
+			return nil, model.NewValidationError("max_concurrent_deliveries", "Số đơn giao tối đa phải lớn hơn 0")
+		}
+		shipper.MaxConcurrentDeliveries = *req.MaxConcurrentDeliveries
+	}
+	if req.Zone != nil {
+		zone := strings.TrimSpace(*req.Zone)
+		shipper.Zone = &zone
+		if zone == "" {
+			shipper.Zone = nil
+		}
+	}
+
+	if err := s.shipperRepo.UpdateAvailability(ctx, shipper); err != nil {
+		return nil, err
+	}
+	return shipper, nil
+}
+
+// SetShift lets a shipper go on or off shift from the shipper app
+func (s *ShipperService) SetShift(ctx context.Context, shipper *model.Shipper, availability string) (*model.Shipper, error) {
+	if availability != model.ShipperOnShift && availability != model.ShipperOffShift {
+		return nil, model.NewValidationError("availability", "Trạng thái ca làm việc không hợp lệ")
+	}
+	shipper.Availability = availability
+	if err := s.shipperRepo.UpdateAvailability(ctx, shipper); err != nil {
+		return nil, err
+	}
+	return shipper, nil
+}
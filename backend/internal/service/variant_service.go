@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"math"
 	"strconv"
 	"time"
 
@@ -10,16 +11,18 @@ import (
 )
 
 type VariantService struct {
-	repo *repository.VariantRepository
+	repo        *repository.VariantRepository
+	productRepo *repository.ProductRepository
 }
 
-func NewVariantService(repo *repository.VariantRepository) *VariantService {
+func NewVariantService(repo *repository.VariantRepository, productRepo *repository.ProductRepository) *VariantService {
 	return &VariantService{
-		repo: repo,
+		repo:        repo,
+		productRepo: productRepo,
 	}
 }
 
-func (s *VariantService) CreateVariant(ctx context.Context, productID, name string, price float64) (*model.Variant, error) {
+func (s *VariantService) CreateVariant(ctx context.Context, productID, name string, price float64, changedBy int64) (*model.Variant, error) {
 	// Convert productID from string to int64
 	productIDInt, err := strconv.ParseInt(productID, 10, 64)
 	if err != nil {
@@ -28,14 +31,15 @@ func (s *VariantService) CreateVariant(ctx context.Context, productID, name stri
 
 	now := time.Now()
 	variant := &model.Variant{
-		ProductID: productIDInt,
-		Name:      name,
-		Price:     price,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ProductID:   productIDInt,
+		Name:        name,
+		Price:       price,
+		IsAvailable: true,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
 	
-	err = s.repo.CreateVariant(ctx, variant)
+	err = s.repo.CreateVariant(ctx, variant, changedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -43,12 +47,149 @@ func (s *VariantService) CreateVariant(ctx context.Context, productID, name stri
 	return variant, nil
 }
 
-func (s *VariantService) ListVariantsByProduct(ctx context.Context, productID string) ([]*model.Variant, error) {
+func (s *VariantService) ListVariantsByProduct(ctx context.Context, productID string, includeArchived bool) ([]*model.Variant, error) {
 	// Convert productID from string to int64
 	productIDInt, err := strconv.ParseInt(productID, 10, 64)
 	if err != nil {
 		return nil, err
 	}
 	
-	return s.repo.ListVariantsByProduct(ctx, productIDInt)
-} 
\ No newline at end of file
+	return s.repo.ListVariantsByProduct(ctx, productIDInt, includeArchived)
+}
+
+// ArchiveVariant takes a variant off the menu. The row is kept because order
+// lines point at it. A product keeps at least one variant, archive the product
+// to take it off the menu entirely.
+func (s *VariantService) ArchiveVariant(ctx context.Context, publicID string) (*model.Variant, error) {
+	variant, err := s.repo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, ErrNotFound
+	}
+	if variant.ArchivedAt != nil {
+		return variant, nil
+	}
+	live, err := s.repo.CountLiveVariants(ctx, variant.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if live <= 1 {
+		return nil, model.NewValidationError("id", "Sản phẩm phải còn ít nhất một variant, hãy lưu trữ cả sản phẩm")
+	}
+	if err = s.repo.Archive(ctx, variant.ID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, variant.ID)
+}
+
+// RestoreVariant puts an archived variant back on the menu
+func (s *VariantService) RestoreVariant(ctx context.Context, publicID string) (*model.Variant, error) {
+	variant, err := s.repo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, ErrNotFound
+	}
+	product, err := s.productRepo.GetProductByID(ctx, variant.ProductID)
+	if err != nil {
+		return nil, err
+	}
+	if product.ArchivedAt != nil {
+		return nil, model.NewValidationError("id", "Sản phẩm đang được lưu trữ, hãy khôi phục sản phẩm trước")
+	}
+	if err = s.repo.Restore(ctx, variant.ID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, variant.ID)
+}
+
+// GetPriceHistory lists the regular price changes of a variant, newest first
+func (s *VariantService) GetPriceHistory(ctx context.Context, publicID string) ([]*model.VariantPriceChange, error) {
+	variant, err := s.repo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, ErrNotFound
+	}
+	return s.repo.ListPriceHistory(ctx, variant.ID)
+}
+
+// GetPriceAt gets the regular price a variant had at the given time
+func (s *VariantService) GetPriceAt(ctx context.Context, publicID string, at time.Time) (*model.VariantPriceChange, error) {
+	variant, err := s.repo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, ErrNotFound
+	}
+	change, err := s.repo.GetPriceAt(ctx, variant.ID, at)
+	if err != nil {
+		return nil, err
+	}
+	if change == nil {
+		return nil, model.NewValidationError("at", "Chưa có giá của variant tại thời điểm này")
+	}
+	return change, nil
+}
+
+// GetPriceChangeImpact compares the sales of a variant in the given number of
+// days before and after each of its price changes
+func (s *VariantService) GetPriceChangeImpact(ctx context.Context, publicID string, days int) ([]*model.PriceChangeImpact, error) {
+	if days < 1 || days > 365 {
+		return nil, model.NewValidationError("days", "Số ngày so sánh phải từ 1 đến 365")
+	}
+	variant, err := s.repo.GetByPublicID(ctx, publicID)
+	if err != nil {
+		return nil, err
+	}
+	if variant == nil {
+		return nil, ErrNotFound
+	}
+
+	impacts, err := s.repo.ListPriceChangeImpact(ctx, variant.ID, days)
+	if err != nil {
+		return nil, err
+	}
+	for _, impact := range impacts {
+		if impact.OldPrice != 0 {
+			impact.PriceChangePercent = roundPercent((impact.NewPrice - impact.OldPrice) / impact.OldPrice * 100)
+		}
+		fillPeriodRates(&impact.Before)
+		fillPeriodRates(&impact.After)
+		impact.QuantityChangePercent = changePercent(impact.Before.QuantityPerDay, impact.After.QuantityPerDay)
+		impact.RevenueChangePercent = changePercent(impact.Before.RevenuePerDay, impact.After.RevenuePerDay)
+	}
+	return impacts, nil
+}
+
+// fillPeriodRates computes the per day figures of a sales period, the periods
+// around a change can be shorter than asked when prices changed close together
+func fillPeriodRates(period *model.PriceChangePeriod) {
+	period.Days = period.Until.Sub(period.From).Hours() / 24
+	if period.Quantity > 0 {
+		period.AverageUnitPrice = period.Revenue / float64(period.Quantity)
+	}
+	if period.Days > 0 {
+		period.QuantityPerDay = float64(period.Quantity) / period.Days
+		period.RevenuePerDay = period.Revenue / period.Days
+	}
+}
+
+// changePercent is the change from before to after in percent, nil when
+// there is nothing to compare against
+func changePercent(before, after float64) *float64 {
+	if before == 0 {
+		return nil
+	}
+	percent := roundPercent((after - before) / before * 100)
+	return &percent
+}
+
+func roundPercent(percent float64) float64 {
+	return math.Round(percent*100) / 100
+}
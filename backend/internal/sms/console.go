@@ -0,0 +1,50 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ConsoleSender writes messages to the server log instead of sending them, for local use
+type ConsoleSender struct{}
+
+func NewConsoleSender() *ConsoleSender {
+	return &ConsoleSender{}
+}
+
+func (s *ConsoleSender) Send(ctx context.Context, phone, message string) error {
+	log.Printf("[SMS] to %s: %s", phone, message)
+	return nil
+}
+
+// FileSender appends messages to a file instead of sending them, for local use and tests
+type FileSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{path: path}
+}
+
+func (s *FileSender) Send(ctx context.Context, phone, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = fmt.Fprintf(file, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), phone, message)
+	return err
+}
@@ -0,0 +1,8 @@
+package sms
+
+import "context"
+
+// SMSSender delivers text messages to phone numbers (OTP codes, ...)
+type SMSSender interface {
+	Send(ctx context.Context, phone, message string) error
+}
@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrInvalidKey is returned when a key escapes the storage root
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage persists uploaded files (delivery proof photos, signatures, ...).
+// Files are not publicly reachable, handlers stream them after checking access.
+type Storage interface {
+	Save(ctx context.Context, key string, r io.Reader) error
+	// Open returns the content stored under key, the caller closes it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -8,8 +8,9 @@ type Client struct {
 	Hub    *Hub
 	Conn   *websocket.Conn
 	Send   chan []byte
-	UserID string // Có thể mở rộng cho phân quyền
-	Role   string // Phân loại client: admin, kitchen, ...
+	UserID string   // Public ID của user, lấy từ JWT claims
+	Role   string   // Role trong JWT claims: super_admin, admin, ...
+	Groups []string // Nhóm nhận broadcast, suy ra từ Role
 }
 
 func (c *Client) ReadPump() {
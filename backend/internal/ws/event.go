@@ -6,6 +6,9 @@ const (
 	EventOrderUpdate    EventType = "order_update"
 	EventDeliveryUpdate EventType = "delivery_update"
 	EventNotification   EventType = "notification"
+	EventKitchenUpdate  EventType = "kitchen_update"
+	// Món/variant hết hàng hoặc bán lại, gửi cho mọi kết nối kể cả menu công khai
+	EventAvailabilityUpdate EventType = "availability_update"
 	// Có thể mở rộng thêm các event khác sau này
 )
 
@@ -6,7 +6,8 @@ import (
 
 type Hub struct {
 	clients    map[*Client]bool
-	groups     map[string]map[*Client]bool // group name (role) -> clients
+	groups     map[string]map[*Client]bool // group name -> clients
+	users      map[string]map[*Client]bool // user public ID -> clients (one per tab/device)
 	broadcast  chan []byte
 	register   chan *Client
 	unregister chan *Client
@@ -17,6 +18,7 @@ func NewHub() *Hub {
 	return &Hub{
 		clients:    make(map[*Client]bool),
 		groups:     make(map[string]map[*Client]bool),
+		users:      make(map[string]map[*Client]bool),
 		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
@@ -29,35 +31,27 @@ func (h *Hub) Run() {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
-			if client.Role != "" {
-				if h.groups[client.Role] == nil {
-					h.groups[client.Role] = make(map[*Client]bool)
+			for _, group := range client.Groups {
+				if h.groups[group] == nil {
+					h.groups[group] = make(map[*Client]bool)
 				}
-				h.groups[client.Role][client] = true
+				h.groups[group][client] = true
+			}
+			if client.UserID != "" {
+				if h.users[client.UserID] == nil {
+					h.users[client.UserID] = make(map[*Client]bool)
+				}
+				h.users[client.UserID][client] = true
 			}
 			h.mu.Unlock()
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				close(client.Send)
-				if client.Role != "" && h.groups[client.Role] != nil {
-					delete(h.groups[client.Role], client)
-				}
-			}
+			h.removeClient(client)
 			h.mu.Unlock()
 		case message := <-h.broadcast:
 			h.mu.Lock()
 			for client := range h.clients {
-				select {
-				case client.Send <- message:
-				default:
-					close(client.Send)
-					delete(h.clients, client)
-					if client.Role != "" && h.groups[client.Role] != nil {
-						delete(h.groups[client.Role], client)
-					}
-				}
+				h.send(client, message)
 			}
 			h.mu.Unlock()
 		}
@@ -72,13 +66,16 @@ func (h *Hub) BroadcastToGroup(group string, message []byte) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 	for client := range h.groups[group] {
-		select {
-		case client.Send <- message:
-		default:
-			close(client.Send)
-			delete(h.clients, client)
-			delete(h.groups[group], client)
-		}
+		h.send(client, message)
+	}
+}
+
+// SendToUser gửi message tới mọi kết nối của một user
+func (h *Hub) SendToUser(userID string, message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.users[userID] {
+		h.send(client, message)
 	}
 }
 
@@ -89,4 +86,33 @@ func (h *Hub) Register(client *Client) {
 
 func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
-} 
\ No newline at end of file
+}
+
+// send đẩy message vào buffer của client, ngắt client nếu buffer đầy (phải giữ mu)
+func (h *Hub) send(client *Client, message []byte) {
+	select {
+	case client.Send <- message:
+	default:
+		h.removeClient(client)
+	}
+}
+
+// removeClient gỡ client khỏi mọi group và đóng Send (phải giữ mu)
+func (h *Hub) removeClient(client *Client) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+	delete(h.clients, client)
+	close(client.Send)
+	for _, group := range client.Groups {
+		if h.groups[group] != nil {
+			delete(h.groups[group], client)
+		}
+	}
+	if h.users[client.UserID] != nil {
+		delete(h.users[client.UserID], client)
+		if len(h.users[client.UserID]) == 0 {
+			delete(h.users, client.UserID)
+		}
+	}
+}
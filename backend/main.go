@@ -2,14 +2,21 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"food-pos-backend/config"
+	"food-pos-backend/internal/assignment"
+	"food-pos-backend/internal/geo"
 	"food-pos-backend/internal/handler"
 	"food-pos-backend/internal/jwt"
 	"food-pos-backend/internal/middleware"
+	"food-pos-backend/internal/model"
+	"food-pos-backend/internal/payment"
 	"food-pos-backend/internal/repository"
 	"food-pos-backend/internal/routes"
 	"food-pos-backend/internal/service"
+	"food-pos-backend/internal/sms"
+	"food-pos-backend/internal/storage"
 	"food-pos-backend/internal/ws"
 
 	"github.com/gin-gonic/gin"
@@ -32,6 +39,10 @@ func main() {
 	defer db.Close()
 
 	r := gin.New()
+	// Rate limits key on the client IP, so only configured proxies may override it with X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.Portal.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 	r.Use(middleware.Logger())
 	r.Use(middleware.CORSMiddleware())
 	r.Use(gin.Recovery())
@@ -39,43 +50,190 @@ func main() {
 	// Initialize JWT service
 	jwtService := jwt.NewJWTService(cfg.JWT.SecretKey)
 
+	// Menus and price list schedules follow the store's clock
+	storeLocation := loadStoreLocation(cfg.Store.Timezone)
+
 	// Initialize repositories
 	ingredientRepo := repository.NewIngredientRepository(db)
 	variantRepo := repository.NewVariantRepository(db)
 	productRepo := repository.NewProductRepository(db)
-	orderRepo := repository.NewOrderRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	availabilityRepo := repository.NewAvailabilityRepository(db)
+	priceListRepo := repository.NewPriceListRepository(db, storeLocation)
+	orderRepo := repository.NewOrderRepository(db, storeLocation)
 	shipperRepo := repository.NewShipperRepository(db)
 	deliveryRepo := repository.NewDeliveryRepository(db)
+	discountRepo := repository.NewDiscountRepository(db)
+	stockRepo := repository.NewStockRepository(db)
+	modifierRepo := repository.NewModifierRepository(db)
+	kitchenRepo := repository.NewKitchenRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	cashSettlementRepo := repository.NewCashSettlementRepository(db)
+	deliveryZoneRepo := repository.NewDeliveryZoneRepository(db)
+	customerAddressRepo := repository.NewCustomerAddressRepository(db)
+	portalRepo := repository.NewPortalRepository(db, storeLocation)
+	customerAccountRepo := repository.NewCustomerAccountRepository(db)
+	otpRepo := repository.NewOTPRepository(db)
+	customerMergeRepo := repository.NewCustomerMergeRepository(db)
+	loyaltyRepo := repository.NewLoyaltyRepository(db)
+	promotionRepo := repository.NewPromotionRepository(db)
 	userRepo := repository.NewUserRepository()
 
 	// Initialize WebSocket Hub (singleton)
 	hub := ws.NewHub()
 	go hub.Run()
 
+	// Initialize file storage
+	fileStorage := newFileStorage(cfg.Storage)
+
 	// Initialize services
 	ingredientService := service.NewIngredientService(ingredientRepo, variantRepo)
-	productService := service.NewProductService(productRepo, ingredientRepo)
-	variantService := service.NewVariantService(variantRepo)
-	orderService := service.NewOrderService(orderRepo, userRepo, hub)
-	shipperService := service.NewShipperService(shipperRepo)
-	deliveryService := service.NewDeliveryService(deliveryRepo, orderRepo, hub)
+	productService := service.NewProductService(productRepo, ingredientRepo, categoryRepo)
+	variantService := service.NewVariantService(variantRepo, productRepo)
+	categoryService := service.NewCategoryService(categoryRepo)
+	availabilityService := service.NewAvailabilityService(availabilityRepo, hub)
+	otpService := service.NewOTPService(otpRepo, newSMSSender(cfg.SMS, cfg.Env), model.OTPPolicy{
+		Length:          cfg.OTP.Length,
+		TTL:             time.Duration(cfg.OTP.TTLSeconds) * time.Second,
+		MaxAttempts:     cfg.OTP.MaxAttempts,
+		ResendInterval:  time.Duration(cfg.OTP.ResendSeconds) * time.Second,
+		MaxSendsPerHour: cfg.OTP.MaxSendsPerHour,
+	})
+	shipperService := service.NewShipperService(shipperRepo, otpService, jwtService)
+	proofPolicy, err := model.NewDeliveryProofPolicy(cfg.Delivery.ProofRequired)
+	if err != nil {
+		log.Fatal("Invalid DELIVERY_PROOF_REQUIRED: ", err)
+	}
+	deliveryService := service.NewDeliveryService(deliveryRepo, orderRepo, hub, fileStorage, proofPolicy)
+	assignmentService := service.NewAssignmentService(shipperRepo, orderRepo, deliveryRepo, paymentRepo, deliveryService, newAssignmentStrategy(cfg.Assignment), cfg.Assignment.Mode, hub)
+	deliveryZoneService := service.NewDeliveryZoneService(deliveryZoneRepo, geo.Point{Lat: cfg.Store.Latitude, Lng: cfg.Store.Longitude}, geo.Haversine)
+	orderService := service.NewOrderService(orderRepo, kitchenRepo, userRepo, customerAddressRepo, customerAccountRepo, assignmentService, deliveryZoneService, hub)
+	discountService := service.NewDiscountService(discountRepo)
+	inventoryService := service.NewInventoryService(stockRepo, ingredientRepo)
+	modifierService := service.NewModifierService(modifierRepo, productRepo)
+	kitchenService := service.NewKitchenService(kitchenRepo, orderService, hub)
+	cashSettlementService := service.NewCashSettlementService(cashSettlementRepo, shipperRepo, hub)
+	customerAddressService := service.NewCustomerAddressService(customerAddressRepo)
+	paymentService := service.NewPaymentService(paymentRepo, newPaymentProviders(cfg.Payment, cfg.Env), cfg.Payment.PublicURL)
+	portalService := service.NewPortalService(portalRepo, orderRepo, paymentRepo, orderService, deliveryZoneService, paymentService)
+	customerMergeService := service.NewCustomerMergeService(customerMergeRepo)
+	customerAuthService := service.NewCustomerAuthService(customerAccountRepo, otpService, jwtService)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo)
+	promotionService := service.NewPromotionService(promotionRepo)
+	priceListService := service.NewPriceListService(priceListRepo)
 
 	// Initialize handlers
 	adminHandler := handler.NewAdminHandler(jwtService)
-	productHandler := handler.NewProductHandler(productService)
-	variantHandler := handler.NewVariantHandler(variantService)
+	productHandler := handler.NewProductHandler(productService, userRepo)
+	variantHandler := handler.NewVariantHandler(variantService, userRepo)
+	categoryHandler := handler.NewCategoryHandler(categoryService)
+	availabilityHandler := handler.NewAvailabilityHandler(availabilityService)
 	ingredientHandler := handler.NewIngredientHandler(ingredientService)
 	orderHandler := handler.NewOrderHandler(orderService, orderRepo, userRepo, jwtService)
 	shipperHandler := handler.NewShipperHandler(shipperService, userRepo)
 	deliveryHandler := handler.NewDeliveryHandler(deliveryService, deliveryRepo, userRepo, jwtService)
 	adminUserHandler := handler.NewAdminUserHandler()
-	wsHandler := handler.NewWebSocketHandler(hub)
+	discountHandler := handler.NewDiscountHandler(discountService, userRepo)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService, userRepo)
+	modifierHandler := handler.NewModifierHandler(modifierService)
+	kitchenHandler := handler.NewKitchenHandler(kitchenService, userRepo)
+	paymentHandler := handler.NewPaymentHandler(paymentService, userRepo, cfg.Payment.ReturnURL)
+	cashSettlementHandler := handler.NewCashSettlementHandler(cashSettlementService, userRepo)
+	assignmentHandler := handler.NewAssignmentHandler(assignmentService, shipperService, userRepo)
+	deliveryZoneHandler := handler.NewDeliveryZoneHandler(deliveryZoneService, orderService, userRepo)
+	customerAddressHandler := handler.NewCustomerAddressHandler(customerAddressService)
+	portalHandler := handler.NewPortalHandler(portalService)
+	customerMergeHandler := handler.NewCustomerMergeHandler(customerMergeService, userRepo)
+	customerAuthHandler := handler.NewCustomerAuthHandler(customerAuthService)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyService, userRepo)
+	promotionHandler := handler.NewPromotionHandler(promotionService, orderService, userRepo)
+	priceListHandler := handler.NewPriceListHandler(priceListService, userRepo)
+	shipperAppHandler := handler.NewShipperAppHandler(shipperService, deliveryService, cashSettlementService, userRepo)
+	wsHandler := handler.NewWebSocketHandler(hub, jwtService, cfg.WebSocket.AllowedOrigins)
+
+	// Recalculate loyalty tiers periodically
+	if cfg.Loyalty.TierRecalcHours > 0 {
+		go loyaltyService.RunTierRecalculation(time.Duration(cfg.Loyalty.TierRecalcHours) * time.Hour)
+	}
+
+	// Put sold out items back on sale at their back-at time and broadcast stock driven sell outs
+	if cfg.Availability.CheckSeconds > 0 {
+		go availabilityService.RunAvailabilityChecks(time.Duration(cfg.Availability.CheckSeconds) * time.Second)
+	}
 
 	// Setup all routes
-	routes.SetupRoutes(r, jwtService, adminHandler, productHandler, variantHandler, ingredientHandler, orderHandler, shipperHandler, deliveryHandler, adminUserHandler, wsHandler)
+	routes.SetupRoutes(r, jwtService, adminHandler, productHandler, variantHandler, categoryHandler, availabilityHandler, ingredientHandler, orderHandler, shipperHandler, deliveryHandler, adminUserHandler, discountHandler, inventoryHandler, modifierHandler, kitchenHandler, paymentHandler, cashSettlementHandler, assignmentHandler, deliveryZoneHandler, customerAddressHandler, customerMergeHandler, loyaltyHandler, promotionHandler, priceListHandler, portalHandler, customerAuthHandler, shipperAppHandler, wsHandler, cfg.Portal, cfg.Payment.MockEnabled(cfg.Env))
 
 	log.Printf("Server started at :%s", cfg.Port)
 	if err := r.Run(":" + cfg.Port); err != nil {
 		log.Fatal(err)
 	}
 }
+
+// newPaymentProviders registers the payment gateways for online payment methods
+func newPaymentProviders(cfg config.PaymentConfig, env string) *payment.Registry {
+	switch cfg.Provider {
+	case "":
+		log.Printf("PAYMENT_PROVIDER is not set, online payments are disabled")
+		return payment.NewRegistry()
+	case "mock":
+		if !cfg.MockEnabled(env) {
+			log.Fatalf("PAYMENT_PROVIDER=mock is only allowed in development (ENV=%s), set PAYMENT_ALLOW_MOCK=true to opt in", env)
+		}
+		if env != "development" && cfg.MockSecret == config.DefaultPaymentMockSecret {
+			log.Fatal("PAYMENT_MOCK_SECRET must be changed outside development")
+		}
+		return payment.NewRegistry(
+			payment.NewMockProvider("momo", cfg.MockSecret, cfg.PublicURL),
+			payment.NewMockProvider("vnpay", cfg.MockSecret, cfg.PublicURL),
+		)
+	default:
+		log.Printf("Unknown payment provider %q, online payments are disabled", cfg.Provider)
+		return payment.NewRegistry()
+	}
+}
+
+// loadStoreLocation loads the store timezone, falling back to the server's local time
+func loadStoreLocation(name string) *time.Location {
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("Unknown store timezone %q, falling back to local time: %v", name, err)
+		return time.Local
+	}
+	return location
+}
+
+// newFileStorage creates the storage for uploaded files
+func newFileStorage(cfg config.StorageConfig) *storage.LocalStorage {
+	if cfg.Driver != "local" {
+		log.Printf("Unknown storage driver %q, falling back to local disk", cfg.Driver)
+	}
+	return storage.NewLocalStorage(cfg.LocalDir)
+}
+
+// newSMSSender creates the sender for OTP text messages
+func newSMSSender(cfg config.SMSConfig, env string) sms.SMSSender {
+	switch cfg.Driver {
+	case "console", "file":
+		if !cfg.LocalEnabled(env) {
+			log.Fatalf("SMS_DRIVER=%s exposes OTP codes and is only allowed in development (ENV=%s), set SMS_ALLOW_LOCAL=true to opt in", cfg.Driver, env)
+		}
+		if cfg.Driver == "file" {
+			return sms.NewFileSender(cfg.FilePath)
+		}
+		return sms.NewConsoleSender()
+	default:
+		log.Fatalf("Unknown SMS driver %q", cfg.Driver)
+		return nil
+	}
+}
+
+// newAssignmentStrategy creates the strategy used to pick shippers for ready orders
+func newAssignmentStrategy(cfg config.AssignmentConfig) assignment.Strategy {
+	strategy, err := assignment.NewStrategy(cfg.Strategy)
+	if err != nil {
+		log.Printf("%v, falling back to %s", err, assignment.StrategyLeastActive)
+		return assignment.LeastActiveStrategy{}
+	}
+	return strategy
+}
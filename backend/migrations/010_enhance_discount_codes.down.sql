@@ -0,0 +1,17 @@
+-- 010_enhance_discount_codes.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_discount_code_usages_order_id;
+DROP INDEX IF EXISTS idx_discount_code_usages_customer_phone;
+DROP INDEX IF EXISTS idx_discount_code_usages_discount_code_id;
+DROP INDEX IF EXISTS idx_discount_code_variants_discount_code_id;
+DROP INDEX IF EXISTS idx_discount_code_products_discount_code_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS discount_code_usages;
+DROP TABLE IF EXISTS discount_code_variants;
+DROP TABLE IF EXISTS discount_code_products;
+
+-- Remove columns from discount_codes table
+ALTER TABLE discount_codes DROP COLUMN IF EXISTS is_stackable;
+ALTER TABLE discount_codes DROP COLUMN IF EXISTS per_customer_limit;
@@ -0,0 +1,40 @@
+-- 010_enhance_discount_codes.up.sql
+
+-- Per-customer limit and stacking rule on discount codes
+ALTER TABLE discount_codes ADD COLUMN IF NOT EXISTS per_customer_limit INTEGER; -- NULL = unlimited
+ALTER TABLE discount_codes ADD COLUMN IF NOT EXISTS is_stackable BOOLEAN NOT NULL DEFAULT false; -- Có thể dùng chung với giảm giá thủ công
+
+-- Restrict a discount code to specific products
+CREATE TABLE IF NOT EXISTS discount_code_products (
+    id BIGSERIAL PRIMARY KEY,
+    discount_code_id BIGINT NOT NULL REFERENCES discount_codes(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE (discount_code_id, product_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Restrict a discount code to specific variants
+CREATE TABLE IF NOT EXISTS discount_code_variants (
+    id BIGSERIAL PRIMARY KEY,
+    discount_code_id BIGINT NOT NULL REFERENCES discount_codes(id) ON DELETE CASCADE,
+    variant_id BIGINT NOT NULL REFERENCES variants(id) ON DELETE CASCADE,
+    UNIQUE (discount_code_id, variant_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Track every redemption so per-customer limits can be enforced
+CREATE TABLE IF NOT EXISTS discount_code_usages (
+    id BIGSERIAL PRIMARY KEY,
+    discount_code_id BIGINT NOT NULL REFERENCES discount_codes(id) ON DELETE CASCADE,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    customer_phone VARCHAR(20),
+    discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_discount_code_products_discount_code_id ON discount_code_products(discount_code_id);
+CREATE INDEX IF NOT EXISTS idx_discount_code_variants_discount_code_id ON discount_code_variants(discount_code_id);
+CREATE INDEX IF NOT EXISTS idx_discount_code_usages_discount_code_id ON discount_code_usages(discount_code_id);
+CREATE INDEX IF NOT EXISTS idx_discount_code_usages_customer_phone ON discount_code_usages(customer_phone);
+CREATE INDEX IF NOT EXISTS idx_discount_code_usages_order_id ON discount_code_usages(order_id);
@@ -0,0 +1,16 @@
+-- 011_create_ingredient_stock.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_ingredient_stock_movements_created_at;
+DROP INDEX IF EXISTS idx_ingredient_stock_movements_order_id;
+DROP INDEX IF EXISTS idx_ingredient_stock_movements_ingredient_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS ingredient_stock_movements;
+
+-- Remove columns from ingredients table
+ALTER TABLE ingredients DROP COLUMN IF EXISTS low_stock_threshold;
+ALTER TABLE ingredients DROP COLUMN IF EXISTS stock_quantity;
+
+-- Drop enum types
+DROP TYPE IF EXISTS stock_movement_type;
@@ -0,0 +1,32 @@
+-- 011_create_ingredient_stock.up.sql
+
+-- Create stock_movement_type enum
+CREATE TYPE stock_movement_type AS ENUM (
+    'receipt',      -- Nhập kho
+    'consumption',  -- Xuất kho theo đơn hàng
+    'adjustment',   -- Điều chỉnh kiểm kê
+    'reversal'      -- Hoàn kho khi hủy đơn
+);
+
+-- On-hand quantity is cached on the ingredient and kept in sync with the ledger
+ALTER TABLE ingredients ADD COLUMN IF NOT EXISTS stock_quantity DECIMAL(12,3) NOT NULL DEFAULT 0;
+ALTER TABLE ingredients ADD COLUMN IF NOT EXISTS low_stock_threshold DECIMAL(12,3) NOT NULL DEFAULT 0;
+
+-- Create ingredient stock ledger
+CREATE TABLE IF NOT EXISTS ingredient_stock_movements (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    ingredient_id BIGINT NOT NULL REFERENCES ingredients(id) ON DELETE CASCADE,
+    movement_type stock_movement_type NOT NULL,
+    quantity DECIMAL(12,3) NOT NULL, -- Dương: nhập, âm: xuất
+    balance_after DECIMAL(12,3) NOT NULL,
+    unit_cost DECIMAL(10,2), -- Giá nhập (chỉ cho receipt)
+    order_id BIGINT REFERENCES orders(id) ON DELETE SET NULL,
+    notes TEXT,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_ingredient_stock_movements_ingredient_id ON ingredient_stock_movements(ingredient_id);
+CREATE INDEX IF NOT EXISTS idx_ingredient_stock_movements_order_id ON ingredient_stock_movements(order_id);
+CREATE INDEX IF NOT EXISTS idx_ingredient_stock_movements_created_at ON ingredient_stock_movements(created_at);
@@ -0,0 +1,13 @@
+-- 012_create_modifier_groups.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_order_item_modifiers_order_item_id;
+DROP INDEX IF EXISTS idx_product_modifier_groups_group_id;
+DROP INDEX IF EXISTS idx_product_modifier_groups_product_id;
+DROP INDEX IF EXISTS idx_modifier_options_group_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS order_item_modifiers;
+DROP TABLE IF EXISTS product_modifier_groups;
+DROP TABLE IF EXISTS modifier_options;
+DROP TABLE IF EXISTS modifier_groups;
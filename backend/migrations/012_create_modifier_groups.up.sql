@@ -0,0 +1,60 @@
+-- 012_create_modifier_groups.up.sql
+
+-- Create modifier groups table (Mức đường, Mức đá, Topping, ...)
+CREATE TABLE IF NOT EXISTS modifier_groups (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    min_select INTEGER NOT NULL DEFAULT 0 CHECK (min_select >= 0),
+    max_select INTEGER NOT NULL DEFAULT 1 CHECK (max_select >= 1),
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (min_select <= max_select)
+);
+
+-- Create modifier options table (50% đường, Ít đá, Trân châu, ...)
+CREATE TABLE IF NOT EXISTS modifier_options (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    modifier_group_id BIGINT NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ingredient_id BIGINT REFERENCES ingredients(id) ON DELETE SET NULL, -- Nguyên liệu tiêu hao (nếu có)
+    ingredient_quantity DECIMAL(10,3) NOT NULL DEFAULT 0,
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Attach modifier groups to products
+CREATE TABLE IF NOT EXISTS product_modifier_groups (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    modifier_group_id BIGINT NOT NULL REFERENCES modifier_groups(id) ON DELETE CASCADE,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, modifier_group_id)
+);
+
+-- Selected options per order item, snapshotted for history
+CREATE TABLE IF NOT EXISTS order_item_modifiers (
+    id BIGSERIAL PRIMARY KEY,
+    order_item_id BIGINT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    modifier_option_id BIGINT REFERENCES modifier_options(id) ON DELETE SET NULL,
+    group_name VARCHAR(100) NOT NULL, -- Store for history
+    option_name VARCHAR(100) NOT NULL, -- Store for history
+    price_delta DECIMAL(10,2) NOT NULL DEFAULT 0,
+    ingredient_id BIGINT REFERENCES ingredients(id) ON DELETE SET NULL,
+    ingredient_quantity DECIMAL(10,3) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_modifier_options_group_id ON modifier_options(modifier_group_id);
+CREATE INDEX IF NOT EXISTS idx_product_modifier_groups_product_id ON product_modifier_groups(product_id);
+CREATE INDEX IF NOT EXISTS idx_product_modifier_groups_group_id ON product_modifier_groups(modifier_group_id);
+CREATE INDEX IF NOT EXISTS idx_order_item_modifiers_order_item_id ON order_item_modifiers(order_item_id);
@@ -0,0 +1,17 @@
+-- 013_create_kitchen_display.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_kitchen_tickets_station_status;
+DROP INDEX IF EXISTS idx_kitchen_tickets_order_id;
+DROP INDEX IF EXISTS idx_kitchen_station_products_station_id;
+DROP INDEX IF EXISTS idx_kitchen_stations_default;
+
+-- Drop tables
+DROP TABLE IF EXISTS kitchen_tickets;
+DROP TABLE IF EXISTS kitchen_station_products;
+DROP TABLE IF EXISTS kitchen_stations;
+
+-- Drop enum types
+DROP TYPE IF EXISTS kitchen_item_status;
+
+-- Note: PostgreSQL cannot drop a value from an enum, 'kitchen' stays in user_role
@@ -0,0 +1,56 @@
+-- 013_create_kitchen_display.up.sql
+
+-- Kitchen staff role for the KDS screens
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'kitchen';
+
+-- Create kitchen_item_status enum
+CREATE TYPE kitchen_item_status AS ENUM (
+    'queued',       -- Chờ làm
+    'in_progress',  -- Đang làm
+    'done',         -- Đã xong
+    'bumped'        -- Đã trả món, ẩn khỏi màn hình
+);
+
+-- Create kitchen stations table (Quầy bar, Quầy trà, Topping, ...)
+CREATE TABLE IF NOT EXISTS kitchen_stations (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    is_default BOOLEAN NOT NULL DEFAULT false, -- Nhận các món chưa được định tuyến
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Only one default station
+CREATE UNIQUE INDEX IF NOT EXISTS idx_kitchen_stations_default ON kitchen_stations(is_default) WHERE is_default = true;
+
+-- Route products to a station
+CREATE TABLE IF NOT EXISTS kitchen_station_products (
+    id BIGSERIAL PRIMARY KEY,
+    kitchen_station_id BIGINT NOT NULL REFERENCES kitchen_stations(id) ON DELETE CASCADE,
+    product_id BIGINT UNIQUE NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One ticket per order item on its station
+CREATE TABLE IF NOT EXISTS kitchen_tickets (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    order_item_id BIGINT UNIQUE NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    kitchen_station_id BIGINT NOT NULL REFERENCES kitchen_stations(id),
+    status kitchen_item_status NOT NULL DEFAULT 'queued',
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    bumped_at TIMESTAMP,
+    updated_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_kitchen_station_products_station_id ON kitchen_station_products(kitchen_station_id);
+CREATE INDEX IF NOT EXISTS idx_kitchen_tickets_order_id ON kitchen_tickets(order_id);
+CREATE INDEX IF NOT EXISTS idx_kitchen_tickets_station_status ON kitchen_tickets(kitchen_station_id, status);
@@ -0,0 +1,15 @@
+-- 014_create_payments.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_payments_created_at;
+DROP INDEX IF EXISTS idx_payments_refund_of_id;
+DROP INDEX IF EXISTS idx_payments_order_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS payments;
+DROP TABLE IF EXISTS payment_methods;
+
+-- Drop enum types
+DROP TYPE IF EXISTS payment_type;
+
+COMMENT ON COLUMN orders.payment_status IS NULL;
@@ -0,0 +1,52 @@
+-- 014_create_payments.up.sql
+
+-- Create payment_type enum
+CREATE TYPE payment_type AS ENUM (
+    'payment',  -- Thanh toán
+    'refund'    -- Hoàn tiền
+);
+
+-- Create payment methods table (replaces the hardcoded list)
+CREATE TABLE IF NOT EXISTS payment_methods (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    sort_order INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO payment_methods (code, name, sort_order) VALUES
+    ('cash', 'Tiền mặt', 1),
+    ('card', 'Thẻ tín dụng', 2),
+    ('transfer', 'Chuyển khoản', 3),
+    ('momo', 'MoMo', 4),
+    ('vnpay', 'VNPay', 5)
+ON CONFLICT (code) DO NOTHING;
+
+-- Create payments ledger (one row per tender or refund)
+CREATE TABLE IF NOT EXISTS payments (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    payment_type payment_type NOT NULL DEFAULT 'payment',
+    payment_method VARCHAR(50) NOT NULL REFERENCES payment_methods(code),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    status VARCHAR(20) NOT NULL DEFAULT 'completed', -- pending, completed, failed
+    reference VARCHAR(100), -- Mã giao dịch
+    refund_of_id BIGINT REFERENCES payments(id), -- Giao dịch gốc của khoản hoàn
+    reason TEXT, -- Lý do hoàn tiền
+    notes TEXT,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id);
+CREATE INDEX IF NOT EXISTS idx_payments_refund_of_id ON payments(refund_of_id);
+CREATE INDEX IF NOT EXISTS idx_payments_created_at ON payments(created_at);
+
+-- orders.payment_status is now derived from the ledger:
+-- pending, partially_paid, paid, refunded (failed kept for legacy rows)
+COMMENT ON COLUMN orders.payment_status IS 'pending, partially_paid, paid, refunded, failed - derived from payments';
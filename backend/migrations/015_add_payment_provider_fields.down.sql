@@ -0,0 +1,7 @@
+-- 015_add_payment_provider_fields.down.sql
+
+DROP INDEX IF EXISTS idx_payments_reference;
+DROP INDEX IF EXISTS idx_payments_gateway_reference;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS pay_url;
+ALTER TABLE payments DROP COLUMN IF EXISTS provider_transaction_id;
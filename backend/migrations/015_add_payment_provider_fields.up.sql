@@ -0,0 +1,10 @@
+-- 015_add_payment_provider_fields.up.sql
+
+-- Gateway (MoMo, VNPay, ...) transaction fields
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS provider_transaction_id VARCHAR(100); -- Mã giao dịch phía cổng thanh toán
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS pay_url TEXT; -- Link thanh toán trả về từ cổng
+
+-- Callbacks/IPNs look payments up by reference
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_gateway_reference ON payments(payment_method, reference)
+    WHERE payment_type = 'payment' AND pay_url IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_payments_reference ON payments(reference);
@@ -0,0 +1,13 @@
+-- 016_create_shipper_accounts.down.sql
+
+DROP INDEX IF EXISTS idx_shippers_user_id;
+
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS rejection_reason;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS rejected_by;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS accepted_at;
+
+-- Remove shipper accounts before unlinking them
+DELETE FROM users WHERE id IN (SELECT user_id FROM shippers WHERE user_id IS NOT NULL);
+ALTER TABLE shippers DROP COLUMN IF EXISTS user_id;
+
+-- Note: PostgreSQL cannot drop a value from an enum, 'shipper' stays in user_role
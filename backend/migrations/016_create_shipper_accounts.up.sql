@@ -0,0 +1,14 @@
+-- 016_create_shipper_accounts.up.sql
+
+-- Add shipper role for the shipper mobile app
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'shipper';
+
+-- Link shippers to their login account
+ALTER TABLE shippers ADD COLUMN IF NOT EXISTS user_id BIGINT UNIQUE REFERENCES users(id) ON DELETE SET NULL;
+
+-- Shipper accept/reject of assigned deliveries
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS accepted_at TIMESTAMP; -- Thời điểm shipper nhận đơn
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS rejected_by BIGINT REFERENCES shippers(id); -- Shipper từ chối gần nhất
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS rejection_reason TEXT; -- Lý do từ chối
+
+CREATE INDEX IF NOT EXISTS idx_shippers_user_id ON shippers(user_id);
@@ -0,0 +1,14 @@
+-- 017_create_delivery_status_history.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_delivery_status_history_created_at;
+DROP INDEX IF EXISTS idx_delivery_status_history_delivery_order_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS delivery_status_history;
+
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS cancelled_at;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS failed_at;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS in_transit_at;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS picked_up_at;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS assigned_at;
@@ -0,0 +1,30 @@
+-- 017_create_delivery_status_history.up.sql
+
+-- Timestamp per delivery status transition (delivered uses actual_delivery_time)
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS assigned_at TIMESTAMP;
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS picked_up_at TIMESTAMP;
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS in_transit_at TIMESTAMP;
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS failed_at TIMESTAMP;
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS cancelled_at TIMESTAMP;
+
+-- Create delivery status history table for tracking
+CREATE TABLE IF NOT EXISTS delivery_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    delivery_order_id BIGINT NOT NULL REFERENCES delivery_orders(id) ON DELETE CASCADE,
+    status delivery_status NOT NULL,
+    previous_status delivery_status,
+    notes TEXT, -- Ghi chú khi thay đổi status
+    changed_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_status_history_delivery_order_id ON delivery_status_history(delivery_order_id);
+CREATE INDEX IF NOT EXISTS idx_delivery_status_history_created_at ON delivery_status_history(created_at);
+
+-- Backfill: current status of existing deliveries
+INSERT INTO delivery_status_history (delivery_order_id, status, changed_by, created_at)
+SELECT id, status, updated_by, updated_at
+FROM delivery_orders d
+WHERE NOT EXISTS (SELECT 1 FROM delivery_status_history h WHERE h.delivery_order_id = d.id);
+
+UPDATE delivery_orders SET assigned_at = created_at WHERE assigned_at IS NULL AND shipper_id IS NOT NULL;
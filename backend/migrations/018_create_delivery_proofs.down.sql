@@ -0,0 +1,10 @@
+-- 018_create_delivery_proofs.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_delivery_proofs_delivery_order_id;
+
+-- Drop tables
+DROP TABLE IF EXISTS delivery_proofs;
+
+-- Drop enum types
+DROP TYPE IF EXISTS delivery_proof_type;
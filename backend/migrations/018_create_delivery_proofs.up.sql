@@ -0,0 +1,26 @@
+-- 018_create_delivery_proofs.up.sql
+
+-- Create delivery_proof_type enum
+CREATE TYPE delivery_proof_type AS ENUM (
+    'photo',     -- Ảnh chụp lúc giao hàng
+    'signature'  -- Chữ ký người nhận (PNG)
+);
+
+-- Create delivery proofs table (photos/signatures attached to a delivery)
+CREATE TABLE IF NOT EXISTS delivery_proofs (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    delivery_order_id BIGINT NOT NULL REFERENCES delivery_orders(id) ON DELETE CASCADE,
+    proof_type delivery_proof_type NOT NULL,
+    file_key VARCHAR(255) NOT NULL, -- Key trong storage
+    file_url TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    file_size BIGINT NOT NULL DEFAULT 0,
+    latitude DECIMAL(9,6), -- Vị trí lúc chụp/ký
+    longitude DECIMAL(9,6),
+    uploaded_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((latitude IS NULL) = (longitude IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_proofs_delivery_order_id ON delivery_proofs(delivery_order_id);
@@ -0,0 +1,16 @@
+-- 019_create_shipper_cash_settlements.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_delivery_orders_cod_outstanding;
+DROP INDEX IF EXISTS idx_delivery_orders_cod_settlement_id;
+DROP INDEX IF EXISTS idx_shipper_cash_settlements_created_at;
+DROP INDEX IF EXISTS idx_shipper_cash_settlements_shipper_id;
+
+-- Drop columns
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS cod_settlement_id;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS cod_settled_at;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS cod_collected_at;
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS cod_amount;
+
+-- Drop tables
+DROP TABLE IF EXISTS shipper_cash_settlements;
@@ -0,0 +1,27 @@
+-- 019_create_shipper_cash_settlements.up.sql
+
+-- Create shipper cash settlements table (shipper hands COD cash over to the store)
+CREATE TABLE IF NOT EXISTS shipper_cash_settlements (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    shipper_id BIGINT NOT NULL REFERENCES shippers(id),
+    expected_amount DECIMAL(10,2) NOT NULL, -- Tổng tiền thu hộ của các đơn được đối soát
+    received_amount DECIMAL(10,2) NOT NULL CHECK (received_amount >= 0), -- Tiền admin thực nhận
+    discrepancy DECIMAL(10,2) NOT NULL, -- received - expected (âm là thiếu)
+    delivery_count INTEGER NOT NULL DEFAULT 0,
+    notes TEXT,
+    settled_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Cash on delivery per delivery order
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS cod_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (cod_amount >= 0);
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS cod_collected_at TIMESTAMP;
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS cod_settled_at TIMESTAMP;
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS cod_settlement_id BIGINT REFERENCES shipper_cash_settlements(id);
+
+CREATE INDEX IF NOT EXISTS idx_shipper_cash_settlements_shipper_id ON shipper_cash_settlements(shipper_id);
+CREATE INDEX IF NOT EXISTS idx_shipper_cash_settlements_created_at ON shipper_cash_settlements(created_at);
+CREATE INDEX IF NOT EXISTS idx_delivery_orders_cod_settlement_id ON delivery_orders(cod_settlement_id);
+CREATE INDEX IF NOT EXISTS idx_delivery_orders_cod_outstanding ON delivery_orders(shipper_id)
+    WHERE status = 'delivered' AND cod_amount > 0 AND cod_settlement_id IS NULL;
@@ -0,0 +1,13 @@
+-- 020_create_shipper_availability.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_delivery_orders_shipper_status;
+DROP INDEX IF EXISTS idx_shippers_availability;
+
+-- Drop columns
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_zone;
+ALTER TABLE shippers DROP COLUMN IF EXISTS last_assigned_at;
+ALTER TABLE shippers DROP COLUMN IF EXISTS shift_started_at;
+ALTER TABLE shippers DROP COLUMN IF EXISTS zone;
+ALTER TABLE shippers DROP COLUMN IF EXISTS max_concurrent_deliveries;
+ALTER TABLE shippers DROP COLUMN IF EXISTS availability;
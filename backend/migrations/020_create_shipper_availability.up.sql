@@ -0,0 +1,16 @@
+-- 020_create_shipper_availability.up.sql
+
+-- Shipper availability used by the automatic assignment engine
+ALTER TABLE shippers ADD COLUMN IF NOT EXISTS availability VARCHAR(20) NOT NULL DEFAULT 'off_shift'
+    CHECK (availability IN ('on_shift', 'off_shift')); -- Đang trong ca / nghỉ ca
+ALTER TABLE shippers ADD COLUMN IF NOT EXISTS max_concurrent_deliveries INTEGER NOT NULL DEFAULT 3
+    CHECK (max_concurrent_deliveries > 0); -- Số đơn giao tối đa cùng lúc
+ALTER TABLE shippers ADD COLUMN IF NOT EXISTS zone VARCHAR(50); -- Khu vực shipper phụ trách
+ALTER TABLE shippers ADD COLUMN IF NOT EXISTS shift_started_at TIMESTAMP;
+ALTER TABLE shippers ADD COLUMN IF NOT EXISTS last_assigned_at TIMESTAMP;
+
+-- Delivery zone of an order, matched against shippers.zone
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_zone VARCHAR(50);
+
+CREATE INDEX IF NOT EXISTS idx_shippers_availability ON shippers(availability) WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_delivery_orders_shipper_status ON delivery_orders(shipper_id, status);
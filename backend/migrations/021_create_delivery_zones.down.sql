@@ -0,0 +1,16 @@
+-- 021_create_delivery_zones.down.sql
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_shipping_fee_audits_order_id;
+DROP INDEX IF EXISTS idx_delivery_zones_active_priority;
+
+-- Drop tables
+DROP TABLE IF EXISTS shipping_fee_audits;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_fee_source;
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_distance_km;
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_longitude;
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_latitude;
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_address;
+
+DROP TABLE IF EXISTS delivery_zones;
@@ -0,0 +1,46 @@
+-- 021_create_delivery_zones.up.sql
+
+-- Create delivery zones table (radius around the store or polygon)
+CREATE TABLE IF NOT EXISTS delivery_zones (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    code VARCHAR(50) UNIQUE NOT NULL, -- Khớp với orders.delivery_zone và shippers.zone
+    name VARCHAR(100) NOT NULL,
+    zone_type VARCHAR(20) NOT NULL CHECK (zone_type IN ('radius', 'polygon')),
+    radius_km DECIMAL(6,2), -- Bán kính tính từ cửa hàng (zone_type = radius)
+    polygon JSONB, -- Danh sách điểm [{"lat":..,"lng":..}] (zone_type = polygon)
+    fee_type VARCHAR(20) NOT NULL CHECK (fee_type IN ('flat', 'per_km')),
+    base_fee DECIMAL(10,2) NOT NULL DEFAULT 0, -- Phí cố định (flat) hoặc phí mở cửa (per_km)
+    per_km_fee DECIMAL(10,2) NOT NULL DEFAULT 0,
+    free_above_subtotal DECIMAL(10,2), -- Miễn phí giao hàng khi tạm tính từ mức này
+    priority INTEGER NOT NULL DEFAULT 0, -- Vùng có priority nhỏ hơn được xét trước
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (zone_type <> 'radius' OR radius_km > 0),
+    CHECK (zone_type <> 'polygon' OR polygon IS NOT NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_delivery_zones_active_priority ON delivery_zones(priority, id) WHERE is_active = true;
+
+-- Delivery address of an order and how its shipping fee was set
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_address TEXT;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_latitude DECIMAL(9,6);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_longitude DECIMAL(9,6);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_distance_km DECIMAL(8,2);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_fee_source VARCHAR(20) NOT NULL DEFAULT 'manual'; -- auto, manual, override
+
+-- Audit of manual shipping fee overrides
+CREATE TABLE IF NOT EXISTS shipping_fee_audits (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    calculated_fee DECIMAL(10,2), -- Phí hệ thống tính (NULL nếu không có tọa độ)
+    previous_fee DECIMAL(10,2),
+    applied_fee DECIMAL(10,2) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipping_fee_audits_order_id ON shipping_fee_audits(order_id);
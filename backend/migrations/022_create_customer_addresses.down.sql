@@ -0,0 +1,14 @@
+-- 022_create_customer_addresses.down.sql
+
+ALTER TABLE delivery_orders DROP COLUMN IF EXISTS delivery_address;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_province;
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_district;
+ALTER TABLE orders DROP COLUMN IF EXISTS delivery_ward;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_address_id;
+
+-- Drop indexes
+DROP INDEX IF EXISTS idx_customer_addresses_default;
+DROP INDEX IF EXISTS idx_customer_addresses_user_id;
+
+DROP TABLE IF EXISTS customer_addresses;
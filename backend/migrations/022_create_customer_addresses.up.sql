@@ -0,0 +1,31 @@
+-- 022_create_customer_addresses.up.sql
+
+-- Saved delivery addresses of customers
+CREATE TABLE IF NOT EXISTS customer_addresses (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    label VARCHAR(50) NOT NULL DEFAULT '', -- Nhà riêng, công ty...
+    address_line VARCHAR(255) NOT NULL, -- Số nhà, tên đường
+    ward VARCHAR(100) NOT NULL DEFAULT '', -- Phường/xã
+    district VARCHAR(100) NOT NULL DEFAULT '', -- Quận/huyện
+    province VARCHAR(100) NOT NULL DEFAULT '', -- Tỉnh/thành phố
+    latitude DECIMAL(9,6),
+    longitude DECIMAL(9,6),
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_customer_addresses_user_id ON customer_addresses(user_id);
+-- Mỗi khách hàng chỉ có một địa chỉ mặc định
+CREATE UNIQUE INDEX IF NOT EXISTS idx_customer_addresses_default ON customer_addresses(user_id) WHERE is_default = true;
+
+-- Address snapshot of an order (delivery_address, delivery_latitude and delivery_longitude were added in 021)
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_address_id BIGINT REFERENCES customer_addresses(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_ward VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_district VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS delivery_province VARCHAR(100);
+
+-- Address snapshot of a delivery, copied from its order when the delivery is created
+ALTER TABLE delivery_orders ADD COLUMN IF NOT EXISTS delivery_address JSONB;
@@ -0,0 +1,3 @@
+-- 023_add_customer_registration.down.sql
+
+ALTER TABLE users DROP COLUMN IF EXISTS registered_at;
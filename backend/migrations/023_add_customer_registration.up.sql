@@ -0,0 +1,4 @@
+-- 023_add_customer_registration.up.sql
+
+-- When a customer registered (or claimed their guest account) on the portal
+ALTER TABLE users ADD COLUMN IF NOT EXISTS registered_at TIMESTAMP;
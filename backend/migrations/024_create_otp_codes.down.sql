@@ -0,0 +1,5 @@
+-- 024_create_otp_codes.down.sql
+
+DROP INDEX IF EXISTS idx_otp_codes_phone;
+
+DROP TABLE IF EXISTS otp_codes;
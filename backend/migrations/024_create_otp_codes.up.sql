@@ -0,0 +1,15 @@
+-- 024_create_otp_codes.up.sql
+
+-- One-time login codes sent by SMS to customers and shippers
+CREATE TABLE IF NOT EXISTS otp_codes (
+    id BIGSERIAL PRIMARY KEY,
+    purpose VARCHAR(30) NOT NULL CHECK (purpose IN ('customer_login', 'shipper_login')),
+    phone VARCHAR(20) NOT NULL, -- Số điện thoại đã chuẩn hóa (0xxxxxxxxx)
+    code_hash VARCHAR(255) NOT NULL, -- Không lưu mã gốc
+    attempts INTEGER NOT NULL DEFAULT 0, -- Số lần nhập mã
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP, -- Đã dùng để đăng nhập
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_otp_codes_phone ON otp_codes(purpose, phone, created_at DESC);
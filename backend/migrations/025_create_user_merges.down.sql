@@ -0,0 +1,8 @@
+-- 025_create_user_merges.down.sql
+
+DROP INDEX IF EXISTS idx_user_merges_source_user_id;
+DROP INDEX IF EXISTS idx_user_merges_target_user_id;
+
+DROP TABLE IF EXISTS user_merges;
+
+ALTER TABLE users DROP COLUMN IF EXISTS merged_into_id;
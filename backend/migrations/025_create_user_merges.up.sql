@@ -0,0 +1,21 @@
+-- 025_create_user_merges.up.sql
+
+-- A merged duplicate customer stays (inactive) and points at the customer it was merged into
+ALTER TABLE users ADD COLUMN IF NOT EXISTS merged_into_id BIGINT REFERENCES users(id);
+
+-- Audit of duplicate customer merges
+CREATE TABLE IF NOT EXISTS user_merges (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    source_user_id BIGINT NOT NULL REFERENCES users(id), -- Khách hàng bị gộp
+    target_user_id BIGINT NOT NULL REFERENCES users(id), -- Khách hàng được giữ lại
+    source_snapshot JSONB NOT NULL, -- Thông tin liên hệ của khách bị gộp trước khi gộp
+    moved_orders INTEGER NOT NULL DEFAULT 0,
+    moved_addresses INTEGER NOT NULL DEFAULT 0,
+    reason TEXT,
+    merged_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_merges_target_user_id ON user_merges(target_user_id);
+CREATE INDEX IF NOT EXISTS idx_user_merges_source_user_id ON user_merges(source_user_id);
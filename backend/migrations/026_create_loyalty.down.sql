@@ -0,0 +1,34 @@
+-- 026_create_loyalty.down.sql
+
+CREATE OR REPLACE FUNCTION update_order_total()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE orders
+    SET subtotal = COALESCE((
+        SELECT SUM(total_price)
+        FROM order_items
+        WHERE order_id = COALESCE(NEW.order_id, OLD.order_id)
+    ), 0)
+    WHERE id = COALESCE(NEW.order_id, OLD.order_id);
+
+    UPDATE orders
+    SET total_amount = GREATEST(0, subtotal - COALESCE(discount_amount, 0) - COALESCE(manual_discount_amount, 0) + COALESCE(shipping_fee, 0))
+    WHERE id = COALESCE(NEW.order_id, OLD.order_id);
+
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+DROP INDEX IF EXISTS idx_orders_customer_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS loyalty_points_earned;
+ALTER TABLE orders DROP COLUMN IF EXISTS loyalty_discount_amount;
+ALTER TABLE orders DROP COLUMN IF EXISTS loyalty_points_redeemed;
+ALTER TABLE orders DROP COLUMN IF EXISTS customer_id;
+
+DROP INDEX IF EXISTS idx_loyalty_ledger_order_entry;
+DROP INDEX IF EXISTS idx_loyalty_ledger_user_id;
+DROP TABLE IF EXISTS loyalty_ledger;
+DROP TABLE IF EXISTS loyalty_accounts;
+DROP TABLE IF EXISTS loyalty_product_bonuses;
+DROP TABLE IF EXISTS loyalty_tiers;
+DROP TABLE IF EXISTS loyalty_settings;
@@ -0,0 +1,108 @@
+-- 026_create_loyalty.up.sql
+
+-- Loyalty program settings (single row)
+CREATE TABLE IF NOT EXISTS loyalty_settings (
+    id SMALLINT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    is_enabled BOOLEAN NOT NULL DEFAULT true,
+    points_per_vnd DECIMAL(12,6) NOT NULL DEFAULT 0.0001 CHECK (points_per_vnd >= 0), -- Mặc định 1 điểm cho mỗi 10.000đ
+    point_value DECIMAL(10,2) NOT NULL DEFAULT 100 CHECK (point_value >= 0), -- Số tiền giảm cho mỗi điểm khi đổi
+    max_redeem_percent DECIMAL(5,2) NOT NULL DEFAULT 50 CHECK (max_redeem_percent >= 0 AND max_redeem_percent <= 100), -- % tối đa của tiền hàng được trừ bằng điểm
+    tier_window_days INTEGER NOT NULL DEFAULT 365 CHECK (tier_window_days > 0), -- Hạng tính theo chi tiêu trong số ngày gần nhất
+    updated_by BIGINT REFERENCES users(id),
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO loyalty_settings (id) VALUES (1) ON CONFLICT (id) DO NOTHING;
+
+-- Tier levels, reached by spending in the tier window
+CREATE TABLE IF NOT EXISTS loyalty_tiers (
+    code VARCHAR(20) PRIMARY KEY CHECK (code IN ('member', 'silver', 'gold')),
+    name VARCHAR(50) NOT NULL,
+    min_spent DECIMAL(12,2) NOT NULL DEFAULT 0 CHECK (min_spent >= 0),
+    earn_multiplier DECIMAL(4,2) NOT NULL DEFAULT 1 CHECK (earn_multiplier > 0), -- Hệ số nhân điểm tích lũy
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO loyalty_tiers (code, name, min_spent, earn_multiplier) VALUES
+    ('member', 'Thành viên', 0, 1),
+    ('silver', 'Bạc', 2000000, 1.2),
+    ('gold', 'Vàng', 5000000, 1.5)
+ON CONFLICT (code) DO NOTHING;
+
+-- Extra points per unit sold of a product
+CREATE TABLE IF NOT EXISTS loyalty_product_bonuses (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL UNIQUE REFERENCES products(id) ON DELETE CASCADE,
+    bonus_points INTEGER NOT NULL CHECK (bonus_points > 0),
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Points balance and tier of a customer
+CREATE TABLE IF NOT EXISTS loyalty_accounts (
+    user_id BIGINT PRIMARY KEY REFERENCES users(id),
+    points_balance INTEGER NOT NULL DEFAULT 0 CHECK (points_balance >= 0),
+    lifetime_points INTEGER NOT NULL DEFAULT 0, -- Tổng điểm đã tích lũy
+    tier VARCHAR(20) NOT NULL DEFAULT 'member' REFERENCES loyalty_tiers(code),
+    tier_spent DECIMAL(12,2) NOT NULL DEFAULT 0, -- Chi tiêu trong kỳ xét hạng gần nhất
+    tier_updated_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Points ledger: every change of a balance, points are signed
+CREATE TABLE IF NOT EXISTS loyalty_ledger (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    user_id BIGINT NOT NULL REFERENCES users(id),
+    order_id BIGINT REFERENCES orders(id) ON DELETE SET NULL,
+    entry_type VARCHAR(20) NOT NULL CHECK (entry_type IN ('earn', 'redeem', 'earn_reversal', 'redeem_refund', 'adjustment')),
+    points INTEGER NOT NULL,
+    balance_after INTEGER NOT NULL,
+    note TEXT,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_loyalty_ledger_user_id ON loyalty_ledger(user_id, created_at);
+-- An order earns, redeems and is reversed at most once
+CREATE UNIQUE INDEX IF NOT EXISTS idx_loyalty_ledger_order_entry ON loyalty_ledger(order_id, entry_type)
+    WHERE order_id IS NOT NULL AND entry_type <> 'adjustment';
+
+-- The customer an order belongs to (the staff member is created_by) and its points
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS customer_id BIGINT REFERENCES users(id);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS loyalty_points_redeemed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS loyalty_discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS loyalty_points_earned INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_orders_customer_id ON orders(customer_id);
+
+-- Keep the points discount when order items change
+CREATE OR REPLACE FUNCTION update_order_total()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Calculate subtotal from order items
+    UPDATE orders
+    SET subtotal = COALESCE((
+        SELECT SUM(total_price)
+        FROM order_items
+        WHERE order_id = COALESCE(NEW.order_id, OLD.order_id)
+    ), 0)
+    WHERE id = COALESCE(NEW.order_id, OLD.order_id);
+
+    -- Calculate total amount (subtotal - discount_amount - manual_discount_amount - loyalty_discount_amount + shipping_fee)
+    UPDATE orders
+    SET total_amount = GREATEST(0, subtotal - COALESCE(discount_amount, 0) - COALESCE(manual_discount_amount, 0)
+        - COALESCE(loyalty_discount_amount, 0) + COALESCE(shipping_fee, 0))
+    WHERE id = COALESCE(NEW.order_id, OLD.order_id);
+
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Existing orders placed by customers themselves belong to them
+UPDATE orders o SET customer_id = o.created_by
+FROM users u
+WHERE u.id = o.created_by AND u.role = 'client' AND o.customer_id IS NULL;
@@ -0,0 +1,34 @@
+-- 027_create_promotions.down.sql
+
+CREATE OR REPLACE FUNCTION update_order_total()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Calculate subtotal from order items
+    UPDATE orders
+    SET subtotal = COALESCE((
+        SELECT SUM(total_price)
+        FROM order_items
+        WHERE order_id = COALESCE(NEW.order_id, OLD.order_id)
+    ), 0)
+    WHERE id = COALESCE(NEW.order_id, OLD.order_id);
+
+    -- Calculate total amount (subtotal - discount_amount - manual_discount_amount - loyalty_discount_amount + shipping_fee)
+    UPDATE orders
+    SET total_amount = GREATEST(0, subtotal - COALESCE(discount_amount, 0) - COALESCE(manual_discount_amount, 0)
+        - COALESCE(loyalty_discount_amount, 0) + COALESCE(shipping_fee, 0))
+    WHERE id = COALESCE(NEW.order_id, OLD.order_id);
+
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS promotion_discount_amount;
+
+DROP INDEX IF EXISTS idx_order_item_promotions_promotion_id;
+DROP INDEX IF EXISTS idx_order_item_promotions_order_item_id;
+DROP INDEX IF EXISTS idx_promotion_variants_promotion_id;
+DROP INDEX IF EXISTS idx_promotion_products_promotion_id;
+DROP TABLE IF EXISTS order_item_promotions;
+DROP TABLE IF EXISTS promotion_variants;
+DROP TABLE IF EXISTS promotion_products;
+DROP TABLE IF EXISTS promotions;
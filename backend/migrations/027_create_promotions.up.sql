@@ -0,0 +1,80 @@
+-- 027_create_promotions.up.sql
+
+-- Automatic promotions evaluated against the whole cart
+CREATE TABLE IF NOT EXISTS promotions (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    promotion_type VARCHAR(20) NOT NULL
+        CHECK (promotion_type IN ('buy_x_get_y', 'stamp_card', 'free_cheapest')), -- Mua X tặng Y / thẻ tích ly / tặng món rẻ nhất
+    buy_quantity INTEGER NOT NULL CHECK (buy_quantity > 0), -- Số món cần mua (free_cheapest: số món tối thiểu)
+    get_quantity INTEGER NOT NULL DEFAULT 1 CHECK (get_quantity > 0), -- Số món được tặng
+    discount_percent DECIMAL(5,2) NOT NULL DEFAULT 100 CHECK (discount_percent > 0 AND discount_percent <= 100), -- % giảm của món được tặng
+    priority INTEGER NOT NULL DEFAULT 0, -- Khuyến mãi ưu tiên cao hơn được xét trước
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    valid_from TIMESTAMP,
+    valid_until TIMESTAMP,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Restrict a promotion to specific products
+CREATE TABLE IF NOT EXISTS promotion_products (
+    id BIGSERIAL PRIMARY KEY,
+    promotion_id BIGINT NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    UNIQUE (promotion_id, product_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Restrict a promotion to specific variants
+CREATE TABLE IF NOT EXISTS promotion_variants (
+    id BIGSERIAL PRIMARY KEY,
+    promotion_id BIGINT NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    variant_id BIGINT NOT NULL REFERENCES variants(id) ON DELETE CASCADE,
+    UNIQUE (promotion_id, variant_id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Promotion applied to an order line, also the stamp history of stamp cards
+CREATE TABLE IF NOT EXISTS order_item_promotions (
+    id BIGSERIAL PRIMARY KEY,
+    order_item_id BIGINT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    promotion_id BIGINT NOT NULL REFERENCES promotions(id),
+    promotion_name VARCHAR(100) NOT NULL, -- Snapshot tên khuyến mãi
+    free_quantity INTEGER NOT NULL CHECK (free_quantity > 0), -- Số món được tặng trên dòng
+    discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS promotion_discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_promotion_products_promotion_id ON promotion_products(promotion_id);
+CREATE INDEX IF NOT EXISTS idx_promotion_variants_promotion_id ON promotion_variants(promotion_id);
+CREATE INDEX IF NOT EXISTS idx_order_item_promotions_order_item_id ON order_item_promotions(order_item_id);
+CREATE INDEX IF NOT EXISTS idx_order_item_promotions_promotion_id ON order_item_promotions(promotion_id);
+
+-- Keep the promotion discount when order items change
+CREATE OR REPLACE FUNCTION update_order_total()
+RETURNS TRIGGER AS $$
+BEGIN
+    -- Calculate subtotal from order items
+    UPDATE orders
+    SET subtotal = COALESCE((
+        SELECT SUM(total_price)
+        FROM order_items
+        WHERE order_id = COALESCE(NEW.order_id, OLD.order_id)
+    ), 0)
+    WHERE id = COALESCE(NEW.order_id, OLD.order_id);
+
+    -- Calculate total amount (subtotal - all discounts + shipping_fee)
+    UPDATE orders
+    SET total_amount = GREATEST(0, subtotal - COALESCE(discount_amount, 0) - COALESCE(manual_discount_amount, 0)
+        - COALESCE(promotion_discount_amount, 0) - COALESCE(loyalty_discount_amount, 0) + COALESCE(shipping_fee, 0))
+    WHERE id = COALESCE(NEW.order_id, OLD.order_id);
+
+    RETURN COALESCE(NEW, OLD);
+END;
+$$ LANGUAGE plpgsql;
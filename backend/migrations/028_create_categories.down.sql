@@ -0,0 +1,9 @@
+-- 028_create_categories.down.sql
+
+DROP INDEX IF EXISTS idx_products_category_id;
+DROP INDEX IF EXISTS idx_categories_parent_id;
+
+ALTER TABLE products DROP COLUMN IF EXISTS sort_order;
+ALTER TABLE products DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,11 @@
+-- 029_add_availability.down.sql
+
+DROP INDEX IF EXISTS idx_variants_available_at;
+DROP INDEX IF EXISTS idx_products_available_at;
+
+ALTER TABLE variants DROP COLUMN IF EXISTS auto_availability;
+ALTER TABLE variants DROP COLUMN IF EXISTS available_at;
+ALTER TABLE variants DROP COLUMN IF EXISTS is_available;
+
+ALTER TABLE products DROP COLUMN IF EXISTS available_at;
+ALTER TABLE products DROP COLUMN IF EXISTS is_available;
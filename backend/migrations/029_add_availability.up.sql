@@ -0,0 +1,14 @@
+-- 029_add_availability.up.sql
+
+-- Staff can mark a product or a single variant as sold out ("86"), optionally
+-- with the time it is expected back. available_at is cleared once it passes.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS is_available BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE products ADD COLUMN IF NOT EXISTS available_at TIMESTAMP;
+
+ALTER TABLE variants ADD COLUMN IF NOT EXISTS is_available BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE variants ADD COLUMN IF NOT EXISTS available_at TIMESTAMP;
+-- Tự hết hàng khi tồn kho nguyên liệu không đủ làm 1 phần theo công thức
+ALTER TABLE variants ADD COLUMN IF NOT EXISTS auto_availability BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS idx_products_available_at ON products(available_at) WHERE NOT is_available;
+CREATE INDEX IF NOT EXISTS idx_variants_available_at ON variants(available_at) WHERE NOT is_available;
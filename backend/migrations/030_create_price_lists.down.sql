@@ -0,0 +1,12 @@
+-- 030_create_price_lists.down.sql
+
+ALTER TABLE order_items DROP COLUMN IF EXISTS base_price;
+ALTER TABLE order_items DROP COLUMN IF EXISTS price_list_name;
+ALTER TABLE order_items DROP COLUMN IF EXISTS price_list_id;
+
+DROP INDEX IF EXISTS idx_price_list_items_price_list_id;
+DROP INDEX IF EXISTS idx_price_list_schedules_price_list_id;
+
+DROP TABLE IF EXISTS price_list_items;
+DROP TABLE IF EXISTS price_list_schedules;
+DROP TABLE IF EXISTS price_lists;
@@ -0,0 +1,52 @@
+-- 030_create_price_lists.up.sql
+
+-- Scheduled prices (happy hour, breakfast menu...). When several lists are in
+-- effect for a variant the one with the highest priority wins.
+CREATE TABLE IF NOT EXISTS price_lists (
+    id BIGSERIAL PRIMARY KEY,
+    public_id UUID UNIQUE NOT NULL DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL,
+    description TEXT,
+    priority INTEGER NOT NULL DEFAULT 0,
+    is_exclusive BOOLEAN NOT NULL DEFAULT false, -- Món trong bảng giá chỉ bán khi bảng giá đang áp dụng (menu theo giờ)
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    valid_from TIMESTAMP,
+    valid_until TIMESTAMP,
+    created_by BIGINT REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Weekly time windows of a price list, in store local time. A list without
+-- windows applies all day within its date range.
+CREATE TABLE IF NOT EXISTS price_list_schedules (
+    id BIGSERIAL PRIMARY KEY,
+    price_list_id BIGINT NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    days_mask SMALLINT NOT NULL DEFAULT 127 CHECK (days_mask BETWEEN 1 AND 127), -- Bit 0 = Chủ nhật ... bit 6 = Thứ bảy
+    start_time TIME NOT NULL DEFAULT '00:00',
+    end_time TIME NOT NULL DEFAULT '00:00', -- Nhỏ hơn hoặc bằng start_time = qua nửa đêm
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Prices of a list: a fixed price or a percentage off, for one variant, all
+-- variants of a product or all products of a category (and its subcategories)
+CREATE TABLE IF NOT EXISTS price_list_items (
+    id BIGSERIAL PRIMARY KEY,
+    price_list_id BIGINT NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    variant_id BIGINT REFERENCES variants(id) ON DELETE CASCADE,
+    product_id BIGINT REFERENCES products(id) ON DELETE CASCADE,
+    category_id BIGINT REFERENCES categories(id) ON DELETE CASCADE,
+    price DECIMAL(10,2) CHECK (price >= 0),
+    discount_percent DECIMAL(5,2) CHECK (discount_percent > 0 AND discount_percent <= 100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (num_nonnulls(variant_id, product_id, category_id) = 1),
+    CHECK (price IS NULL OR discount_percent IS NULL)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_list_schedules_price_list_id ON price_list_schedules(price_list_id);
+CREATE INDEX IF NOT EXISTS idx_price_list_items_price_list_id ON price_list_items(price_list_id);
+
+-- Price list applied to an order line, with the regular price it replaced
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS price_list_id BIGINT REFERENCES price_lists(id) ON DELETE SET NULL;
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS price_list_name VARCHAR(100);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS base_price DECIMAL(10,2);
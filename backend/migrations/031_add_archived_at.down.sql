@@ -0,0 +1,11 @@
+-- 031_add_archived_at.down.sql
+
+DROP INDEX IF EXISTS idx_shippers_not_archived;
+DROP INDEX IF EXISTS idx_ingredients_not_archived;
+DROP INDEX IF EXISTS idx_variants_product_id_not_archived;
+DROP INDEX IF EXISTS idx_products_not_archived;
+
+ALTER TABLE shippers DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE ingredients DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE variants DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE products DROP COLUMN IF EXISTS archived_at;
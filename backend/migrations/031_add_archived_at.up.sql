@@ -0,0 +1,14 @@
+-- 031_add_archived_at.up.sql
+
+-- Archiving replaces deleting for rows that orders, deliveries and stock
+-- history point at. Archived rows are hidden from lists and cannot be used in
+-- new orders but keep resolving for history.
+ALTER TABLE products ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE variants ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE ingredients ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE shippers ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_products_not_archived ON products(id) WHERE archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_variants_product_id_not_archived ON variants(product_id) WHERE archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_ingredients_not_archived ON ingredients(id) WHERE archived_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_shippers_not_archived ON shippers(id) WHERE archived_at IS NULL;
@@ -0,0 +1,5 @@
+-- 032_create_variant_price_history.down.sql
+
+DROP INDEX IF EXISTS idx_variant_price_history_variant_id_changed_at;
+
+DROP TABLE IF EXISTS variant_price_history;
@@ -0,0 +1,21 @@
+-- 032_create_variant_price_history.up.sql
+
+-- Regular price of a variant over time, one row per change. Scheduled prices
+-- from price lists are not recorded here.
+CREATE TABLE IF NOT EXISTS variant_price_history (
+    id BIGSERIAL PRIMARY KEY,
+    variant_id BIGINT NOT NULL REFERENCES variants(id) ON DELETE CASCADE,
+    old_price DECIMAL(10,2), -- NULL khi tạo variant
+    new_price DECIMAL(10,2) NOT NULL,
+    changed_by BIGINT REFERENCES users(id),
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_variant_price_history_variant_id_changed_at ON variant_price_history(variant_id, changed_at);
+
+-- Seed the current prices. Earlier changes were not recorded, the current price
+-- is only known to hold since the variant was last updated.
+INSERT INTO variant_price_history (variant_id, old_price, new_price, changed_at)
+SELECT v.id, NULL, v.price, COALESCE(v.updated_at, v.created_at, CURRENT_TIMESTAMP)
+FROM variants v
+WHERE NOT EXISTS (SELECT 1 FROM variant_price_history h WHERE h.variant_id = v.id);
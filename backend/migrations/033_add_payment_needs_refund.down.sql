@@ -0,0 +1,6 @@
+-- 033_add_payment_needs_refund.down.sql
+
+DROP INDEX IF EXISTS idx_payments_pending;
+DROP INDEX IF EXISTS idx_payments_needs_refund;
+
+ALTER TABLE payments DROP COLUMN IF EXISTS needs_refund;
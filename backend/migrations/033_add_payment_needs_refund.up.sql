@@ -0,0 +1,10 @@
+-- 033_add_payment_needs_refund.up.sql
+
+-- A gateway payment that settles after the order was already paid by other
+-- tenders is kept as completed (the money was taken) and flagged for refund
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS needs_refund BOOLEAN NOT NULL DEFAULT false; -- Thanh toán vượt số tiền còn lại, cần hoàn tiền
+
+CREATE INDEX IF NOT EXISTS idx_payments_needs_refund ON payments(needs_refund) WHERE needs_refund;
+
+-- Pending gateway checkouts hold their amount against the order balance
+CREATE INDEX IF NOT EXISTS idx_payments_pending ON payments(order_id, created_at) WHERE status = 'pending';
@@ -0,0 +1,3 @@
+-- 034_normalize_discount_usage_phones.down.sql
+
+-- Phone normalization cannot be undone, the original formatting is not kept
@@ -0,0 +1,7 @@
+-- 034_normalize_discount_usage_phones.up.sql
+
+-- Per-customer discount limits count usages by normalized phone (digits only,
+-- +84 written as a leading 0), bring existing redemptions in line
+UPDATE discount_code_usages
+SET customer_phone = regexp_replace(regexp_replace(customer_phone, '\D', '', 'g'), '^84(\d{9})$', '0\1')
+WHERE customer_phone IS NOT NULL;
@@ -0,0 +1,7 @@
+-- 035_add_customer_register_otp.down.sql
+
+DELETE FROM otp_codes WHERE purpose = 'customer_register';
+
+ALTER TABLE otp_codes DROP CONSTRAINT IF EXISTS otp_codes_purpose_check;
+ALTER TABLE otp_codes ADD CONSTRAINT otp_codes_purpose_check
+    CHECK (purpose IN ('customer_login', 'shipper_login'));
@@ -0,0 +1,6 @@
+-- 035_add_customer_register_otp.up.sql
+
+-- Registering a customer account requires an OTP sent to the phone
+ALTER TABLE otp_codes DROP CONSTRAINT IF EXISTS otp_codes_purpose_check;
+ALTER TABLE otp_codes ADD CONSTRAINT otp_codes_purpose_check
+    CHECK (purpose IN ('customer_login', 'customer_register', 'shipper_login'));
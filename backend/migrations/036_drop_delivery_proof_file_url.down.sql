@@ -0,0 +1,3 @@
+-- 036_drop_delivery_proof_file_url.down.sql
+
+ALTER TABLE delivery_proofs ADD COLUMN IF NOT EXISTS file_url TEXT NOT NULL DEFAULT '';
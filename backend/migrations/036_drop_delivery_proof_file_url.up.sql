@@ -0,0 +1,6 @@
+-- 036_drop_delivery_proof_file_url.up.sql
+
+-- Proof files are no longer served from a public URL, they are streamed by
+-- GET /api/admin/deliveries/:id/proofs/:proofId/file and
+-- GET /api/shipper/deliveries/:id/proofs/:proofId/file after an access check
+ALTER TABLE delivery_proofs DROP COLUMN IF EXISTS file_url;
@@ -0,0 +1,7 @@
+-- 037_restrict_discount_code_usage_delete.up.sql
+
+-- Deleting a used discount code must not erase its redemption history,
+-- used codes are deactivated instead
+ALTER TABLE discount_code_usages DROP CONSTRAINT IF EXISTS discount_code_usages_discount_code_id_fkey;
+ALTER TABLE discount_code_usages ADD CONSTRAINT discount_code_usages_discount_code_id_fkey
+    FOREIGN KEY (discount_code_id) REFERENCES discount_codes(id) ON DELETE RESTRICT;
@@ -18,6 +18,7 @@ import OrderList from "../../organisms/OrderList";
 import Page from "../../common/Page";
 import { useRef } from "react";
 import { statusConfig } from "../../../config/orderStatusConfig";
+import { getToken } from "../../../utils/auth";
 
 const OrderStatusPage = () => {
   const bgColor = useColorModeValue("white", "gray.800");
@@ -63,7 +64,7 @@ const OrderStatusPage = () => {
   // WebSocket realtime logic
   useEffect(() => {
     const ws = new window.WebSocket(
-      `${import.meta.env.VITE_WS_URL || "ws://localhost:8080"}/ws?role=admin`
+      `${import.meta.env.VITE_WS_URL || "ws://localhost:8080"}/ws?token=${encodeURIComponent(getToken() || "")}`
     );
     ws.onopen = () => {
       console.log("[WebSocket] Opened");